@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var appendCmd = &cobra.Command{
+	Use:   "append",
+	Short: "Appends additional resources to an existing backup",
+	Long:  "Backs up additional resources of the Kafka cluster (for example the Secrets skipped in the original backup) and appends them to an existing backup while updating its manifest and checksums.",
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := backuper.NewAppender(cmd)
+		if err != nil {
+			slog.Error("Failed to create appender", "error", err)
+			exit(1)
+		}
+		defer a.Close()
+
+		slog.Info("Starting to append resources to the backup", "file", a.BackupFileName, "add", a.Add, "name", a.Name, "namespace", a.Namespace)
+
+		if err := a.Append(); err != nil {
+			slog.Error("Failed to append resources to the backup", "error", err)
+			a.Discard()
+			exit(1)
+		}
+
+		if err := a.Upload(); err != nil {
+			slog.Error("Failed to upload the backup to the storage", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Resources were appended to the backup", "file", a.BackupFileName, "add", a.Add)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(appendCmd)
+
+	appendCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
+	utils.AddConnectionFlags(appendCmd.Flags())
+	appendCmd.Flags().String("namespace", "", "Namespace of the Kafka cluster. If not specified, the namespace from the backup manifest is used.")
+	appendCmd.Flags().String("name", "", "Name of the Kafka cluster. If not specified, the name from the backup manifest is used.")
+	appendCmd.Flags().String("filename", "", "The name of the backup file to which the resources should be appended")
+	_ = appendCmd.MarkFlagRequired("filename")
+	appendCmd.Flags().StringSlice("add", nil, "Resources which should be appended to the backup. Supported values are ca-secrets, user-secrets, cluster-layout, listener-certificates, authentication-secrets, config-maps, referenced-secrets, external-connectivity, entity-operator, and rebalances.")
+	_ = appendCmd.MarkFlagRequired("add")
+	appendCmd.Flags().String("storage", "", "Location where the updated backup should be uploaded. Local directories, file:// URLs, HTTP(S) URLs, and URLs handled by storage plugins are supported. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Kafka cluster.")
+	appendCmd.Flags().StringArray("storage-header", nil, "HTTP header in the <name>: <value> format added to the requests to HTTP(S) storages. Environment variables in the value are expanded. Can be used multiple times.")
+	appendCmd.Flags().String("hmac-key-file", "", "File with the secret key used to sign the updated backup manifest. Required when the original backup is signed.")
+	archive.AddPassphraseFlags(appendCmd.Flags(), "File with the passphrase used to encrypt the appended streams selected by the --encrypt-streams option. The existing streams are copied unchanged.")
+	appendCmd.Flags().StringSlice("encrypt-streams", backuper.DefaultEncryptedStreams, "Names or glob patterns of the appended streams which are encrypted when a passphrase is provided. Use * to encrypt all streams.")
+	appendCmd.Flags().Bool("usage-stats", false, "Record anonymized statistics about the updated backup in the backup manifest. If not specified, the statistics of the original backup are kept. The statistics are never sent anywhere.")
+	appendCmd.Flags().Uint32("timeout", 0, "Timeout for backing up the appended resources. When not set or set to 0, the backup is not limited by any timeout. In milliseconds.")
+	appendCmd.Flags().Bool("skip-metadata-cleansing", false, "Skips cleansing of metadata of the appended resources")
+	appendCmd.Flags().StringSlice("preserved-annotations", utils.DefaultPreservedAnnotations, "Transient annotations of the Kafka, KafkaConnect, KafkaMirrorMaker2, KafkaMirrorMaker, and KafkaBridge resources which are preserved when cleansing the metadata. Only the transient annotations such as strimzi.io/restart-connector are removed, all other annotations are always preserved.")
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -30,9 +34,20 @@ func init() {
 	rootCmd.AddCommand(backupCmd)
 
 	backupCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
+	utils.AddConnectionFlags(backupCmd.PersistentFlags())
 	backupCmd.PersistentFlags().String("namespace", "", "Namespace of the cluster to backup. If not specified, defaults to the namespace from your Kubernetes configuration.")
 	backupCmd.PersistentFlags().String("name", "", "Name of the cluster to backup")
-	_ = backupCmd.MarkPersistentFlagRequired("name")
 	backupCmd.PersistentFlags().String("filename", "", "The name of the resulting backup file")
+	backupCmd.PersistentFlags().String("storage", "", "Location where the backup should be uploaded after it is created. Local directories, file:// URLs, HTTP(S) URLs, and URLs handled by storage plugins are supported. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Kafka cluster.")
+	backupCmd.PersistentFlags().StringArray("storage-header", nil, "HTTP header in the <name>: <value> format added to the requests to HTTP(S) storages. Environment variables in the value are expanded. Can be used multiple times.")
+	backupCmd.PersistentFlags().Bool("stream-upload", false, "Upload the backup into the storage configured with the --storage option while it is being created instead of after it is complete. The upload slows down the backup when the storage cannot keep up, so the data do not pile up in memory. The local backup file is still written. The upload of a failed backup is aborted.")
+	backupCmd.PersistentFlags().String("hmac-key-file", "", "File with the secret key used to sign the backup manifest and the digests of all streams with HMAC-SHA256, so that any tampering with the backup can be detected before it is restored")
+	archive.AddPassphraseFlags(backupCmd.PersistentFlags(), "File with the passphrase used to encrypt the streams selected by the --encrypt-streams option with AES-256-GCM. If no passphrase is provided, the backup is not encrypted.")
+	backupCmd.PersistentFlags().StringSlice("encrypt-streams", backuper.DefaultEncryptedStreams, "Names or glob patterns of the streams which are encrypted when a passphrase is provided. Use * to encrypt all streams. The manifest is never encrypted.")
+	events.AddFlags(backupCmd.PersistentFlags())
+	backupCmd.PersistentFlags().Bool("usage-stats", false, "Record anonymized statistics about the backup (such as its duration, the number of resources, and its size) in the backup manifest. The statistics are never sent anywhere.")
+	backupCmd.PersistentFlags().Uint32("timeout", 0, "Timeout for the whole backup including its upload into the storage. When not set or set to 0, the backup is not limited by any timeout. In milliseconds.")
+	backupCmd.PersistentFlags().Bool("read-only-check", false, "Check before the backup that the service account can read all backed up resources and reject any Kubernetes API request which would modify them during the backup. Options which modify the resources, such as --quiesce, --annotate-kafka, or --track-history, cannot be used.")
 	backupCmd.PersistentFlags().Bool("skip-metadata-cleansing", false, "Skips cleansing of metadata when creating the backup")
+	backupCmd.PersistentFlags().StringSlice("preserved-annotations", utils.DefaultPreservedAnnotations, "Transient annotations of the Kafka, KafkaConnect, KafkaMirrorMaker2, KafkaMirrorMaker, and KafkaBridge resources which are preserved when cleansing the metadata. Only the transient annotations such as strimzi.io/restart-connector are removed, all other annotations are always preserved.")
 }
@@ -0,0 +1,160 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/phases"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var backupAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Backup Strimzi-based Apache Kafka cluster together with the clusters connected to it",
+	Long:  "Backs up the Kafka cluster together with the Kafka Connect, Kafka MirrorMaker 2, and legacy Kafka MirrorMaker clusters and the Kafka Bridge connected to it into a single backup file. The connected clusters are discovered in the namespace of the Kafka cluster through its bootstrap service unless their names are set with the --connect-name, --mirrormaker2-name, --mirrormaker-name, and --bridge-name options. The backup can be restored with the restore all command.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		target := backuper.Target{Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+		data := events.Data{Kind: "all", Name: target.Name}
+
+		b, err := backuper.NewKafkaBackuper(cmd, target)
+		if err != nil {
+			slog.Error("Failed to create backuper", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+		defer b.Close()
+
+		slog.Info("Starting backup of Kafka cluster and the clusters connected to it", "name", b.Name, "namespace", b.Namespace)
+		data.Namespace = b.Namespace
+		emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+		options := kafkaBackupOptions()
+
+		if options.Connect, err = connectedCluster(cmd, "connect", b.DiscoverConnect); err != nil {
+			slog.Error("Failed to find the Kafka Connect cluster connected to the Kafka cluster", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.MirrorMaker2, err = connectedCluster(cmd, "mirrormaker2", b.DiscoverMirrorMaker2); err != nil {
+			slog.Error("Failed to find the Kafka MirrorMaker 2 cluster connected to the Kafka cluster", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.MirrorMaker, err = connectedCluster(cmd, "mirrormaker", b.DiscoverMirrorMaker); err != nil {
+			slog.Error("Failed to find the Kafka MirrorMaker cluster connected to the Kafka cluster", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.Bridge, err = connectedCluster(cmd, "bridge", b.DiscoverBridge); err != nil {
+			slog.Error("Failed to find the Kafka Bridge connected to the Kafka cluster", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.SkipConnectSecrets, err = cmd.Flags().GetBool("skip-connect-secrets"); err != nil {
+			slog.Error("Failed to get the --skip-connect-secrets flag", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.SkipMirrorMaker2Secrets, err = cmd.Flags().GetBool("skip-mirrormaker2-secrets"); err != nil {
+			slog.Error("Failed to get the --skip-mirrormaker2-secrets flag", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.SkipMirrorMakerSecrets, err = cmd.Flags().GetBool("skip-mirrormaker-secrets"); err != nil {
+			slog.Error("Failed to get the --skip-mirrormaker-secrets flag", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if options.SkipBridgeSecrets, err = cmd.Flags().GetBool("skip-bridge-secrets"); err != nil {
+			slog.Error("Failed to get the --skip-bridge-secrets flag", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := phases.Run(context.TODO(), b.Phases(options)); err != nil {
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		slog.Info("Backup of Kafka cluster and the clusters connected to it is complete", "name", b.Name, "namespace", b.Namespace, "connect", options.Connect, "mirrormaker2", options.MirrorMaker2, "mirrormaker", options.MirrorMaker, "bridge", options.Bridge, "filename", b.FileName())
+		data.FileName = b.FileName()
+		emitter.Finished(events.OperationBackup, data, nil)
+	},
+}
+
+// connectedCluster returns the name of the cluster of the given kind which is backed up together with the Kafka
+// cluster. It is taken from the --<kind>-name option or discovered when the option is not set. No cluster is backed up
+// when the --skip-<kind> option is set.
+func connectedCluster(cmd *cobra.Command, kind string, discover func() (string, error)) (string, error) {
+	skip, err := cmd.Flags().GetBool("skip-" + kind)
+	if err != nil {
+		slog.Error("Failed to get the --skip-"+kind+" flag", "error", err)
+		return "", err
+	}
+
+	if skip {
+		slog.Info("Skipping the backup of the connected cluster", "kind", kind)
+		return "", nil
+	}
+
+	if name := cmd.Flag(kind + "-name").Value.String(); name != "" {
+		return name, nil
+	}
+
+	return discover()
+}
+
+func init() {
+	backupCmd.AddCommand(backupAllCmd)
+
+	addBackupKafkaFlags(backupAllCmd)
+	backupAllCmd.PersistentFlags().String("connect-name", "", "Name of the Kafka Connect cluster backed up together with the Kafka cluster. If not specified, the Kafka Connect cluster using the Kafka cluster is discovered.")
+	backupAllCmd.PersistentFlags().String("mirrormaker2-name", "", "Name of the Kafka MirrorMaker 2 cluster backed up together with the Kafka cluster. If not specified, the Kafka MirrorMaker 2 cluster using the Kafka cluster is discovered.")
+	backupAllCmd.PersistentFlags().String("mirrormaker-name", "", "Name of the legacy Kafka MirrorMaker cluster backed up together with the Kafka cluster. If not specified, the Kafka MirrorMaker cluster using the Kafka cluster is discovered.")
+	backupAllCmd.PersistentFlags().String("bridge-name", "", "Name of the Kafka Bridge backed up together with the Kafka cluster. If not specified, the Kafka Bridge using the Kafka cluster is discovered.")
+	backupAllCmd.PersistentFlags().Bool("skip-connect", false, "Skip the backup of the Kafka Connect cluster")
+	backupAllCmd.PersistentFlags().Bool("skip-mirrormaker2", false, "Skip the backup of the Kafka MirrorMaker 2 cluster")
+	backupAllCmd.PersistentFlags().Bool("skip-mirrormaker", false, "Skip the backup of the legacy Kafka MirrorMaker cluster")
+	backupAllCmd.PersistentFlags().Bool("skip-bridge", false, "Skip the backup of the Kafka Bridge")
+	backupAllCmd.PersistentFlags().Bool("skip-connect-secrets", false, "Skip backup of the Secrets referenced from the KafkaConnect resource")
+	backupAllCmd.PersistentFlags().Bool("skip-mirrormaker2-secrets", false, "Skip backup of the Secrets referenced from the KafkaMirrorMaker2 resource")
+	backupAllCmd.PersistentFlags().Bool("skip-mirrormaker-secrets", false, "Skip backup of the Secrets referenced from the KafkaMirrorMaker resource")
+	backupAllCmd.PersistentFlags().Bool("skip-bridge-secrets", false, "Skip backup of the Secrets referenced from the KafkaBridge resource")
+}
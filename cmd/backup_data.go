@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var backupDataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Backup the records of selected Kafka topics",
+	Long:  "Consumes the records of the selected topics using the Kafka protocol and stores them in the backup. It is intended for small topics such as the configuration topics, as all records of a topic are kept in memory while it is backed up.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		target := backuper.Target{Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+		data := events.Data{Kind: "data", Name: target.Name}
+
+		b, err := backuper.NewDataBackuper(cmd, target)
+		if err != nil {
+			slog.Error("Failed to create backuper", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+		defer b.Close()
+
+		slog.Info("Starting backup of topic data", "name", b.Name, "namespace", b.Namespace, "topics", cmd.Flag("topics").Value.String(), "connect", b.Connects)
+		data.Namespace = b.Namespace
+		emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+		if err := b.CheckReadOnly(backuper.DataBackupRules()); err != nil {
+			slog.Error("The backup of topic data cannot run with the rights to only read the backed up resources", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupTopicData(); err != nil {
+			slog.Error("Failed to backup the topic data", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.WriteManifest(); err != nil {
+			slog.Error("Failed to write the backup manifest", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.Upload(); err != nil {
+			slog.Error("Failed to upload the backup to the storage", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		slog.Info("Backup of topic data is complete", "name", b.Name, "namespace", b.Namespace, "filename", b.FileName())
+		data.FileName = b.FileName()
+		emitter.Finished(events.OperationBackup, data, nil)
+	},
+}
+
+func init() {
+	backupCmd.AddCommand(backupDataCmd)
+
+	utils.AddKafkaFlags(backupDataCmd.PersistentFlags())
+	backupDataCmd.PersistentFlags().String("topics", "", "Regular expression matching the names of the topics whose records should be backed up. The expression has to match the whole topic name.")
+	backupDataCmd.PersistentFlags().StringSlice("connect", nil, "Names of the KafkaConnect resources whose internal offsets, configs, and status topics should be backed up, so that the restored Kafka Connect clusters resume the connectors from their original offsets")
+	backupDataCmd.PersistentFlags().String("max-bytes", "1Gi", "Maximal total size of the keys and values of the backed up records. The backup fails when the selected topics contain more data. Kubernetes quantities such as 512Mi or 1Gi are supported.")
+}
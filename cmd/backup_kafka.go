@@ -17,77 +17,199 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/phases"
 	"github.com/spf13/cobra"
 	"log/slog"
-	"os"
 )
 
 var (
-	skipCaSecrets   bool
-	skipUserSecrets bool
-	backupKafkaCmd  = &cobra.Command{
+	skipCaSecrets               bool
+	skipUserSecrets             bool
+	includeClusterLayout        bool
+	includeApicurioRegistry     bool
+	includeExternalConnectivity bool
+	includeEntityOperator       bool
+	includeRebalances           bool
+	includeCRDs                 bool
+	annotateKafka               bool
+	trackHistory                bool
+	backupKafkaCmd              = &cobra.Command{
 		Use:   "kafka",
 		Short: "Backup Strimzi-based Apache Kafka cluster",
 		Long:  "Backup Strimzi-based Apache Kafka cluster",
 		Run: func(cmd *cobra.Command, args []string) {
-			b, err := backuper.NewKafkaBackuper(cmd)
+			emitter, err := events.NewEmitterFromFlags(cmd)
 			if err != nil {
-				slog.Error("Failed to create backuper", "error", err)
-				os.Exit(1)
+				exit(1)
 			}
-			defer b.Close()
 
-			slog.Info("Starting backup of Kafka cluster", "name", b.Name, "namespace", b.Namespace)
-
-			if err := b.BackupKafka(); err != nil {
-				slog.Error("Failed to backup Kafka", "error", err)
-				b.Discard()
-				os.Exit(1)
+			targets, err := backuper.ParseTargets(cmd)
+			if err != nil {
+				slog.Error("Failed to parse the Kafka clusters to backup", "error", err)
+				exit(1)
 			}
 
-			if err := b.BackupKafkaNodePools(); err != nil {
-				slog.Error("Failed to backup Kafka node pools", "error", err)
-				b.Discard()
-				os.Exit(1)
+			allClusters, err := cmd.Flags().GetBool("all-clusters")
+			if err != nil {
+				slog.Error("Failed to get the --all-clusters flag", "error", err)
+				exit(1)
 			}
 
-			if !skipCaSecrets {
-				if err := b.BackupCaSecrets(); err != nil {
-					slog.Error("Failed to backup CA Secrets", "error", err)
-					b.Discard()
-					os.Exit(1)
+			if allClusters {
+				if _, err := backupAllClusters(cmd, targets, emitter); err != nil {
+					exit(1)
 				}
-			}
 
-			if err := b.BackupKafkaTopics(); err != nil {
-				slog.Error("Failed to backup Kafka topics", "error", err)
-				b.Discard()
-				os.Exit(1)
-			}
+				return
+			} else if len(targets) == 1 {
+				if _, err := backupKafka(cmd, targets[0], emitter); err != nil {
+					exit(1)
+				}
 
-			if err := b.BackupKafkaUsers(); err != nil {
-				slog.Error("Failed to backup Kafka users", "error", err)
-				b.Discard()
-				os.Exit(1)
+				return
 			}
 
-			if !skipUserSecrets {
-				if err := b.BackupUserSecrets(); err != nil {
-					slog.Error("Failed to backup User Secrets", "error", err)
-					b.Discard()
-					os.Exit(1)
-				}
+			parallelism, err := cmd.Flags().GetInt("parallelism")
+			if err != nil {
+				slog.Error("Failed to get the --parallelism flag", "error", err)
+				exit(1)
 			}
 
-			slog.Info("Backup of Kafka cluster is complete", "name", b.Name, "namespace", b.Namespace)
+			slog.Info("Starting backup of multiple Kafka clusters", "clusters", len(targets), "parallelism", parallelism)
+
+			results := backuper.RunParallel(targets, parallelism, func(target backuper.Target) (string, error) {
+				return backupKafka(cmd, target, emitter)
+			})
+
+			if !backuper.LogSummary(results) {
+				exit(1)
+			}
 		},
 	}
 )
 
+// backupKafka backs up a single Kafka cluster by running the backup phases and returns the name of the backup file. The
+// start and the result of the backup are sent as CloudEvents when the sink is configured.
+func backupKafka(cmd *cobra.Command, target backuper.Target, emitter *events.Emitter) (fileName string, err error) {
+	data := events.Data{Kind: "kafka", Namespace: target.Namespace, Name: target.Name}
+	defer func() {
+		if err == nil {
+			data.FileName = fileName
+		}
+		emitter.Finished(events.OperationBackup, data, err)
+	}()
+
+	b, err := backuper.NewKafkaBackuper(cmd, target)
+	if err != nil {
+		slog.Error("Failed to create backuper", "name", target.Name, "error", err)
+		return "", err
+	}
+	defer b.Close()
+
+	slog.Info("Starting backup of Kafka cluster", "name", b.Name, "namespace", b.Namespace)
+	data.Namespace = b.Namespace
+	emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+	if err := phases.Run(context.TODO(), b.Phases(kafkaBackupOptions())); err != nil {
+		return b.FileName(), err
+	}
+
+	slog.Info("Backup of Kafka cluster is complete", "name", b.Name, "namespace", b.Namespace)
+
+	return b.FileName(), nil
+}
+
+// backupAllClusters backs up all Kafka clusters discovered in the namespace into a single backup file and returns its
+// name. The start and the result of the backup are sent as CloudEvents when the sink is configured.
+func backupAllClusters(cmd *cobra.Command, targets []backuper.Target, emitter *events.Emitter) (fileName string, err error) {
+	data := events.Data{Kind: "kafka", Namespace: targets[0].Namespace, Name: backuper.AllClustersName}
+	defer func() {
+		if err == nil {
+			data.FileName = fileName
+		}
+		emitter.Finished(events.OperationBackup, data, err)
+	}()
+
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+
+	b, err := backuper.NewKafkaBackuper(cmd, backuper.Target{Namespace: targets[0].Namespace, Name: backuper.AllClustersName, FileName: targets[0].FileName})
+	if err != nil {
+		slog.Error("Failed to create backuper", "error", err)
+		return "", err
+	}
+	defer b.Close()
+
+	backupPhases, err := b.AllClustersPhases(names, kafkaBackupOptions())
+	if err != nil {
+		b.Discard()
+		return "", err
+	}
+
+	slog.Info("Starting backup of all Kafka clusters", "clusters", names, "namespace", b.Namespace)
+	emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+	if err := phases.Run(context.TODO(), backupPhases); err != nil {
+		return b.FileName(), err
+	}
+
+	slog.Info("Backup of all Kafka clusters is complete", "clusters", names, "namespace", b.Namespace, "file", b.FileName())
+
+	return b.FileName(), nil
+}
+
+// kafkaBackupOptions returns the options of the Kafka cluster backup selected by the command line flags
+func kafkaBackupOptions() backuper.KafkaBackupOptions {
+	return backuper.KafkaBackupOptions{
+		SkipCaSecrets:               skipCaSecrets,
+		SkipUserSecrets:             skipUserSecrets,
+		IncludeClusterLayout:        includeClusterLayout,
+		IncludeApicurioRegistry:     includeApicurioRegistry,
+		IncludeExternalConnectivity: includeExternalConnectivity,
+		IncludeEntityOperator:       includeEntityOperator,
+		IncludeRebalances:           includeRebalances,
+		IncludeCRDs:                 includeCRDs,
+		AnnotateKafka:               annotateKafka,
+		TrackHistory:                trackHistory,
+	}
+}
+
 func init() {
 	backupCmd.AddCommand(backupKafkaCmd)
 
 	backupCmd.PersistentFlags().BoolVar(&skipCaSecrets, "skip-ca-secrets", false, "Skip backup of the Cluster and Client Certification Authority Secrets")
 	backupCmd.PersistentFlags().BoolVar(&skipUserSecrets, "skip-user-secrets", false, "Skip backup of the Kafka User Secrets")
+
+	addBackupKafkaFlags(backupKafkaCmd)
+	backupKafkaCmd.PersistentFlags().String("namespace-selector", "", "Label selector of the namespaces in which all Kafka clusters should be backed up. Cannot be used together with the --name option.")
+	backupKafkaCmd.PersistentFlags().Bool("all-clusters", false, "Back up all Kafka clusters in the namespace into a single backup file. The manifest of the backup contains the index of the streams of each Kafka cluster. Cannot be used together with the --name, --namespace-selector, and --track-history options.")
+	backupKafkaCmd.PersistentFlags().StringSlice("exclude-namespaces", nil, "Namespaces which should be skipped when discovering the Kafka clusters using the --namespace-selector option")
+	backupKafkaCmd.PersistentFlags().Int("parallelism", 1, "Number of Kafka clusters backed up in parallel when multiple clusters are specified in the --name option or discovered using the --namespace-selector option")
+}
+
+// addBackupKafkaFlags adds the flags used by the Kafka cluster backup. They are shared by the backup kafka and backup
+// all commands.
+func addBackupKafkaFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&includeClusterLayout, "include-cluster-layout", false, "Include the StrimziPodSets and the assignment of Kafka nodes to Kubernetes worker nodes in the backup. This information is not restored.")
+	cmd.PersistentFlags().Bool("quiesce", false, "Pause the reconciliation of the KafkaTopic and KafkaUser resources by the Entity Operator while the backup is running")
+	cmd.PersistentFlags().BoolVar(&includeApicurioRegistry, "include-apicurio-registry", false, "Include the Apicurio Registries storing their data in the Kafka cluster, the ConfigMaps referenced from them, and their artifacts exported using the Apicurio Registry REST API in the backup")
+	cmd.PersistentFlags().String("apicurio-registry-url", "", "URL of the Apicurio Registry REST API used to export the artifacts. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Apicurio Registry. If not specified, the Kubernetes Service created by the Apicurio Registry operator is used.")
+	cmd.PersistentFlags().StringArray("apicurio-registry-header", nil, "HTTP header in the <name>: <value> format added to the requests to the Apicurio Registry REST API. Environment variables in the value are expanded. Can be used multiple times.")
+	cmd.PersistentFlags().BoolVar(&includeExternalConnectivity, "include-external-connectivity", false, "Include the cert-manager Certificates issuing the listener certificates and the external-dns DNSEndpoints with the hostnames of the external listeners in the backup. On OpenShift, the Routes of the route listeners and their custom certificates are included as well.")
+	cmd.PersistentFlags().BoolVar(&includeEntityOperator, "include-entity-operator", false, "Include the Secrets with the certificates of the Topic and User Operators and their RoleBindings in the backup")
+	cmd.PersistentFlags().BoolVar(&includeRebalances, "include-rebalances", false, "Include the KafkaRebalance resources in the backup. The KafkaRebalances created by the Cluster Operator for the auto-rebalancing are not included. The restored KafkaRebalances are created once the Kafka cluster is ready.")
+	cmd.PersistentFlags().Bool("rebalance-templates-only", false, "Include only the KafkaRebalance templates used by the auto-rebalancing when using the --include-rebalances option")
+	cmd.PersistentFlags().BoolVar(&includeCRDs, "include-crds", false, "Include the Strimzi CustomResourceDefinitions installed in the Kubernetes cluster in the backup. They can be restored into a bare Kubernetes cluster using the restore crds command.")
+	cmd.PersistentFlags().StringSlice("topic-namespaces", nil, "Additional namespaces watched by the Topic Operator (its watchedNamespace) from which the KafkaTopics belonging to the Kafka cluster are backed up")
+	cmd.PersistentFlags().Bool("follow-watched-namespaces", false, "Back up the KafkaTopics and KafkaUsers from the namespaces watched by the Topic and User Operators (their watchedNamespace). The namespaces which the backup is not allowed to list are skipped with a warning.")
+	cmd.PersistentFlags().BoolVar(&annotateKafka, "annotate-kafka", false, "Annotate the Kafka resource with the time and the location of the last successful backup")
+	cmd.PersistentFlags().BoolVar(&trackHistory, "track-history", false, "Record the sizes of the backed up streams in the strimzi-backup-history-<name> ConfigMap and report the streams which shrunk by more than the --shrink-threshold compared to the previous backup")
+	cmd.PersistentFlags().Float64("shrink-threshold", 0.5, "Fraction by which a stream has to shrink compared to the previous backup to be reported when using the --track-history option")
+	cmd.PersistentFlags().Bool("skip-lint", false, "Skip the checks of the backed up resources for deprecated fields, missing configuration, and expiring certificates")
+	cmd.PersistentFlags().Uint32("quiesce-timeout", 300000, "Timeout for how long to wait for the Entity Operator to pause or resume the reconciliation. In milliseconds.")
 }
@@ -0,0 +1,95 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var backupMirrorMakerCmd = &cobra.Command{
+	Use:   "mirrormaker",
+	Short: "Backup Strimzi-based legacy Kafka MirrorMaker cluster",
+	Long:  "Backs up the legacy KafkaMirrorMaker resource and the Secrets and ConfigMaps referenced from it. It can be used to keep a copy of the Kafka MirrorMaker cluster while migrating to Kafka MirrorMaker 2 or to newer Strimzi versions.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		target := backuper.Target{Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+		data := events.Data{Kind: "mirrormaker", Name: target.Name}
+
+		b, err := backuper.NewMirrorMakerBackuper(cmd, target)
+		if err != nil {
+			slog.Error("Failed to create backuper", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+		defer b.Close()
+
+		slog.Info("Starting backup of legacy Kafka MirrorMaker cluster", "name", b.Name, "namespace", b.Namespace)
+		data.Namespace = b.Namespace
+		emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+		if err := b.CheckReadOnly(backuper.MirrorMakerBackupRules()); err != nil {
+			slog.Error("The backup of legacy Kafka MirrorMaker cannot run with the rights to only read the backed up resources", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupKafkaMirrorMaker(); err != nil {
+			slog.Error("Failed to backup legacy Kafka MirrorMaker", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupReferencedResources(); err != nil {
+			slog.Error("Failed to backup the Secrets and ConfigMaps used by legacy Kafka MirrorMaker", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.WriteManifest(); err != nil {
+			slog.Error("Failed to write the backup manifest", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.Upload(); err != nil {
+			slog.Error("Failed to upload the backup to the storage", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		slog.Info("Backup of legacy Kafka MirrorMaker cluster is complete", "name", b.Name, "namespace", b.Namespace, "filename", b.FileName())
+		data.FileName = b.FileName()
+		emitter.Finished(events.OperationBackup, data, nil)
+	},
+}
+
+func init() {
+	backupCmd.AddCommand(backupMirrorMakerCmd)
+
+	backupMirrorMakerCmd.PersistentFlags().Bool("skip-mirrormaker-secrets", false, "Skip backup of the Secrets referenced from the KafkaMirrorMaker resource")
+}
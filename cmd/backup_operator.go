@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var backupOperatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Backup the Strimzi Cluster Operator installation",
+	Long:  "Backs up the Deployment of the Strimzi Cluster Operator, the ConfigMaps and Secrets used by it, its ServiceAccount, the ClusterRoles, Roles, and their bindings granting it the rights, and the Lease used for the leader election. It can be used to install the Cluster Operator before restoring the operands when rebuilding the whole environment. The --name option is the name of the Cluster Operator Deployment and defaults to strimzi-cluster-operator.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		target := backuper.Target{Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+		if target.Name == "" {
+			target.Name = backuper.DefaultOperatorName
+		}
+		data := events.Data{Kind: "operator", Name: target.Name}
+
+		b, err := backuper.NewOperatorBackuper(cmd, target)
+		if err != nil {
+			slog.Error("Failed to create backuper", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+		defer b.Close()
+
+		slog.Info("Starting backup of the Strimzi Cluster Operator", "name", b.Name, "namespace", b.Namespace)
+		data.Namespace = b.Namespace
+		emitter.Emit(events.OperationBackup, events.PhaseStarted, data)
+
+		if err := b.CheckReadOnly(backuper.OperatorBackupRules(includeCRDs)); err != nil {
+			slog.Error("The backup of the Cluster Operator cannot run with the rights to only read the backed up resources", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		// The CRDs are written first, so that they are installed before the Cluster Operator when restored
+		if includeCRDs {
+			if err := b.BackupCRDs(); err != nil {
+				slog.Error("Failed to backup the Strimzi CustomResourceDefinitions", "error", err)
+				b.Discard()
+				emitter.Finished(events.OperationBackup, data, err)
+				exit(1)
+			}
+		}
+
+		if err := b.BackupDeployment(); err != nil {
+			slog.Error("Failed to backup the Cluster Operator Deployment", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupConfiguration(); err != nil {
+			slog.Error("Failed to backup the ConfigMaps and Secrets used by the Cluster Operator", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupRBAC(); err != nil {
+			slog.Error("Failed to backup the ServiceAccount, Roles, and RoleBindings of the Cluster Operator", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.BackupLeases(); err != nil {
+			slog.Error("Failed to backup the Lease of the Cluster Operator", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.WriteManifest(); err != nil {
+			slog.Error("Failed to write the backup manifest", "error", err)
+			b.Discard()
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		if err := b.Upload(); err != nil {
+			slog.Error("Failed to upload the backup to the storage", "error", err)
+			emitter.Finished(events.OperationBackup, data, err)
+			exit(1)
+		}
+
+		slog.Info("Backup of the Strimzi Cluster Operator is complete", "name", b.Name, "namespace", b.Namespace, "filename", b.FileName())
+		data.FileName = b.FileName()
+		emitter.Finished(events.OperationBackup, data, nil)
+	},
+}
+
+func init() {
+	backupCmd.AddCommand(backupOperatorCmd)
+
+	backupOperatorCmd.PersistentFlags().BoolVar(&includeCRDs, "include-crds", false, "Include the Strimzi CustomResourceDefinitions installed in the Kubernetes cluster in the backup. They are restored before the Cluster Operator when using the --restore-crds option of the restore operator command.")
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/differ"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <old-backup> <new-backup>",
+	Short: "Generates a changelog between two backups",
+	Long:  "Generates a human-readable changelog of the Kafka cluster configuration, the topic configurations, and the user ACLs between two backups for change-review processes",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		d, err := differ.NewDiffer(cmd, args)
+		if err != nil {
+			slog.Error("Failed to create differ", "error", err)
+			exit(1)
+		}
+
+		if err := d.Changelog(os.Stdout, cmd.Flag("format").Value.String()); err != nil {
+			slog.Error("Failed to generate the changelog", "error", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.PersistentFlags().String("format", differ.FormatMarkdown, "Format of the changelog. Use markdown or json.")
+}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/differ"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var diffArchivesCmd = &cobra.Command{
+	Use:   "diff-archives <old-backup> <new-backup>",
+	Short: "Shows the differences between two backups",
+	Long:  "Shows the resources which were added, removed, or changed between two backups without the need to access the Kubernetes cluster",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		d, err := differ.NewDiffer(cmd, args)
+		if err != nil {
+			slog.Error("Failed to create differ", "error", err)
+			exit(1)
+		}
+
+		changes, err := d.Diff(os.Stdout)
+		if err != nil {
+			slog.Error("Failed to compare the backups", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Comparison of backups is complete", "old", d.OldFileName, "new", d.NewFileName, "changes", len(changes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffArchivesCmd)
+
+	diffArchivesCmd.PersistentFlags().Bool("include-status", false, "Include the changes to the status of the resources")
+}
@@ -17,10 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
 	"github.com/scholzj/strimzi-backup/pkg/exporter"
 	"github.com/spf13/cobra"
 	"log/slog"
-	"os"
 )
 
 var exportCmd = &cobra.Command{
@@ -31,7 +31,7 @@ var exportCmd = &cobra.Command{
 		e, err := exporter.NewExporter(cmd)
 		if err != nil {
 			slog.Error("Failed to export backup", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 		defer e.Close()
 
@@ -53,4 +53,6 @@ func init() {
 	_ = exportCmd.MarkPersistentFlagRequired("filename")
 	exportCmd.PersistentFlags().String("target-directory", "", "The directory where the files should be exported")
 	_ = exportCmd.MarkPersistentFlagRequired("target-directory")
+	exportCmd.PersistentFlags().String("format", exporter.FormatFiles, "Format of the export. Use files to export the resources into separate files by their type, helm-values to derive a Helm values file from the Kafka cluster, crossplane to wrap the resources into Crossplane Object resources, terraform to wrap them into Terraform kubernetes_manifest resources, combined-yaml to write them into a single multi-document YAML file in the order in which they should be applied, or json to export the resources into separate JSON files by their type.")
+	archive.AddLimitFlags(exportCmd.PersistentFlags())
 }
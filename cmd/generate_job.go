@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/generator"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var generateJobCmd = &cobra.Command{
+	Use:   "job [flags] -- <command> [<args>]",
+	Short: "Generate a Kubernetes Job running strimzi-backup",
+	Long:  "Generates a Kubernetes Job running the strimzi-backup command given after -- inside the Kubernetes cluster using the official container image. The container runs as a non-root user with a read-only root filesystem and uses a projected service account token. The Job is written to the standard output, so it can be applied with kubectl apply -f -.",
+	Run: func(cmd *cobra.Command, args []string) {
+		g, err := generator.NewJobGenerator(cmd)
+		if err != nil {
+			slog.Error("Failed to create the Job generator", "error", err)
+			exit(1)
+		}
+
+		if err := g.Generate(os.Stdout, args); err != nil {
+			slog.Error("Failed to generate the Job", "error", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(generateJobCmd)
+
+	generateJobCmd.PersistentFlags().String("job-name", "strimzi-backup", "Name of the generated Job")
+	generateJobCmd.PersistentFlags().String("namespace", "", "Namespace of the generated Job. If not specified, the namespace is not set and the Job is created in the namespace used by kubectl.")
+	generateJobCmd.PersistentFlags().String("image", generator.DefaultImage, "Container image of strimzi-backup used by the Job")
+	generateJobCmd.PersistentFlags().String("service-account", "strimzi-backup", "Service account used by the Job. It has to have the RBAC rights needed by the strimzi-backup command.")
+	generateJobCmd.PersistentFlags().String("persistent-volume-claim", "", "Name of the PersistentVolumeClaim mounted as the /backups working directory of the Job. If not specified, an emptyDir volume is used and the backups have to be uploaded using the --storage option.")
+	generateJobCmd.PersistentFlags().Int32("ttl", 86400, "Time after which the finished Job is deleted. Set to 0 to keep the finished Job. In seconds.")
+}
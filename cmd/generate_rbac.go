@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/generator"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var generateRbacCmd = &cobra.Command{
+	Use:   "rbac",
+	Short: "Generate the Role and RoleBinding for running strimzi-backup",
+	Long:  "Generates the Role with the rights needed by strimzi-backup and the RoleBinding binding it to the service account. In the backup mode, the Role allows only reading the resources backed up by the backup commands, so that it can be used together with the --read-only-check option. In the restore mode, the Role allows only the requests needed to restore the resources with the restore commands. The resources are written to the standard output, so they can be applied with kubectl apply -f -.",
+	Run: func(cmd *cobra.Command, args []string) {
+		g, err := generator.NewRbacGenerator(cmd)
+		if err != nil {
+			slog.Error("Failed to create the RBAC generator", "error", err)
+			exit(1)
+		}
+
+		if err := g.Generate(os.Stdout); err != nil {
+			slog.Error("Failed to generate the RBAC resources", "error", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(generateRbacCmd)
+
+	generateRbacCmd.PersistentFlags().String("mode", generator.RbacModeBackup, "Commands for which the rights are generated. Supported are backup and restore.")
+	generateRbacCmd.PersistentFlags().String("role-name", "strimzi-backup", "Name of the generated Role and RoleBinding")
+	generateRbacCmd.PersistentFlags().String("namespace", "", "Namespace of the generated Role and RoleBinding and of the service account. If not specified, the namespace is not set and the resources are created in the namespace used by kubectl.")
+	generateRbacCmd.PersistentFlags().String("service-account", "strimzi-backup", "Service account to which the Role is bound")
+}
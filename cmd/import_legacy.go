@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/importer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var importLegacyCmd = &cobra.Command{
+	Use:   "import-legacy",
+	Short: "Converts backups created by the legacy backup scripts",
+	Long:  "Converts the backups created by the older shell-script based Strimzi backup tools into the strimzi-backup format, so that they can be restored using the restore command.",
+	Run: func(cmd *cobra.Command, args []string) {
+		i, err := importer.NewLegacyImporter(cmd)
+		if err != nil {
+			slog.Error("Failed to create legacy importer", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Starting import of legacy backup", "source", i.Source, "output", i.OutputFileName)
+
+		if err := i.Import(); err != nil {
+			slog.Error("Failed to import the legacy backup", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Import of legacy backup is complete", "output", i.OutputFileName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importLegacyCmd)
+
+	importLegacyCmd.Flags().String("source", "", "The legacy backup which should be imported. It can be a directory or a .zip, .tar, .tar.gz, or .tgz archive.")
+	_ = importLegacyCmd.MarkFlagRequired("source")
+	importLegacyCmd.Flags().String("output", "", "The name of the resulting backup file")
+	_ = importLegacyCmd.MarkFlagRequired("output")
+	importLegacyCmd.Flags().String("name", "", "Name of the Kafka cluster which should be imported. Required only when the legacy backup contains multiple Kafka clusters.")
+}
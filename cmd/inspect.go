@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/inspector"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Shows the composition of the backup",
+	Long:  "Shows the number of resources, the sizes, and the durations of the individual streams of the backup, either as a table or as Prometheus metrics",
+	Run: func(cmd *cobra.Command, args []string) {
+		i, err := inspector.NewInspector(cmd)
+		if err != nil {
+			slog.Error("Failed to create inspector", "error", err)
+			exit(1)
+		}
+
+		if err := i.Inspect(os.Stdout); err != nil {
+			slog.Error("Failed to inspect the backup", "error", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+
+	inspectCmd.PersistentFlags().String("filename", "", "The name of the backup file to inspect")
+	_ = inspectCmd.MarkPersistentFlagRequired("filename")
+	inspectCmd.PersistentFlags().String("output", inspector.OutputText, "Output format. Use text for a human-readable table or prometheus for the Prometheus text format which can be used with the textfile collector of the Prometheus Node Exporter.")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/merger"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <backup-file> <backup-file>...",
+	Short: "Merges multiple backups into one",
+	Long:  "Merges the streams from multiple backup files into a single backup file. When the same stream is present in multiple backups, the newest one is used.",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := merger.NewMerger(cmd, args)
+		if err != nil {
+			slog.Error("Failed to create merger", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Starting merge of backups", "filenames", m.BackupFileNames, "output", m.OutputFileName)
+
+		if err := m.Merge(); err != nil {
+			slog.Error("Failed to merge the backups", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Merge of backups is complete", "output", m.OutputFileName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().String("output", "", "The name of the resulting merged backup file")
+	_ = mergeCmd.MarkFlagRequired("output")
+}
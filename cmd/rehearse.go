@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"time"
+)
+
+var rehearseCmd = &cobra.Command{
+	Use:   "rehearse",
+	Short: "Rehearses the restore of a backup against a test cluster",
+	Long:  "Restores the backup into a designated test Kubernetes cluster and reports whether the Kafka cluster becomes ready to periodically verify that the backups can be restored",
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := restorer.NewKafkaRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			exit(1)
+		}
+		defer r.Close()
+
+		r.MinimalResources, err = cmd.Flags().GetBool("minimal-resources")
+		if err != nil {
+			slog.Error("Failed to get the --minimal-resources flag", "error", err)
+			exit(1)
+		}
+
+		cleanup, err := cmd.Flags().GetBool("cleanup")
+		if err != nil {
+			slog.Error("Failed to get the --cleanup flag", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Starting restore rehearsal", "filename", r.BackupFileName, "context", cmd.Flag("context").Value.String(), "name", r.Name, "namespace", r.Namespace, "minimalResources", r.MinimalResources)
+
+		start := time.Now()
+		rehearsalErr := r.Rehearse()
+		duration := time.Since(start).Round(time.Second)
+
+		if cleanup {
+			if err := r.Cleanup(); err != nil {
+				slog.Error("Failed to clean up the restored resources", "error", err)
+			}
+		}
+
+		if rehearsalErr != nil {
+			slog.Error("Restore rehearsal failed", "filename", r.BackupFileName, "name", r.Name, "namespace", r.Namespace, "duration", duration, "error", rehearsalErr)
+			exit(1)
+		}
+
+		slog.Info("Restore rehearsal succeeded. The Kafka cluster is ready.", "filename", r.BackupFileName, "name", r.Name, "namespace", r.Namespace, "duration", duration)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rehearseCmd)
+
+	addRestoreFlags(rehearseCmd)
+	addRestoreKafkaFlags(rehearseCmd)
+	_ = rehearseCmd.MarkPersistentFlagRequired("context")
+	rehearseCmd.PersistentFlags().Bool("minimal-resources", false, "Remove the resource requests and limits and the JVM options and use ephemeral storage, so that the cluster fits into small test clusters such as kind")
+	rehearseCmd.PersistentFlags().Bool("cleanup", false, "Delete the restored resources once the rehearsal is complete")
+}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/inventory"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generates an inventory report of the backup",
+	Long:  "Generates an inventory report of the Kafka cluster, its node pools, topics, users, and certificates for audits and documentation. The report is generated only from the backup.",
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := inventory.NewReporter(cmd)
+		if err != nil {
+			slog.Error("Failed to create reporter", "error", err)
+			exit(1)
+		}
+
+		if err := r.Report(os.Stdout); err != nil {
+			slog.Error("Failed to generate the inventory report", "error", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.PersistentFlags().String("filename", "", "The name of the backup file from which the report should be generated")
+	_ = reportCmd.MarkPersistentFlagRequired("filename")
+	reportCmd.PersistentFlags().String("output", inventory.OutputMarkdown, "Output format. Use md for Markdown or html for a standalone HTML page.")
+	archive.AddLimitFlags(reportCmd.PersistentFlags())
+}
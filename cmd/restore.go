@@ -17,7 +17,11 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
 	"github.com/spf13/cobra"
+	"time"
 )
 
 var restoreCmd = &cobra.Command{
@@ -29,10 +33,32 @@ var restoreCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(restoreCmd)
 
-	restoreCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
-	restoreCmd.PersistentFlags().String("namespace", "", "Namespace of the cluster to restore. If not specified, defaults to the namespace from your Kubernetes configuration.")
-	restoreCmd.PersistentFlags().String("name", "", "Name of the cluster to restore")
-	restoreCmd.PersistentFlags().Uint32("timeout", 300000, "Timeout for how long to wait for the cluster to restore. In milliseconds.")
-	restoreCmd.PersistentFlags().String("filename", "", "The name of the file to restore")
-	_ = restoreCmd.MarkPersistentFlagRequired("filename")
+	addRestoreFlags(restoreCmd)
+	events.AddFlags(restoreCmd.PersistentFlags())
+}
+
+// addRestoreFlags adds the flags used by the restorer. They are shared by the restore and rehearse commands.
+func addRestoreFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
+	utils.AddConnectionFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().String("namespace", "", "Namespace of the cluster to restore. If not specified, defaults to the namespace from your Kubernetes configuration.")
+	cmd.PersistentFlags().String("target-namespace", "", "Namespace into which the cluster is restored and in which all lookups happen. Alias of the --namespace option which cannot be combined with a different --namespace value.")
+	cmd.PersistentFlags().String("source-namespace", "", "Namespace from which the backup was taken. Used for the {namespace} placeholder of the --storage option and the restore fails when the Kafka cluster in the backup comes from a different namespace. If not specified, the backup is looked up under the target namespace and its namespace is not checked.")
+	cmd.PersistentFlags().String("name", "", "Name of the cluster to restore")
+	cmd.PersistentFlags().String("source-cluster", "", "Name of the Kafka cluster in the backup which should be restored. Used to select the Kafka cluster from the backups containing multiple Kafka clusters when it is restored under a different name, and the restore fails when the backup does not contain this Kafka cluster. If not specified, the Kafka cluster with the same name as the restored cluster is selected.")
+	cmd.PersistentFlags().Uint32("timeout", 300000, "Timeout for restoring the topic data, importing the Apicurio Registry artifacts, and checking the existing data volumes. When set explicitly, it is used also instead of the --pause-timeout and --ready-timeout options which are not set. In milliseconds.")
+	cmd.PersistentFlags().Uint32("pause-timeout", 120000, "Timeout for how long to wait for the Cluster Operator to confirm that the reconciliation of the restored cluster is paused. In milliseconds.")
+	cmd.PersistentFlags().Uint32("ready-timeout", 1800000, "Timeout for how long to wait for the restored cluster to get ready after it is unpaused. In milliseconds.")
+	cmd.PersistentFlags().Uint32("stall-timeout", 300000, "When the --ready-timeout expires while the Cluster Operator is still making progress with the Kafka cluster (for example rolling its Pods), keep waiting until no progress is observed for this long. Set to 0 to fail right when the --ready-timeout expires. In milliseconds.")
+	cmd.PersistentFlags().String("filename", "", "The name of the file to restore")
+	_ = cmd.MarkPersistentFlagRequired("filename")
+	cmd.PersistentFlags().String("storage", "", "Location from which the backup file should be read. Local directories, file:// URLs, HTTP(S) URLs, and URLs handled by storage plugins are supported. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Kafka cluster. If not specified, the backup file is read from the local filesystem.")
+	cmd.PersistentFlags().StringArray("storage-header", nil, "HTTP header in the <name>: <value> format added to the requests to HTTP(S) storages. Environment variables in the value are expanded. Can be used multiple times.")
+	cmd.PersistentFlags().String("hmac-key-file", "", "File with the secret key used to verify the HMAC of the backup manifest and the digests of all streams before anything is restored. The restore fails when the backup is not signed with this key or was modified.")
+	archive.AddPassphraseFlags(cmd.PersistentFlags(), "File with the passphrase used to decrypt the encrypted streams of the backup. Required when the backup contains encrypted streams which should be restored.")
+	cmd.PersistentFlags().String("name-mapping-file", "", "YAML file with the mapping of the topic names, user names, and their prefixes used to rename the topics and users while restoring them (for example into a shared multi-tenant cluster)")
+	archive.AddLimitFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().Bool("force", false, "Update existing resources even when they were not restored by strimzi-backup or are managed by GitOps tools such as Argo CD or Flux, and take over the fields owned by other field managers")
+	cmd.PersistentFlags().Duration("lock-ttl", 10*time.Minute, "Time after which the lock preventing concurrent restores of the same Kafka cluster expires when it is not renewed (for example because the restore was killed). Set to 0 to disable the lock.")
+	cmd.PersistentFlags().Bool("resume", false, "Checkpoint the restore progress in a ConfigMap and resume an interrupted restore from it. Enabled automatically when running inside a Kubernetes cluster.")
 }
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Restore all clusters from a combined backup",
+	Long:  "Restores all clusters from a combined backup created with the backup all command or with the merge command. The Kafka cluster is restored first under the name from the --name option. Once it is ready, the Kafka Connect, Kafka MirrorMaker 2, and Kafka MirrorMaker clusters and the Kafka Bridge are restored one after another under their names from the backup.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "all", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewAllRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		for _, component := range r.Components {
+			data.Kind = component.Kind
+			data.Name = component.Name
+
+			slog.Info("Starting restoration of the cluster", "kind", component.Kind, "name", component.Name)
+			emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+			if err := r.Restore(component); err != nil {
+				slog.Error("Failed to restore the cluster", "kind", component.Kind, "name", component.Name, "error", err)
+				emitter.Finished(events.OperationRestore, data, err)
+				exit(1)
+			}
+
+			slog.Info("Cluster was restored", "kind", component.Kind, "name", component.Name)
+			emitter.Finished(events.OperationRestore, data, nil)
+		}
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreAllCmd)
+
+	addRestoreKafkaFlags(restoreAllCmd)
+	restoreAllCmd.PersistentFlags().Bool("restore-external-connectivity", false, "Restore the cert-manager Certificates, the external-dns DNSEndpoints, and the OpenShift Routes of the external listeners and the custom certificates of the route listeners when they are included in the backup")
+	restoreAllCmd.PersistentFlags().String("route-domain", "", "Domain of the OpenShift Routes of the restored Kafka cluster. The hosts of the route listeners and of the restored Routes are moved into this domain.")
+	restoreAllCmd.PersistentFlags().Bool("restore-entity-operator", false, "Restore the Secrets with the certificates of the Topic and User Operators and their RoleBindings when they are included in the backup")
+	restoreAllCmd.PersistentFlags().StringToString("topic-namespaces", nil, "Mapping of the namespaces in the <backup-namespace>=<target-namespace> format for the KafkaTopics backed up from other namespaces than the namespace of the Kafka cluster. The KafkaTopics from the namespaces which are not mapped are restored into their original namespaces. The namespace watched by the Topic Operator is updated accordingly.")
+	restoreAllCmd.PersistentFlags().StringToString("user-namespaces", nil, "Mapping of the namespaces in the <backup-namespace>=<target-namespace> format for the KafkaUsers and their Secrets backed up from the namespace watched by the User Operator. The KafkaUsers from the namespaces which are not mapped are restored into their original namespaces. The namespace watched by the User Operator is updated accordingly.")
+	restoreAllCmd.PersistentFlags().Bool("skip-kafka", false, "Skip restoring of the Kafka cluster")
+	restoreAllCmd.PersistentFlags().Bool("skip-connect", false, "Skip restoring of the Kafka Connect cluster")
+	restoreAllCmd.PersistentFlags().Bool("skip-mirrormaker2", false, "Skip restoring of the Kafka MirrorMaker 2 cluster")
+	restoreAllCmd.PersistentFlags().Bool("skip-mirrormaker", false, "Skip restoring of the legacy Kafka MirrorMaker cluster")
+	restoreAllCmd.PersistentFlags().Bool("skip-bridge", false, "Skip restoring of the Kafka Bridge")
+	restoreAllCmd.PersistentFlags().Bool("skip-mm2-start", false, "Leave the restored Kafka MirrorMaker 2 cluster paused instead of starting it, for example until the replication direction is confirmed")
+	restoreAllCmd.PersistentFlags().Bool("skip-registry-check", false, "Skip checking that the container registry into which the Kafka Connect build pushes the new container image is reachable before the Kafka Connect cluster is unpaused")
+}
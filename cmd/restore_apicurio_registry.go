@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreApicurioRegistryCmd = &cobra.Command{
+	Use:   "apicurio-registry",
+	Short: "Restore the artifacts of the Apicurio Registries",
+	Long:  "Imports the artifacts exported from the Apicurio Registries during the backup using the Apicurio Registry REST API. The Apicurio Registries have to be restored and running already.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "apicurio-registry", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewApicurioRegistryRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of Apicurio Registry artifacts", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreArtifacts(); err != nil {
+			slog.Error("Failed to restore the Apicurio Registry artifacts", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Apicurio Registry artifacts were restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreApicurioRegistryCmd)
+
+	restoreApicurioRegistryCmd.PersistentFlags().String("apicurio-registry-url", "", "URL of the Apicurio Registry REST API used to import the artifacts. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Apicurio Registry. If not specified, the Kubernetes Service created by the Apicurio Registry operator is used.")
+	restoreApicurioRegistryCmd.PersistentFlags().StringArray("apicurio-registry-header", nil, "HTTP header in the <name>: <value> format added to the requests to the Apicurio Registry REST API. Environment variables in the value are expanded. Can be used multiple times.")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreCrdsCmd = &cobra.Command{
+	Use:   "crds",
+	Short: "Restore the Strimzi CustomResourceDefinitions",
+	Long:  "Restores the Strimzi CustomResourceDefinitions from a backup created with the --include-crds option and waits until they are established. Run it against a bare Kubernetes cluster before installing the Cluster Operator and restoring the operands. The restore needs the rights to create the CustomResourceDefinitions. The --name option is used only to find the backup in the storage and for the restore lock. It defaults to strimzi-crds.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		if cmd.Flag("name").Value.String() == "" {
+			_ = cmd.Flags().Set("name", "strimzi-crds")
+		}
+
+		data := events.Data{Kind: "crds", Name: cmd.Flag("name").Value.String(), Namespace: cmd.Flag("namespace").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewCRDRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of the Strimzi CustomResourceDefinitions", "filename", r.BackupFileName)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreCRDs(); err != nil {
+			slog.Error("Failed to restore the Strimzi CustomResourceDefinitions", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Strimzi CustomResourceDefinitions were restored")
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreCrdsCmd)
+}
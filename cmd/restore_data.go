@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreDataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Restore the records of Kafka topics",
+	Long:  "Produces the records of the topics stored in the backup by the backup data command back into the Kafka cluster. The topics have to exist already.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "data", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewDataRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of topic data", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreTopicData(); err != nil {
+			slog.Error("Failed to restore the topic data", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Topic data were restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreDataCmd)
+
+	utils.AddKafkaFlags(restoreDataCmd.PersistentFlags())
+	restoreDataCmd.PersistentFlags().String("topics", "", "Regular expression matching the names of the topics whose records should be restored. The expression has to match the whole topic name. If not specified, all topics from the backup are restored.")
+}
@@ -17,10 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
 	"github.com/scholzj/strimzi-backup/pkg/restorer"
 	"github.com/spf13/cobra"
 	"log/slog"
-	"os"
 )
 
 var restoreKafkaCmd = &cobra.Command{
@@ -28,28 +28,105 @@ var restoreKafkaCmd = &cobra.Command{
 	Short: "Restore Strimzi-based Apache Kafka cluster",
 	Long:  "Restore Strimzi-based Apache Kafka cluster",
 	Run: func(cmd *cobra.Command, args []string) {
-		r, err := restorer.NewKafkaRestorer(cmd)
+		emitter, err := events.NewEmitterFromFlags(cmd)
 		if err != nil {
-			slog.Error("Failed to create restorer", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
-		defer r.Close()
 
-		slog.Info("Starting restoration of Kafka cluster", "name", r.Name, "namespace", r.Namespace)
-
-		if err := r.RestoreKafka(); err != nil {
-			slog.Error("Failed to restore the Kafka cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
-			panic(1)
+		allClusters, err := cmd.Flags().GetBool("all-clusters")
+		if err != nil {
+			slog.Error("Failed to get the --all-clusters flag", "error", err)
+			exit(1)
 		}
 
-		slog.Info("Kafka cluster was restored", "name", r.Name, "namespace", r.Namespace)
+		if allClusters {
+			restoreAllKafkaClusters(cmd, emitter)
+		} else if err := restoreKafkaCluster(cmd, emitter); err != nil {
+			exit(1)
+		}
 	},
 }
 
+// restoreKafkaCluster restores the Kafka cluster from the --name option
+func restoreKafkaCluster(cmd *cobra.Command, emitter *events.Emitter) error {
+	data := events.Data{Kind: "kafka", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+	r, err := restorer.NewKafkaRestorer(cmd)
+	if err != nil {
+		slog.Error("Failed to create restorer", "error", err)
+		emitter.Finished(events.OperationRestore, data, err)
+		return err
+	}
+	defer r.Close()
+
+	slog.Info("Starting restoration of Kafka cluster", "name", r.Name, "namespace", r.Namespace)
+	data.Namespace = r.Namespace
+	emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+	if err := r.RestoreKafka(); err != nil {
+		slog.Error("Failed to restore the Kafka cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
+		emitter.Finished(events.OperationRestore, data, err)
+		return err
+	}
+
+	slog.Info("Kafka cluster was restored", "name", r.Name, "namespace", r.Namespace)
+	emitter.Finished(events.OperationRestore, data, nil)
+
+	return nil
+}
+
+// restoreAllKafkaClusters restores all Kafka clusters from the backup created with the --all-clusters option one after
+// another under their names from the backup. A failure of one Kafka cluster does not stop the restore of the other
+// Kafka clusters.
+func restoreAllKafkaClusters(cmd *cobra.Command, emitter *events.Emitter) {
+	names, err := restorer.BackupClusters(cmd)
+	if err != nil {
+		emitter.Finished(events.OperationRestore, events.Data{Kind: "kafka", Namespace: cmd.Flag("namespace").Value.String(), FileName: cmd.Flag("filename").Value.String()}, err)
+		exit(1)
+	}
+
+	slog.Info("Restoring all Kafka clusters from the backup", "clusters", names)
+
+	var failed []string
+	for _, name := range names {
+		_ = cmd.Flags().Set("name", name)
+
+		if err := restoreKafkaCluster(cmd, emitter); err != nil {
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		slog.Error("Failed to restore some of the Kafka clusters", "failed", failed, "restored", len(names)-len(failed))
+		exit(1)
+	}
+
+	slog.Info("All Kafka clusters were restored", "clusters", names)
+}
+
 func init() {
 	restoreCmd.AddCommand(restoreKafkaCmd)
 
-	restoreKafkaCmd.PersistentFlags().Bool("skip-ca-secrets", false, "Skip restoring of the Cluster and Client Certification Authority Secrets")
-	restoreKafkaCmd.PersistentFlags().Bool("skip-user-secrets", false, "Skip restoring of the Kafka User Secrets")
-	restoreKafkaCmd.PersistentFlags().Bool("skip-cluster-id", false, "Skip restoring of the Kafka Cluster ID")
+	addRestoreKafkaFlags(restoreKafkaCmd)
+	restoreKafkaCmd.PersistentFlags().Bool("restore-external-connectivity", false, "Restore the cert-manager Certificates, the external-dns DNSEndpoints, and the OpenShift Routes of the external listeners and the custom certificates of the route listeners when they are included in the backup")
+	restoreKafkaCmd.PersistentFlags().String("route-domain", "", "Domain of the OpenShift Routes of the restored Kafka cluster. The hosts of the route listeners and of the restored Routes are moved into this domain.")
+	restoreKafkaCmd.PersistentFlags().Bool("restore-entity-operator", false, "Restore the Secrets with the certificates of the Topic and User Operators and their RoleBindings when they are included in the backup")
+	restoreKafkaCmd.PersistentFlags().StringToString("topic-namespaces", nil, "Mapping of the namespaces in the <backup-namespace>=<target-namespace> format for the KafkaTopics backed up from other namespaces than the namespace of the Kafka cluster. The KafkaTopics from the namespaces which are not mapped are restored into their original namespaces. The namespace watched by the Topic Operator is updated accordingly.")
+	restoreKafkaCmd.PersistentFlags().StringToString("user-namespaces", nil, "Mapping of the namespaces in the <backup-namespace>=<target-namespace> format for the KafkaUsers and their Secrets backed up from the namespace watched by the User Operator. The KafkaUsers from the namespaces which are not mapped are restored into their original namespaces. The namespace watched by the User Operator is updated accordingly.")
+	restoreKafkaCmd.PersistentFlags().Bool("all-clusters", false, "Restore all Kafka clusters from a backup created with the --all-clusters option of the backup kafka command one after another under their names from the backup. The {name} placeholder of the --storage option uses all-clusters. Cannot be used together with the --name and --source-cluster options.")
+	restoreKafkaCmd.PersistentFlags().Bool("merge-into-existing", false, "Merge the backup into an existing running Kafka cluster. Only the Kafka Topics, Kafka Users, and their Secrets which do not exist yet are added. The Kafka cluster, its node pools, and its Secrets are not modified.")
+}
+
+// addRestoreKafkaFlags adds the flags used by the Kafka restorer. They are shared by the restore kafka and rehearse
+// commands.
+func addRestoreKafkaFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("skip-ca-secrets", false, "Skip restoring of the Cluster and Client Certification Authority Secrets")
+	cmd.PersistentFlags().Bool("skip-user-secrets", false, "Skip restoring of the Kafka User Secrets")
+	cmd.PersistentFlags().Bool("skip-cluster-id", false, "Skip restoring of the Kafka Cluster ID")
+	cmd.PersistentFlags().Bool("skip-node-id-assignment", false, "Skip setting the strimzi.io/next-node-ids annotation on the restored Kafka Node Pools based on the node IDs from the backup")
+	cmd.PersistentFlags().Bool("rekey-user-secrets", false, "Generate new passwords for the SCRAM-SHA-512 users and let the User Operator issue new certificates for the TLS users instead of restoring the original credentials from the backup")
+	cmd.PersistentFlags().String("credential-report", "", "File where the report of the changed credentials should be written when using the --rekey-user-secrets option. If not specified, the report is only logged.")
+	cmd.PersistentFlags().Bool("skip-volume-check", false, "Skip checking that the existing data volumes of the Kafka nodes, which are reused by the restored Kafka cluster, contain the restored Cluster ID")
+	cmd.PersistentFlags().String("volume-check-image", restorer.DefaultVolumeCheckImage, "Container image of the helper Pods reading the Cluster ID from the existing data volumes. The image has to contain the sh and cat commands.")
+	cmd.PersistentFlags().Bool("fail-on-node-id-change", false, "Fail the restore when the node IDs assigned to the restored Kafka Node Pools differ from the backup instead of only printing a warning")
 }
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreMirrorMakerCmd = &cobra.Command{
+	Use:   "mirrormaker",
+	Short: "Restore Strimzi-based legacy Kafka MirrorMaker cluster",
+	Long:  "Restores the legacy Kafka MirrorMaker cluster from a backup created with the backup mirrormaker command. The KafkaMirrorMaker resource is restored paused and unpaused once its Secrets and ConfigMaps are restored. The KafkaMirrorMaker resource was removed in Strimzi 0.46, so it can be restored only into Kubernetes clusters running older Strimzi versions.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "mirrormaker", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewMirrorMakerRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of legacy Kafka MirrorMaker cluster", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreMirrorMaker(); err != nil {
+			slog.Error("Failed to restore the legacy Kafka MirrorMaker cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Legacy Kafka MirrorMaker cluster was restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreMirrorMakerCmd)
+}
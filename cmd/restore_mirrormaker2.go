@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreMirrorMaker2Cmd = &cobra.Command{
+	Use:   "mirrormaker2",
+	Short: "Restore Strimzi-based Kafka MirrorMaker 2 cluster",
+	Long:  "Restores the Kafka MirrorMaker 2 cluster from a backup created with the backup mirrormaker2 command. The Secrets and ConfigMaps of the source and target clusters are restored first. The KafkaMirrorMaker2 resource is then restored paused and unpaused unless the --skip-mm2-start option is used.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "mirrormaker2", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewMirrorMaker2Restorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of Kafka MirrorMaker 2 cluster", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreMirrorMaker2(); err != nil {
+			slog.Error("Failed to restore the Kafka MirrorMaker 2 cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Kafka MirrorMaker 2 cluster was restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreMirrorMaker2Cmd)
+
+	restoreMirrorMaker2Cmd.PersistentFlags().Bool("skip-mm2-start", false, "Leave the restored Kafka MirrorMaker 2 cluster paused instead of starting it, for example until the replication direction is confirmed")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreNodePoolsCmd = &cobra.Command{
+	Use:   "nodepools",
+	Short: "Restore the Kafka Node Pools of an existing Kafka cluster",
+	Long:  "Restores only the Kafka Node Pools which do not exist anymore into an existing Kafka cluster, for example to recover from an accidentally deleted node pool. The node IDs from the backup are reused and the existing node pools are not modified.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		data := events.Data{Kind: "nodepools", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewNodePoolRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of Kafka Node Pools", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreNodePools(); err != nil {
+			slog.Error("Failed to restore the Kafka Node Pools", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Kafka Node Pools were restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreNodePoolsCmd)
+
+	restoreNodePoolsCmd.PersistentFlags().StringSlice("node-pool", nil, "Name of the Kafka Node Pool which should be restored. Can be used multiple times. If not specified, all Kafka Node Pools missing in the Kafka cluster are restored.")
+	restoreNodePoolsCmd.PersistentFlags().Bool("skip-node-id-assignment", false, "Skip setting the strimzi.io/next-node-ids annotation on the restored Kafka Node Pools based on the node IDs from the backup")
+}
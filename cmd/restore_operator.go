@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/events"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var restoreOperatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Restore the Strimzi Cluster Operator installation",
+	Long:  "Restores the Strimzi Cluster Operator installation from a backup created with the backup operator command and waits for the Cluster Operator to get available. The Deployment is restored only after its ServiceAccount, ClusterRoles, Roles, their bindings, ConfigMaps, and Secrets. Run it before restoring the operands when rebuilding the whole environment. The restore needs the rights to create the ClusterRoles and ClusterRoleBindings. The --name option is the name of the Cluster Operator Deployment and defaults to strimzi-cluster-operator.",
+	Run: func(cmd *cobra.Command, args []string) {
+		emitter, err := events.NewEmitterFromFlags(cmd)
+		if err != nil {
+			exit(1)
+		}
+
+		if cmd.Flag("name").Value.String() == "" {
+			_ = cmd.Flags().Set("name", backuper.DefaultOperatorName)
+		}
+
+		data := events.Data{Kind: "operator", Namespace: cmd.Flag("namespace").Value.String(), Name: cmd.Flag("name").Value.String(), FileName: cmd.Flag("filename").Value.String()}
+
+		r, err := restorer.NewOperatorRestorer(cmd)
+		if err != nil {
+			slog.Error("Failed to create restorer", "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+		defer r.Close()
+
+		slog.Info("Starting restoration of the Strimzi Cluster Operator", "name", r.Name, "namespace", r.Namespace)
+		data.Namespace = r.Namespace
+		emitter.Emit(events.OperationRestore, events.PhaseStarted, data)
+
+		if err := r.RestoreOperator(); err != nil {
+			slog.Error("Failed to restore the Strimzi Cluster Operator", "name", r.Name, "namespace", r.Namespace, "error", err)
+			emitter.Finished(events.OperationRestore, data, err)
+			exit(1)
+		}
+
+		slog.Info("Strimzi Cluster Operator was restored", "name", r.Name, "namespace", r.Namespace)
+		emitter.Finished(events.OperationRestore, data, nil)
+	},
+}
+
+func init() {
+	restoreCmd.AddCommand(restoreOperatorCmd)
+
+	restoreOperatorCmd.PersistentFlags().Bool("restore-crds", false, "Restore the Strimzi CustomResourceDefinitions included in the backup with the --include-crds option before the Cluster Operator. Needs the rights to create the CustomResourceDefinitions.")
+}
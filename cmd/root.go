@@ -17,8 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"log/slog"
 	"os"
 
+	"github.com/scholzj/strimzi-backup/pkg/report"
 	"github.com/spf13/cobra"
 )
 
@@ -37,6 +39,35 @@ func Execute() {
 	}
 }
 
+var (
+	quiet   bool
+	verbose bool
+)
+
 func init() {
-	// Nothing here right now
+	report.Install()
+
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Log only errors. Useful when running from cron or other schedulers.")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log debug messages including the individual resources which are backed up, restored, or exported")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+
+	cobra.OnInitialize(func() {
+		if quiet {
+			slog.SetLogLoggerLevel(slog.LevelError)
+		} else if verbose {
+			slog.SetLogLoggerLevel(slog.LevelDebug)
+		}
+	})
+
+	// The report with the warnings and errors is printed at the end of the run, so that they do not get lost in long
+	// outputs
+	cobra.OnFinalize(func() {
+		report.Print(os.Stderr)
+	})
+}
+
+// exit prints the report with the warnings and errors collected during the run and exits with the exit code
+func exit(code int) {
+	report.Print(os.Stderr)
+	os.Exit(code)
 }
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/rotator"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var rotateCaCmd = &cobra.Command{
+	Use:   "rotate-ca",
+	Short: "Rotates the Certification Authorities of the Kafka cluster",
+	Long:  "Rotates the Cluster and Clients Certification Authorities managed by the Strimzi Cluster Operator using the Strimzi CA renewal annotations. It can be used after a restore when the CA keys should not be reused after they have been stored in the backup.",
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := rotator.NewRotator(cmd)
+		if err != nil {
+			slog.Error("Failed to create CA rotator", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Starting rotation of the Certification Authorities", "name", r.Name, "namespace", r.Namespace, "renewOnly", r.RenewOnly)
+
+		if err := r.RotateCa(); err != nil {
+			slog.Error("Failed to rotate the Certification Authorities", "name", r.Name, "namespace", r.Namespace, "error", err)
+			exit(1)
+		}
+
+		slog.Info("Certification Authorities were rotated", "name", r.Name, "namespace", r.Namespace)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rotateCaCmd)
+
+	rotateCaCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
+	utils.AddConnectionFlags(rotateCaCmd.PersistentFlags())
+	rotateCaCmd.PersistentFlags().String("namespace", "", "Namespace of the Kafka cluster. If not specified, defaults to the namespace from your Kubernetes configuration.")
+	rotateCaCmd.PersistentFlags().String("name", "", "Name of the Kafka cluster")
+	_ = rotateCaCmd.MarkPersistentFlagRequired("name")
+	rotateCaCmd.PersistentFlags().Uint32("timeout", 1800000, "Timeout for how long to wait for the Cluster Operator to rotate the Certification Authorities. In milliseconds.")
+	rotateCaCmd.PersistentFlags().Uint32("stall-timeout", 300000, "When the --timeout expires while the Cluster Operator is still making progress with the Kafka cluster (for example rolling its Pods), keep waiting until no progress is observed for this long. Set to 0 to fail right when the --timeout expires. In milliseconds.")
+	rotateCaCmd.PersistentFlags().Bool("after-restore", false, "Verify that the Kafka cluster was restored by strimzi-backup and wait for it to be ready before rotating the Certification Authorities")
+	rotateCaCmd.PersistentFlags().Bool("renew-only", false, "Only renew the CA certificates and keep the CA private keys instead of replacing them")
+	rotateCaCmd.PersistentFlags().Bool("skip-cluster-ca", false, "Do not rotate the Cluster CA")
+	rotateCaCmd.PersistentFlags().Bool("skip-clients-ca", false, "Do not rotate the Clients CA")
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/splitter"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Splits the backup into multiple backups",
+	Long:  "Splits the backup into multiple backup files by the kind of the resources, so that the sensitive parts can be stored and access controlled independently",
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := splitter.NewSplitter(cmd)
+		if err != nil {
+			slog.Error("Failed to create splitter", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Starting split of backup", "filename", s.BackupFileName, "target-directory", s.TargetDirectory, "by", s.SplitBy)
+
+		if err := s.Split(); err != nil {
+			slog.Error("Failed to split the backup", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Split of backup is complete", "filename", s.BackupFileName, "target-directory", s.TargetDirectory)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+
+	splitCmd.PersistentFlags().String("filename", "", "The name of the backup file to split")
+	_ = splitCmd.MarkPersistentFlagRequired("filename")
+	splitCmd.PersistentFlags().String("target-directory", ".", "The directory where the split backup files should be written")
+	splitCmd.PersistentFlags().String("by", splitter.SplitByKind, "How to split the backup. Use kind to split it into kafka, topics, users, and secrets backups or stream to split every stream into its own backup.")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/status"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"time"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Checks the freshness of the backups",
+	Long:  "Checks that the newest backup of the Kafka cluster is not older than the maximal age. It exits with non-zero exit code otherwise, so that it can be used as a monitoring probe.",
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := status.NewChecker(cmd)
+		if err != nil {
+			slog.Error("Failed to create status checker", "error", err)
+			exit(1)
+		}
+
+		newest, err := c.Check()
+		if err != nil {
+			slog.Error("Backup status check failed", "error", err)
+			exit(1)
+		}
+
+		slog.Info("The newest backup is fresh", "filename", newest.FileName, "createdAt", newest.CreatedAt, "age", time.Since(newest.CreatedAt).Round(time.Second), "maxAge", c.MaxAge)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.PersistentFlags().String("storage", "", "Location of the backups. Local directories, file:// URLs, HTTP(S) URLs, and URLs handled by storage plugins are supported. The {namespace} and {name} placeholders are replaced with the namespace and the name of the Kafka cluster.")
+	statusCmd.PersistentFlags().StringArray("storage-header", nil, "HTTP header in the <name>: <value> format added to the requests to HTTP(S) storages. Environment variables in the value are expanded. Can be used multiple times.")
+	_ = statusCmd.MarkPersistentFlagRequired("storage")
+	statusCmd.PersistentFlags().String("namespace", "", "Namespace of the Kafka cluster whose backups should be checked. If not specified, backups from all namespaces are considered.")
+	statusCmd.PersistentFlags().String("name", "", "Name of the Kafka cluster whose backups should be checked. If not specified, all backups are considered.")
+	statusCmd.PersistentFlags().Duration("max-age", 24*time.Hour, "Maximal age of the newest backup")
+	statusCmd.PersistentFlags().String("pushgateway", "", "URL of the Prometheus Pushgateway where the time of the newest backup should be pushed")
+}
@@ -17,29 +17,72 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
 	"log/slog"
 	"os"
-	"runtime/debug"
 
 	"github.com/spf13/cobra"
 )
 
+const (
+	versionOutputText = "text"
+	versionOutputJson = "json"
+)
+
+// versionInfo is the version information printed with the --output json option
+type versionInfo struct {
+	utils.BuildInfo
+
+	// ArchiveFormatVersions are the versions of the backup archive format which this binary can read and restore
+	ArchiveFormatVersions []string `json:"archiveFormatVersions"`
+	// EncryptionFormatVersions are the versions of the format of the encrypted streams which this binary can decrypt
+	EncryptionFormatVersions []string `json:"encryptionFormatVersions"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Shows a version of the Strimzi Backup application",
-	Long:  `Shows a version of the Strimzi Backup application.`,
+	Long:  `Shows a version of the Strimzi Backup application, the commit and the platform it was built from, and the supported versions of the backup archive format.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		buildInfo, ok := debug.ReadBuildInfo()
-		if !ok {
-			slog.Error("Failed to get Strimzi Backup version information")
-			os.Exit(1)
-		} else {
-			slog.Info("Strimzi Backup version: " + buildInfo.Main.Version)
+		output := cmd.Flag("output").Value.String()
+		buildInfo := utils.GetBuildInfo()
+
+		switch output {
+		case versionOutputText:
+			slog.Info("Strimzi Backup version: " + buildInfo.Version)
 			slog.Info("Go version: " + buildInfo.GoVersion)
+			slog.Info("Platform: " + buildInfo.Platform)
+			if buildInfo.Commit != "" {
+				slog.Info("Commit: " + buildInfo.Commit)
+			}
+			if buildInfo.BuildDate != "" {
+				slog.Info("Build date: " + buildInfo.BuildDate)
+			}
+			slog.Info("Archive format: " + archive.FormatVersion)
+		case versionOutputJson:
+			info := versionInfo{
+				BuildInfo:                buildInfo,
+				ArchiveFormatVersions:    archive.SupportedFormatVersions,
+				EncryptionFormatVersions: []string{archive.EncryptionFormatVersion},
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(info); err != nil {
+				slog.Error("Failed to print the version information", "error", err)
+				exit(1)
+			}
+		default:
+			slog.Error("Unsupported value of the --output option (supported values are text and json)", "output", output)
+			exit(1)
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().String("output", versionOutputText, "Output format. Use text for a human-readable output or json for a JSON document which can be used by automation to check the compatibility of the backups.")
 }
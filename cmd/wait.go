@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-backup/pkg/waiter"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Waits until the Kafka cluster is ready or paused",
+	Long:  "Waits until the Kafka cluster is ready or until its reconciliation is paused. It can be used in pipelines which perform manual steps between the phases of the restore.",
+	Run: func(cmd *cobra.Command, args []string) {
+		w, err := waiter.NewWaiter(cmd)
+		if err != nil {
+			slog.Error("Failed to create waiter", "error", err)
+			exit(1)
+		}
+
+		slog.Info("Waiting for the Kafka cluster", "name", w.Name, "namespace", w.Namespace, "for", w.For)
+
+		if err := w.Wait(); err != nil {
+			slog.Error("The Kafka cluster did not reach the expected state", "name", w.Name, "namespace", w.Namespace, "for", w.For, "error", err)
+			exit(1)
+		}
+
+		slog.Info("The Kafka cluster reached the expected state", "name", w.Name, "namespace", w.Namespace, "for", w.For)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	waitCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file to use for Kubernetes API requests. If not specified, strimzi-backup will try to auto-detect the Kubernetes configuration.")
+	utils.AddConnectionFlags(waitCmd.PersistentFlags())
+	waitCmd.PersistentFlags().String("namespace", "", "Namespace of the Kafka cluster. If not specified, defaults to the namespace from your Kubernetes configuration.")
+	waitCmd.PersistentFlags().String("name", "", "Name of the Kafka cluster")
+	_ = waitCmd.MarkPersistentFlagRequired("name")
+	waitCmd.PersistentFlags().String("for", waiter.ForReady, "State of the Kafka cluster to wait for. Use ready to wait until the Kafka cluster is ready or paused to wait until its reconciliation is paused.")
+	waitCmd.PersistentFlags().Uint32("timeout", 300000, "Timeout for how long to wait for the Kafka cluster. In milliseconds.")
+	waitCmd.PersistentFlags().Uint32("stall-timeout", 300000, "When waiting for the Kafka cluster to be ready and the --timeout expires while the Cluster Operator is still making progress, keep waiting until no progress is observed for this long. Set to 0 to fail right when the --timeout expires. In milliseconds.")
+}
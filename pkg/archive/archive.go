@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Stream is a single GZIP member of the backup archive
+type Stream struct {
+	Name    string
+	Comment string
+	ModTime time.Time
+	Data    []byte
+	// Encrypted is set by the encryption stage of the pipeline when the stream was encrypted or decrypted
+	Encrypted bool
+}
+
+// ClusterStreamSeparator separates the name of the Kafka cluster from the name of the stream in the backups containing
+// multiple Kafka clusters. It cannot be part of the names of the Kafka clusters or of the Kafka topics.
+const ClusterStreamSeparator = "@"
+
+// ClusterStreamName returns the name of the stream of the Kafka cluster in the backups containing multiple Kafka
+// clusters
+func ClusterStreamName(cluster string, name string) string {
+	return cluster + ClusterStreamSeparator + name
+}
+
+// SplitClusterStreamName splits the name of the stream from the backups containing multiple Kafka clusters into the name
+// of the Kafka cluster and the name of the stream. The cluster is empty for the streams which do not belong to any
+// Kafka cluster.
+func SplitClusterStreamName(name string) (string, string) {
+	if cluster, stream, found := strings.Cut(name, ClusterStreamSeparator); found {
+		return cluster, stream
+	}
+
+	return "", name
+}
+
+// ValidateStreamName checks that the name of a stream read from the backup is a plain file name and that it is not
+// repeated in the backup. The backups might come from an untrusted storage, so the names with path separators, drive
+// letters, or parent directory references are rejected. The names of the validated streams are recorded in seen.
+func ValidateStreamName(name string, seen map[string]bool) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("the backup contains a stream with the invalid name %q", name)
+	} else if strings.ContainsAny(name, "/\\:\x00") {
+		return fmt.Errorf("the backup contains a stream with the name %q which is not a plain file name", name)
+	} else if seen[name] {
+		return fmt.Errorf("the backup contains the stream %s more than once", name)
+	}
+
+	seen[name] = true
+
+	return nil
+}
+
+// ReadStreams reads all streams from the backup archive into memory
+func ReadStreams(fileName string) ([]Stream, error) {
+	file, err := os.OpenFile(fileName, os.O_RDONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open file", "error", err, "file", fileName)
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadStreamsFrom(file, fileName)
+}
+
+// ReadLimitedStreams reads all streams from the backup archive into memory and fails when the backup exceeds the limits
+func ReadLimitedStreams(fileName string, limits *Limits) ([]Stream, error) {
+	file, err := os.OpenFile(fileName, os.O_RDONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open file", "error", err, "file", fileName)
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadLimitedStreamsFrom(file, fileName, limits)
+}
+
+// ReadStreamsFrom reads all streams of the backup from the reader. The file name is used only for logging.
+func ReadStreamsFrom(reader io.Reader, fileName string) ([]Stream, error) {
+	return ReadLimitedStreamsFrom(reader, fileName, nil)
+}
+
+// ReadLimitedStreamsFrom reads all streams of the backup from the reader and fails when the backup exceeds the limits.
+// The file name is used only for logging.
+func ReadLimitedStreamsFrom(reader io.Reader, fileName string, limits *Limits) ([]Stream, error) {
+	archiveReader, err := NewReader(reader, limits)
+	if err != nil {
+		slog.Error("Failed to read file", "error", err, "file", fileName)
+		return nil, err
+	}
+	defer archiveReader.Close()
+
+	var streams []Stream
+	for {
+		stream, err := archiveReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read the backup", "error", err, "file", fileName)
+			return nil, err
+		}
+
+		streams = append(streams, *stream)
+	}
+
+	return streams, nil
+}
+
+// WriteStreams writes the streams into a new backup archive. It fails if the file already exists.
+func WriteStreams(fileName string, streams []Stream) error {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open backup file", "error", err, "file", fileName)
+		return err
+	}
+
+	archiveWriter := NewWriter(file)
+
+	for _, stream := range streams {
+		if _, err := archiveWriter.Copy(stream); err != nil {
+			slog.Error("Failed to write the stream to the backup file", "error", err, "stream", stream.Name)
+			_ = file.Close()
+			return err
+		}
+	}
+
+	if err := archiveWriter.Close(); err != nil {
+		slog.Error("Failed to flush the backup file", "error", err)
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		slog.Error("Failed to close the backup file", "error", err, "file", fileName)
+		return err
+	}
+
+	return nil
+}
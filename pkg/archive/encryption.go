@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"os"
+)
+
+// EncryptionFormatVersion is the version of the format of the encrypted streams
+const EncryptionFormatVersion = "v1"
+
+// encryptionHeader marks the encrypted streams. It is followed by the random salt used to derive the key from the
+// passphrase, the random nonce, and the data encrypted with AES-256-GCM.
+var encryptionHeader = []byte("strimzi-backup-encrypted:" + EncryptionFormatVersion + "\n")
+
+const (
+	encryptionSaltSize = 16
+	encryptionKeySize  = 32
+)
+
+// ReadEncryptionKey reads the passphrase used to encrypt and decrypt the streams from the file. Trailing new lines are
+// ignored, so that the passphrase can be easily stored in a file or in a Kubernetes Secret.
+func ReadEncryptionKey(fileName string) ([]byte, error) {
+	key, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	key = bytes.TrimRight(key, "\r\n")
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the encryption key file %s is empty", fileName)
+	}
+
+	return key, nil
+}
+
+// IsEncrypted indicates whether the stream data are encrypted
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptionHeader)
+}
+
+// Encrypt encrypts the stream data with a key derived from the passphrase. The name of the stream is authenticated
+// together with the data, so that encrypted streams cannot be swapped.
+func Encrypt(data []byte, passphrase []byte, name string) ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]byte, 0, len(encryptionHeader)+len(salt)+len(nonce)+len(data)+aead.Overhead())
+	encrypted = append(encrypted, encryptionHeader...)
+	encrypted = append(encrypted, salt...)
+	encrypted = append(encrypted, nonce...)
+
+	return aead.Seal(encrypted, nonce, data, []byte(name)), nil
+}
+
+// Decrypt decrypts the stream data encrypted by Encrypt
+func Decrypt(data []byte, passphrase []byte, name string) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("the stream %s is not encrypted", name)
+	}
+
+	data = data[len(encryptionHeader):]
+	if len(data) < encryptionSaltSize {
+		return nil, fmt.Errorf("the encrypted stream %s is truncated", name)
+	}
+
+	aead, err := newAEAD(passphrase, data[:encryptionSaltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[encryptionSaltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("the encrypted stream %s is truncated", name)
+	}
+
+	decrypted, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the stream %s: wrong encryption key or the stream was modified", name)
+	}
+
+	return decrypted, nil
+}
+
+// newAEAD derives the AES-256 key from the passphrase and the salt using scrypt and creates the AES-GCM cipher
+func newAEAD(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sigs.k8s.io/yaml"
+)
+
+// Digest returns the SHA-256 digest of the stream data as recorded in the manifest
+func Digest(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// ReadHMACKey reads the key used to sign the manifest from the file. Trailing new lines are ignored, so that the key
+// can be easily stored in a file or in a Kubernetes Secret.
+func ReadHMACKey(fileName string) ([]byte, error) {
+	key, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	key = bytes.TrimRight(key, "\r\n")
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the HMAC key file %s is empty", fileName)
+	}
+
+	return key, nil
+}
+
+// Sign calculates the HMAC-SHA256 of the manifest (including the digests of all streams) and stores it in the manifest
+func (m *Manifest) Sign(key []byte) error {
+	signature, err := m.signature(key)
+	if err != nil {
+		return err
+	}
+
+	m.HMAC = signature
+	return nil
+}
+
+// signature calculates the HMAC-SHA256 of the manifest without the HMAC field
+func (m *Manifest) signature(key []byte) (string, error) {
+	unsigned := *m
+	unsigned.HMAC = ""
+
+	data, err := yaml.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyHMAC reads the backup from the reader and verifies the HMAC of the manifest and the digests of all streams in
+// the backup. The streams are read one by one and only their digests are kept, so the backup does not have to fit into
+// memory. It fails when the backup is not signed, when it was signed with a different key, or when any stream was added,
+// removed, or modified after the backup was taken.
+func VerifyHMAC(reader io.Reader, key []byte, limits *Limits) error {
+	archiveReader, err := NewReader(reader, limits)
+	if err != nil {
+		return err
+	}
+	defer archiveReader.Close()
+
+	var manifest *Manifest
+	var names []string
+	actual := map[string]string{}
+
+	for {
+		stream, err := archiveReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if stream.Name == ManifestFilename {
+			if manifest, err = FindManifest([]Stream{*stream}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		names = append(names, stream.Name)
+		actual[stream.Name] = Digest(stream.Data)
+	}
+
+	if manifest == nil {
+		return fmt.Errorf("the backup does not contain a manifest and cannot be verified")
+	} else if manifest.HMAC == "" {
+		return fmt.Errorf("the backup manifest is not signed")
+	}
+
+	expected, err := manifest.signature(key)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(manifest.HMAC)) {
+		return fmt.Errorf("the HMAC of the backup manifest does not match: the manifest was modified or signed with a different key")
+	}
+
+	digests := map[string]string{}
+	for _, stream := range manifest.Streams {
+		digests[stream.Name] = stream.Digest
+	}
+
+	for _, name := range names {
+		digest, ok := digests[name]
+		if !ok {
+			return fmt.Errorf("the stream %s is not listed in the signed backup manifest", name)
+		}
+
+		if actual[name] != digest {
+			return fmt.Errorf("the digest of the stream %s does not match the signed backup manifest", name)
+		}
+
+		delete(digests, name)
+	}
+
+	for name := range digests {
+		if name != ManifestFilename {
+			return fmt.Errorf("the stream %s listed in the signed backup manifest is missing in the backup", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testHMACKey = []byte("hmac-key")
+
+// signedArchive writes the streams into an archive with a manifest signed with the test key
+func signedArchive(t *testing.T, streams ...Stream) []byte {
+	t.Helper()
+
+	manifest := Manifest{Version: "test", FormatVersion: FormatVersion}
+	for _, stream := range streams {
+		manifest.Streams = append(manifest.Streams, StreamStats{Name: stream.Name, Digest: Digest(stream.Data)})
+	}
+
+	if err := manifest.Sign(testHMACKey); err != nil {
+		t.Fatalf("failed to sign the manifest: %v", err)
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal the manifest: %v", err)
+	}
+
+	return writeArchive(t, nil, append(streams, Stream{Name: ManifestFilename, ModTime: time.Now(), Data: data})...)
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	data := signedArchive(t,
+		Stream{Name: "kafka.yaml", ModTime: time.Now(), Data: testData},
+		Stream{Name: "ca-secrets.yaml", ModTime: time.Now(), Data: []byte("secrets")},
+	)
+
+	if err := VerifyHMAC(bytes.NewReader(data), testHMACKey, nil); err != nil {
+		t.Fatalf("the signed backup should be verified: %v", err)
+	}
+
+	err := VerifyHMAC(bytes.NewReader(data), []byte("other-key"), nil)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("the backup signed with a different key should not be verified: %v", err)
+	}
+}
+
+func TestVerifyHMACModifiedStream(t *testing.T) {
+	signed := signedArchive(t, Stream{Name: "kafka.yaml", ModTime: time.Now(), Data: testData})
+
+	// Replace the stream while keeping the signed manifest
+	var manifest []byte
+	reader, err := NewReader(bytes.NewReader(signed), nil)
+	if err != nil {
+		t.Fatalf("failed to open the archive: %v", err)
+	}
+	for range 2 {
+		stream, err := reader.Next()
+		if err != nil {
+			t.Fatalf("failed to read the archive: %v", err)
+		}
+
+		if stream.Name == ManifestFilename {
+			manifest = stream.Data
+		}
+	}
+	_ = reader.Close()
+
+	modified := writeArchive(t, nil,
+		Stream{Name: "kafka.yaml", ModTime: time.Now(), Data: []byte("modified")},
+		Stream{Name: ManifestFilename, ModTime: time.Now(), Data: manifest},
+	)
+
+	err = VerifyHMAC(bytes.NewReader(modified), testHMACKey, nil)
+	if err == nil || !strings.Contains(err.Error(), "digest of the stream kafka.yaml") {
+		t.Fatalf("the modified stream should not be verified: %v", err)
+	}
+}
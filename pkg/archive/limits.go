@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"log/slog"
+)
+
+// AddLimitFlags adds the flags with the limits of the backups which are read by the restore and export commands
+func AddLimitFlags(flags *pflag.FlagSet) {
+	flags.String("max-stream-size", "2Gi", "Maximal decompressed size of a single stream of the backup. Reading the backup fails when any of its streams is bigger. Kubernetes quantities such as 512Mi or 1Gi are supported. Set to 0 to disable the limit.")
+	flags.Int("max-resources", 100000, "Maximal total number of resources in all streams of the backup. Reading the backup fails when it contains more resources. Set to 0 to disable the limit.")
+}
+
+// Limits protect against corrupted or malicious backups which decompress into huge streams (decompression bombs) or
+// which contain an excessive number of resources. Zero values disable the limits. A nil Limits does not limit anything.
+type Limits struct {
+	MaxStreamSize int64
+	MaxResources  int
+	resources     int
+}
+
+// NewLimitsFromFlags creates the limits from the flags added with AddLimitFlags
+func NewLimitsFromFlags(cmd *cobra.Command) (*Limits, error) {
+	maxStreamSize, err := resource.ParseQuantity(cmd.Flag("max-stream-size").Value.String())
+	if err != nil {
+		slog.Error("Failed to parse the --max-stream-size option", "maxStreamSize", cmd.Flag("max-stream-size").Value.String(), "error", err)
+		return nil, err
+	}
+
+	maxResources, err := cmd.Flags().GetInt("max-resources")
+	if err != nil {
+		slog.Error("Failed to get the --max-resources flag", "error", err)
+		return nil, err
+	}
+
+	if maxStreamSize.Value() < 0 || maxResources < 0 {
+		slog.Error("The --max-stream-size and --max-resources options cannot be negative")
+		return nil, fmt.Errorf("the --max-stream-size and --max-resources options cannot be negative")
+	}
+
+	return &Limits{MaxStreamSize: maxStreamSize.Value(), MaxResources: maxResources}, nil
+}
+
+// ReadAll reads the whole stream from the reader. It stops reading and fails as soon as the stream exceeds the maximal
+// stream size, so that the decompressed data are never fully loaded into memory.
+func (l *Limits) ReadAll(reader io.Reader, name string) ([]byte, error) {
+	if l == nil || l.MaxStreamSize == 0 {
+		return io.ReadAll(reader)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, l.MaxStreamSize+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) > l.MaxStreamSize {
+		return nil, fmt.Errorf("the stream %s is bigger than the --max-stream-size limit of %d bytes", name, l.MaxStreamSize)
+	}
+
+	return data, nil
+}
+
+// AddResources counts the resources in the stream and fails when the total number of resources read from the backup
+// exceeds the maximal number of resources
+func (l *Limits) AddResources(name string, data []byte) error {
+	if l == nil || l.MaxResources == 0 {
+		return nil
+	}
+
+	l.resources += CountResources(data)
+	if l.resources > l.MaxResources {
+		return fmt.Errorf("the backup contains more than %d resources (the limit set by the --max-resources option was exceeded in the stream %s)", l.MaxResources, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"time"
+)
+
+// ManifestFilename is the name of the stream with the manifest describing the backup
+const ManifestFilename = "manifest.yaml"
+
+// FormatVersion is the version of the format of the backup archive: the GZIP members with the streams followed by the
+// manifest. It is increased only when older versions of Strimzi Backup cannot read the new backups anymore. Since v2,
+// the encrypted streams are compressed before they are encrypted.
+const FormatVersion = "v2"
+
+// MultiClusterFormatVersion is the version of the format of the backup archives containing multiple Kafka clusters.
+// Their streams are prefixed with the name of the Kafka cluster, so older versions of Strimzi Backup cannot restore them.
+const MultiClusterFormatVersion = "v3"
+
+// SupportedFormatVersions are the versions of the format of the backup archive which can be read and restored
+var SupportedFormatVersions = []string{"v1", FormatVersion, MultiClusterFormatVersion}
+
+// Manifest describes the backup and its content
+type Manifest struct {
+	Version       string    `json:"version"`
+	FormatVersion string    `json:"formatVersion,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Namespace     string    `json:"namespace"`
+	Name          string    `json:"name"`
+	// OperatorVersion is the version of the Strimzi Cluster Operator which last reconciled the backed up Kafka cluster
+	OperatorVersion string        `json:"operatorVersion,omitempty"`
+	Streams         []StreamStats `json:"streams"`
+	Findings        []LintFinding `json:"findings,omitempty"`
+	Usage           *UsageStats   `json:"usage,omitempty"`
+	// Clusters is the index of the Kafka clusters in the backups containing multiple Kafka clusters
+	Clusters []ClusterIndex `json:"clusters,omitempty"`
+	// HMAC is the HMAC-SHA256 of the manifest (without this field) keyed with a user-provided secret
+	HMAC string `json:"hmac,omitempty"`
+}
+
+// StreamStats describes the composition of a single stream from the backup
+type StreamStats struct {
+	Name              string `json:"name"`
+	Resources         int    `json:"resources"`
+	UncompressedBytes int64  `json:"uncompressedBytes"`
+	CompressedBytes   int64  `json:"compressedBytes"`
+	DurationMillis    int64  `json:"durationMillis"`
+	Digest            string `json:"sha256,omitempty"`
+	Encrypted         bool   `json:"encrypted,omitempty"`
+}
+
+// ClusterIndex lists the streams of a single Kafka cluster in the backups containing multiple Kafka clusters
+type ClusterIndex struct {
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	OperatorVersion string   `json:"operatorVersion,omitempty"`
+	Streams         []string `json:"streams"`
+}
+
+// UsageStats are the anonymized statistics about taking the backup. They are recorded in the manifest only when
+// enabled with the --usage-stats option and are never sent anywhere. They do not contain any names of the backed up
+// resources, so that the manifests can be collected to analyze the capacity trends of the backups.
+type UsageStats struct {
+	DurationMillis    int64  `json:"durationMillis"`
+	Streams           int    `json:"streams"`
+	Resources         int    `json:"resources"`
+	UncompressedBytes int64  `json:"uncompressedBytes"`
+	CompressedBytes   int64  `json:"compressedBytes"`
+	Findings          int    `json:"findings"`
+	OperatingSystem   string `json:"os"`
+	Architecture      string `json:"arch"`
+	InCluster         bool   `json:"inCluster"`
+}
+
+// LintFinding describes a potential problem with the backed up resources found while taking the backup
+type LintFinding struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+}
+
+// FindManifest returns the manifest from the backup streams or nil if the backup does not contain any manifest (for
+// example because it was taken with an older version of strimzi-backup)
+func FindManifest(streams []Stream) (*Manifest, error) {
+	for _, stream := range streams {
+		if stream.Name == ManifestFilename {
+			manifest := Manifest{}
+			if err := yaml.Unmarshal(stream.Data, &manifest); err != nil {
+				slog.Error("Failed to unmarshal the backup manifest", "error", err)
+				return nil, err
+			}
+
+			return &manifest, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ReadManifest reads the manifest from the backup archive without decrypting or decompressing any of its streams. It
+// returns nil if the backup does not contain any manifest.
+func ReadManifest(reader io.Reader, limits *Limits) (*Manifest, error) {
+	archiveReader, err := NewReader(reader, limits)
+	if err != nil {
+		return nil, err
+	}
+	defer archiveReader.Close()
+
+	for {
+		stream, err := archiveReader.Next()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if stream.Name == ManifestFilename {
+			return FindManifest([]Stream{*stream})
+		}
+	}
+}
+
+// ClusterNames returns the names of the Kafka clusters in the backups containing multiple Kafka clusters
+func (m *Manifest) ClusterNames() []string {
+	names := make([]string, 0, len(m.Clusters))
+	for _, cluster := range m.Clusters {
+		names = append(names, cluster.Name)
+	}
+
+	return names
+}
+
+// CountResources returns the number of Kubernetes resources in the stream. Lists are counted by their items, any other
+// non-empty stream is counted as a single resource.
+func CountResources(data []byte) int {
+	list := struct {
+		Items []any `json:"items"`
+	}{}
+
+	if err := yaml.Unmarshal(data, &list); err != nil || list.Items == nil {
+		if len(data) == 0 {
+			return 0
+		}
+
+		return 1
+	}
+
+	return len(list.Items)
+}
+
+// WritePrometheusMetrics writes the stream statistics from the manifest in the Prometheus text format. The output can
+// be used with the textfile collector of the Prometheus Node Exporter.
+func WritePrometheusMetrics(w io.Writer, manifest *Manifest) error {
+	labels := func(stream string) string {
+		return fmt.Sprintf("namespace=%s,name=%s,stream=%s", strconv.Quote(manifest.Namespace), strconv.Quote(manifest.Name), strconv.Quote(stream))
+	}
+
+	metrics := []struct {
+		name  string
+		help  string
+		value func(StreamStats) float64
+	}{
+		{"strimzi_backup_stream_resources", "Number of resources in the backup stream", func(s StreamStats) float64 { return float64(s.Resources) }},
+		{"strimzi_backup_stream_uncompressed_bytes", "Uncompressed size of the backup stream in bytes", func(s StreamStats) float64 { return float64(s.UncompressedBytes) }},
+		{"strimzi_backup_stream_compressed_bytes", "Compressed size of the backup stream in bytes", func(s StreamStats) float64 { return float64(s.CompressedBytes) }},
+		{"strimzi_backup_stream_duration_seconds", "Time it took to back up the stream in seconds", func(s StreamStats) float64 { return float64(s.DurationMillis) / 1000 }},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+
+		for _, stream := range manifest.Streams {
+			if _, err := fmt.Fprintf(w, "%s{%s} %s\n", metric.name, labels(stream.Name), strconv.FormatFloat(metric.value(stream), 'f', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(manifest.Findings) > 0 {
+		counts := map[string]int{}
+		for _, finding := range manifest.Findings {
+			counts[finding.Severity]++
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP strimzi_backup_lint_findings Number of problems found by the lint checks while taking the backup\n# TYPE strimzi_backup_lint_findings gauge\n"); err != nil {
+			return err
+		}
+
+		for _, severity := range []string{"error", "warning"} {
+			if _, err := fmt.Fprintf(w, "strimzi_backup_lint_findings{namespace=%s,name=%s,severity=%s} %d\n", strconv.Quote(manifest.Namespace), strconv.Quote(manifest.Name), strconv.Quote(severity), counts[severity]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if manifest.Usage != nil {
+		if _, err := fmt.Fprintf(w, "# HELP strimzi_backup_duration_seconds Time it took to take the whole backup in seconds\n# TYPE strimzi_backup_duration_seconds gauge\nstrimzi_backup_duration_seconds{namespace=%s,name=%s} %s\n", strconv.Quote(manifest.Namespace), strconv.Quote(manifest.Name), strconv.FormatFloat(float64(manifest.Usage.DurationMillis)/1000, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+
+	if !manifest.CreatedAt.IsZero() {
+		if _, err := fmt.Fprintf(w, "# HELP strimzi_backup_created_timestamp_seconds Time when the backup was created\n# TYPE strimzi_backup_created_timestamp_seconds gauge\nstrimzi_backup_created_timestamp_seconds{namespace=%s,name=%s} %d\n", strconv.Quote(manifest.Namespace), strconv.Quote(manifest.Name), manifest.CreatedAt.Unix()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+	"io"
+	"os"
+)
+
+// PassphraseEnvVar is the environment variable with the passphrase used when no passphrase file is specified
+const PassphraseEnvVar = "STRIMZI_BACKUP_PASSPHRASE"
+
+// AddPassphraseFlags adds the flags with the passphrase used to encrypt or decrypt the streams. The passphrase itself
+// can never be passed on the command line, so that it does not end up in the shell history or in the process list.
+func AddPassphraseFlags(flags *pflag.FlagSet, usage string) {
+	flags.String("passphrase-file", "", usage+" Use - to read the passphrase from the standard input (you are prompted for it when running in a terminal). If not specified, the passphrase is read from the "+PassphraseEnvVar+" environment variable.")
+	flags.String("encryption-key-file", "", usage)
+	_ = flags.MarkDeprecated("encryption-key-file", "use --passphrase-file instead")
+}
+
+// ReadPassphrase reads the passphrase from the file specified in the --passphrase-file option (or the deprecated
+// --encryption-key-file option), from the standard input, or from the STRIMZI_BACKUP_PASSPHRASE environment variable.
+// It returns nil when no passphrase is provided. When confirm is set, the passphrase entered interactively has to be
+// entered twice, so that a typo does not make the backup impossible to decrypt.
+func ReadPassphrase(cmd *cobra.Command, confirm bool) ([]byte, error) {
+	fileName := cmd.Flag("passphrase-file").Value.String()
+	if deprecatedFileName := cmd.Flag("encryption-key-file").Value.String(); deprecatedFileName != "" {
+		if fileName != "" {
+			return nil, fmt.Errorf("the --passphrase-file and --encryption-key-file options cannot be used together")
+		}
+
+		fileName = deprecatedFileName
+	}
+
+	if fileName == "-" {
+		return readPassphraseFromStdin(confirm)
+	} else if fileName != "" {
+		return ReadEncryptionKey(fileName)
+	} else if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	return nil, nil
+}
+
+// readPassphraseFromStdin prompts for the passphrase without echoing it when the standard input is a terminal.
+// Otherwise, the passphrase is read from the standard input until its end (for example when it is piped from a secret
+// manager).
+func readPassphraseFromStdin(confirm bool) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		passphrase, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		passphrase = bytes.TrimRight(passphrase, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("the passphrase read from the standard input is empty")
+		}
+
+		return passphrase, nil
+	}
+
+	passphrase, err := promptPassphrase(fd, "Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if confirm {
+		repeated, err := promptPassphrase(fd, "Repeat the passphrase: ")
+		if err != nil {
+			return nil, err
+		} else if !bytes.Equal(passphrase, repeated) {
+			return nil, fmt.Errorf("the passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// promptPassphrase prompts for the passphrase on the standard error output, so that the prompt does not mix with the
+// output of the command
+func promptPassphrase(fd int, prompt string) ([]byte, error) {
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	} else if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase is empty")
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"path"
+)
+
+// Stage transforms the data of the streams written into and read from the backup archive. The stages do not depend on
+// the container format of the archive, so that new transformations can be added without changing the backup and
+// restore logic.
+type Stage interface {
+	// Write transforms the stream before it is compressed and written into the archive
+	Write(stream *Stream) error
+	// Read reverses the transformation after the stream is read from the archive and decompressed
+	Read(stream *Stream) error
+}
+
+// Pipeline is an ordered list of stages. The stages are applied in their order when writing the streams and in the
+// reverse order when reading them.
+type Pipeline []Stage
+
+// Write passes the stream through all stages of the pipeline before it is written into the archive
+func (p Pipeline) Write(stream *Stream) error {
+	for _, stage := range p {
+		if err := stage.Write(stream); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read passes the stream read from the archive through all stages of the pipeline in the reverse order
+func (p Pipeline) Read(stream *Stream) error {
+	for i := len(p) - 1; i >= 0; i-- {
+		if err := p[i].Read(stream); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EncryptionStage encrypts the selected streams with the passphrase when writing them and decrypts the encrypted
+// streams when reading them. Without the passphrase, nothing is encrypted and reading an encrypted stream fails.
+type EncryptionStage struct {
+	Passphrase []byte
+	// Patterns are the names or glob patterns of the encrypted streams. The manifest is never encrypted.
+	Patterns []string
+}
+
+func (s *EncryptionStage) Write(stream *Stream) error {
+	if !s.selected(stream.Name) {
+		return nil
+	}
+
+	data, err := Encrypt(stream.Data, s.Passphrase, stream.Name)
+	if err != nil {
+		return err
+	}
+
+	stream.Data = data
+	stream.Encrypted = true
+
+	return nil
+}
+
+func (s *EncryptionStage) Read(stream *Stream) error {
+	if !IsEncrypted(stream.Data) {
+		return nil
+	}
+
+	if s.Passphrase == nil {
+		return fmt.Errorf("the stream %s is encrypted and no passphrase was provided (use the --passphrase-file option or the %s environment variable)", stream.Name, PassphraseEnvVar)
+	}
+
+	data, err := Decrypt(stream.Data, s.Passphrase, stream.Name)
+	if err != nil {
+		return err
+	}
+
+	stream.Data = data
+	stream.Encrypted = true
+
+	return nil
+}
+
+// selected indicates whether the stream should be encrypted. Only the streams matching the patterns are encrypted, so
+// that the other streams can still be inspected and compared without the passphrase.
+func (s *EncryptionStage) selected(name string) bool {
+	return s.Passphrase != nil && matchesStream(name, s.Patterns)
+}
+
+// compressionHeader marks the streams compressed by the CompressionStage. It is followed by the DEFLATE compressed data.
+var compressionHeader = []byte("strimzi-backup-compressed:v1\n")
+
+// CompressionStage compresses the selected streams with DEFLATE. The GZIP members of the archive cannot compress the
+// encrypted data, so the encrypted streams are compressed by this stage before they are encrypted.
+type CompressionStage struct {
+	// Patterns are the names or glob patterns of the compressed streams. The manifest is never compressed.
+	Patterns []string
+	// MaxSize is the maximal decompressed size of a stream. Zero disables the limit.
+	MaxSize int64
+}
+
+func (s *CompressionStage) Write(stream *Stream) error {
+	if !matchesStream(stream.Name, s.Patterns) {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write(compressionHeader)
+
+	compressor, err := flate.NewWriter(&buffer, flate.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	if _, err := compressor.Write(stream.Data); err != nil {
+		return err
+	}
+
+	if err := compressor.Close(); err != nil {
+		return err
+	}
+
+	stream.Data = buffer.Bytes()
+
+	return nil
+}
+
+func (s *CompressionStage) Read(stream *Stream) error {
+	if !bytes.HasPrefix(stream.Data, compressionHeader) {
+		return nil
+	}
+
+	decompressor := flate.NewReader(bytes.NewReader(stream.Data[len(compressionHeader):]))
+	defer decompressor.Close()
+
+	// The limits are applied while decompressing, so that decompression bombs are not loaded into memory
+	data, err := (&Limits{MaxStreamSize: s.MaxSize}).ReadAll(decompressor, stream.Name)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("the compressed stream %s is truncated", stream.Name)
+	} else if err != nil {
+		return fmt.Errorf("failed to decompress the stream %s: %v", stream.Name, err)
+	}
+
+	stream.Data = data
+
+	return nil
+}
+
+// chunkHeader marks the streams split into chunks by the ChunkStage. It is followed by the chunks, each starting with
+// its length and its CRC-32 checksum, and by an empty chunk with the CRC-32 checksum of the whole stream.
+var chunkHeader = []byte("strimzi-backup-chunked:v1\n")
+
+// DefaultChunkSize is the size of the chunks used by the ChunkStage when no size is set
+const DefaultChunkSize = 1024 * 1024
+
+// ChunkStage splits the streams into chunks protected by their own checksums. Damaged or truncated streams are detected
+// together with the position of the first damaged chunk. The GZIP members of the archive protect each stream with a
+// checksum already, so the stage is meant for tools embedding the pipeline which store the streams in other ways.
+type ChunkStage struct {
+	// Size is the size of the chunks. DefaultChunkSize is used when it is zero.
+	Size int
+}
+
+func (s *ChunkStage) Write(stream *Stream) error {
+	// The manifest is never split, so that it can always be read without any stages
+	if stream.Name == ManifestFilename {
+		return nil
+	}
+
+	size := s.Size
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	chunks := (len(stream.Data) + size - 1) / size
+	data := make([]byte, 0, len(chunkHeader)+len(stream.Data)+(chunks+1)*8)
+	data = append(data, chunkHeader...)
+
+	for offset := 0; offset < len(stream.Data); offset += size {
+		chunk := stream.Data[offset:min(offset+size, len(stream.Data))]
+		data = binary.BigEndian.AppendUint32(data, uint32(len(chunk)))
+		data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+		data = append(data, chunk...)
+	}
+
+	// The empty chunk at the end protects against missing chunks
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(stream.Data))
+
+	stream.Data = data
+
+	return nil
+}
+
+func (s *ChunkStage) Read(stream *Stream) error {
+	if !bytes.HasPrefix(stream.Data, chunkHeader) {
+		return nil
+	}
+
+	remaining := stream.Data[len(chunkHeader):]
+	var data []byte
+
+	for chunk := 0; ; chunk++ {
+		if len(remaining) < 8 {
+			return fmt.Errorf("the stream %s is truncated after %d chunks", stream.Name, chunk)
+		}
+
+		length := binary.BigEndian.Uint32(remaining)
+		checksum := binary.BigEndian.Uint32(remaining[4:])
+		remaining = remaining[8:]
+
+		if length == 0 {
+			if checksum != crc32.ChecksumIEEE(data) {
+				return fmt.Errorf("the stream %s is damaged: the checksum of the whole stream does not match", stream.Name)
+			} else if len(remaining) > 0 {
+				return fmt.Errorf("the stream %s is damaged: unexpected data after the last chunk", stream.Name)
+			}
+
+			break
+		}
+
+		if uint64(len(remaining)) < uint64(length) {
+			return fmt.Errorf("the stream %s is truncated in the chunk %d", stream.Name, chunk)
+		} else if crc32.ChecksumIEEE(remaining[:length]) != checksum {
+			return fmt.Errorf("the stream %s is damaged: the checksum of the chunk %d does not match", stream.Name, chunk)
+		}
+
+		data = append(data, remaining[:length]...)
+		remaining = remaining[length:]
+	}
+
+	stream.Data = data
+
+	return nil
+}
+
+// matchesStream indicates whether the stream matches any of the names or glob patterns. The manifest never matches, so
+// that it can always be read without any stages.
+func matchesStream(name string, patterns []string) bool {
+	if name == ManifestFilename {
+		return false
+	}
+
+	// The streams of the Kafka clusters in the backups containing multiple clusters are matched without the cluster name
+	_, name = SplitClusterStreamName(name)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
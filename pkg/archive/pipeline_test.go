@@ -0,0 +1,324 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testData = []byte(strings.Repeat("apiVersion: kafka.strimzi.io/v1beta2\nkind: KafkaTopic\n", 1000))
+
+// roundTrip writes the stream through the stage and reads it back
+func roundTrip(t *testing.T, stage Stage, name string, data []byte) *Stream {
+	t.Helper()
+
+	stream := Stream{Name: name, Data: bytes.Clone(data)}
+	if err := stage.Write(&stream); err != nil {
+		t.Fatalf("failed to write the stream: %v", err)
+	}
+
+	if err := stage.Read(&stream); err != nil {
+		t.Fatalf("failed to read the stream: %v", err)
+	}
+
+	if !bytes.Equal(stream.Data, data) {
+		t.Fatalf("the stream read back does not match the written stream")
+	}
+
+	return &stream
+}
+
+// written writes the stream through the stage and returns the transformed data
+func written(t *testing.T, stage Stage, name string, data []byte) []byte {
+	t.Helper()
+
+	stream := Stream{Name: name, Data: bytes.Clone(data)}
+	if err := stage.Write(&stream); err != nil {
+		t.Fatalf("failed to write the stream: %v", err)
+	}
+
+	return stream.Data
+}
+
+// expectReadError reads the data through the stage and checks that it fails with the expected error
+func expectReadError(t *testing.T, stage Stage, name string, data []byte, expected string) {
+	t.Helper()
+
+	stream := Stream{Name: name, Data: data}
+	err := stage.Read(&stream)
+	if err == nil {
+		t.Fatalf("reading the stream should fail")
+	} else if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("unexpected error %q, expected it to contain %q", err, expected)
+	}
+}
+
+func TestEncryptionStageRoundTrip(t *testing.T) {
+	stage := &EncryptionStage{Passphrase: []byte("secret"), Patterns: []string{"*-secrets.yaml"}}
+
+	stream := roundTrip(t, stage, "ca-secrets.yaml", testData)
+	if !stream.Encrypted {
+		t.Fatalf("the stream should be marked as encrypted")
+	}
+
+	if data := written(t, stage, "ca-secrets.yaml", testData); !IsEncrypted(data) {
+		t.Fatalf("the stream matching the patterns should be encrypted")
+	}
+
+	if data := written(t, stage, "kafka.yaml", testData); !bytes.Equal(data, testData) {
+		t.Fatalf("the stream not matching the patterns should not be encrypted")
+	}
+
+	if data := written(t, &EncryptionStage{Passphrase: []byte("secret"), Patterns: []string{"*"}}, ManifestFilename, testData); !bytes.Equal(data, testData) {
+		t.Fatalf("the manifest should never be encrypted")
+	}
+}
+
+func TestEncryptionStageWrongKey(t *testing.T) {
+	data := written(t, &EncryptionStage{Passphrase: []byte("secret"), Patterns: []string{"*"}}, "ca-secrets.yaml", testData)
+
+	expectReadError(t, &EncryptionStage{Passphrase: []byte("wrong")}, "ca-secrets.yaml", data, "wrong encryption key")
+	expectReadError(t, &EncryptionStage{}, "ca-secrets.yaml", data, "no passphrase was provided")
+
+	// The name of the stream is authenticated, so that the encrypted streams cannot be swapped
+	expectReadError(t, &EncryptionStage{Passphrase: []byte("secret")}, "user-secrets.yaml", data, "wrong encryption key")
+}
+
+func TestEncryptionStageTruncated(t *testing.T) {
+	data := written(t, &EncryptionStage{Passphrase: []byte("secret"), Patterns: []string{"*"}}, "ca-secrets.yaml", testData)
+
+	expectReadError(t, &EncryptionStage{Passphrase: []byte("secret")}, "ca-secrets.yaml", data[:len(data)-10], "the stream was modified")
+	expectReadError(t, &EncryptionStage{Passphrase: []byte("secret")}, "ca-secrets.yaml", data[:len(encryptionHeader)+4], "is truncated")
+}
+
+func TestCompressionStageRoundTrip(t *testing.T) {
+	stage := &CompressionStage{Patterns: []string{"kafka-*"}}
+
+	roundTrip(t, stage, "kafka-topics.yaml", testData)
+	roundTrip(t, stage, "kafka-topics.yaml", nil)
+
+	if data := written(t, stage, "kafka-topics.yaml", testData); !bytes.HasPrefix(data, compressionHeader) || len(data) >= len(testData) {
+		t.Fatalf("the stream matching the patterns should be compressed")
+	}
+
+	if data := written(t, stage, "ca-secrets.yaml", testData); !bytes.Equal(data, testData) {
+		t.Fatalf("the stream not matching the patterns should not be compressed")
+	}
+}
+
+func TestCompressionStageTruncated(t *testing.T) {
+	data := written(t, &CompressionStage{Patterns: []string{"*"}}, "kafka-topics.yaml", testData)
+
+	expectReadError(t, &CompressionStage{}, "kafka-topics.yaml", data[:len(data)/2], "is truncated")
+}
+
+func TestCompressionStageMaxSize(t *testing.T) {
+	data := written(t, &CompressionStage{Patterns: []string{"*"}}, "kafka-topics.yaml", testData)
+
+	expectReadError(t, &CompressionStage{MaxSize: 1024}, "kafka-topics.yaml", data, "--max-stream-size")
+}
+
+func TestChunkStageRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 7, 1000, len(testData), len(testData) + 1} {
+		roundTrip(t, &ChunkStage{Size: size}, "kafka-topics.yaml", testData)
+	}
+
+	roundTrip(t, &ChunkStage{Size: 10}, "kafka-topics.yaml", nil)
+}
+
+func TestChunkStageTruncated(t *testing.T) {
+	data := written(t, &ChunkStage{Size: 1000}, "kafka-topics.yaml", testData)
+
+	expectReadError(t, &ChunkStage{}, "kafka-topics.yaml", data[:len(data)-8], "is truncated after")
+	expectReadError(t, &ChunkStage{}, "kafka-topics.yaml", data[:len(chunkHeader)+8+500], "is truncated in the chunk 0")
+
+	// A whole missing chunk is detected by the checksum of the whole stream
+	chunk := 8 + 1000
+	missing := append(bytes.Clone(data[:len(chunkHeader)+chunk]), data[len(chunkHeader)+2*chunk:]...)
+	expectReadError(t, &ChunkStage{}, "kafka-topics.yaml", missing, "checksum of the whole stream does not match")
+}
+
+func TestChunkStageDamaged(t *testing.T) {
+	data := written(t, &ChunkStage{Size: 1000}, "kafka-topics.yaml", testData)
+	data[len(chunkHeader)+8+1008+10] ^= 0xff
+
+	expectReadError(t, &ChunkStage{}, "kafka-topics.yaml", data, "checksum of the chunk 1 does not match")
+}
+
+// testPipeline returns the stages in the order in which they are used together
+func testPipeline(passphrase string) []Stage {
+	return []Stage{
+		&CompressionStage{Patterns: []string{"*"}},
+		&EncryptionStage{Passphrase: []byte(passphrase), Patterns: []string{"*-secrets.yaml"}},
+		&ChunkStage{Size: 100},
+	}
+}
+
+// writeArchive writes the streams through the pipeline into a new archive
+func writeArchive(t *testing.T, stages []Stage, streams ...Stream) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer, stages...)
+
+	for _, stream := range streams {
+		if _, err := writer.Write(&stream); err != nil {
+			t.Fatalf("failed to write the stream %s: %v", stream.Name, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close the archive: %v", err)
+	}
+
+	return buffer.Bytes()
+}
+
+// readArchive reads all streams from the archive through the pipeline
+func readArchive(data []byte, stages []Stage) (map[string][]byte, error) {
+	reader, err := NewReader(bytes.NewReader(data), nil, stages...)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	streams := map[string][]byte{}
+	for {
+		stream, err := reader.Next()
+		if err == io.EOF {
+			return streams, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		streams[stream.Name] = stream.Data
+	}
+}
+
+func TestPipelineRoundTrip(t *testing.T) {
+	data := writeArchive(t, testPipeline("secret"),
+		Stream{Name: "kafka.yaml", ModTime: time.Now(), Data: testData},
+		Stream{Name: "ca-secrets.yaml", ModTime: time.Now(), Data: testData},
+		Stream{Name: ManifestFilename, ModTime: time.Now(), Data: []byte("version: test\n")},
+	)
+
+	streams, err := readArchive(data, testPipeline("secret"))
+	if err != nil {
+		t.Fatalf("failed to read the archive: %v", err)
+	}
+
+	for _, name := range []string{"kafka.yaml", "ca-secrets.yaml"} {
+		if !bytes.Equal(streams[name], testData) {
+			t.Fatalf("the stream %s read back does not match the written stream", name)
+		}
+	}
+
+	if string(streams[ManifestFilename]) != "version: test\n" {
+		t.Fatalf("the manifest should be stored without any transformation")
+	}
+
+	// The archive is a standard GZIP file and the manifest can be read without the pipeline
+	raw, err := readArchive(data, nil)
+	if err != nil {
+		t.Fatalf("failed to read the archive without the pipeline: %v", err)
+	} else if string(raw[ManifestFilename]) != "version: test\n" {
+		t.Fatalf("the manifest should be readable without the pipeline")
+	}
+}
+
+func TestPipelineWrongKey(t *testing.T) {
+	data := writeArchive(t, testPipeline("secret"), Stream{Name: "ca-secrets.yaml", ModTime: time.Now(), Data: testData})
+
+	if _, err := readArchive(data, testPipeline("wrong")); err == nil || !strings.Contains(err.Error(), "wrong encryption key") {
+		t.Fatalf("reading the archive with a wrong key should fail, got %v", err)
+	}
+}
+
+func TestPipelineTruncated(t *testing.T) {
+	data := writeArchive(t, testPipeline("secret"),
+		Stream{Name: "kafka.yaml", ModTime: time.Now(), Data: testData},
+		Stream{Name: "ca-secrets.yaml", ModTime: time.Now(), Data: testData},
+	)
+
+	if _, err := readArchive(data[:len(data)-20], testPipeline("secret")); err == nil {
+		t.Fatalf("reading a truncated archive should fail")
+	}
+}
+
+func TestReaderSelectCluster(t *testing.T) {
+	data := writeArchive(t, testPipeline("secret"),
+		Stream{Name: ClusterStreamName("my-cluster", "kafka.yaml"), ModTime: time.Now(), Data: testData},
+		Stream{Name: ClusterStreamName("my-cluster", "ca-secrets.yaml"), ModTime: time.Now(), Data: testData},
+		Stream{Name: ClusterStreamName("other", "ca-secrets.yaml"), ModTime: time.Now(), Data: []byte("other")},
+		Stream{Name: ManifestFilename, ModTime: time.Now(), Data: []byte("version: test\n")},
+	)
+
+	reader, err := NewReader(bytes.NewReader(data), nil, testPipeline("secret")...)
+	if err != nil {
+		t.Fatalf("failed to open the archive: %v", err)
+	}
+	defer reader.Close()
+	reader.SelectCluster("my-cluster")
+
+	var names []string
+	for {
+		stream, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read the archive: %v", err)
+		}
+
+		if stream.Name != ManifestFilename && !bytes.Equal(stream.Data, testData) {
+			t.Fatalf("the stream %s does not match the written data", stream.Name)
+		}
+
+		names = append(names, stream.Name)
+	}
+
+	if strings.Join(names, ",") != "kafka.yaml,ca-secrets.yaml,manifest.yaml" {
+		t.Fatalf("unexpected streams read for the selected cluster: %v", names)
+	}
+
+	if !reader.Seen("ca-secrets.yaml") {
+		t.Fatalf("the stream of the selected cluster should be seen without the cluster name")
+	}
+}
+
+func TestReadManifestClusterIndex(t *testing.T) {
+	data := writeArchive(t, testPipeline("secret"),
+		Stream{Name: ClusterStreamName("my-cluster", "ca-secrets.yaml"), ModTime: time.Now(), Data: testData},
+		Stream{Name: ClusterStreamName("other", "ca-secrets.yaml"), ModTime: time.Now(), Data: testData},
+		Stream{Name: ManifestFilename, ModTime: time.Now(), Data: []byte("version: test\nclusters:\n- name: my-cluster\n- name: other\n")},
+	)
+
+	// The encrypted streams are skipped without the passphrase
+	manifest, err := ReadManifest(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("failed to read the manifest: %v", err)
+	} else if manifest == nil {
+		t.Fatalf("the manifest should be found")
+	}
+
+	if strings.Join(manifest.ClusterNames(), ",") != "my-cluster,other" {
+		t.Fatalf("unexpected clusters in the manifest: %v", manifest.ClusterNames())
+	}
+}
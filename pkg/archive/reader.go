@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// Reader reads the streams from the backup archive one by one. Streams with invalid or repeated names and streams
+// exceeding the limits are rejected. The data of each stream pass through the pipeline after they are read.
+type Reader struct {
+	bufferedReader *bufio.Reader
+	gzipReader     *gzip.Reader
+	pipeline       Pipeline
+	limits         *Limits
+	seen           map[string]bool
+	started        bool
+	cluster        string
+}
+
+// NewReader creates the reader reading the archive from the file or any other reader. The limits are optional.
+func NewReader(reader io.Reader, limits *Limits, stages ...Stage) (*Reader, error) {
+	bufferedReader := bufio.NewReader(reader)
+	gzipReader, err := gzip.NewReader(bufferedReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		bufferedReader: bufferedReader,
+		gzipReader:     gzipReader,
+		pipeline:       stages,
+		limits:         limits,
+		seen:           map[string]bool{},
+	}, nil
+}
+
+// SelectCluster selects the Kafka cluster whose streams are read from the backups containing multiple Kafka clusters.
+// The streams of the other Kafka clusters are skipped and the name of the selected cluster is removed from the names of
+// its streams. The backups with a single Kafka cluster are read as they are.
+func (r *Reader) SelectCluster(name string) {
+	r.cluster = name
+}
+
+// Next reads the next stream from the archive. It returns io.EOF when there are no more streams.
+func (r *Reader) Next() (*Stream, error) {
+	for {
+		stream, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		cluster, name := SplitClusterStreamName(stream.Name)
+		if cluster == "" || r.cluster == "" {
+			return stream, nil
+		} else if cluster == r.cluster {
+			stream.Name = name
+			return stream, nil
+		}
+	}
+}
+
+func (r *Reader) next() (*Stream, error) {
+	if r.started {
+		if err := r.gzipReader.Reset(r.bufferedReader); err != nil {
+			return nil, err
+		}
+	}
+	r.started = true
+
+	r.gzipReader.Multistream(false)
+
+	stream := Stream{Name: r.gzipReader.Name, Comment: r.gzipReader.Comment, ModTime: r.gzipReader.ModTime}
+	if err := ValidateStreamName(stream.Name, r.seen); err != nil {
+		return nil, err
+	}
+
+	data, err := r.limits.ReadAll(r.gzipReader, stream.Name)
+	if err != nil {
+		return nil, err
+	}
+	stream.Data = data
+
+	// The streams of the other Kafka clusters are not decrypted
+	if cluster, _ := SplitClusterStreamName(stream.Name); cluster != "" && r.cluster != "" && cluster != r.cluster {
+		return &stream, nil
+	}
+
+	if err := r.pipeline.Read(&stream); err != nil {
+		return nil, err
+	}
+
+	if err := r.limits.AddResources(stream.Name, stream.Data); err != nil {
+		return nil, err
+	}
+
+	return &stream, nil
+}
+
+// Seen indicates whether the stream with the given name was already read from the archive
+func (r *Reader) Seen(name string) bool {
+	if r.cluster != "" && r.seen[ClusterStreamName(r.cluster, name)] {
+		return true
+	}
+
+	return r.seen[name]
+}
+
+// Close closes the reader. It does not close the underlying reader.
+func (r *Reader) Close() error {
+	return r.gzipReader.Close()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// Writer writes the streams into the backup archive. Each stream passes through the pipeline and is written as a
+// separate GZIP member, so that the archive can be read by the standard GZIP tools as well.
+type Writer struct {
+	bufferedWriter *bufio.Writer
+	countingWriter *countingWriter
+	gzipWriter     *gzip.Writer
+	pipeline       Pipeline
+}
+
+// NewWriter creates the writer writing the archive into the file or any other writer
+func NewWriter(writer io.Writer, stages ...Stage) *Writer {
+	bufferedWriter := bufio.NewWriter(writer)
+	countingWriter := &countingWriter{writer: bufferedWriter}
+
+	return &Writer{
+		bufferedWriter: bufferedWriter,
+		countingWriter: countingWriter,
+		gzipWriter:     gzip.NewWriter(countingWriter),
+		pipeline:       stages,
+	}
+}
+
+// Write passes the stream through the pipeline and writes it as a new GZIP member. The data of the stream are replaced
+// with the transformed data. It returns the compressed size of the stream.
+func (w *Writer) Write(stream *Stream) (int64, error) {
+	if err := w.pipeline.Write(stream); err != nil {
+		return 0, err
+	}
+
+	return w.Copy(*stream)
+}
+
+// Copy writes the stream as a new GZIP member without passing it through the pipeline. It is used to copy the streams
+// read from another archive. It returns the compressed size of the stream.
+func (w *Writer) Copy(stream Stream) (int64, error) {
+	compressedBefore := w.countingWriter.count
+
+	w.gzipWriter.Reset(w.countingWriter)
+	w.gzipWriter.Name = stream.Name
+	w.gzipWriter.Comment = stream.Comment
+	w.gzipWriter.ModTime = stream.ModTime
+
+	if _, err := w.gzipWriter.Write(stream.Data); err != nil {
+		return 0, err
+	}
+
+	if err := w.gzipWriter.Close(); err != nil {
+		return 0, err
+	}
+
+	return w.countingWriter.count - compressedBefore, nil
+}
+
+// Close flushes the archive. Every GZIP member is completed when its stream is written, so only the buffered data
+// remain to be written. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.bufferedWriter.Flush()
+}
+
+// countingWriter counts the bytes written through it to measure the compressed size of the streams
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count += int64(n)
+	return n, err
+}
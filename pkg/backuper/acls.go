@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"cmp"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"log/slog"
+	"slices"
+)
+
+// normalizeAcls sorts the ACL rules of the KafkaUser and the operations within them and removes the duplicate rules.
+// Kafka treats the ACL rules as a set, so the normalization does not change their meaning. But it makes sure that the
+// backups of unchanged users are identical regardless of the order in which the rules were written.
+func normalizeAcls(user *v1beta2.KafkaUser) {
+	if user.Spec == nil || user.Spec.Authorization == nil || len(user.Spec.Authorization.Acls) == 0 {
+		return
+	}
+
+	acls := user.Spec.Authorization.Acls
+	for i := range acls {
+		slices.Sort(acls[i].Operations)
+		acls[i].Operations = slices.Compact(acls[i].Operations)
+	}
+
+	slices.SortStableFunc(acls, compareAclRules)
+	normalized := slices.CompactFunc(acls, func(a v1beta2.AclRule, b v1beta2.AclRule) bool {
+		return compareAclRules(a, b) == 0
+	})
+
+	if removed := len(acls) - len(normalized); removed > 0 {
+		slog.Info("Removed duplicate ACL rules from KafkaUser", "name", user.Name, "duplicates", removed)
+	}
+
+	user.Spec.Authorization.Acls = normalized
+}
+
+// compareAclRules orders the ACL rules by their resource first, so that the rules for the same resource are next to
+// each other
+func compareAclRules(a v1beta2.AclRule, b v1beta2.AclRule) int {
+	var aResource, bResource v1beta2.AclRuleResource
+	if a.Resource != nil {
+		aResource = *a.Resource
+	}
+	if b.Resource != nil {
+		bResource = *b.Resource
+	}
+
+	return cmp.Or(
+		cmp.Compare(aResource.Type, bResource.Type),
+		cmp.Compare(aResource.Name, bResource.Name),
+		cmp.Compare(aResource.PatternType, bResource.PatternType),
+		cmp.Compare(a.Type, b.Type),
+		cmp.Compare(a.Host, b.Host),
+		cmp.Compare(a.Operation, b.Operation),
+		slices.Compare(a.Operations, b.Operations),
+	)
+}
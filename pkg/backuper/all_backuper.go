@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"log/slog"
+	"strings"
+)
+
+// DiscoverConnect finds the Kafka Connect cluster in the namespace of the Kafka cluster which connects to it through
+// its bootstrap service. Returns an empty name when there is no such cluster. The backup can contain only one Kafka
+// Connect cluster, so the discovery fails when more of them are found.
+func (b *KafkaBackuper) DiscoverConnect() (string, error) {
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaConnects(b.Namespace).List(b.ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the Kafka Connect clusters", "namespace", b.Namespace, "error", err)
+		return "", err
+	}
+
+	var names []string
+	for _, resource := range resources.Items {
+		if resource.Spec != nil && utils.UsesBootstrapService(resource.Spec.BootstrapServers, resource.Namespace, b.Name, b.Namespace) {
+			names = append(names, resource.Name)
+		}
+	}
+
+	return b.connectedCluster("connect", names)
+}
+
+// DiscoverMirrorMaker2 finds the Kafka MirrorMaker 2 cluster in the namespace of the Kafka cluster which connects to it
+// through its bootstrap service as one of its clusters. Returns an empty name when there is no such cluster.
+func (b *KafkaBackuper) DiscoverMirrorMaker2() (string, error) {
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(b.Namespace).List(b.ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the Kafka MirrorMaker 2 clusters", "namespace", b.Namespace, "error", err)
+		return "", err
+	}
+
+	var names []string
+	for _, resource := range resources.Items {
+		if resource.Spec == nil {
+			continue
+		}
+
+		for _, cluster := range resource.Spec.Clusters {
+			if utils.UsesBootstrapService(cluster.BootstrapServers, resource.Namespace, b.Name, b.Namespace) {
+				names = append(names, resource.Name)
+				break
+			}
+		}
+	}
+
+	return b.connectedCluster("mirrormaker2", names)
+}
+
+// DiscoverMirrorMaker finds the legacy Kafka MirrorMaker cluster in the namespace of the Kafka cluster which consumes
+// from it or produces to it through its bootstrap service. Returns an empty name when there is no such cluster or when
+// the KafkaMirrorMaker custom resource definition is not installed.
+func (b *KafkaBackuper) DiscoverMirrorMaker() (string, error) {
+	resources, err := b.DynamicClient.Resource(utils.KafkaMirrorMakerResource).Namespace(b.Namespace).List(b.ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		slog.Debug("The KafkaMirrorMaker custom resource definition is not installed", "namespace", b.Namespace)
+		return "", nil
+	} else if err != nil {
+		slog.Error("Failed to list the Kafka MirrorMaker clusters", "namespace", b.Namespace, "error", err)
+		return "", err
+	}
+
+	var names []string
+	for _, resource := range resources.Items {
+		for _, side := range []string{"consumer", "producer"} {
+			bootstrapServers, _, _ := unstructured.NestedString(resource.Object, "spec", side, "bootstrapServers")
+			if utils.UsesBootstrapService(bootstrapServers, resource.GetNamespace(), b.Name, b.Namespace) {
+				names = append(names, resource.GetName())
+				break
+			}
+		}
+	}
+
+	return b.connectedCluster("mirrormaker", names)
+}
+
+// DiscoverBridge finds the Kafka Bridge in the namespace of the Kafka cluster which connects to it through its
+// bootstrap service. Returns an empty name when there is no such bridge.
+func (b *KafkaBackuper) DiscoverBridge() (string, error) {
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaBridges(b.Namespace).List(b.ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the Kafka Bridges", "namespace", b.Namespace, "error", err)
+		return "", err
+	}
+
+	var names []string
+	for _, resource := range resources.Items {
+		if resource.Spec != nil && utils.UsesBootstrapService(resource.Spec.BootstrapServers, resource.Namespace, b.Name, b.Namespace) {
+			names = append(names, resource.Name)
+		}
+	}
+
+	return b.connectedCluster("bridge", names)
+}
+
+// connectedCluster returns the only cluster of the given kind connected to the Kafka cluster
+func (b *KafkaBackuper) connectedCluster(kind string, names []string) (string, error) {
+	switch len(names) {
+	case 0:
+		slog.Info("No cluster connected to the Kafka cluster found", "kind", kind, "namespace", b.Namespace)
+		return "", nil
+	case 1:
+		slog.Info("Discovered cluster connected to the Kafka cluster", "kind", kind, "name", names[0], "namespace", b.Namespace)
+		return names[0], nil
+	default:
+		slog.Error("Multiple clusters connected to the Kafka cluster found, but the backup can contain only one of them", "kind", kind, "names", names, "namespace", b.Namespace)
+		return "", fmt.Errorf("multiple %s clusters (%s) use the Kafka cluster %s: select one of them with the --%s-name option", kind, strings.Join(names, ", "), b.Name, kind)
+	}
+}
+
+// component returns a copy of the backuper writing into the same backup file under the name of a cluster connected to
+// the Kafka cluster. The streams written by the copy are taken over using collect once it is done.
+func (b *Backuper) component(name string) Backuper {
+	component := *b
+	component.Name = name
+
+	return component
+}
+
+// collect takes over the streams and the lint findings written by a copy of the backuper created by component
+func (b *Backuper) collect(component *Backuper) {
+	b.streamStats = component.streamStats
+	b.lintFindings = component.lintFindings
+}
+
+// backupConnect backs up the Kafka Connect cluster with the given name into the backup of the Kafka cluster
+func (b *KafkaBackuper) backupConnect(name string, skipSecrets bool) error {
+	cb := ConnectBackuper{Backuper: b.component(name), skipSecrets: skipSecrets}
+	defer b.collect(&cb.Backuper)
+
+	if err := cb.BackupKafkaConnect(); err != nil {
+		return err
+	}
+
+	if err := cb.BackupKafkaConnectors(); err != nil {
+		return err
+	}
+
+	return cb.BackupReferencedResources()
+}
+
+// backupMirrorMaker2 backs up the Kafka MirrorMaker 2 cluster with the given name into the backup of the Kafka cluster
+func (b *KafkaBackuper) backupMirrorMaker2(name string, skipSecrets bool) error {
+	mb := MirrorMaker2Backuper{Backuper: b.component(name), skipSecrets: skipSecrets}
+	defer b.collect(&mb.Backuper)
+
+	if err := mb.BackupKafkaMirrorMaker2(); err != nil {
+		return err
+	}
+
+	return mb.BackupReferencedResources()
+}
+
+// backupMirrorMaker backs up the legacy Kafka MirrorMaker cluster with the given name into the backup of the Kafka
+// cluster
+func (b *KafkaBackuper) backupMirrorMaker(name string, skipSecrets bool) error {
+	mb := MirrorMakerBackuper{Backuper: b.component(name), skipSecrets: skipSecrets}
+	defer b.collect(&mb.Backuper)
+
+	if err := mb.BackupKafkaMirrorMaker(); err != nil {
+		return err
+	}
+
+	return mb.BackupReferencedResources()
+}
+
+// backupBridge backs up the Kafka Bridge with the given name into the backup of the Kafka cluster
+func (b *KafkaBackuper) backupBridge(name string, skipSecrets bool) error {
+	bb := BridgeBackuper{Backuper: b.component(name), skipSecrets: skipSecrets}
+	defer b.collect(&bb.Backuper)
+
+	if err := bb.BackupKafkaBridge(); err != nil {
+		return err
+	}
+
+	return bb.BackupReferencedResources()
+}
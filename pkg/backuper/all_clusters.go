@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/phases"
+	"log/slog"
+)
+
+// AllClustersName is used instead of the name of the Kafka cluster for the backup of all Kafka clusters in the namespace
+// created with the --all-clusters option. It is used in the name of the backup file and in the storage location.
+const AllClustersName = "all-clusters"
+
+// clusterBackuper returns a copy of the backuper backing up the Kafka cluster with the given name into the same backup. Its
+// streams are prefixed with the name of the Kafka cluster.
+func (b *KafkaBackuper) clusterBackuper(name string) *KafkaBackuper {
+	cluster := *b
+	cluster.Name = name
+	cluster.cluster = name
+
+	return &cluster
+}
+
+// AllClustersPhases returns the phases of the backup of multiple Kafka clusters from the same namespace into a single
+// backup. The resources of each Kafka cluster are backed up in their own phases prefixed with the cluster name. The
+// manifest contains the index of the streams of each Kafka cluster.
+func (b *KafkaBackuper) AllClustersPhases(names []string, options KafkaBackupOptions) ([]phases.Phase, error) {
+	if options.TrackHistory {
+		slog.Error("--track-history option cannot be used together with the --all-clusters option")
+		return nil, fmt.Errorf("--track-history option cannot be used together with the --all-clusters option")
+	}
+
+	clusters := make([]*KafkaBackuper, 0, len(names))
+	for _, name := range names {
+		clusters = append(clusters, b.clusterBackuper(name))
+	}
+
+	// The quiesced resources of all Kafka clusters are resumed when the backup is discarded
+	discard := func() {
+		for _, cluster := range clusters {
+			if err := cluster.Unquiesce(); err != nil {
+				slog.Error("Failed to resume reconciliation of the KafkaTopic and KafkaUser resources", "name", cluster.Name, "error", err)
+			}
+		}
+
+		b.Backuper.Discard()
+	}
+
+	var backupPhases []phases.Phase
+	for _, cluster := range clusters {
+		for _, phase := range cluster.clusterPhases(options, discard) {
+			backupPhases = append(backupPhases, phases.New(cluster.Name+"/"+phase.Name(), phase.Describe()+" of the Kafka cluster "+cluster.Name, func(ctx context.Context) error {
+				// The streams and lint findings are collected into the shared backup after each phase
+				cluster.streamStats = b.streamStats
+				cluster.lintFindings = b.lintFindings
+				defer b.collect(&cluster.Backuper)
+
+				return phase.Run(ctx)
+			}))
+		}
+	}
+
+	backupPhases = append(backupPhases, writePhase(PhaseWriteManifest, "Write the backup manifest with the index of the Kafka clusters", "Failed to write the backup manifest", discard, func() error {
+		b.indexClusters(clusters)
+		return b.WriteManifest()
+	}))
+
+	for _, cluster := range clusters {
+		backupPhases = append(backupPhases, finishPhase(cluster.Name+"/"+PhaseUnquiesce, "Resume the reconciliation of the KafkaTopics and KafkaUsers of the Kafka cluster "+cluster.Name, "Failed to resume the Entity Operator", cluster.Unquiesce))
+	}
+
+	backupPhases = append(backupPhases, finishPhase(PhaseUpload, "Upload the backup to the storage", "Failed to upload the backup to the storage", b.Upload))
+
+	if options.AnnotateKafka {
+		for _, cluster := range clusters {
+			backupPhases = append(backupPhases, finishPhase(cluster.Name+"/"+PhaseAnnotateKafka, "Annotate the Kafka resource of the Kafka cluster "+cluster.Name+" with the last backup", "Failed to annotate the Kafka resource with the last backup", cluster.AnnotateKafka))
+		}
+	}
+
+	return backupPhases, nil
+}
+
+// indexClusters records the streams of each Kafka cluster for the manifest
+func (b *KafkaBackuper) indexClusters(clusters []*KafkaBackuper) {
+	b.clusterIndex = nil
+	for _, cluster := range clusters {
+		index := archive.ClusterIndex{Namespace: cluster.Namespace, Name: cluster.Name, OperatorVersion: cluster.operatorVersion}
+		for _, stats := range b.streamStats {
+			if name, _ := archive.SplitClusterStreamName(stats.Name); name == cluster.Name {
+				index.Streams = append(index.Streams, stats.Name)
+			}
+		}
+
+		b.clusterIndex = append(b.clusterIndex, index)
+	}
+}
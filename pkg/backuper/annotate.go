@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"log/slog"
+	"time"
+)
+
+const (
+	// LastBackupTimestampAnnotation contains the time of the last successful backup of the Kafka cluster
+	LastBackupTimestampAnnotation = "strimzi-backup/last-backup-timestamp"
+	// LastBackupLocationAnnotation contains the location of the last successful backup of the Kafka cluster
+	LastBackupLocationAnnotation = "strimzi-backup/last-backup-location"
+)
+
+// AnnotateKafka records the time and the location of the successful backup in the annotations of the Kafka resource,
+// so that the backup posture is visible alongside the cluster status. When the backup was uploaded to the storage, the
+// location points to the uploaded backup.
+func (b *KafkaBackuper) AnnotateKafka() error {
+	location := b.Location()
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				LastBackupTimestampAnnotation: time.Now().UTC().Format(time.RFC3339),
+				LastBackupLocationAnnotation:  location,
+			},
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to marshal the annotations of the Kafka resource", "error", err)
+		return err
+	}
+
+	// The backup deadline might be already expired at this point, so we do not use it here
+	if _, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Patch(context.Background(), b.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: utils.FieldManager}); err != nil {
+		slog.Error("Failed to annotate the Kafka resource with the last backup", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("Annotated the Kafka resource with the last backup", "name", b.Name, "namespace", b.Namespace, "location", location)
+
+	return nil
+}
+
+// removeLastBackupAnnotations removes the annotations describing the last backup, so that they are not restored
+func removeLastBackupAnnotations(metadata *metav1.ObjectMeta) {
+	delete(metadata.Annotations, LastBackupTimestampAnnotation)
+	delete(metadata.Annotations, LastBackupLocationAnnotation)
+}
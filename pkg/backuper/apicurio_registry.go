@@ -0,0 +1,205 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/registry"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ApicurioRegistriesFilename         = "apicurio-registries.yaml"
+	ApicurioRegistryConfigMapsFilename = "apicurio-registry-config-maps.yaml"
+	ApicurioRegistryArtifactsPrefix    = "apicurio-registry-artifacts-"
+	ApicurioRegistryArtifactsSuffix    = ".zip"
+)
+
+// ApicurioRegistryArtifactsStreamName returns the name of the stream with the artifacts exported from the Apicurio
+// Registry
+func ApicurioRegistryArtifactsStreamName(registryName string) string {
+	return ApicurioRegistryArtifactsPrefix + registryName + ApicurioRegistryArtifactsSuffix
+}
+
+// RegistryFromArtifactsStreamName returns the name of the Apicurio Registry whose artifacts are stored in the stream
+// and whether the stream contains the artifacts
+func RegistryFromArtifactsStreamName(name string) (string, bool) {
+	if !strings.HasPrefix(name, ApicurioRegistryArtifactsPrefix) || !strings.HasSuffix(name, ApicurioRegistryArtifactsSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(name, ApicurioRegistryArtifactsPrefix), ApicurioRegistryArtifactsSuffix), true
+}
+
+// BackupApicurioRegistries backs up the Apicurio Registries storing their data in the Kafka cluster using the KafkaSQL
+// storage. The custom resources and the ConfigMaps referenced from their environment variables are stored as YAML and
+// the artifacts are exported using the admin REST API of each registry.
+func (b *KafkaBackuper) BackupApicurioRegistries() error {
+	start := time.Now()
+
+	slog.Info("Backing up the Apicurio Registries using the Kafka cluster", "name", b.Name, "namespace", b.Namespace)
+
+	registries := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	for _, resource := range []struct {
+		kind string
+		list func() (*unstructured.UnstructuredList, error)
+	}{
+		{registry.ApicurioRegistryKind, func() (*unstructured.UnstructuredList, error) {
+			return b.DynamicClient.Resource(registry.ApicurioRegistryResource).Namespace(b.Namespace).List(b.ctx, metav1.ListOptions{})
+		}},
+		{registry.ApicurioRegistry3Kind, func() (*unstructured.UnstructuredList, error) {
+			return b.DynamicClient.Resource(registry.ApicurioRegistry3Resource).Namespace(b.Namespace).List(b.ctx, metav1.ListOptions{})
+		}},
+	} {
+		resources, err := resource.list()
+		if errors.IsNotFound(err) {
+			slog.Debug("The Apicurio Registry custom resource is not installed", "kind", resource.kind)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the Apicurio Registries", "kind", resource.kind, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		sortByName(resources.Items)
+		for _, item := range resources.Items {
+			if registry.UsesKafkaCluster(&item, b.Name, b.Namespace) {
+				slog.Debug("Backing up Apicurio Registry", "kind", resource.kind, "name", item.GetName())
+				registries.Items = append(registries.Items, item)
+			}
+		}
+	}
+
+	if len(registries.Items) == 0 {
+		slog.Warn("No Apicurio Registries using the Kafka cluster found", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	var configMapNames []string
+	for _, item := range registries.Items {
+		for _, configMapName := range registry.ConfigMapNames(&item) {
+			if !slices.Contains(configMapNames, configMapName) {
+				configMapNames = append(configMapNames, configMapName)
+			}
+		}
+	}
+	slices.Sort(configMapNames)
+
+	if err := b.backupApicurioRegistryConfigMaps(configMapNames); err != nil {
+		return err
+	}
+
+	if !b.skipMetadataCleansing {
+		// We want to avoid copying the resource, so we use the index
+		for i := range registries.Items {
+			registries.Items[i].SetManagedFields(nil)
+			registries.Items[i].SetResourceVersion("")
+			registries.Items[i].SetUID("")
+			registries.Items[i].SetGeneration(0)
+			registries.Items[i].SetCreationTimestamp(metav1.Time{})
+			registries.Items[i].SetOwnerReferences(nil)
+			unstructured.RemoveNestedField(registries.Items[i].Object, "status")
+		}
+	}
+
+	registriesYaml, err := yaml.Marshal(registries)
+	if err != nil {
+		slog.Error("Failed to marshal the Apicurio Registries to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(ApicurioRegistriesFilename, StreamDescription(ApicurioRegistriesFilename), registriesYaml, len(registries.Items), start); err != nil {
+		return err
+	}
+
+	// The artifacts are stored after the custom resources, so that the kind of each registry is known when importing
+	// them during the restore
+	for _, item := range registries.Items {
+		if err := b.backupApicurioRegistryArtifacts(item.GetKind(), item.GetName()); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Backup of the Apicurio Registries complete", "registries", len(registries.Items))
+
+	return nil
+}
+
+// backupApicurioRegistryConfigMaps backs up the ConfigMaps referenced from the Apicurio Registries
+func (b *KafkaBackuper) backupApicurioRegistryConfigMaps(names []string) error {
+	start := time.Now()
+
+	resources := &v1.ConfigMapList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMapList"}}
+	for _, name := range names {
+		configMap, err := b.KubernetesClient.CoreV1().ConfigMaps(b.Namespace).Get(b.ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Warn("The ConfigMap referenced from the Apicurio Registry does not exist", "name", name, "namespace", b.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the ConfigMap referenced from the Apicurio Registry", "name", name, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&configMap.ObjectMeta)
+		}
+
+		resources.Items = append(resources.Items, *configMap)
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the ConfigMaps to YAML", "error", err)
+		return err
+	}
+
+	return b.writeStream(ApicurioRegistryConfigMapsFilename, StreamDescription(ApicurioRegistryConfigMapsFilename), resourcesYaml, len(resources.Items), start)
+}
+
+// backupApicurioRegistryArtifacts exports the artifacts from the Apicurio Registry and stores the exported ZIP file as a
+// new stream
+func (b *KafkaBackuper) backupApicurioRegistryArtifacts(kind string, name string) error {
+	start := time.Now()
+
+	url := registry.DefaultURL(kind, name, b.Namespace)
+	if b.apicurioRegistryURL != "" {
+		url = strings.NewReplacer("{namespace}", b.Namespace, "{name}", name).Replace(b.apicurioRegistryURL)
+	}
+
+	slog.Info("Exporting the artifacts from the Apicurio Registry", "name", name, "url", url)
+
+	client, err := registry.NewClient(url, kind, b.apicurioRegistryHeaders)
+	if err != nil {
+		slog.Error("Failed to create the Apicurio Registry client", "name", name, "error", err)
+		return err
+	}
+
+	data, err := client.Export(b.ctx)
+	if err != nil {
+		slog.Error("Failed to export the artifacts from the Apicurio Registry", "name", name, "url", url, "error", err)
+		return err
+	}
+
+	return b.writeStream(ApicurioRegistryArtifactsStreamName(name), "Artifacts exported from the Apicurio Registry "+name, data, 1, start)
+}
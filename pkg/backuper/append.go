@@ -0,0 +1,313 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/storage"
+	"github.com/spf13/cobra"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// AppendCaSecrets appends the Cluster and Clients CA Secrets
+	AppendCaSecrets = "ca-secrets"
+	// AppendUserSecrets appends the Kafka User Secrets
+	AppendUserSecrets = "user-secrets"
+	// AppendClusterLayout appends the StrimziPodSets and the Kafka node assignments
+	AppendClusterLayout = "cluster-layout"
+	// AppendListenerCertificates appends the Secrets with the custom listener certificates
+	AppendListenerCertificates = "listener-certificates"
+	// AppendAuthenticationSecrets appends the Secrets used by the authentication of the Kafka listeners and by the
+	// authorization
+	AppendAuthenticationSecrets = "authentication-secrets"
+	// AppendConfigMaps appends the ConfigMaps referenced from the Kafka cluster
+	AppendConfigMaps = "config-maps"
+	// AppendReferencedSecrets appends the Secrets referenced from the pod templates and the configuration of the Kafka
+	// cluster
+	AppendReferencedSecrets = "referenced-secrets"
+	// AppendExternalConnectivity appends the cert-manager Certificates and the external-dns DNSEndpoints of the external
+	// listeners and the OpenShift Routes of the route listeners
+	AppendExternalConnectivity = "external-connectivity"
+	// AppendEntityOperator appends the Secrets with the certificates and the RoleBindings of the Entity Operator
+	AppendEntityOperator = "entity-operator"
+	// AppendRebalances appends the KafkaRebalances
+	AppendRebalances = "rebalances"
+)
+
+// appendableStreams maps the resources which can be appended to an existing backup to the streams they are stored in
+var appendableStreams = map[string][]string{
+	AppendCaSecrets:             {CaSecretsFilename},
+	AppendUserSecrets:           {KafkaUserSecretsFilename},
+	AppendClusterLayout:         {StrimziPodSetsFilename, NodeAssignmentsFilename},
+	AppendListenerCertificates:  {KafkaListenerCertificatesFilename},
+	AppendAuthenticationSecrets: {KafkaAuthenticationSecretsFilename},
+	AppendConfigMaps:            {KafkaConfigMapsFilename},
+	AppendReferencedSecrets:     {KafkaReferencedSecretsFilename},
+	AppendExternalConnectivity:  {CertManagerCertificatesFilename, ExternalDnsEndpointsFilename, OpenShiftRoutesFilename},
+	AppendEntityOperator:        {EntityOperatorSecretsFilename, EntityOperatorRoleBindingsFilename},
+	AppendRebalances:            {KafkaRebalancesFilename},
+}
+
+// Appender adds streams with additional resources to an existing backup. The backup is rewritten into a temporary file
+// which replaces the original backup only when all streams and the updated manifest are written.
+type Appender struct {
+	KafkaBackuper
+
+	BackupFileName string
+	Add            []string
+	streams        []archive.Stream
+	manifest       *archive.Manifest
+}
+
+func NewAppender(cmd *cobra.Command) (*Appender, error) {
+	backupFileName := cmd.Flag("filename").Value.String()
+	if backupFileName == "" {
+		slog.Error("--filename option is required")
+		return nil, fmt.Errorf("--filename option is required")
+	}
+
+	add, err := cmd.Flags().GetStringSlice("add")
+	if err != nil {
+		slog.Error("Failed to get the --add flag", "error", err)
+		return nil, err
+	}
+
+	if len(add) == 0 {
+		slog.Error("--add option is required")
+		return nil, fmt.Errorf("--add option is required")
+	}
+
+	streams, err := archive.ReadStreams(backupFileName)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", backupFileName)
+		return nil, err
+	}
+
+	for _, resources := range add {
+		names, ok := appendableStreams[resources]
+		if !ok {
+			slog.Error("Unsupported resources in the --add option", "add", resources)
+			return nil, fmt.Errorf("unsupported resources %s in the --add option (supported are %s, %s, %s, %s, %s, %s, %s, %s, %s, and %s)", resources, AppendCaSecrets, AppendUserSecrets, AppendClusterLayout, AppendListenerCertificates, AppendAuthenticationSecrets, AppendConfigMaps, AppendReferencedSecrets, AppendExternalConnectivity, AppendEntityOperator, AppendRebalances)
+		}
+
+		for _, stream := range streams {
+			if slices.Contains(names, stream.Name) {
+				slog.Error("The backup already contains the stream", "stream", stream.Name, "file", backupFileName)
+				return nil, fmt.Errorf("the backup %s already contains the stream %s", backupFileName, stream.Name)
+			}
+		}
+	}
+
+	manifest, err := archive.FindManifest(streams)
+	if err != nil {
+		slog.Error("Failed to read the backup manifest", "error", err, "file", backupFileName)
+		return nil, err
+	}
+
+	target := Target{Name: cmd.Flag("name").Value.String(), Namespace: cmd.Flag("namespace").Value.String()}
+	if manifest != nil {
+		if (target.Name != "" && target.Name != manifest.Name) || (target.Namespace != "" && target.Namespace != manifest.Namespace) {
+			slog.Error("The backup belongs to a different Kafka cluster", "name", manifest.Name, "namespace", manifest.Namespace, "file", backupFileName)
+			return nil, fmt.Errorf("the backup %s belongs to the Kafka cluster %s in namespace %s", backupFileName, manifest.Name, manifest.Namespace)
+		}
+
+		target.Name = manifest.Name
+		target.Namespace = manifest.Namespace
+
+		if manifest.HMAC != "" && cmd.Flag("hmac-key-file").Value.String() == "" {
+			// Appending without the key would leave the backup with a signature which does not match its content
+			slog.Error("The backup manifest is signed and the --hmac-key-file option is required to sign it again", "file", backupFileName)
+			return nil, fmt.Errorf("the backup manifest is signed and the --hmac-key-file option is required to sign it again")
+		}
+	} else {
+		slog.Warn("The backup does not contain a manifest, the Kafka cluster is taken from the --name and --namespace options", "file", backupFileName)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		slog.Error("Failed to generate the name of the temporary backup file", "error", err)
+		return nil, err
+	}
+	target.FileName = filepath.Join(filepath.Dir(backupFileName), "."+filepath.Base(backupFileName)+".append-"+hex.EncodeToString(suffix))
+
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	appender := Appender{
+		KafkaBackuper:  KafkaBackuper{Backuper: *backuper},
+		BackupFileName: backupFileName,
+		Add:            add,
+		streams:        streams,
+		manifest:       manifest,
+	}
+
+	return &appender, nil
+}
+
+// Append copies the streams of the original backup, backs up the additional resources, writes the updated manifest,
+// and replaces the original backup. The creation time of the original backup is kept in the manifest.
+func (a *Appender) Append() error {
+	originalStats := map[string]archive.StreamStats{}
+	createdAt := time.Now()
+	if a.manifest != nil {
+		for _, stats := range a.manifest.Streams {
+			originalStats[stats.Name] = stats
+		}
+
+		a.lintFindings = append(a.lintFindings, a.manifest.Findings...)
+		createdAt = a.manifest.CreatedAt
+
+		// The usage statistics of the original backup are kept unless they are collected again for the updated backup
+		a.usage = a.manifest.Usage
+		a.operatorVersion = a.manifest.OperatorVersion
+	}
+
+	for _, stream := range a.streams {
+		if stream.Name == archive.ManifestFilename {
+			continue
+		}
+
+		slog.Debug("Copying stream from the original backup", "stream", stream.Name)
+
+		// The streams are copied as they are, so that the encrypted streams do not need to be decrypted
+		compressedBytes, err := a.writer.Copy(stream)
+		if err != nil {
+			slog.Error("Failed to copy the stream from the original backup", "stream", stream.Name, "error", err)
+			return err
+		}
+
+		stats, ok := originalStats[stream.Name]
+		if !ok {
+			stats = archive.StreamStats{Name: stream.Name, Resources: archive.CountResources(stream.Data)}
+		}
+		stats.UncompressedBytes = int64(len(stream.Data))
+		stats.CompressedBytes = compressedBytes
+		stats.Digest = archive.Digest(stream.Data)
+		stats.Encrypted = archive.IsEncrypted(stream.Data)
+
+		a.streamStats = append(a.streamStats, stats)
+	}
+
+	for _, resources := range a.Add {
+		slog.Info("Appending resources to the backup", "resources", resources, "file", a.BackupFileName)
+
+		var err error
+		switch resources {
+		case AppendCaSecrets:
+			err = a.BackupCaSecrets()
+		case AppendUserSecrets:
+			err = a.BackupUserSecrets()
+		case AppendClusterLayout:
+			if err = a.BackupStrimziPodSets(); err == nil {
+				err = a.BackupNodeAssignments()
+			}
+		case AppendListenerCertificates:
+			err = a.BackupListenerCertificates()
+		case AppendAuthenticationSecrets:
+			err = a.BackupAuthenticationSecrets()
+		case AppendConfigMaps:
+			err = a.BackupKafkaConfigMaps()
+		case AppendReferencedSecrets:
+			err = a.BackupKafkaReferencedSecrets()
+		case AppendExternalConnectivity:
+			err = a.BackupExternalConnectivity()
+		case AppendEntityOperator:
+			err = a.BackupEntityOperator()
+		case AppendRebalances:
+			err = a.BackupKafkaRebalances()
+		}
+
+		if err != nil {
+			slog.Error("Failed to append resources to the backup", "resources", resources, "error", err)
+			return err
+		}
+	}
+
+	if err := a.writeManifest(createdAt); err != nil {
+		slog.Error("Failed to write the backup manifest", "error", err)
+		return err
+	}
+
+	a.Close()
+
+	if info, err := os.Stat(a.BackupFileName); err == nil {
+		if err := os.Chmod(a.FileName(), info.Mode().Perm()); err != nil {
+			slog.Warn("Failed to copy the permissions of the original backup", "file", a.BackupFileName, "error", err)
+		}
+	}
+
+	if err := os.Rename(a.FileName(), a.BackupFileName); err != nil {
+		slog.Error("Failed to replace the original backup", "file", a.BackupFileName, "error", err)
+		return err
+	}
+
+	return a.updateChecksumFile()
+}
+
+// Upload uploads the updated backup together with its checksum into the storage configured with the --storage option.
+// Does nothing when no storage is configured.
+func (a *Appender) Upload() error {
+	if a.storage == nil {
+		return nil
+	}
+	defer a.cancel()
+
+	return a.uploadFile(a.BackupFileName)
+}
+
+// updateChecksumFile updates the checksum file next to the local backup if it exists, so that it matches the updated
+// backup
+func (a *Appender) updateChecksumFile() error {
+	checksumFileName := a.BackupFileName + storage.ChecksumSuffix
+	if _, err := os.Stat(checksumFileName); err != nil {
+		return nil
+	}
+
+	file, err := os.Open(a.BackupFileName)
+	if err != nil {
+		slog.Error("Failed to open the backup file", "error", err, "file", a.BackupFileName)
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		slog.Error("Failed to calculate the checksum of the backup", "error", err, "file", a.BackupFileName)
+		return err
+	}
+
+	if err := os.WriteFile(checksumFileName, []byte(hex.EncodeToString(hash.Sum(nil))+"  "+filepath.Base(a.BackupFileName)+"\n"), 0644); err != nil {
+		slog.Error("Failed to update the checksum file", "error", err, "file", checksumFileName)
+		return err
+	}
+
+	slog.Info("Checksum file updated", "file", checksumFileName)
+
+	return nil
+}
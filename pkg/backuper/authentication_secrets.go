@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+)
+
+const (
+	KafkaAuthenticationSecretsFilename = "kafka-authentication-secrets.yaml"
+)
+
+// BackupAuthenticationSecrets backs up the Secrets referenced from the authentication of the Kafka listeners and from
+// the authorization of the Kafka cluster, such as the OAuth client secrets and the trusted certificates of the
+// authorization servers. Without them, the Kafka cluster using OAuth cannot be reconciled after the restore. Nothing is
+// written when the Kafka resource does not reference any such Secrets.
+func (b *KafkaBackuper) BackupAuthenticationSecrets() error {
+	kafka, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	names := authenticationSecrets(kafka)
+	if len(names) == 0 {
+		slog.Info("No Secrets referenced from the authentication and authorization of the Kafka cluster found", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	return b.backupReferencedSecrets(KafkaAuthenticationSecretsFilename, names)
+}
+
+// authenticationSecrets returns the names of the Secrets referenced from the authentication of the Kafka listeners
+// (OAuth client secrets and trusted certificates and the Secrets of the custom authentication), from the Keycloak
+// authorization, and from the Cruise Control API users
+func authenticationSecrets(kafka *v1beta2.Kafka) []string {
+	if kafka.Spec == nil {
+		return nil
+	}
+
+	var secrets []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(secrets, name) {
+			secrets = append(secrets, name)
+		}
+	}
+
+	if kafka.Spec.Kafka != nil {
+		for _, listener := range kafka.Spec.Kafka.Listeners {
+			auth := listener.Authentication
+			if auth == nil {
+				continue
+			}
+
+			for _, certificate := range auth.TlsTrustedCertificates {
+				add(certificate.SecretName)
+			}
+
+			if auth.ClientSecret != nil {
+				add(auth.ClientSecret.SecretName)
+			}
+
+			for _, secret := range auth.Secrets {
+				add(secret.SecretName)
+			}
+		}
+
+		if kafka.Spec.Kafka.Authorization != nil {
+			for _, certificate := range kafka.Spec.Kafka.Authorization.TlsTrustedCertificates {
+				add(certificate.SecretName)
+			}
+		}
+	}
+
+	if kafka.Spec.CruiseControl != nil && kafka.Spec.CruiseControl.ApiUsers != nil && kafka.Spec.CruiseControl.ApiUsers.ValueFrom != nil && kafka.Spec.CruiseControl.ApiUsers.ValueFrom.SecretKeyRef != nil {
+		add(kafka.Spec.CruiseControl.ApiUsers.ValueFrom.SecretKeyRef.Name)
+	}
+
+	slices.Sort(secrets)
+
+	return secrets
+}
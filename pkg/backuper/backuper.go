@@ -17,51 +17,282 @@ limitations under the License.
 package backuper
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/storage"
 	"github.com/scholzj/strimzi-backup/pkg/utils"
 	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"log/slog"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sigs.k8s.io/yaml"
+	"strings"
 	"time"
 )
 
 type Backuper struct {
 	KubernetesClient      *kubernetes.Clientset
 	StrimziClient         *strimzi.Clientset
+	DynamicClient         *dynamic.DynamicClient
 	Namespace             string
 	Name                  string
 	skipMetadataCleansing bool
+	preservedAnnotations  []string
 	backupFile            *os.File
-	bufferedWriter        *bufio.Writer
-	gzipWriter            *gzip.Writer
+	writer                *archive.Writer
+	streamStats           []archive.StreamStats
+	lintFindings          []archive.LintFinding
+	storage               storage.Storage
+	storageLocation       string
+	upload                *storage.StreamingUpload
+	hmacKey               []byte
+	usageStats            bool
+	readOnlyCheck         bool
+	usage                 *archive.UsageStats
+	operatorVersion       string
+	// The name of the Kafka cluster prefixing the streams in the backups containing multiple Kafka clusters
+	cluster      string
+	clusterIndex []archive.ClusterIndex
+	startedAt    time.Time
+	closed       bool
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
-func NewBackuper(cmd *cobra.Command) (*Backuper, error) {
-	name := cmd.Flag("name").Value.String()
+// Target identifies the Kafka cluster which should be backed up and the file it should be backed up into
+type Target struct {
+	Namespace string
+	Name      string
+	FileName  string
+}
+
+// ParseTargets parses the clusters to back up from the --name option. Multiple clusters can be specified as a
+// comma-separated list. Clusters from namespaces other than the default one can be specified as <namespace>/<name>.
+// When the --namespace-selector option is used, the clusters are discovered in the matching namespaces instead. Each
+// cluster is backed up into its own backup file. When the --all-clusters option is used, all clusters in the namespace
+// are discovered and backed up into a single backup file.
+func ParseTargets(cmd *cobra.Command) ([]Target, error) {
+	var targets []Target
+
+	allClusters, err := cmd.Flags().GetBool("all-clusters")
+	if err != nil {
+		slog.Error("Failed to get the --all-clusters flag", "error", err)
+		return nil, err
+	}
+
+	timeout, err := cmd.Flags().GetUint32("timeout")
+	if err != nil {
+		slog.Error("Failed to get the --timeout flag", "error", err)
+		return nil, err
+	}
+
+	// The discovery of the Kafka clusters is bound by the backup timeout as well
+	ctx, cancel := backupContext(timeout)
+	defer cancel()
+
+	discover := cmd.Flag("namespace-selector").Value.String() != "" || allClusters
+	if allClusters {
+		if cmd.Flag("name").Value.String() != "" || cmd.Flag("namespace-selector").Value.String() != "" {
+			slog.Error("--all-clusters option cannot be used together with the --name and --namespace-selector options")
+			return nil, fmt.Errorf("--all-clusters option cannot be used together with the --name and --namespace-selector options")
+		}
+
+		discovered, err := discoverNamespaceTargets(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(discovered) == 0 {
+			slog.Error("No Kafka clusters found in the namespace")
+			return nil, fmt.Errorf("no Kafka clusters found in the namespace")
+		}
+
+		targets = discovered
+	} else if discover {
+		if cmd.Flag("name").Value.String() != "" {
+			slog.Error("--name and --namespace-selector options cannot be used together")
+			return nil, fmt.Errorf("--name and --namespace-selector options cannot be used together")
+		}
+
+		discovered, err := discoverTargets(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(discovered) == 0 {
+			slog.Error("No Kafka clusters found in the namespaces matching the --namespace-selector option")
+			return nil, fmt.Errorf("no Kafka clusters found in the namespaces matching the --namespace-selector option")
+		}
+
+		targets = discovered
+	} else {
+		for _, entry := range strings.Split(cmd.Flag("name").Value.String(), ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			if namespace, name, found := strings.Cut(entry, "/"); found {
+				targets = append(targets, Target{Namespace: namespace, Name: name})
+			} else {
+				targets = append(targets, Target{Name: entry})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		slog.Error("--name option is required")
+		return nil, fmt.Errorf("--name option is required")
+	}
+
+	backupFileName := cmd.Flag("filename").Value.String()
+	if allClusters {
+		// All clusters share the same backup file
+		for i := range targets {
+			targets[i].FileName = backupFileName
+		}
+	} else if len(targets) == 1 && !discover {
+		targets[0].FileName = backupFileName
+	} else if backupFileName != "" {
+		slog.Error("--filename option cannot be used when backing up multiple clusters")
+		return nil, fmt.Errorf("--filename option cannot be used when backing up multiple clusters")
+	}
+
+	// When backing up multiple clusters, the file names are left empty and generated by NewBackuper once the namespace
+	// of each cluster is known, so that each of them gets its own file
+
+	return targets, nil
+}
+
+// backupContext creates the context with the deadline from the --timeout option. Without the timeout, the context is
+// only cancelled.
+func backupContext(timeout uint32) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	}
+
+	return context.WithCancel(context.Background())
+}
+
+func NewBackuper(cmd *cobra.Command, target Target) (*Backuper, error) {
+	name := target.Name
 	if name == "" {
 		slog.Error("--name option is required")
 		return nil, fmt.Errorf("--name option is required")
 	}
 
-	kubeClient, strimziClient, namespace, err := utils.CreateKubernetesClients(cmd)
+	kubeClient, strimziClient, dynamicClient, namespace, err := utils.CreateKubernetesClients(cmd)
 	if err != nil {
 		slog.Error("Failed to create Kubernetes clients", "error", err)
 		return nil, err
 	}
 
+	if target.Namespace != "" {
+		namespace = target.Namespace
+	}
+
 	metadataCleansing, err := cmd.Flags().GetBool("skip-metadata-cleansing")
 	if err != nil {
 		slog.Error("Failed to get the --skip-metadata-cleansing flag", "error", err)
 		return nil, err
 	}
 
-	backupFileName := cmd.Flag("filename").Value.String()
+	preservedAnnotations, err := cmd.Flags().GetStringSlice("preserved-annotations")
+	if err != nil {
+		slog.Error("Failed to get the --preserved-annotations flag", "error", err)
+		return nil, err
+	}
+
+	timeout, err := cmd.Flags().GetUint32("timeout")
+	if err != nil {
+		slog.Error("Failed to get the --timeout flag", "error", err)
+		return nil, err
+	}
+
+	backupStorage, err := storage.NewFromFlags(cmd, namespace, name)
+	if err != nil {
+		slog.Error("Failed to configure the storage", "storage", cmd.Flag("storage").Value.String(), "error", err)
+		return nil, err
+	}
+
+	var storageLocation string
+	if backupStorage != nil {
+		// The location was already validated when creating the storage
+		storageLocation, _ = storage.ExpandLocation(cmd.Flag("storage").Value.String(), namespace, name)
+	}
+
+	var hmacKey []byte
+	if hmacKeyFile := cmd.Flag("hmac-key-file").Value.String(); hmacKeyFile != "" {
+		hmacKey, err = archive.ReadHMACKey(hmacKeyFile)
+		if err != nil {
+			slog.Error("Failed to read the HMAC key", "error", err, "file", hmacKeyFile)
+			return nil, err
+		}
+	}
+
+	encryptionKey, err := archive.ReadPassphrase(cmd, true)
+	if err != nil {
+		slog.Error("Failed to read the encryption passphrase", "error", err)
+		return nil, err
+	}
+
+	encryptedStreams, err := cmd.Flags().GetStringSlice("encrypt-streams")
+	if err != nil {
+		slog.Error("Failed to get the --encrypt-streams flag", "error", err)
+		return nil, err
+	}
+
+	for _, pattern := range encryptedStreams {
+		if _, err := path.Match(pattern, ""); err != nil {
+			slog.Error("Invalid pattern in the --encrypt-streams option", "pattern", pattern, "error", err)
+			return nil, err
+		}
+	}
+
+	usageStats, err := cmd.Flags().GetBool("usage-stats")
+	if err != nil {
+		slog.Error("Failed to get the --usage-stats flag", "error", err)
+		return nil, err
+	}
+
+	// The read-only check is available only in the backup commands
+	var readOnlyCheck bool
+	if cmd.Flags().Lookup("read-only-check") != nil {
+		readOnlyCheck, err = cmd.Flags().GetBool("read-only-check")
+		if err != nil {
+			slog.Error("Failed to get the --read-only-check flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// The streaming upload is available only in the backup commands
+	var streamUpload bool
+	if cmd.Flags().Lookup("stream-upload") != nil {
+		streamUpload, err = cmd.Flags().GetBool("stream-upload")
+		if err != nil {
+			slog.Error("Failed to get the --stream-upload flag", "error", err)
+			return nil, err
+		}
+	}
+
+	if streamUpload && backupStorage == nil {
+		slog.Error("The --stream-upload option requires the --storage option")
+		return nil, fmt.Errorf("the --stream-upload option requires the --storage option")
+	}
+
+	backupFileName := target.FileName
 	if backupFileName == "" {
-		backupFileName = "backup-" + time.Now().Format("2006-01-02-15-04-05") + ".gz"
+		backupFileName = DefaultFileName(namespace, name)
 	}
 	backupFile, err := os.OpenFile(backupFileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
 	if err != nil {
@@ -69,40 +300,109 @@ func NewBackuper(cmd *cobra.Command) (*Backuper, error) {
 		return nil, err
 	}
 
-	bufferedWriter := bufio.NewWriter(backupFile)
-	gzipWriter := gzip.NewWriter(bufferedWriter)
+	// The deadline applies to the whole backup including its upload, so that a hanging API server or storage does not
+	// block the backup forever
+	ctx, cancel := backupContext(timeout)
+
+	// With --stream-upload, the backup is uploaded while it is written into the local file. The upload is bound by the
+	// backup deadline as well.
+	var output io.Writer = backupFile
+	var upload *storage.StreamingUpload
+	if streamUpload {
+		slog.Info("Uploading the backup while it is created", "file", backupFileName)
+		upload = storage.StartUpload(ctx, backupStorage, filepath.Base(backupFileName))
+		output = io.MultiWriter(backupFile, upload)
+	}
+
+	// Only the streams matching the --encrypt-streams option are encrypted. The GZIP members cannot compress the encrypted
+	// data, so these streams are compressed before they are encrypted.
+	var stages []archive.Stage
+	if encryptionKey != nil {
+		stages = append(stages, &archive.CompressionStage{Patterns: encryptedStreams})
+	}
+	stages = append(stages, &archive.EncryptionStage{Passphrase: encryptionKey, Patterns: encryptedStreams})
+	writer := archive.NewWriter(output, stages...)
 
 	backuper := Backuper{
 		KubernetesClient:      kubeClient,
 		StrimziClient:         strimziClient,
+		DynamicClient:         dynamicClient,
 		Namespace:             namespace,
 		Name:                  name,
 		skipMetadataCleansing: metadataCleansing,
+		preservedAnnotations:  preservedAnnotations,
 		backupFile:            backupFile,
-		bufferedWriter:        bufferedWriter,
-		gzipWriter:            gzipWriter,
+		writer:                writer,
+		storage:               backupStorage,
+		storageLocation:       storageLocation,
+		upload:                upload,
+		hmacKey:               hmacKey,
+		usageStats:            usageStats,
+		readOnlyCheck:         readOnlyCheck,
+		startedAt:             time.Now(),
+		ctx:                   ctx,
+		cancel:                cancel,
 	}
 
 	return &backuper, nil
 }
 
-func (b *Backuper) Close() {
-	if b.gzipWriter != nil {
-		err := b.gzipWriter.Flush()
-		if err != nil {
-			slog.Error("Failed to flush the GZIP writer", "error", err)
-		}
+// DefaultFileName generates the name of the backup file used when the --filename option is not set. It contains the
+// namespace and the name of the Kafka cluster, the current UTC time, and a short random suffix, so that backups
+// started in the same second do not collide.
+func DefaultFileName(namespace string, name string) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		// Should never happen, but the file is opened exclusively anyway, so the collision would not go unnoticed
+		slog.Warn("Failed to generate random suffix for the backup file name", "error", err)
+	}
 
-		err = b.gzipWriter.Close()
-		if err != nil {
-			slog.Error("Failed to close the GZIP writer", "error", err)
+	return "backup-" + namespace + "-" + name + "-" + time.Now().UTC().Format("2006-01-02-15-04-05") + "Z-" + hex.EncodeToString(suffix) + ".gz"
+}
+
+// FileName returns the name of the backup file
+func (b *Backuper) FileName() string {
+	return b.backupFile.Name()
+}
+
+// Location returns where the backup is stored. When the --storage option is used, it is the location of the uploaded
+// backup without any credentials or query parameters. Otherwise, it is the absolute path of the local backup file.
+func (b *Backuper) Location() string {
+	if b.storageLocation != "" {
+		location := strings.TrimSuffix(b.storageLocation, "/")
+		if parsed, err := url.Parse(location); err == nil && parsed.Scheme != "" {
+			parsed.User = nil
+			parsed.RawQuery = ""
+			parsed.Fragment = ""
+			location = parsed.String()
 		}
+
+		return location + "/" + filepath.Base(b.FileName())
+	}
+
+	location, err := filepath.Abs(b.FileName())
+	if err != nil {
+		return b.FileName()
+	}
+
+	return location
+}
+
+func (b *Backuper) Close() {
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	// The backup deadline applies also to the upload, so it is cancelled only once the backup is uploaded or discarded
+	if b.storage == nil {
+		b.cancel()
 	}
 
-	if b.bufferedWriter != nil {
-		err := b.bufferedWriter.Flush()
+	if b.writer != nil {
+		err := b.writer.Close()
 		if err != nil {
-			slog.Error("Failed to flush the buffered writer", "error", err)
+			slog.Error("Failed to flush the backup file", "error", err)
 		}
 	}
 
@@ -114,8 +414,69 @@ func (b *Backuper) Close() {
 	}
 }
 
+// Upload closes the backup file and uploads it together with its checksum into the storage configured with the
+// --storage option. The upload has to finish within the --timeout of the backup. The local backup file is kept. Does
+// nothing when no storage is configured.
+func (b *Backuper) Upload() error {
+	if b.storage == nil {
+		return nil
+	}
+	defer b.cancel()
+
+	b.Close()
+
+	if b.upload != nil {
+		return b.finishUpload()
+	}
+
+	return b.uploadFile(b.FileName())
+}
+
+// finishUpload waits until the backup uploaded while it was created is completely stored and uploads its checksum
+func (b *Backuper) finishUpload() error {
+	upload := b.upload
+	b.upload = nil
+
+	if err := upload.Finish(); err != nil {
+		slog.Error("Failed to upload the backup", "error", err, "file", b.FileName())
+		return err
+	}
+
+	slog.Info("Backup uploaded", "file", b.FileName())
+
+	return nil
+}
+
+// uploadFile uploads the backup file together with its checksum into the storage
+func (b *Backuper) uploadFile(fileName string) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		slog.Error("Failed to open the backup file for upload", "error", err, "file", fileName)
+		return err
+	}
+	defer file.Close()
+
+	slog.Info("Uploading the backup", "file", fileName)
+
+	if err := storage.UploadWithChecksum(b.ctx, b.storage, filepath.Base(fileName), file); err != nil {
+		slog.Error("Failed to upload the backup", "error", err, "file", fileName)
+		return err
+	}
+
+	slog.Info("Backup uploaded", "file", fileName)
+
+	return nil
+}
+
 func (b *Backuper) Discard() {
 	b.Close()
+	b.cancel()
+
+	if b.upload != nil {
+		slog.Info("Aborting the upload of the incomplete backup", "filename", b.backupFile.Name())
+		b.upload.Abort(fmt.Errorf("the backup was discarded"))
+		b.upload = nil
+	}
 
 	slog.Info("Removing incomplete backup file", "filename", b.backupFile.Name())
 
@@ -123,3 +484,102 @@ func (b *Backuper) Discard() {
 		slog.Error("Failed to remove discarded backup file", "error", err)
 	}
 }
+
+// writeStream writes the data as a new stream (GZIP member) into the backup file and records its statistics for the
+// manifest. The streams matching the --encrypt-streams option are encrypted by the pipeline of the archive writer.
+func (b *Backuper) writeStream(name string, comment string, data []byte, resources int, start time.Time) error {
+	if err := b.ctx.Err(); err != nil {
+		slog.Error("The backup did not finish within the timeout", "stream", name, "error", err)
+		return err
+	}
+
+	if b.cluster != "" {
+		name = archive.ClusterStreamName(b.cluster, name)
+	}
+
+	stream := archive.Stream{Name: name, Comment: comment, ModTime: time.Now(), Data: data}
+
+	compressedBytes, err := b.writer.Write(&stream)
+	if err != nil {
+		slog.Error("Failed to write the stream to the backup file", "stream", name, "error", err)
+		return err
+	}
+
+	b.streamStats = append(b.streamStats, archive.StreamStats{
+		Name:              name,
+		Resources:         resources,
+		UncompressedBytes: int64(len(stream.Data)),
+		CompressedBytes:   compressedBytes,
+		DurationMillis:    time.Since(start).Milliseconds(),
+		Digest:            archive.Digest(stream.Data),
+		Encrypted:         stream.Encrypted,
+	})
+
+	return nil
+}
+
+// WriteManifest writes the manifest with the statistics of all streams written so far as the last stream of the backup
+func (b *Backuper) WriteManifest() error {
+	return b.writeManifest(time.Now())
+}
+
+// writeManifest writes the manifest of the backup created at the given time
+func (b *Backuper) writeManifest(createdAt time.Time) error {
+	start := time.Now()
+
+	manifest := archive.Manifest{
+		Version:         utils.Version(),
+		FormatVersion:   archive.FormatVersion,
+		CreatedAt:       createdAt.UTC(),
+		Namespace:       b.Namespace,
+		Name:            b.Name,
+		OperatorVersion: b.operatorVersion,
+		Streams:         b.streamStats,
+		Findings:        b.lintFindings,
+		Usage:           b.usage,
+		Clusters:        b.clusterIndex,
+	}
+
+	if len(b.clusterIndex) > 0 {
+		manifest.FormatVersion = archive.MultiClusterFormatVersion
+	}
+
+	if b.usageStats {
+		manifest.Usage = b.collectUsageStats()
+	}
+
+	if b.hmacKey != nil {
+		if err := manifest.Sign(b.hmacKey); err != nil {
+			slog.Error("Failed to sign the backup manifest", "error", err)
+			return err
+		}
+	}
+
+	manifestYaml, err := yaml.Marshal(manifest)
+	if err != nil {
+		slog.Error("Failed to marshal the backup manifest to YAML", "error", err)
+		return err
+	}
+
+	return b.writeStream(archive.ManifestFilename, StreamDescription(archive.ManifestFilename), manifestYaml, 0, start)
+}
+
+// collectUsageStats collects the anonymized statistics about the backup from the streams written so far
+func (b *Backuper) collectUsageStats() *archive.UsageStats {
+	usage := archive.UsageStats{
+		DurationMillis:  time.Since(b.startedAt).Milliseconds(),
+		Streams:         len(b.streamStats),
+		Findings:        len(b.lintFindings),
+		OperatingSystem: runtime.GOOS,
+		Architecture:    runtime.GOARCH,
+		InCluster:       utils.IsRunningInCluster(),
+	}
+
+	for _, stats := range b.streamStats {
+		usage.Resources += stats.Resources
+		usage.UncompressedBytes += stats.UncompressedBytes
+		usage.CompressedBytes += stats.CompressedBytes
+	}
+
+	return &usage
+}
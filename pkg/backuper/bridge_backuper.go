@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	KafkaBridgeFilename           = "kafka-bridge.yaml"
+	KafkaBridgeSecretsFilename    = "kafka-bridge-secrets.yaml"
+	KafkaBridgeConfigMapsFilename = "kafka-bridge-config-maps.yaml"
+)
+
+// BridgeBackuper backs up a Kafka Bridge: the KafkaBridge resource and the Secrets and ConfigMaps referenced from it
+type BridgeBackuper struct {
+	Backuper
+	references
+
+	skipSecrets bool
+}
+
+// BackupKafkaBridge backs up the KafkaBridge resource and collects the Secrets and ConfigMaps referenced from it
+func (b *BridgeBackuper) BackupKafkaBridge() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaBridge resource", "name", b.Name)
+
+	resource, err := b.StrimziClient.KafkaV1beta2().KafkaBridges(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka Bridge", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	b.addReferences(bridgeReferences(resource))
+
+	removeLastBackupAnnotations(&resource.ObjectMeta)
+
+	if !b.skipMetadataCleansing {
+		// Cleanse the metadata
+		utils.CleanseMetadata(&resource.ObjectMeta)
+		utils.CleanseAnnotations(&resource.ObjectMeta, b.preservedAnnotations)
+	}
+
+	resourceYaml, err := yaml.Marshal(resource)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka Bridge to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaBridgeFilename, StreamDescription(KafkaBridgeFilename), resourceYaml, 1, start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaBridge resource complete", "name", b.Name)
+
+	return nil
+}
+
+// BackupReferencedResources backs up the Secrets and ConfigMaps referenced from the KafkaBridge resource. It has to be
+// called after it was backed up. The Secrets are skipped when the --skip-bridge-secrets option is used.
+func (b *BridgeBackuper) BackupReferencedResources() error {
+	if b.skipSecrets {
+		slog.Info("Skipping the backup of the Secrets referenced from the Kafka Bridge", "secrets", len(b.secrets))
+	} else if err := b.backupReferencedSecrets(KafkaBridgeSecretsFilename, b.secrets); err != nil {
+		return err
+	}
+
+	return b.backupReferencedConfigMaps(KafkaBridgeConfigMapsFilename, b.configMaps)
+}
+
+// bridgeReferences returns the names of the Secrets and ConfigMaps referenced from the KafkaBridge resource: the
+// trusted certificates and the credentials used to connect to the Kafka cluster, the logging configuration, and the
+// references found by the generic scanner in the pod templates
+func bridgeReferences(bridge *v1beta2.KafkaBridge) (secrets []string, configMaps []string) {
+	spec := bridge.Spec
+	if spec == nil {
+		return nil, nil
+	}
+
+	secrets = clientSecrets(spec.Tls, spec.Authentication)
+	_, configMaps = configurationReferences(spec.Logging, nil, nil)
+	scannedSecrets, scannedConfigMaps := scanReferences(spec, bridge.Namespace)
+
+	return append(secrets, scannedSecrets...), append(configMaps, scannedConfigMaps...)
+}
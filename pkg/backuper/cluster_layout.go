@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StrimziPodSetsFilename  = "strimzi-pod-sets.yaml"
+	NodeAssignmentsFilename = "node-assignments.yaml"
+)
+
+var strimziPodSetResource = schema.GroupVersionResource{Group: "core.strimzi.io", Version: "v1beta2", Resource: "strimzipodsets"}
+
+// NodeAssignment describes how a single Kafka node was laid out at the time of the backup
+type NodeAssignment struct {
+	PodName        string   `json:"podName"`
+	NodeId         int32    `json:"nodeId"`
+	NodePool       string   `json:"nodePool,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	KubernetesNode string   `json:"kubernetesNode,omitempty"`
+	Phase          string   `json:"phase,omitempty"`
+}
+
+// BackupStrimziPodSets stores the StrimziPodSets of the Kafka cluster. They are stored only for information and are not
+// restored as the Cluster Operator recreates them.
+func (b *KafkaBackuper) BackupStrimziPodSets() error {
+	start := time.Now()
+
+	slog.Info("Backing up the StrimziPodSet resources", "labelSelector", "strimzi.io/cluster="+b.Name)
+
+	resources, err := b.DynamicClient.Resource(strimziPodSetResource).Namespace(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get StrimziPodSets belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	sortByName(resources.Items)
+
+	if !b.skipMetadataCleansing {
+		resources.SetResourceVersion("")
+
+		// We want to avoid copying the resource, so we use the index
+		for i := range resources.Items {
+			resources.Items[i].SetManagedFields(nil)
+			resources.Items[i].SetResourceVersion("")
+			resources.Items[i].SetUID("")
+			resources.Items[i].SetOwnerReferences(nil)
+		}
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the StrimziPodSets to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(StrimziPodSetsFilename, StreamDescription(StrimziPodSetsFilename), resourcesYaml, len(resources.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the StrimziPodSet resources complete", "labelSelector", "strimzi.io/cluster="+b.Name)
+
+	return nil
+}
+
+// BackupNodeAssignments stores the summary of the Kafka nodes, their IDs, roles, and the Kubernetes worker nodes they
+// were running on. It is stored only for information and is not restored.
+func (b *KafkaBackuper) BackupNodeAssignments() error {
+	start := time.Now()
+
+	slog.Info("Backing up the Kafka node assignments", "labelSelector", "strimzi.io/cluster="+b.Name+",strimzi.io/name="+b.Name+"-kafka")
+
+	pods, err := b.KubernetesClient.CoreV1().Pods(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name + ",strimzi.io/name=" + b.Name + "-kafka"})
+	if err != nil {
+		slog.Error("Failed to get Pods belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	assignments := make([]NodeAssignment, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		nodeId, err := strconv.ParseInt(pod.Name[strings.LastIndex(pod.Name, "-")+1:], 10, 32)
+		if err != nil {
+			slog.Warn("Failed to determine the node ID of the Kafka Pod", "pod", pod.Name, "error", err)
+			continue
+		}
+
+		var roles []string
+		if pod.Labels["strimzi.io/controller-role"] == "true" {
+			roles = append(roles, "controller")
+		}
+		if pod.Labels["strimzi.io/broker-role"] == "true" {
+			roles = append(roles, "broker")
+		}
+
+		assignments = append(assignments, NodeAssignment{
+			PodName:        pod.Name,
+			NodeId:         int32(nodeId),
+			NodePool:       pod.Labels["strimzi.io/pool-name"],
+			Roles:          roles,
+			KubernetesNode: pod.Spec.NodeName,
+			Phase:          string(pod.Status.Phase),
+		})
+	}
+
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].NodeId < assignments[j].NodeId
+	})
+
+	assignmentsYaml, err := yaml.Marshal(assignments)
+	if err != nil {
+		slog.Error("Failed to marshal the node assignments to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(NodeAssignmentsFilename, StreamDescription(NodeAssignmentsFilename), assignmentsYaml, len(assignments), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the Kafka node assignments complete", "nodes", len(assignments))
+
+	return nil
+}
@@ -0,0 +1,358 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"time"
+)
+
+const (
+	KafkaConnectFilename           = "kafka-connect.yaml"
+	KafkaConnectorsFilename        = "kafka-connectors.yaml"
+	KafkaConnectSecretsFilename    = "kafka-connect-secrets.yaml"
+	KafkaConnectConfigMapsFilename = "kafka-connect-config-maps.yaml"
+)
+
+// ConnectBackuper backs up a Kafka Connect cluster: the KafkaConnect resource, its KafkaConnectors, and the Secrets and
+// ConfigMaps referenced from them
+type ConnectBackuper struct {
+	Backuper
+	references
+
+	skipSecrets           bool
+	useConnectorResources bool
+}
+
+// references collects the names of the Secrets and ConfigMaps referenced from the backed up resources
+type references struct {
+	secrets    []string
+	configMaps []string
+}
+
+func NewConnectBackuper(cmd *cobra.Command, target Target) (*ConnectBackuper, error) {
+	skipSecrets, err := cmd.Flags().GetBool("skip-connect-secrets")
+	if err != nil {
+		slog.Error("Failed to get the --skip-connect-secrets flag", "error", err)
+		return nil, err
+	}
+
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConnectBackuper{Backuper: *backuper, skipSecrets: skipSecrets}, nil
+}
+
+// BackupKafkaConnect backs up the KafkaConnect resource and collects the Secrets and ConfigMaps referenced from it
+func (b *ConnectBackuper) BackupKafkaConnect() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaConnect resource", "name", b.Name)
+
+	resource, err := b.StrimziClient.KafkaV1beta2().KafkaConnects(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka Connect cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	b.addReferences(connectReferences(resource))
+	b.useConnectorResources = resource.Annotations["strimzi.io/use-connector-resources"] == "true"
+
+	if resource.Spec != nil && resource.Spec.Build != nil && resource.Spec.Build.Output != nil {
+		// The image built by the Cluster Operator is not part of the backup. It is rebuilt after the restore unless the
+		// container registry keeps it.
+		slog.Info("The Kafka Connect cluster uses a container image built by the Cluster Operator", "name", b.Name, "image", resource.Spec.Build.Output.Image, "type", resource.Spec.Build.Output.Type)
+	}
+
+	removeLastBackupAnnotations(&resource.ObjectMeta)
+
+	if !b.skipMetadataCleansing {
+		// Cleanse the metadata
+		utils.CleanseMetadata(&resource.ObjectMeta)
+		utils.CleanseAnnotations(&resource.ObjectMeta, b.preservedAnnotations)
+	}
+
+	resourceYaml, err := yaml.Marshal(resource)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka Connect cluster to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaConnectFilename, StreamDescription(KafkaConnectFilename), resourceYaml, 1, start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaConnect resource complete", "name", b.Name)
+
+	return nil
+}
+
+// BackupKafkaConnectors backs up the KafkaConnector resources belonging to the Kafka Connect cluster
+func (b *ConnectBackuper) BackupKafkaConnectors() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaConnector resources", "labelSelector", "strimzi.io/cluster="+b.Name)
+
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaConnectors(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get KafkaConnectors belonging to the Kafka Connect cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	if len(resources.Items) > 0 && !b.useConnectorResources {
+		slog.Warn("The Kafka Connect cluster does not have the strimzi.io/use-connector-resources annotation => its KafkaConnectors are backed up, but the Cluster Operator ignores them", "name", b.Name, "connectors", len(resources.Items))
+	}
+
+	sortByName(resources.Items)
+
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up KafkaConnector", "name", resource.Name)
+
+		if resource.Spec != nil && resource.Spec.AlterOffsets != nil && resource.Spec.AlterOffsets.FromConfigMap != nil {
+			b.addReferences(nil, []string{resource.Spec.AlterOffsets.FromConfigMap.Name})
+		}
+	}
+
+	if !b.skipMetadataCleansing {
+		// Cleanse the metadata
+		resources.ListMeta = metav1.ListMeta{}
+
+		// We want to avoid copying the resource, so we use the index
+		for i := range resources.Items {
+			utils.CleanseMetadata(&resources.Items[i].ObjectMeta)
+		}
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the KafkaConnectors to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaConnectorsFilename, StreamDescription(KafkaConnectorsFilename), resourcesYaml, len(resources.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaConnector resources complete", "labelSelector", "strimzi.io/cluster="+b.Name)
+
+	return nil
+}
+
+// BackupReferencedResources backs up the Secrets and ConfigMaps referenced from the KafkaConnect and KafkaConnector
+// resources. It has to be called after they were backed up. The Secrets are skipped when the --skip-connect-secrets
+// option is used.
+func (b *ConnectBackuper) BackupReferencedResources() error {
+	if b.skipSecrets {
+		slog.Info("Skipping the backup of the Secrets referenced from the Kafka Connect cluster", "secrets", len(b.secrets))
+	} else if err := b.backupReferencedSecrets(KafkaConnectSecretsFilename, b.secrets); err != nil {
+		return err
+	}
+
+	return b.backupReferencedConfigMaps(KafkaConnectConfigMapsFilename, b.configMaps)
+}
+
+// backupReferencedSecrets backs up the Secrets referenced from the backed up resources into a new stream
+func (b *Backuper) backupReferencedSecrets(stream string, names []string) error {
+	start := time.Now()
+
+	slog.Info("Backing up the referenced Secrets", "secrets", len(names))
+
+	resources := &v1.SecretList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "SecretList"}}
+	for _, name := range names {
+		secret, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).Get(b.ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Warn("The referenced Secret does not exist", "name", name, "namespace", b.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the referenced Secret", "name", name, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&secret.ObjectMeta)
+		}
+
+		resources.Items = append(resources.Items, *secret)
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the Secrets to YAML", "error", err)
+		return err
+	}
+
+	return b.writeStream(stream, StreamDescription(stream), resourcesYaml, len(resources.Items), start)
+}
+
+// backupReferencedConfigMaps backs up the ConfigMaps referenced from the backed up resources into a new stream
+func (b *Backuper) backupReferencedConfigMaps(stream string, names []string) error {
+	start := time.Now()
+
+	slog.Info("Backing up the referenced ConfigMaps", "configMaps", len(names))
+
+	resources := &v1.ConfigMapList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMapList"}}
+	for _, name := range names {
+		configMap, err := b.KubernetesClient.CoreV1().ConfigMaps(b.Namespace).Get(b.ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Warn("The referenced ConfigMap does not exist", "name", name, "namespace", b.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the referenced ConfigMap", "name", name, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&configMap.ObjectMeta)
+		}
+
+		resources.Items = append(resources.Items, *configMap)
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the ConfigMaps to YAML", "error", err)
+		return err
+	}
+
+	return b.writeStream(stream, StreamDescription(stream), resourcesYaml, len(resources.Items), start)
+}
+
+// addReferences records the referenced Secrets and ConfigMaps, so that each of them is backed up only once
+func (r *references) addReferences(secrets []string, configMaps []string) {
+	for _, name := range secrets {
+		if name != "" && !slices.Contains(r.secrets, name) {
+			r.secrets = append(r.secrets, name)
+		}
+	}
+
+	for _, name := range configMaps {
+		if name != "" && !slices.Contains(r.configMaps, name) {
+			r.configMaps = append(r.configMaps, name)
+		}
+	}
+
+	slices.Sort(r.secrets)
+	slices.Sort(r.configMaps)
+}
+
+// connectReferences returns the names of the Secrets and ConfigMaps referenced from the KafkaConnect resource: the
+// trusted certificates and the credentials used to connect to the Kafka cluster, the push Secret of the build output,
+// the logging and metrics configuration, the external configuration, and the references found by the generic scanner
+// in the pod templates and the configuration
+func connectReferences(connect *v1beta2.KafkaConnect) (secrets []string, configMaps []string) {
+	spec := connect.Spec
+	if spec == nil {
+		return nil, nil
+	}
+
+	secrets = clientSecrets(spec.Tls, spec.Authentication)
+
+	if spec.Build != nil && spec.Build.Output != nil {
+		secrets = append(secrets, spec.Build.Output.PushSecret)
+	}
+
+	if spec.Template != nil && spec.Template.BuildConfig != nil {
+		secrets = append(secrets, spec.Template.BuildConfig.PullSecret)
+	}
+
+	externalSecrets, configMaps := configurationReferences(spec.Logging, spec.MetricsConfig, spec.ExternalConfiguration)
+	scannedSecrets, scannedConfigMaps := scanReferences(spec, connect.Namespace)
+
+	return append(append(secrets, externalSecrets...), scannedSecrets...), append(configMaps, scannedConfigMaps...)
+}
+
+// clientSecrets returns the names of the Secrets with the trusted certificates and the credentials used to connect to
+// a Kafka cluster
+func clientSecrets(tls *v1beta2.ClientTls, auth *v1beta2.KafkaClientAuthentication) (secrets []string) {
+	if tls != nil {
+		for _, certificate := range tls.TrustedCertificates {
+			secrets = append(secrets, certificate.SecretName)
+		}
+	}
+
+	if auth != nil {
+		for _, certificate := range auth.TlsTrustedCertificates {
+			secrets = append(secrets, certificate.SecretName)
+		}
+
+		for _, source := range []*v1beta2.GenericSecretSource{auth.ClientSecret, auth.ClientAssertion, auth.AccessToken, auth.RefreshToken} {
+			if source != nil {
+				secrets = append(secrets, source.SecretName)
+			}
+		}
+
+		if auth.CertificateAndKey != nil {
+			secrets = append(secrets, auth.CertificateAndKey.SecretName)
+		}
+
+		if auth.PasswordSecret != nil {
+			secrets = append(secrets, auth.PasswordSecret.SecretName)
+		}
+	}
+
+	return secrets
+}
+
+// configurationReferences returns the names of the Secrets and ConfigMaps used by the logging and metrics
+// configuration and by the external configuration of the Kafka Connect based clusters
+func configurationReferences(logging *v1beta2.Logging, metrics *v1beta2.MetricsConfig, external *v1beta2.ExternalConfiguration) (secrets []string, configMaps []string) {
+	if logging != nil && logging.ValueFrom != nil && logging.ValueFrom.ConfigMapKeyRef != nil {
+		configMaps = append(configMaps, logging.ValueFrom.ConfigMapKeyRef.Name)
+	}
+
+	if metrics != nil && metrics.ValueFrom != nil && metrics.ValueFrom.ConfigMapKeyRef != nil {
+		configMaps = append(configMaps, metrics.ValueFrom.ConfigMapKeyRef.Name)
+	}
+
+	if external != nil {
+		for _, env := range external.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+
+			if env.ValueFrom.SecretKeyRef != nil {
+				secrets = append(secrets, env.ValueFrom.SecretKeyRef.Name)
+			}
+
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps = append(configMaps, env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+
+		for _, volume := range external.Volumes {
+			if volume.Secret != nil {
+				secrets = append(secrets, volume.Secret.SecretName)
+			}
+
+			if volume.ConfigMap != nil {
+				configMaps = append(configMaps, volume.ConfigMap.Name)
+			}
+		}
+	}
+
+	return secrets, configMaps
+}
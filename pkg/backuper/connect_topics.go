@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"fmt"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+// ConnectTopicsFilename is the name of the stream with the internal topics of the Kafka Connect clusters whose records
+// are stored in the backup
+const ConnectTopicsFilename = "connect-topics.yaml"
+
+// Roles of the Kafka Connect internal topics
+const (
+	ConnectOffsetsTopic = "offsets"
+	ConnectConfigsTopic = "configs"
+	ConnectStatusTopic  = "status"
+)
+
+// connectTopicOptions maps the Kafka Connect configuration options with the names of the internal topics to their roles
+// and to their default values used by Strimzi
+var connectTopicOptions = []struct {
+	option       string
+	role         string
+	defaultTopic string
+}{
+	{"offset.storage.topic", ConnectOffsetsTopic, "connect-cluster-offsets"},
+	{"config.storage.topic", ConnectConfigsTopic, "connect-cluster-configs"},
+	{"status.storage.topic", ConnectStatusTopic, "connect-cluster-status"},
+}
+
+// ConnectTopic describes an internal topic of a Kafka Connect cluster, so that it can be recreated with the same
+// configuration before its records are restored
+type ConnectTopic struct {
+	Connect           string            `json:"connect"`
+	Role              string            `json:"role"`
+	Topic             string            `json:"topic"`
+	Partitions        int32             `json:"partitions"`
+	ReplicationFactor int16             `json:"replicationFactor"`
+	Config            map[string]string `json:"config,omitempty"`
+}
+
+// resolveConnectTopics finds the internal topics of the Kafka Connect clusters from the --connect option and collects
+// their configuration
+func (b *DataBackuper) resolveConnectTopics(admin *kadm.Client, details kadm.TopicDetails) ([]ConnectTopic, error) {
+	var connectTopics []ConnectTopic
+
+	for _, connectName := range b.Connects {
+		connect, err := b.StrimziClient.KafkaV1beta2().KafkaConnects(b.Namespace).Get(b.ctx, connectName, metav1.GetOptions{})
+		if err != nil {
+			slog.Error("Failed to get the KafkaConnect resource", "name", connectName, "namespace", b.Namespace, "error", err)
+			return nil, err
+		}
+
+		for _, option := range connectTopicOptions {
+			topic := option.defaultTopic
+			if value, ok := connect.Spec.Config[option.option]; ok {
+				topic = fmt.Sprintf("%v", value)
+			}
+
+			detail, ok := details[topic]
+			if !ok || detail.Err != nil {
+				slog.Error("The internal topic of the Kafka Connect cluster does not exist", "connect", connectName, "role", option.role, "topic", topic)
+				return nil, fmt.Errorf("the %s topic %s of the Kafka Connect cluster %s does not exist", option.role, topic, connectName)
+			}
+
+			var replicationFactor int16
+			for _, partition := range detail.Partitions {
+				replicationFactor = max(replicationFactor, int16(len(partition.Replicas)))
+			}
+
+			connectTopics = append(connectTopics, ConnectTopic{
+				Connect:           connectName,
+				Role:              option.role,
+				Topic:             topic,
+				Partitions:        int32(len(detail.Partitions)),
+				ReplicationFactor: replicationFactor,
+				Config:            map[string]string{},
+			})
+		}
+	}
+
+	if len(connectTopics) == 0 {
+		return nil, nil
+	}
+
+	var topics []string
+	for _, connectTopic := range connectTopics {
+		topics = append(topics, connectTopic.Topic)
+	}
+
+	configs, err := admin.DescribeTopicConfigs(b.ctx, topics...)
+	if err != nil {
+		slog.Error("Failed to describe the configuration of the Kafka Connect internal topics", "error", err)
+		return nil, err
+	}
+
+	for i := range connectTopics {
+		config, err := configs.On(connectTopics[i].Topic, nil)
+		if err == nil {
+			err = config.Err
+		}
+		if err != nil {
+			slog.Error("Failed to describe the configuration of the Kafka Connect internal topic", "topic", connectTopics[i].Topic, "error", err)
+			return nil, err
+		}
+
+		// Only the options set for the topic are kept, the rest is inherited from the broker configuration
+		for _, entry := range config.Configs {
+			if entry.Source == kmsg.ConfigSourceDynamicTopicConfig && entry.Value != nil {
+				connectTopics[i].Config[entry.Key] = *entry.Value
+			}
+		}
+	}
+
+	return connectTopics, nil
+}
+
+// backupConnectTopics writes the descriptions of the Kafka Connect internal topics as a new stream
+func (b *DataBackuper) backupConnectTopics(connectTopics []ConnectTopic) error {
+	start := time.Now()
+
+	connectTopicsYaml, err := yaml.Marshal(connectTopics)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka Connect internal topics to YAML", "error", err)
+		return err
+	}
+
+	return b.writeStream(ConnectTopicsFilename, StreamDescription(ConnectTopicsFilename), connectTopicsYaml, len(connectTopics), start)
+}
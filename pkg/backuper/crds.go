@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+const StrimziCRDsFilename = "strimzi-crds.yaml"
+
+// CustomResourceDefinitionResource is the CustomResourceDefinition resource. The apiextensions client is not used by
+// strimzi-backup, so the CRDs are handled using the dynamic client.
+var CustomResourceDefinitionResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// IsStrimziGroup checks whether the API group belongs to Strimzi (such as kafka.strimzi.io or core.strimzi.io)
+func IsStrimziGroup(group string) bool {
+	return group == "strimzi.io" || strings.HasSuffix(group, ".strimzi.io")
+}
+
+// BackupCRDs backs up the Strimzi CustomResourceDefinitions installed in the Kubernetes cluster. Apart from the cleansed
+// metadata, the stored versions from their status are kept to show in which versions the custom resources were stored
+// in the source cluster. They allow to install the CRDs into a bare Kubernetes cluster before restoring the operands.
+func (b *Backuper) BackupCRDs() error {
+	start := time.Now()
+
+	slog.Info("Backing up the Strimzi CustomResourceDefinitions")
+
+	crds, err := b.DynamicClient.Resource(CustomResourceDefinitionResource).List(b.ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the CustomResourceDefinitions", "error", err)
+		return err
+	}
+
+	sortByName(crds.Items)
+
+	strimziCrds := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	for _, crd := range crds.Items {
+		if group, _, _ := unstructured.NestedString(crd.Object, "spec", "group"); !IsStrimziGroup(group) {
+			continue
+		}
+
+		storedVersions, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+		slog.Debug("Backing up CustomResourceDefinition", "name", crd.GetName(), "storedVersions", storedVersions)
+
+		crd.SetAPIVersion(CustomResourceDefinitionResource.GroupVersion().String())
+		crd.SetKind("CustomResourceDefinition")
+
+		if !b.skipMetadataCleansing {
+			crd.SetManagedFields(nil)
+			crd.SetResourceVersion("")
+			crd.SetUID("")
+			crd.SetGeneration(0)
+			crd.SetCreationTimestamp(metav1.Time{})
+			crd.SetOwnerReferences(nil)
+			unstructured.RemoveNestedField(crd.Object, "status")
+
+			if len(storedVersions) > 0 {
+				_ = unstructured.SetNestedStringSlice(crd.Object, storedVersions, "status", "storedVersions")
+			}
+		}
+
+		strimziCrds.Items = append(strimziCrds.Items, crd)
+	}
+
+	if len(strimziCrds.Items) == 0 {
+		slog.Warn("No Strimzi CustomResourceDefinitions found in the Kubernetes cluster")
+	}
+
+	resourcesYaml, err := yaml.Marshal(strimziCrds)
+	if err != nil {
+		slog.Error("Failed to marshal the CustomResourceDefinitions to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(StrimziCRDsFilename, StreamDescription(StrimziCRDsFilename), resourcesYaml, len(strimziCrds.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the Strimzi CustomResourceDefinitions complete", "crds", len(strimziCrds.Items))
+
+	return nil
+}
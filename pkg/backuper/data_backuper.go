@@ -0,0 +1,313 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// TopicDataStreamPrefix is the prefix of the streams with the records of the topics
+	TopicDataStreamPrefix = "topic-data-"
+	// TopicDataStreamSuffix is the suffix of the streams with the records of the topics. The records are stored as JSON
+	// Lines.
+	TopicDataStreamSuffix = ".jsonl"
+)
+
+// TopicRecord is a single record of the topic stored in the backup. The key and the value are base64 encoded by the
+// JSON encoding. Records with a null value (tombstones) keep the null value.
+type TopicRecord struct {
+	Partition int32               `json:"partition"`
+	Offset    int64               `json:"offset"`
+	Timestamp time.Time           `json:"timestamp"`
+	Key       []byte              `json:"key"`
+	Value     []byte              `json:"value"`
+	Headers   []TopicRecordHeader `json:"headers,omitempty"`
+}
+
+// TopicRecordHeader is a single header of the topic record
+type TopicRecordHeader struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// TopicDataStreamName returns the name of the stream with the records of the topic
+func TopicDataStreamName(topic string) string {
+	return TopicDataStreamPrefix + topic + TopicDataStreamSuffix
+}
+
+// TopicFromDataStreamName returns the name of the topic whose records are stored in the stream and whether the stream
+// contains topic records
+func TopicFromDataStreamName(name string) (string, bool) {
+	if !strings.HasPrefix(name, TopicDataStreamPrefix) || !strings.HasSuffix(name, TopicDataStreamSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(name, TopicDataStreamPrefix), TopicDataStreamSuffix), true
+}
+
+// DataBackuper backs up the records of selected topics using the Kafka protocol. It is intended for small topics such
+// as the compacted configuration topics, as all records of a topic are kept in memory while it is backed up.
+type DataBackuper struct {
+	Backuper
+
+	KafkaClient *kgo.Client
+	Topics      *regexp.Regexp
+	Connects    []string
+	MaxBytes    int64
+	bytes       int64
+}
+
+func NewDataBackuper(cmd *cobra.Command, target Target) (*DataBackuper, error) {
+	connects, err := cmd.Flags().GetStringSlice("connect")
+	if err != nil {
+		slog.Error("Failed to get the --connect flag", "error", err)
+		return nil, err
+	}
+
+	topics := cmd.Flag("topics").Value.String()
+	if topics == "" && len(connects) == 0 {
+		slog.Error("--topics or --connect option is required")
+		return nil, fmt.Errorf("--topics or --connect option is required")
+	}
+
+	var topicsRegexp *regexp.Regexp
+	if topics != "" {
+		// The regular expression has to match the whole topic name
+		topicsRegexp, err = regexp.Compile("^(?:" + topics + ")$")
+		if err != nil {
+			slog.Error("Failed to parse the --topics option", "topics", topics, "error", err)
+			return nil, err
+		}
+	}
+
+	maxBytes, err := resource.ParseQuantity(cmd.Flag("max-bytes").Value.String())
+	if err != nil {
+		slog.Error("Failed to parse the --max-bytes option", "maxBytes", cmd.Flag("max-bytes").Value.String(), "error", err)
+		return nil, err
+	}
+
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Records from aborted transactions are not backed up. The control records are kept to recognize the end of
+	// partitions ending with a transaction marker.
+	kafkaClient, err := utils.CreateKafkaClient(cmd, backuper.KubernetesClient, backuper.Namespace, backuper.Name, kgo.FetchIsolationLevel(kgo.ReadCommitted()), kgo.KeepControlRecords())
+	if err != nil {
+		backuper.Discard()
+		return nil, err
+	}
+
+	return &DataBackuper{Backuper: *backuper, KafkaClient: kafkaClient, Topics: topicsRegexp, Connects: connects, MaxBytes: maxBytes.Value()}, nil
+}
+
+func (b *DataBackuper) Close() {
+	b.KafkaClient.Close()
+	b.Backuper.Close()
+}
+
+// BackupTopicData backs up the records of all topics matching the --topics option and of the internal topics of the
+// Kafka Connect clusters from the --connect option. Each topic is stored in its own stream. The records are read from
+// the beginning of each partition up to its last stable offset at the time the backup started.
+func (b *DataBackuper) BackupTopicData() error {
+	admin := kadm.NewClient(b.KafkaClient)
+
+	details, err := admin.ListTopics(b.ctx)
+	if err != nil {
+		slog.Error("Failed to list the topics", "error", err)
+		return err
+	}
+
+	var topics []string
+	if b.Topics != nil {
+		for _, topic := range details.Names() {
+			if b.Topics.MatchString(topic) {
+				topics = append(topics, topic)
+			}
+		}
+
+		if len(topics) == 0 {
+			slog.Error("No topics matching the --topics option found", "topics", b.Topics.String())
+			return fmt.Errorf("no topics matching the --topics option found")
+		}
+	}
+
+	connectTopics, err := b.resolveConnectTopics(admin, details)
+	if err != nil {
+		return err
+	}
+
+	for _, connectTopic := range connectTopics {
+		if !slices.Contains(topics, connectTopic.Topic) {
+			topics = append(topics, connectTopic.Topic)
+		}
+	}
+	sort.Strings(topics)
+
+	if len(connectTopics) > 0 {
+		// The Kafka Connect internal topics are described first, so that they can be created before their records
+		// are restored
+		if err := b.backupConnectTopics(connectTopics); err != nil {
+			return err
+		}
+	}
+
+	startOffsets, err := admin.ListStartOffsets(b.ctx, topics...)
+	if err == nil {
+		err = startOffsets.Error()
+	}
+	if err != nil {
+		slog.Error("Failed to list the start offsets of the topics", "error", err)
+		return err
+	}
+
+	endOffsets, err := admin.ListCommittedOffsets(b.ctx, topics...)
+	if err == nil {
+		err = endOffsets.Error()
+	}
+	if err != nil {
+		slog.Error("Failed to list the end offsets of the topics", "error", err)
+		return err
+	}
+
+	for _, topic := range topics {
+		if err := b.backupTopic(topic, startOffsets[topic], endOffsets[topic]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupTopic consumes the records of a single topic between the start and end offsets and writes them as a new
+// stream
+func (b *DataBackuper) backupTopic(topic string, startOffsets map[int32]kadm.ListedOffset, endOffsets map[int32]kadm.ListedOffset) error {
+	start := time.Now()
+
+	slog.Info("Backing up the records of the topic", "topic", topic)
+
+	offsets := map[int32]kgo.Offset{}
+	remaining := map[int32]int64{}
+	var partitions []int32
+	for partition, endOffset := range endOffsets {
+		partitions = append(partitions, partition)
+		if startOffset := startOffsets[partition].Offset; endOffset.Offset > startOffset {
+			offsets[partition] = kgo.NewOffset().At(startOffset)
+			remaining[partition] = endOffset.Offset
+		}
+	}
+
+	var records []TopicRecord
+	if len(offsets) > 0 {
+		b.KafkaClient.AddConsumePartitions(map[string]map[int32]kgo.Offset{topic: offsets})
+		defer b.KafkaClient.RemoveConsumePartitions(map[string][]int32{topic: partitions})
+	}
+
+	for len(remaining) > 0 {
+		fetches := b.KafkaClient.PollFetches(b.ctx)
+		if err := b.ctx.Err(); err != nil {
+			slog.Error("The backup did not finish within the timeout", "topic", topic, "error", err)
+			return err
+		}
+
+		if errs := fetches.Errors(); len(errs) > 0 {
+			slog.Error("Failed to consume the records of the topic", "topic", errs[0].Topic, "partition", errs[0].Partition, "error", errs[0].Err)
+			return errs[0].Err
+		}
+
+		var err error
+		fetches.EachRecord(func(record *kgo.Record) {
+			endOffset, ok := remaining[record.Partition]
+			if !ok || record.Topic != topic || record.Offset >= endOffset || err != nil {
+				return
+			}
+
+			if record.Offset+1 >= endOffset {
+				delete(remaining, record.Partition)
+			}
+
+			if record.Attrs.IsControl() {
+				return
+			}
+
+			b.bytes += int64(len(record.Key) + len(record.Value))
+			if b.bytes > b.MaxBytes {
+				err = fmt.Errorf("the records of the selected topics exceed the --max-bytes limit of %d bytes", b.MaxBytes)
+				return
+			}
+
+			topicRecord := TopicRecord{
+				Partition: record.Partition,
+				Offset:    record.Offset,
+				Timestamp: record.Timestamp.UTC(),
+				Key:       record.Key,
+				Value:     record.Value,
+			}
+			for _, header := range record.Headers {
+				topicRecord.Headers = append(topicRecord.Headers, TopicRecordHeader{Key: header.Key, Value: header.Value})
+			}
+
+			records = append(records, topicRecord)
+		})
+
+		if err != nil {
+			slog.Error("Failed to back up the records of the topic", "topic", topic, "error", err)
+			return err
+		}
+	}
+
+	// The records from different partitions are fetched in parallel => we sort them to get reproducible backups
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Partition != records[j].Partition {
+			return records[i].Partition < records[j].Partition
+		}
+
+		return records[i].Offset < records[j].Offset
+	})
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			slog.Error("Failed to marshal the record to JSON", "topic", topic, "partition", record.Partition, "offset", record.Offset, "error", err)
+			return err
+		}
+	}
+
+	if err := b.writeStream(TopicDataStreamName(topic), "Records of topic "+topic, data.Bytes(), len(records), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the records of the topic complete", "topic", topic, "records", len(records))
+
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+)
+
+// discoverTargets finds all Kafka clusters in the namespaces matching the --namespace-selector option. Namespaces listed
+// in the --exclude-namespaces option are skipped. This allows to enroll the Kafka clusters into the backup simply by
+// labeling their namespaces.
+func discoverTargets(ctx context.Context, cmd *cobra.Command) ([]Target, error) {
+	namespaceSelector := cmd.Flag("namespace-selector").Value.String()
+
+	excludedNamespaces, err := cmd.Flags().GetStringSlice("exclude-namespaces")
+	if err != nil {
+		slog.Error("Failed to get the --exclude-namespaces flag", "error", err)
+		return nil, err
+	}
+
+	kubeClient, strimziClient, _, _, err := utils.CreateKubernetesClients(cmd)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes clients", "error", err)
+		return nil, err
+	}
+
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: namespaceSelector})
+	if err != nil {
+		slog.Error("Failed to list the namespaces", "namespaceSelector", namespaceSelector, "error", err)
+		return nil, err
+	}
+
+	var targets []Target
+
+	for _, namespace := range namespaces.Items {
+		if slices.Contains(excludedNamespaces, namespace.Name) {
+			slog.Info("Skipping excluded namespace", "namespace", namespace.Name)
+			continue
+		}
+
+		discovered, err := namespaceTargets(ctx, strimziClient, namespace.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, discovered...)
+	}
+
+	return targets, nil
+}
+
+// discoverNamespaceTargets finds all Kafka clusters in the namespace from the --namespace option or from the Kubernetes
+// configuration. It is used by the --all-clusters option.
+func discoverNamespaceTargets(ctx context.Context, cmd *cobra.Command) ([]Target, error) {
+	_, strimziClient, _, namespace, err := utils.CreateKubernetesClients(cmd)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes clients", "error", err)
+		return nil, err
+	}
+
+	return namespaceTargets(ctx, strimziClient, namespace)
+}
+
+// namespaceTargets lists the Kafka clusters in the namespace
+func namespaceTargets(ctx context.Context, strimziClient *strimzi.Clientset, namespace string) ([]Target, error) {
+	kafkas, err := strimziClient.KafkaV1beta2().Kafkas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the Kafka clusters", "namespace", namespace, "error", err)
+		return nil, err
+	}
+
+	var targets []Target
+	for _, kafka := range kafkas.Items {
+		slog.Info("Discovered Kafka cluster", "name", kafka.Name, "namespace", kafka.Namespace)
+		targets = append(targets, Target{Namespace: kafka.Namespace, Name: kafka.Name})
+	}
+
+	return targets, nil
+}
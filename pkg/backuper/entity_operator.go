@@ -0,0 +1,190 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	EntityOperatorSecretsFilename      = "entity-operator-secrets.yaml"
+	EntityOperatorRoleBindingsFilename = "entity-operator-role-bindings.yaml"
+)
+
+// entityOperatorRoleBinding identifies a RoleBinding of the Topic or User Operator. The RoleBindings are created in
+// the namespace of the Kafka cluster and in the namespace watched by the operator.
+type entityOperatorRoleBinding struct {
+	Namespace string
+	Name      string
+}
+
+// entityOperatorResources returns the names of the Secrets with the certificates of the Topic and User Operators and
+// their RoleBindings created by the Cluster Operator for the Kafka cluster
+func entityOperatorResources(kafka *v1beta2.Kafka) ([]string, []entityOperatorRoleBinding) {
+	if kafka.Spec == nil || kafka.Spec.EntityOperator == nil {
+		return nil, nil
+	}
+
+	var secretNames []string
+	var roleBindings []entityOperatorRoleBinding
+
+	addOperator := func(operator string, watchedNamespace string) {
+		secretNames = append(secretNames, kafka.Name+"-entity-"+operator+"-operator-certs")
+
+		namespaces := []string{kafka.Namespace}
+		if watchedNamespace != "" && watchedNamespace != kafka.Namespace {
+			namespaces = append(namespaces, watchedNamespace)
+		}
+
+		for _, namespace := range namespaces {
+			roleBindings = append(roleBindings, entityOperatorRoleBinding{Namespace: namespace, Name: kafka.Name + "-entity-" + operator + "-operator-role"})
+		}
+	}
+
+	if topicOperator := kafka.Spec.EntityOperator.TopicOperator; topicOperator != nil {
+		addOperator("topic", topicOperator.WatchedNamespace)
+	}
+
+	if userOperator := kafka.Spec.EntityOperator.UserOperator; userOperator != nil {
+		addOperator("user", userOperator.WatchedNamespace)
+	}
+
+	return secretNames, roleBindings
+}
+
+// lintEntityOperator checks that the Secrets and RoleBindings needed by the Topic and User Operators exist. Without
+// them, the operators of the restored Kafka cluster cannot work until the Cluster Operator recreates them.
+func (b *KafkaBackuper) lintEntityOperator(kafka *v1beta2.Kafka) {
+	if b.skipLint {
+		return
+	}
+
+	secretNames, roleBindings := entityOperatorResources(kafka)
+
+	for _, secretName := range secretNames {
+		if _, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).Get(b.ctx, secretName, metav1.GetOptions{}); errors.IsNotFound(err) {
+			b.addFinding(LintSeverityWarning, "Secret", secretName, "The Secret with the certificate of the Entity Operator does not exist")
+		} else if err != nil {
+			b.addFinding(LintSeverityError, "Secret", secretName, fmt.Sprintf("Failed to get the Secret with the certificate of the Entity Operator: %v", err))
+		}
+	}
+
+	for _, roleBinding := range roleBindings {
+		if _, err := b.KubernetesClient.RbacV1().RoleBindings(roleBinding.Namespace).Get(b.ctx, roleBinding.Name, metav1.GetOptions{}); errors.IsNotFound(err) {
+			b.addFinding(LintSeverityWarning, "RoleBinding", roleBinding.Namespace+"/"+roleBinding.Name, "The RoleBinding of the Entity Operator does not exist")
+		} else if err != nil {
+			b.addFinding(LintSeverityError, "RoleBinding", roleBinding.Namespace+"/"+roleBinding.Name, fmt.Sprintf("Failed to get the RoleBinding of the Entity Operator: %v", err))
+		}
+	}
+}
+
+// BackupEntityOperator backs up the Secrets with the certificates of the Topic and User Operators and their
+// RoleBindings. They are normally recreated by the Cluster Operator, but restoring them allows the operators of the
+// restored Kafka cluster to work right away.
+func (b *KafkaBackuper) BackupEntityOperator() error {
+	slog.Info("Backing up the Secrets and RoleBindings of the Entity Operator", "name", b.Name, "namespace", b.Namespace)
+
+	kafka, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	secretNames, roleBindingNames := entityOperatorResources(kafka)
+	if len(secretNames) == 0 {
+		slog.Warn("The Kafka cluster has no Entity Operator", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	start := time.Now()
+
+	secrets := &v1.SecretList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "SecretList"}}
+	for _, secretName := range secretNames {
+		secret, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).Get(b.ctx, secretName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Info("The Secret of the Entity Operator does not exist => skipping it", "name", secretName, "namespace", b.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the Secret of the Entity Operator", "name", secretName, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		slog.Debug("Backing up Entity Operator Secret", "name", secret.Name)
+		secrets.Items = append(secrets.Items, *secret)
+	}
+
+	if len(secrets.Items) > 0 {
+		if !b.skipMetadataCleansing {
+			b.cleanseSecretMetadata(secrets)
+		}
+
+		secretsYaml, err := yaml.Marshal(secrets)
+		if err != nil {
+			slog.Error("Failed to marshal the Entity Operator Secrets to YAML", "error", err)
+			return err
+		}
+
+		if err := b.writeStream(EntityOperatorSecretsFilename, StreamDescription(EntityOperatorSecretsFilename), secretsYaml, len(secrets.Items), start); err != nil {
+			return err
+		}
+	}
+
+	start = time.Now()
+
+	roleBindings := &rbacv1.RoleBindingList{TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBindingList"}}
+	for _, roleBindingName := range roleBindingNames {
+		roleBinding, err := b.KubernetesClient.RbacV1().RoleBindings(roleBindingName.Namespace).Get(b.ctx, roleBindingName.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Info("The RoleBinding of the Entity Operator does not exist => skipping it", "name", roleBindingName.Name, "namespace", roleBindingName.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the RoleBinding of the Entity Operator", "name", roleBindingName.Name, "namespace", roleBindingName.Namespace, "error", err)
+			return err
+		}
+
+		slog.Debug("Backing up Entity Operator RoleBinding", "name", roleBinding.Name, "namespace", roleBinding.Namespace)
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&roleBinding.ObjectMeta)
+		}
+		roleBindings.Items = append(roleBindings.Items, *roleBinding)
+	}
+
+	if len(roleBindings.Items) > 0 {
+		roleBindingsYaml, err := yaml.Marshal(roleBindings)
+		if err != nil {
+			slog.Error("Failed to marshal the Entity Operator RoleBindings to YAML", "error", err)
+			return err
+		}
+
+		if err := b.writeStream(EntityOperatorRoleBindingsFilename, StreamDescription(EntityOperatorRoleBindingsFilename), roleBindingsYaml, len(roleBindings.Items), start); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Backup of the Secrets and RoleBindings of the Entity Operator complete", "secrets", len(secrets.Items), "roleBindings", len(roleBindings.Items))
+
+	return nil
+}
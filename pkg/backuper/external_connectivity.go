@@ -0,0 +1,217 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	CertManagerCertificatesFilename = "cert-manager-certificates.yaml"
+	ExternalDnsEndpointsFilename    = "external-dns-endpoints.yaml"
+	OpenShiftRoutesFilename         = "openshift-routes.yaml"
+	// ListenerCertificatesFilename is the stream with the custom certificates of the route listeners written by older
+	// versions. The custom certificates of all listeners are now backed up in the kafka-listener-certificates.yaml
+	// stream.
+	ListenerCertificatesFilename = "listener-certificate-secrets.yaml"
+
+	// ExternalDnsHostnameAnnotation is the annotation of the Services and Ingresses used by external-dns to create the
+	// DNS records
+	ExternalDnsHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+)
+
+var (
+	CertManagerCertificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	ExternalDnsEndpointResource    = schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"}
+	OpenShiftRouteResource         = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+)
+
+// BackupExternalConnectivity backs up the cert-manager Certificates issuing the listener certificates of the external
+// listeners and the external-dns DNSEndpoints with the hostnames of the external listeners. The external-dns
+// annotations of the listener Services are part of the Kafka resource and are backed up with it. When running on
+// OpenShift, the Routes of the route listeners are backed up as well. The Secrets with the custom certificates of the
+// listeners are backed up by BackupListenerCertificates.
+func (b *KafkaBackuper) BackupExternalConnectivity() error {
+	slog.Info("Backing up the cert-manager and external-dns resources of the external listeners", "name", b.Name, "namespace", b.Namespace)
+
+	kafka, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	secretNames, hostnames := externalListenerSecretsAndHostnames(kafka)
+	routeListeners := hasRouteListeners(kafka)
+	if len(secretNames) == 0 && len(hostnames) == 0 && !routeListeners {
+		slog.Warn("The Kafka cluster has no external listeners with custom certificates or hostnames", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	certificates, err := b.backupExternalConnectivityResources(CertManagerCertificatesFilename, CertManagerCertificateResource, func(item *unstructured.Unstructured) bool {
+		secretName, _, _ := unstructured.NestedString(item.Object, "spec", "secretName")
+		dnsNames, _, _ := unstructured.NestedStringSlice(item.Object, "spec", "dnsNames")
+
+		return slices.Contains(secretNames, secretName) || slices.ContainsFunc(dnsNames, func(dnsName string) bool { return slices.Contains(hostnames, dnsName) })
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := b.backupExternalConnectivityResources(ExternalDnsEndpointsFilename, ExternalDnsEndpointResource, func(item *unstructured.Unstructured) bool {
+		records, _, _ := unstructured.NestedSlice(item.Object, "spec", "endpoints")
+
+		return slices.ContainsFunc(records, func(record interface{}) bool {
+			dnsName, _, _ := unstructured.NestedString(record.(map[string]interface{}), "dnsName")
+			return slices.Contains(hostnames, dnsName)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	routes := 0
+	if routeListeners {
+		routes, err = b.backupExternalConnectivityResources(OpenShiftRoutesFilename, OpenShiftRouteResource, func(item *unstructured.Unstructured) bool {
+			return item.GetLabels()["strimzi.io/cluster"] == b.Name
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Backup of the cert-manager and external-dns resources complete", "certificates", certificates, "dnsEndpoints", endpoints, "routes", routes)
+
+	return nil
+}
+
+// backupExternalConnectivityResources backs up the resources matching the filter into a new stream. Nothing is written
+// when the custom resource is not installed or no resource matches the filter.
+func (b *KafkaBackuper) backupExternalConnectivityResources(name string, resource schema.GroupVersionResource, filter func(item *unstructured.Unstructured) bool) (int, error) {
+	start := time.Now()
+
+	resources, err := b.DynamicClient.Resource(resource).Namespace(b.Namespace).List(b.ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		slog.Debug("The custom resource is not installed", "resource", resource.GroupResource().String())
+		return 0, nil
+	} else if err != nil {
+		slog.Error("Failed to list the resources", "resource", resource.GroupResource().String(), "namespace", b.Namespace, "error", err)
+		return 0, err
+	}
+
+	sortByName(resources.Items)
+
+	matching := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	for _, item := range resources.Items {
+		if filter(&item) {
+			slog.Debug("Backing up resource", "kind", item.GetKind(), "name", item.GetName())
+
+			if !b.skipMetadataCleansing {
+				item.SetManagedFields(nil)
+				item.SetResourceVersion("")
+				item.SetUID("")
+				item.SetGeneration(0)
+				item.SetCreationTimestamp(metav1.Time{})
+				item.SetOwnerReferences(nil)
+				unstructured.RemoveNestedField(item.Object, "status")
+			}
+
+			matching.Items = append(matching.Items, item)
+		}
+	}
+
+	if len(matching.Items) == 0 {
+		slog.Info("No resources used by the external listeners found", "resource", resource.GroupResource().String(), "namespace", b.Namespace)
+		return 0, nil
+	}
+
+	resourcesYaml, err := yaml.Marshal(matching)
+	if err != nil {
+		slog.Error("Failed to marshal the resources to YAML", "resource", resource.GroupResource().String(), "error", err)
+		return 0, err
+	}
+
+	return len(matching.Items), b.writeStream(name, StreamDescription(name), resourcesYaml, len(matching.Items), start)
+}
+
+// externalListenerSecretsAndHostnames returns the names of the Secrets with the custom listener certificates and the
+// hostnames of the external listeners of the Kafka cluster
+func externalListenerSecretsAndHostnames(kafka *v1beta2.Kafka) ([]string, []string) {
+	var secretNames, hostnames []string
+
+	addHostnames := func(values ...string) {
+		for _, hostname := range values {
+			if hostname != "" && !slices.Contains(hostnames, hostname) {
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+
+	addAnnotatedHostnames := func(annotations map[string]string) {
+		if value, ok := annotations[ExternalDnsHostnameAnnotation]; ok {
+			for _, hostname := range strings.Split(value, ",") {
+				addHostnames(strings.TrimSpace(hostname))
+			}
+		}
+	}
+
+	if kafka.Spec == nil || kafka.Spec.Kafka == nil {
+		return nil, nil
+	}
+
+	for _, listener := range kafka.Spec.Kafka.Listeners {
+		if listener.Type == v1beta2.INTERNAL_KAFKALISTENERTYPE || listener.Type == v1beta2.CLUSTER_IP_KAFKALISTENERTYPE || listener.Configuration == nil {
+			continue
+		}
+
+		if listener.Configuration.BrokerCertChainAndKey != nil && listener.Configuration.BrokerCertChainAndKey.SecretName != "" && !slices.Contains(secretNames, listener.Configuration.BrokerCertChainAndKey.SecretName) {
+			secretNames = append(secretNames, listener.Configuration.BrokerCertChainAndKey.SecretName)
+		}
+
+		if bootstrap := listener.Configuration.Bootstrap; bootstrap != nil {
+			addHostnames(bootstrap.Host)
+			addHostnames(bootstrap.AlternativeNames...)
+			addAnnotatedHostnames(bootstrap.Annotations)
+		}
+
+		for _, broker := range listener.Configuration.Brokers {
+			addHostnames(broker.Host, broker.AdvertisedHost)
+			addAnnotatedHostnames(broker.Annotations)
+		}
+	}
+
+	return secretNames, hostnames
+}
+
+// hasRouteListeners returns whether the Kafka cluster has any route listeners
+func hasRouteListeners(kafka *v1beta2.Kafka) bool {
+	if kafka.Spec == nil || kafka.Spec.Kafka == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(kafka.Spec.Kafka.Listeners, func(listener v1beta2.GenericKafkaListener) bool {
+		return listener.Type == v1beta2.ROUTE_KAFKALISTENERTYPE
+	})
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	// HistoryConfigMapPrefix is the prefix of the ConfigMaps with the statistics of the previous backups of the Kafka
+	// clusters
+	HistoryConfigMapPrefix = "strimzi-backup-history-"
+
+	historyKey = "history.yaml"
+	// maxHistoryEntries is the number of the previous backups whose statistics are kept in the history
+	maxHistoryEntries = 10
+)
+
+// HistoryEntry describes the streams of a single backup stored in the history
+type HistoryEntry struct {
+	FileName  string          `json:"fileName"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Streams   []HistoryStream `json:"streams"`
+}
+
+// HistoryStream describes the size of a single stream of the backup stored in the history
+type HistoryStream struct {
+	Name              string `json:"name"`
+	Resources         int    `json:"resources"`
+	UncompressedBytes int64  `json:"uncompressedBytes"`
+	CompressedBytes   int64  `json:"compressedBytes"`
+}
+
+// HistoryConfigMapName returns the name of the ConfigMap with the history of the backups of the Kafka cluster
+func HistoryConfigMapName(name string) string {
+	return HistoryConfigMapPrefix + name
+}
+
+// CheckStreamAnomalies compares the streams written so far with the streams of the previous backup from the history.
+// Streams which shrunk by more than the threshold or disappeared are reported as lint findings, because they usually
+// indicate a changed label selector or missing RBAC permissions rather than genuinely deleted resources.
+func (b *KafkaBackuper) CheckStreamAnomalies() error {
+	configMap, err := b.KubernetesClient.CoreV1().ConfigMaps(b.Namespace).Get(b.ctx, HistoryConfigMapName(b.Name), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		slog.Info("No history of the previous backups found => the streams cannot be compared", "configMap", HistoryConfigMapName(b.Name), "namespace", b.Namespace)
+		return nil
+	} else if err != nil {
+		slog.Error("Failed to get the history of the previous backups", "configMap", HistoryConfigMapName(b.Name), "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	if err := yaml.Unmarshal([]byte(configMap.Data[historyKey]), &b.history); err != nil {
+		slog.Error("Failed to unmarshal the history of the previous backups", "configMap", configMap.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	if len(b.history) == 0 {
+		return nil
+	}
+
+	previous := b.history[len(b.history)-1]
+	current := map[string]archive.StreamStats{}
+	for _, stats := range b.streamStats {
+		current[stats.Name] = stats
+	}
+
+	for _, stream := range previous.Streams {
+		stats, ok := current[stream.Name]
+		if !ok {
+			b.addFinding(LintSeverityWarning, "Backup", stream.Name, fmt.Sprintf("The stream is missing in the backup but it was present in the previous backup %s", previous.FileName))
+			continue
+		}
+
+		// The data streams and the informational streams might not contain any resources, so their size is compared
+		if stream.Resources > 0 && shrunk(int64(stream.Resources), int64(stats.Resources), b.shrinkThreshold) {
+			b.addFinding(LintSeverityWarning, "Backup", stream.Name, fmt.Sprintf("The number of resources in the stream dropped from %d in the previous backup %s to %d", stream.Resources, previous.FileName, stats.Resources))
+		} else if stream.Resources == 0 && shrunk(stream.UncompressedBytes, stats.UncompressedBytes, b.shrinkThreshold) {
+			b.addFinding(LintSeverityWarning, "Backup", stream.Name, fmt.Sprintf("The size of the stream dropped from %d bytes in the previous backup %s to %d bytes", stream.UncompressedBytes, previous.FileName, stats.UncompressedBytes))
+		}
+	}
+
+	return nil
+}
+
+// shrunk indicates whether the current value dropped by more than the threshold (a fraction of the previous value)
+func shrunk(previous int64, current int64, threshold float64) bool {
+	return previous > 0 && float64(previous-current) > float64(previous)*threshold
+}
+
+// RecordHistory adds the streams of the completed backup to the history of the previous backups. Only the statistics
+// of the last backups are kept.
+func (b *KafkaBackuper) RecordHistory() error {
+	entry := HistoryEntry{FileName: filepath.Base(b.FileName()), CreatedAt: b.startedAt.UTC()}
+	for _, stats := range b.streamStats {
+		if stats.Name == archive.ManifestFilename {
+			continue
+		}
+
+		entry.Streams = append(entry.Streams, HistoryStream{Name: stats.Name, Resources: stats.Resources, UncompressedBytes: stats.UncompressedBytes, CompressedBytes: stats.CompressedBytes})
+	}
+
+	history := append(b.history, entry)
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+
+	historyYaml, err := yaml.Marshal(history)
+	if err != nil {
+		slog.Error("Failed to marshal the history of the backups", "error", err)
+		return err
+	}
+
+	configMap := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      HistoryConfigMapName(b.Name),
+			Namespace: b.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "strimzi-backup"},
+		},
+		Data: map[string]string{historyKey: string(historyYaml)},
+	}
+
+	if _, err := utils.Apply(b.KubernetesClient.CoreV1().ConfigMaps(b.Namespace).Patch, configMap.Name, configMap, false); err != nil {
+		slog.Error("Failed to update the history of the backups", "configMap", configMap.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The backup was recorded in the history", "configMap", configMap.Name, "namespace", b.Namespace, "backups", len(history))
+
+	return nil
+}
@@ -17,7 +17,7 @@ limitations under the License.
 package backuper
 
 import (
-	"context"
+	"fmt"
 	"github.com/scholzj/strimzi-backup/pkg/utils"
 	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
 	"github.com/spf13/cobra"
@@ -25,11 +25,36 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"log/slog"
 	"sigs.k8s.io/yaml"
+	"slices"
+	"sort"
 	"time"
 )
 
 type KafkaBackuper struct {
 	Backuper
+
+	Quiesced       bool
+	quiesceTimeout uint32
+	quiescedTopics []string
+	quiescedUsers  []string
+	skipLint       bool
+	brokerConfig   v1beta2.MapStringObject
+	// Legacy Kafka clusters without node pools have no KafkaNodePool resources to back up
+	withoutNodePools bool
+
+	apicurioRegistryURL     string
+	apicurioRegistryHeaders []string
+
+	rebalanceTemplatesOnly bool
+	// Additional namespaces watched by the Topic Operator from which the KafkaTopics are backed up
+	topicNamespaces []string
+	// Additional namespaces watched by the User Operator from which the KafkaUsers and their Secrets are backed up
+	userNamespaces []string
+	// Follow the namespaces watched by the Topic and User Operators
+	followWatched bool
+
+	shrinkThreshold float64
+	history         []HistoryEntry
 }
 
 const (
@@ -41,32 +66,135 @@ const (
 	KafkaUserSecretsFilename = "kafka-user-secrets.yaml"
 )
 
-func NewKafkaBackuper(cmd *cobra.Command) (*KafkaBackuper, error) {
-	backuper, err := NewBackuper(cmd)
+// DefaultEncryptedStreams are the streams with the key material which are encrypted by default when the encryption is
+// enabled
+var DefaultEncryptedStreams = []string{CaSecretsFilename, KafkaListenerCertificatesFilename, KafkaAuthenticationSecretsFilename, KafkaReferencedSecretsFilename, KafkaUserSecretsFilename, EntityOperatorSecretsFilename, KafkaConnectSecretsFilename, KafkaMirrorMaker2SecretsFilename, KafkaMirrorMakerSecretsFilename, KafkaBridgeSecretsFilename, OperatorSecretsFilename, ListenerCertificatesFilename}
+
+func NewKafkaBackuper(cmd *cobra.Command, target Target) (*KafkaBackuper, error) {
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	quiesce, err := cmd.Flags().GetBool("quiesce")
+	if err != nil {
+		slog.Error("Failed to get the --quiesce flag", "error", err)
+		return nil, err
+	}
+
+	quiesceTimeout, err := cmd.Flags().GetUint32("quiesce-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --quiesce-timeout flag", "error", err)
+		return nil, err
+	}
+
+	skipLint, err := cmd.Flags().GetBool("skip-lint")
 	if err != nil {
+		slog.Error("Failed to get the --skip-lint flag", "error", err)
 		return nil, err
 	}
 
-	return &KafkaBackuper{Backuper: *backuper}, nil
+	apicurioRegistryHeaders, err := cmd.Flags().GetStringArray("apicurio-registry-header")
+	if err != nil {
+		slog.Error("Failed to get the --apicurio-registry-header flag", "error", err)
+		return nil, err
+	}
+
+	// The history is tracked only by the backup kafka command
+	var shrinkThreshold float64
+	if cmd.Flags().Lookup("shrink-threshold") != nil {
+		shrinkThreshold, err = cmd.Flags().GetFloat64("shrink-threshold")
+		if err != nil {
+			slog.Error("Failed to get the --shrink-threshold flag", "error", err)
+			return nil, err
+		}
+
+		if shrinkThreshold <= 0 || shrinkThreshold > 1 {
+			slog.Error("The --shrink-threshold option has to be greater than 0 and at most 1", "shrinkThreshold", shrinkThreshold)
+			return nil, fmt.Errorf("the --shrink-threshold option has to be greater than 0 and at most 1")
+		}
+	}
+
+	// Only the backup kafka command can limit the backed up KafkaRebalances to the templates
+	var rebalanceTemplatesOnly bool
+	if cmd.Flags().Lookup("rebalance-templates-only") != nil {
+		rebalanceTemplatesOnly, err = cmd.Flags().GetBool("rebalance-templates-only")
+		if err != nil {
+			slog.Error("Failed to get the --rebalance-templates-only flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// Only the backup kafka command can back up the KafkaTopics from other namespaces
+	var topicNamespaces []string
+	if cmd.Flags().Lookup("topic-namespaces") != nil {
+		topicNamespaces, err = cmd.Flags().GetStringSlice("topic-namespaces")
+		if err != nil {
+			slog.Error("Failed to get the --topic-namespaces flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// Only the backup kafka command can follow the namespaces watched by the Entity Operator
+	var followWatched bool
+	if cmd.Flags().Lookup("follow-watched-namespaces") != nil {
+		followWatched, err = cmd.Flags().GetBool("follow-watched-namespaces")
+		if err != nil {
+			slog.Error("Failed to get the --follow-watched-namespaces flag", "error", err)
+			return nil, err
+		}
+	}
+
+	return &KafkaBackuper{
+		Backuper:                *backuper,
+		Quiesced:                quiesce,
+		quiesceTimeout:          quiesceTimeout,
+		skipLint:                skipLint,
+		apicurioRegistryURL:     cmd.Flag("apicurio-registry-url").Value.String(),
+		apicurioRegistryHeaders: apicurioRegistryHeaders,
+		rebalanceTemplatesOnly:  rebalanceTemplatesOnly,
+		topicNamespaces:         topicNamespaces,
+		followWatched:           followWatched,
+		shrinkThreshold:         shrinkThreshold,
+	}, nil
 }
 
 func (b *KafkaBackuper) BackupKafka() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = KafkaFilename
-	b.gzipWriter.Comment = "Kafka cluster"
-	b.gzipWriter.ModTime = time.Now()
+	start := time.Now()
 
 	slog.Info("Backing up the Kafka resource", "name", b.Name)
 
-	resource, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(context.TODO(), b.Name, metav1.GetOptions{})
+	resource, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
 	if err != nil {
 		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
 		return err
 	}
 
+	usesNodePools, err := utils.UsesNodePools(b.ctx, b.StrimziClient, resource)
+	if err != nil {
+		slog.Error("Failed to get KafkaNodePools belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	b.lintKafka(resource, usesNodePools)
+	b.lintEntityOperator(resource)
+
+	b.withoutNodePools = !usesNodePools
+
+	if err := b.followWatchedNamespaces(resource); err != nil {
+		return err
+	}
+
+	if resource.Status != nil {
+		b.operatorVersion = resource.Status.OperatorLastSuccessfulVersion
+	}
+
+	removeLastBackupAnnotations(&resource.ObjectMeta)
+
 	if !b.skipMetadataCleansing {
 		// Cleanse the metadata
 		utils.CleanseMetadata(&resource.ObjectMeta)
+		utils.CleanseAnnotations(&resource.ObjectMeta, b.preservedAnnotations)
 	}
 
 	resourceYaml, err := yaml.Marshal(resource)
@@ -75,15 +203,7 @@ func (b *KafkaBackuper) BackupKafka() error {
 		return err
 	}
 
-	_, err = b.gzipWriter.Write(resourceYaml)
-	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
-		return err
-	}
-
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(KafkaFilename, StreamDescription(KafkaFilename), resourceYaml, 1, start); err != nil {
 		return err
 	}
 
@@ -93,19 +213,27 @@ func (b *KafkaBackuper) BackupKafka() error {
 }
 
 func (b *KafkaBackuper) BackupKafkaNodePools() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = KafkaNodePoolsFilename
-	b.gzipWriter.Comment = "List of Kafka Node Pools"
-	b.gzipWriter.ModTime = time.Now()
+	if b.withoutNodePools {
+		slog.Info("The Kafka cluster does not use node pools => skipping the backup of the KafkaNodePool resources", "name", b.Name)
+		return nil
+	}
+
+	start := time.Now()
 
 	slog.Info("Backing up the KafkaNodePool resources", "labelSelector", "strimzi.io/cluster="+b.Name)
 
-	resources, err := b.StrimziClient.KafkaV1beta2().KafkaNodePools(b.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaNodePools(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
 	if err != nil {
 		slog.Error("Failed to get KafkaNodePools belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
 		return err
 	}
 
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up KafkaNodePool", "name", resource.Name)
+	}
+
+	sortByName(resources.Items)
+
 	if !b.skipMetadataCleansing {
 		// Cleanse the metadata
 		b.cleanseKafkaNodePoolMetadata(resources)
@@ -117,15 +245,7 @@ func (b *KafkaBackuper) BackupKafkaNodePools() error {
 		return err
 	}
 
-	_, err = b.gzipWriter.Write(resourcesYaml)
-	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
-		return err
-	}
-
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(KafkaNodePoolsFilename, StreamDescription(KafkaNodePoolsFilename), resourcesYaml, len(resources.Items), start); err != nil {
 		return err
 	}
 
@@ -135,19 +255,24 @@ func (b *KafkaBackuper) BackupKafkaNodePools() error {
 }
 
 func (b *KafkaBackuper) BackupCaSecrets() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = CaSecretsFilename
-	b.gzipWriter.Comment = "List of CA Secrets"
-	b.gzipWriter.ModTime = time.Now()
+	start := time.Now()
 
 	slog.Info("Backing up the CA Secret resources", "labelSelector", "strimzi.io/component-type=certificate-authority,strimzi.io/cluster="+b.Name)
 
-	resources, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/component-type=certificate-authority,strimzi.io/cluster=" + b.Name})
+	resources, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/component-type=certificate-authority,strimzi.io/cluster=" + b.Name})
 	if err != nil {
 		slog.Error("Failed to get CA Secrets belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
 		return err
 	}
 
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up CA Secret", "name", resource.Name)
+	}
+
+	sortByName(resources.Items)
+
+	b.lintCaSecrets(resources)
+
 	if !b.skipMetadataCleansing {
 		// Cleanse the Secret metadata
 		b.cleanseSecretMetadata(resources)
@@ -159,15 +284,7 @@ func (b *KafkaBackuper) BackupCaSecrets() error {
 		return err
 	}
 
-	_, err = b.gzipWriter.Write(resourcesYaml)
-	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
-		return err
-	}
-
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(CaSecretsFilename, StreamDescription(CaSecretsFilename), resourcesYaml, len(resources.Items), start); err != nil {
 		return err
 	}
 
@@ -177,39 +294,45 @@ func (b *KafkaBackuper) BackupCaSecrets() error {
 }
 
 func (b *KafkaBackuper) BackupKafkaTopics() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = KafkaTopicsFilename
-	b.gzipWriter.Comment = "List of Kafka Topics"
-	b.gzipWriter.ModTime = time.Now()
+	start := time.Now()
 
 	slog.Info("Backing up the KafkaTopic resources", "labelSelector", "strimzi.io/cluster="+b.Name)
 
-	resources, err := b.StrimziClient.KafkaV1beta2().KafkaTopics(b.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	resources, err := b.listKafkaTopics()
 	if err != nil {
-		slog.Error("Failed to get KafkaTopics belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
 		return err
 	}
 
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up KafkaTopic", "name", resource.Name, "namespace", resource.Namespace)
+	}
+
 	if !b.skipMetadataCleansing {
 		// Cleanse the metadata
 		b.cleanseKafkaTopicMetadata(resources)
 	}
 
-	resourcesYaml, err := yaml.Marshal(resources)
-	if err != nil {
-		slog.Error("Failed to marshal the KafkaTopics to YAML", "error", err)
-		return err
+	for i := range resources.Items {
+		// Only the KafkaTopics from the namespace of the Kafka cluster are quiesced
+		if resources.Items[i].Namespace == b.Namespace {
+			b.removeQuiesceAnnotation(&resources.Items[i].ObjectMeta, b.quiescedTopics)
+		}
+
+		// The status is not restored, so the name of the Kafka topic has to be recorded in the spec
+		if utils.PinKafkaTopicName(&resources.Items[i]) {
+			slog.Info("Recording the name of the Kafka topic which differs from the KafkaTopic name", "name", resources.Items[i].Name, "topicName", resources.Items[i].Spec.TopicName)
+		}
 	}
 
-	_, err = b.gzipWriter.Write(resourcesYaml)
+	b.lintKafkaTopics(resources)
+
+	resourcesYaml, err := yaml.Marshal(resources)
 	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
+		slog.Error("Failed to marshal the KafkaTopics to YAML", "error", err)
 		return err
 	}
 
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(KafkaTopicsFilename, StreamDescription(KafkaTopicsFilename), resourcesYaml, len(resources.Items), start); err != nil {
 		return err
 	}
 
@@ -218,40 +341,72 @@ func (b *KafkaBackuper) BackupKafkaTopics() error {
 	return nil
 }
 
+// listKafkaTopics lists the KafkaTopics belonging to the Kafka cluster from its namespace and from the additional
+// namespaces set with the --topic-namespaces option or watched by the Topic Operator. The KafkaTopics are sorted by
+// their name within each namespace and the KafkaTopics from the namespace of the Kafka cluster come first.
+func (b *KafkaBackuper) listKafkaTopics() (*v1beta2.KafkaTopicList, error) {
+	var topics *v1beta2.KafkaTopicList
+
+	namespaces := append([]string{b.Namespace}, b.topicNamespaces...)
+	for i, namespace := range namespaces {
+		if slices.Contains(namespaces[:i], namespace) {
+			continue
+		}
+
+		resources, err := b.StrimziClient.KafkaV1beta2().KafkaTopics(namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+		if err != nil {
+			slog.Error("Failed to get KafkaTopics belonging to the Kafka cluster", "name", b.Name, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		sortByName(resources.Items)
+
+		if topics == nil {
+			topics = resources
+		} else {
+			slog.Info("Backing up the KafkaTopic resources from an additional namespace", "namespace", namespace, "topics", len(resources.Items))
+			topics.Items = append(topics.Items, resources.Items...)
+		}
+	}
+
+	return topics, nil
+}
+
 func (b *KafkaBackuper) BackupKafkaUsers() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = KafkaUsersFilename
-	b.gzipWriter.Comment = "List of Kafka Users"
-	b.gzipWriter.ModTime = time.Now()
+	start := time.Now()
 
 	slog.Info("Backing up the KafkaUser resources", "labelSelector", "strimzi.io/cluster="+b.Name)
 
-	resources, err := b.StrimziClient.KafkaV1beta2().KafkaUsers(b.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	resources, err := b.listKafkaUsers()
 	if err != nil {
-		slog.Error("Failed to get KafkaUsers belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
 		return err
 	}
 
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up KafkaUser", "name", resource.Name, "namespace", resource.Namespace)
+	}
+
 	if !b.skipMetadataCleansing {
 		// Cleanse the metadata
 		b.cleanseKafkaUserMetadata(resources)
 	}
 
-	resourcesYaml, err := yaml.Marshal(resources)
-	if err != nil {
-		slog.Error("Failed to marshal the KafkaUsers to YAML", "error", err)
-		return err
+	for i := range resources.Items {
+		// Only the KafkaUsers from the namespace of the Kafka cluster are quiesced
+		if resources.Items[i].Namespace == b.Namespace {
+			b.removeQuiesceAnnotation(&resources.Items[i].ObjectMeta, b.quiescedUsers)
+		}
+
+		normalizeAcls(&resources.Items[i])
 	}
 
-	_, err = b.gzipWriter.Write(resourcesYaml)
+	resourcesYaml, err := yaml.Marshal(resources)
 	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
+		slog.Error("Failed to marshal the KafkaUsers to YAML", "error", err)
 		return err
 	}
 
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(KafkaUsersFilename, StreamDescription(KafkaUsersFilename), resourcesYaml, len(resources.Items), start); err != nil {
 		return err
 	}
 
@@ -260,18 +415,56 @@ func (b *KafkaBackuper) BackupKafkaUsers() error {
 	return nil
 }
 
+// listKafkaUsers lists the KafkaUsers belonging to the Kafka cluster from its namespace and from the namespace watched
+// by the User Operator when following it with the --follow-watched-namespaces option. The KafkaUsers are sorted by
+// their name within each namespace and the KafkaUsers from the namespace of the Kafka cluster come first.
+func (b *KafkaBackuper) listKafkaUsers() (*v1beta2.KafkaUserList, error) {
+	var users *v1beta2.KafkaUserList
+
+	for _, namespace := range append([]string{b.Namespace}, b.userNamespaces...) {
+		resources, err := b.StrimziClient.KafkaV1beta2().KafkaUsers(namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+		if err != nil {
+			slog.Error("Failed to get KafkaUsers belonging to the Kafka cluster", "name", b.Name, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		sortByName(resources.Items)
+
+		if users == nil {
+			users = resources
+		} else {
+			slog.Info("Backing up the KafkaUser resources from an additional namespace", "namespace", namespace, "users", len(resources.Items))
+			users.Items = append(users.Items, resources.Items...)
+		}
+	}
+
+	return users, nil
+}
+
 func (b *KafkaBackuper) BackupUserSecrets() error {
-	b.gzipWriter.Reset(b.bufferedWriter)
-	b.gzipWriter.Name = KafkaUserSecretsFilename
-	b.gzipWriter.Comment = "List of User Secrets"
-	b.gzipWriter.ModTime = time.Now()
+	start := time.Now()
 
 	slog.Info("Backing up the User Secret resources", "labelSelector", "strimzi.io/kind=KafkaUser,strimzi.io/cluster="+b.Name)
 
-	resources, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/kind=KafkaUser,strimzi.io/cluster=" + b.Name})
-	if err != nil {
-		slog.Error("Failed to get User Secrets belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
-		return err
+	var resources *v1.SecretList
+	for _, namespace := range append([]string{b.Namespace}, b.userNamespaces...) {
+		secrets, err := b.KubernetesClient.CoreV1().Secrets(namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/kind=KafkaUser,strimzi.io/cluster=" + b.Name})
+		if err != nil {
+			slog.Error("Failed to get User Secrets belonging to the Kafka cluster", "name", b.Name, "namespace", namespace, "error", err)
+			return err
+		}
+
+		sortByName(secrets.Items)
+
+		if resources == nil {
+			resources = secrets
+		} else {
+			resources.Items = append(resources.Items, secrets.Items...)
+		}
+	}
+
+	for _, resource := range resources.Items {
+		slog.Debug("Backing up User Secret", "name", resource.Name, "namespace", resource.Namespace)
 	}
 
 	if !b.skipMetadataCleansing {
@@ -285,15 +478,7 @@ func (b *KafkaBackuper) BackupUserSecrets() error {
 		return err
 	}
 
-	_, err = b.gzipWriter.Write(resourcesYaml)
-	if err != nil {
-		slog.Error("Failed to write the YAML to the backup file", "error", err)
-		return err
-	}
-
-	err = b.gzipWriter.Close()
-	if err != nil {
-		slog.Error("Failed to close the GZIP writer when resetting the stream", "error", err)
+	if err := b.writeStream(KafkaUserSecretsFilename, StreamDescription(KafkaUserSecretsFilename), resourcesYaml, len(resources.Items), start); err != nil {
 		return err
 	}
 
@@ -303,6 +488,8 @@ func (b *KafkaBackuper) BackupUserSecrets() error {
 }
 
 func (b *KafkaBackuper) cleanseSecretMetadata(resources *v1.SecretList) {
+	resources.ListMeta = metav1.ListMeta{}
+
 	// We want to avoid copying the resource, so we use the index
 	for i := range resources.Items {
 		utils.CleanseMetadata(&resources.Items[i].ObjectMeta)
@@ -310,6 +497,8 @@ func (b *KafkaBackuper) cleanseSecretMetadata(resources *v1.SecretList) {
 }
 
 func (b *KafkaBackuper) cleanseKafkaNodePoolMetadata(resources *v1beta2.KafkaNodePoolList) {
+	resources.ListMeta = metav1.ListMeta{}
+
 	// We want to avoid copying the resource, so we use the index
 	for i := range resources.Items {
 		utils.CleanseMetadata(&resources.Items[i].ObjectMeta)
@@ -317,6 +506,8 @@ func (b *KafkaBackuper) cleanseKafkaNodePoolMetadata(resources *v1beta2.KafkaNod
 }
 
 func (b *KafkaBackuper) cleanseKafkaTopicMetadata(resources *v1beta2.KafkaTopicList) {
+	resources.ListMeta = metav1.ListMeta{}
+
 	// We want to avoid copying the resource, so we use the index
 	for i := range resources.Items {
 		utils.CleanseMetadata(&resources.Items[i].ObjectMeta)
@@ -324,12 +515,25 @@ func (b *KafkaBackuper) cleanseKafkaTopicMetadata(resources *v1beta2.KafkaTopicL
 }
 
 func (b *KafkaBackuper) cleanseKafkaUserMetadata(resources *v1beta2.KafkaUserList) {
+	resources.ListMeta = metav1.ListMeta{}
+
 	// We want to avoid copying the resource, so we use the index
 	for i := range resources.Items {
 		utils.CleanseMetadata(&resources.Items[i].ObjectMeta)
 	}
 }
 
+// sortByName sorts the resources by their name, so that the streams with unchanged resources are byte-identical
+// regardless of the order in which the Kubernetes API returned them
+func sortByName[T any, PT interface {
+	*T
+	GetName() string
+}](items []T) {
+	sort.Slice(items, func(i, j int) bool {
+		return PT(&items[i]).GetName() < PT(&items[j]).GetName()
+	})
+}
+
 //func (b *KafkaBackuper) Close() {
 //	b.Backuper.Close()
 //}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+)
+
+const (
+	KafkaConfigMapsFilename        = "kafka-config-maps.yaml"
+	KafkaReferencedSecretsFilename = "kafka-referenced-secrets.yaml"
+)
+
+// BackupKafkaConfigMaps backs up the ConfigMaps referenced from the Kafka resource and its node pools, such as the
+// logging and metrics configuration or the additional volumes. Without them, the Cluster Operator fails to reconcile
+// the restored cluster. Nothing is written when no ConfigMaps are referenced.
+func (b *KafkaBackuper) BackupKafkaConfigMaps() error {
+	_, configMaps, err := b.kafkaReferences()
+	if err != nil {
+		return err
+	}
+
+	if len(configMaps) == 0 {
+		slog.Info("No ConfigMaps referenced from the Kafka cluster found", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	return b.backupReferencedConfigMaps(KafkaConfigMapsFilename, configMaps)
+}
+
+// BackupKafkaReferencedSecrets backs up the Secrets referenced from the pod templates and the configuration of the
+// Kafka resource and its node pools, such as the additional volumes, the environment variables, the image pull
+// Secrets, or the configuration providers. Nothing is written when no such Secrets are referenced.
+func (b *KafkaBackuper) BackupKafkaReferencedSecrets() error {
+	secrets, _, err := b.kafkaReferences()
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		slog.Info("No Secrets referenced from the pod templates and the configuration of the Kafka cluster found", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	return b.backupReferencedSecrets(KafkaReferencedSecretsFilename, secrets)
+}
+
+// kafkaReferences returns the names of the Secrets and ConfigMaps referenced from the Kafka resource and its node
+// pools. The Secrets with the custom listener certificates and the Secrets used by the authentication and
+// authorization are not included, as they are backed up in their own streams.
+func (b *KafkaBackuper) kafkaReferences() (secrets []string, configMaps []string, err error) {
+	kafka, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return nil, nil, err
+	}
+
+	nodePools, err := b.StrimziClient.KafkaV1beta2().KafkaNodePools(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get KafkaNodePools belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return nil, nil, err
+	}
+
+	secrets, configMaps = scanReferences(kafka.Spec, b.Namespace)
+	for _, nodePool := range nodePools.Items {
+		poolSecrets, poolConfigMaps := scanReferences(nodePool.Spec, b.Namespace)
+		secrets = append(secrets, poolSecrets...)
+		configMaps = append(configMaps, poolConfigMaps...)
+	}
+
+	backedUp := append(listenerCertificateSecrets(kafka), authenticationSecrets(kafka)...)
+	secrets = slices.DeleteFunc(secrets, func(name string) bool {
+		return slices.Contains(backedUp, name)
+	})
+
+	return uniqueNames(secrets), uniqueNames(configMaps), nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"time"
+)
+
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+
+	minInSyncReplicasOption = "min.insync.replicas"
+
+	// certificateExpirationWarning is how long before their expiration the certificates are reported
+	certificateExpirationWarning = 30 * 24 * time.Hour
+)
+
+// addFinding records the lint finding for the manifest and logs it
+func (b *Backuper) addFinding(severity string, kind string, name string, message string) {
+	slog.Warn("Lint check found a problem", "severity", severity, "kind", kind, "name", name, "message", message)
+
+	b.lintFindings = append(b.lintFindings, archive.LintFinding{Severity: severity, Kind: kind, Name: name, Message: message})
+}
+
+// lintKafka checks the Kafka resource for deprecated fields, missing configuration, and expired listener certificates
+func (b *KafkaBackuper) lintKafka(kafka *v1beta2.Kafka, usesNodePools bool) {
+	if b.skipLint {
+		return
+	}
+
+	if kafka.Spec == nil {
+		return
+	}
+
+	if kafka.Spec.Zookeeper != nil {
+		b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The spec.zookeeper field is deprecated. ZooKeeper-based clusters are not supported anymore.")
+	}
+
+	if kafka.Spec.JmxTrans != nil {
+		b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The spec.jmxTrans field is deprecated and ignored")
+	}
+
+	if kafka.Spec.EntityOperator != nil && kafka.Spec.EntityOperator.TlsSidecar != nil {
+		b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The spec.entityOperator.tlsSidecar field is deprecated and ignored")
+	}
+
+	if kafka.Spec.Kafka == nil {
+		return
+	}
+
+	if !usesNodePools {
+		b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The Kafka cluster does not use node pools. Kafka clusters without node pools are not supported by Strimzi 0.46 and newer.")
+	} else {
+		if kafka.Spec.Kafka.Replicas != 0 {
+			b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The spec.kafka.replicas field is ignored when node pools are used")
+		}
+
+		if kafka.Spec.Kafka.Storage != nil {
+			b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The spec.kafka.storage field is ignored when node pools are used")
+		}
+	}
+
+	if _, ok := kafka.Spec.Kafka.Config[minInSyncReplicasOption]; !ok {
+		b.addFinding(LintSeverityWarning, "Kafka", kafka.Name, "The "+minInSyncReplicasOption+" option is not configured. The Kafka default value 1 allows losing messages when a broker fails.")
+	}
+
+	for _, listener := range kafka.Spec.Kafka.Listeners {
+		if listener.Configuration == nil || listener.Configuration.BrokerCertChainAndKey == nil {
+			continue
+		}
+
+		source := listener.Configuration.BrokerCertChainAndKey
+		secret, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).Get(b.ctx, source.SecretName, metav1.GetOptions{})
+		if err != nil {
+			b.addFinding(LintSeverityError, "Kafka", kafka.Name, fmt.Sprintf("Failed to get the Secret %s with the certificate of the listener %s: %v", source.SecretName, listener.Name, err))
+			continue
+		}
+
+		b.lintCertificate("Kafka", kafka.Name, fmt.Sprintf("certificate of the listener %s", listener.Name), secret.Data[source.Certificate])
+	}
+
+	b.brokerConfig = kafka.Spec.Kafka.Config
+}
+
+// lintKafkaTopics checks the KafkaTopic resources for single-replica topics and missing min.insync.replicas
+func (b *KafkaBackuper) lintKafkaTopics(topics *v1beta2.KafkaTopicList) {
+	if b.skipLint {
+		return
+	}
+
+	_, brokerMinInSyncReplicas := b.brokerConfig[minInSyncReplicasOption]
+
+	for _, topic := range topics.Items {
+		if topic.Spec == nil {
+			continue
+		}
+
+		if topic.Spec.Replicas == 1 {
+			b.addFinding(LintSeverityWarning, "KafkaTopic", topic.Name, "The topic has only a single replica. Its data will be unavailable or lost when a broker fails.")
+		} else if _, ok := topic.Spec.Config[minInSyncReplicasOption]; topic.Spec.Replicas > 1 && !ok && !brokerMinInSyncReplicas {
+			b.addFinding(LintSeverityWarning, "KafkaTopic", topic.Name, "The "+minInSyncReplicasOption+" option is not configured for the topic or the Kafka cluster")
+		}
+	}
+}
+
+// lintCaSecrets checks the expiration of the Cluster and Clients CA certificates
+func (b *KafkaBackuper) lintCaSecrets(secrets *v1.SecretList) {
+	if b.skipLint {
+		return
+	}
+
+	for _, secret := range secrets.Items {
+		if certificate, ok := secret.Data["ca.crt"]; ok {
+			b.lintCertificate("Secret", secret.Name, "CA certificate", certificate)
+		}
+	}
+}
+
+// lintCertificate reports the certificates which are expired or expire soon
+func (b *Backuper) lintCertificate(kind string, name string, description string, data []byte) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		b.addFinding(LintSeverityError, kind, name, fmt.Sprintf("Failed to decode the %s", description))
+		return
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		b.addFinding(LintSeverityError, kind, name, fmt.Sprintf("Failed to parse the %s: %v", description, err))
+		return
+	}
+
+	if time.Now().After(certificate.NotAfter) {
+		b.addFinding(LintSeverityError, kind, name, fmt.Sprintf("The %s expired on %s", description, certificate.NotAfter.Format(time.RFC3339)))
+	} else if time.Now().Add(certificateExpirationWarning).After(certificate.NotAfter) {
+		b.addFinding(LintSeverityWarning, kind, name, fmt.Sprintf("The %s expires on %s", description, certificate.NotAfter.Format(time.RFC3339)))
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"time"
+)
+
+const (
+	KafkaListenerCertificatesFilename = "kafka-listener-certificates.yaml"
+)
+
+// BackupListenerCertificates backs up the Secrets with the custom certificates of the Kafka listeners
+// (brokerCertChainAndKey). Without them, the Cluster Operator would generate new listener certificates for the restored
+// cluster and the clients trusting the custom certificates would fail to connect. Nothing is written when no listener
+// uses a custom certificate.
+func (b *KafkaBackuper) BackupListenerCertificates() error {
+	start := time.Now()
+
+	kafka, err := b.StrimziClient.KafkaV1beta2().Kafkas(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	names := listenerCertificateSecrets(kafka)
+	if len(names) == 0 {
+		slog.Info("No listeners with custom certificates found", "name", b.Name, "namespace", b.Namespace)
+		return nil
+	}
+
+	slog.Info("Backing up the Secrets with the custom listener certificates", "secrets", len(names))
+
+	resources := &v1.SecretList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "SecretList"}}
+	for _, name := range names {
+		secret, err := b.KubernetesClient.CoreV1().Secrets(b.Namespace).Get(b.ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Warn("The Secret with the custom listener certificate does not exist", "name", name, "namespace", b.Namespace)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the Secret with the custom listener certificate", "name", name, "namespace", b.Namespace, "error", err)
+			return err
+		}
+
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&secret.ObjectMeta)
+		}
+
+		resources.Items = append(resources.Items, *secret)
+	}
+
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the Secrets to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaListenerCertificatesFilename, StreamDescription(KafkaListenerCertificatesFilename), resourcesYaml, len(resources.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the Secrets with the custom listener certificates complete", "secrets", len(resources.Items))
+
+	return nil
+}
+
+// listenerCertificateSecrets returns the names of the Secrets with the custom certificates of the Kafka listeners
+func listenerCertificateSecrets(kafka *v1beta2.Kafka) []string {
+	if kafka.Spec == nil || kafka.Spec.Kafka == nil {
+		return nil
+	}
+
+	var secretNames []string
+	for _, listener := range kafka.Spec.Kafka.Listeners {
+		if listener.Configuration == nil || listener.Configuration.BrokerCertChainAndKey == nil {
+			continue
+		}
+
+		if name := listener.Configuration.BrokerCertChainAndKey.SecretName; name != "" && !slices.Contains(secretNames, name) {
+			secretNames = append(secretNames, name)
+		}
+	}
+
+	slices.Sort(secretNames)
+
+	return secretNames
+}
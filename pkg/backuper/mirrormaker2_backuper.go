@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	KafkaMirrorMaker2Filename           = "kafka-mirror-maker-2.yaml"
+	KafkaMirrorMaker2SecretsFilename    = "kafka-mirror-maker-2-secrets.yaml"
+	KafkaMirrorMaker2ConfigMapsFilename = "kafka-mirror-maker-2-config-maps.yaml"
+)
+
+// MirrorMaker2Backuper backs up a Kafka MirrorMaker 2 cluster: the KafkaMirrorMaker2 resource and the Secrets and
+// ConfigMaps referenced from it
+type MirrorMaker2Backuper struct {
+	Backuper
+	references
+
+	skipSecrets bool
+}
+
+func NewMirrorMaker2Backuper(cmd *cobra.Command, target Target) (*MirrorMaker2Backuper, error) {
+	skipSecrets, err := cmd.Flags().GetBool("skip-mirrormaker2-secrets")
+	if err != nil {
+		slog.Error("Failed to get the --skip-mirrormaker2-secrets flag", "error", err)
+		return nil, err
+	}
+
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MirrorMaker2Backuper{Backuper: *backuper, skipSecrets: skipSecrets}, nil
+}
+
+// BackupKafkaMirrorMaker2 backs up the KafkaMirrorMaker2 resource and collects the Secrets and ConfigMaps referenced
+// from it
+func (b *MirrorMaker2Backuper) BackupKafkaMirrorMaker2() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaMirrorMaker2 resource", "name", b.Name)
+
+	resource, err := b.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka MirrorMaker 2 cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	b.addReferences(mirrorMaker2References(resource))
+
+	removeLastBackupAnnotations(&resource.ObjectMeta)
+
+	if !b.skipMetadataCleansing {
+		// Cleanse the metadata
+		utils.CleanseMetadata(&resource.ObjectMeta)
+		utils.CleanseAnnotations(&resource.ObjectMeta, b.preservedAnnotations)
+	}
+
+	resourceYaml, err := yaml.Marshal(resource)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka MirrorMaker 2 cluster to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaMirrorMaker2Filename, StreamDescription(KafkaMirrorMaker2Filename), resourceYaml, 1, start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaMirrorMaker2 resource complete", "name", b.Name)
+
+	return nil
+}
+
+// BackupReferencedResources backs up the Secrets and ConfigMaps referenced from the KafkaMirrorMaker2 resource. It has
+// to be called after it was backed up. The Secrets are skipped when the --skip-mirrormaker2-secrets option is used.
+func (b *MirrorMaker2Backuper) BackupReferencedResources() error {
+	if b.skipSecrets {
+		slog.Info("Skipping the backup of the Secrets referenced from the Kafka MirrorMaker 2 cluster", "secrets", len(b.secrets))
+	} else if err := b.backupReferencedSecrets(KafkaMirrorMaker2SecretsFilename, b.secrets); err != nil {
+		return err
+	}
+
+	return b.backupReferencedConfigMaps(KafkaMirrorMaker2ConfigMapsFilename, b.configMaps)
+}
+
+// mirrorMaker2References returns the names of the Secrets and ConfigMaps referenced from the KafkaMirrorMaker2
+// resource: the trusted certificates and the credentials used to connect to the source and target Kafka clusters, the
+// logging and metrics configuration, the external configuration, and the references found by the generic scanner in
+// the pod templates and the configuration
+func mirrorMaker2References(mirrorMaker2 *v1beta2.KafkaMirrorMaker2) (secrets []string, configMaps []string) {
+	spec := mirrorMaker2.Spec
+	if spec == nil {
+		return nil, nil
+	}
+
+	for _, cluster := range spec.Clusters {
+		secrets = append(secrets, clientSecrets(cluster.Tls, cluster.Authentication)...)
+	}
+
+	externalSecrets, configMaps := configurationReferences(spec.Logging, spec.MetricsConfig, spec.ExternalConfiguration)
+	scannedSecrets, scannedConfigMaps := scanReferences(spec, mirrorMaker2.Namespace)
+
+	return append(append(secrets, externalSecrets...), scannedSecrets...), append(configMaps, scannedConfigMaps...)
+}
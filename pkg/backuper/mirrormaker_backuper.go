@@ -0,0 +1,173 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	KafkaMirrorMakerFilename           = "kafka-mirror-maker.yaml"
+	KafkaMirrorMakerSecretsFilename    = "kafka-mirror-maker-secrets.yaml"
+	KafkaMirrorMakerConfigMapsFilename = "kafka-mirror-maker-config-maps.yaml"
+)
+
+// MirrorMakerBackuper backs up a legacy Kafka MirrorMaker cluster: the KafkaMirrorMaker resource and the Secrets and
+// ConfigMaps referenced from it. The KafkaMirrorMaker resource was removed from Strimzi, so it is handled as an
+// unstructured resource.
+type MirrorMakerBackuper struct {
+	Backuper
+	references
+
+	skipSecrets bool
+}
+
+func NewMirrorMakerBackuper(cmd *cobra.Command, target Target) (*MirrorMakerBackuper, error) {
+	skipSecrets, err := cmd.Flags().GetBool("skip-mirrormaker-secrets")
+	if err != nil {
+		slog.Error("Failed to get the --skip-mirrormaker-secrets flag", "error", err)
+		return nil, err
+	}
+
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MirrorMakerBackuper{Backuper: *backuper, skipSecrets: skipSecrets}, nil
+}
+
+// BackupKafkaMirrorMaker backs up the KafkaMirrorMaker resource and collects the Secrets and ConfigMaps referenced from
+// it
+func (b *MirrorMakerBackuper) BackupKafkaMirrorMaker() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaMirrorMaker resource", "name", b.Name)
+
+	resource, err := b.DynamicClient.Resource(utils.KafkaMirrorMakerResource).Namespace(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		slog.Error("The Kafka MirrorMaker cluster or the KafkaMirrorMaker custom resource definition was not found", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	} else if err != nil {
+		slog.Error("Failed to get the Kafka MirrorMaker cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	secrets, configMaps, err := mirrorMakerReferences(resource)
+	if err != nil {
+		slog.Error("Failed to read the Secrets and ConfigMaps referenced from the Kafka MirrorMaker cluster", "name", b.Name, "error", err)
+		return err
+	}
+	b.addReferences(secrets, configMaps)
+
+	if err := b.cleanseMirrorMakerMetadata(resource); err != nil {
+		slog.Error("Failed to cleanse the metadata of the Kafka MirrorMaker cluster", "name", b.Name, "error", err)
+		return err
+	}
+
+	resourceYaml, err := yaml.Marshal(resource)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka MirrorMaker cluster to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaMirrorMakerFilename, StreamDescription(KafkaMirrorMakerFilename), resourceYaml, 1, start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaMirrorMaker resource complete", "name", b.Name)
+
+	return nil
+}
+
+// BackupReferencedResources backs up the Secrets and ConfigMaps referenced from the KafkaMirrorMaker resource. It has
+// to be called after it was backed up. The Secrets are skipped when the --skip-mirrormaker-secrets option is used.
+func (b *MirrorMakerBackuper) BackupReferencedResources() error {
+	if b.skipSecrets {
+		slog.Info("Skipping the backup of the Secrets referenced from the Kafka MirrorMaker cluster", "secrets", len(b.secrets))
+	} else if err := b.backupReferencedSecrets(KafkaMirrorMakerSecretsFilename, b.secrets); err != nil {
+		return err
+	}
+
+	return b.backupReferencedConfigMaps(KafkaMirrorMakerConfigMapsFilename, b.configMaps)
+}
+
+// cleanseMirrorMakerMetadata cleanses the metadata of the unstructured KafkaMirrorMaker resource the same way as the
+// metadata of the other Strimzi resources
+func (b *MirrorMakerBackuper) cleanseMirrorMakerMetadata(resource *unstructured.Unstructured) error {
+	metadata, err := utils.UnstructuredObjectMeta(resource)
+	if err != nil {
+		return err
+	}
+
+	removeLastBackupAnnotations(metadata)
+
+	if !b.skipMetadataCleansing {
+		utils.CleanseMetadata(metadata)
+		utils.CleanseAnnotations(metadata, b.preservedAnnotations)
+	}
+
+	return utils.SetUnstructuredObjectMeta(resource, metadata)
+}
+
+// mirrorMakerSpec is the part of the KafkaMirrorMaker spec with references to Secrets and ConfigMaps
+type mirrorMakerSpec struct {
+	Consumer      *mirrorMakerClient     `json:"consumer,omitempty"`
+	Producer      *mirrorMakerClient     `json:"producer,omitempty"`
+	Logging       *v1beta2.Logging       `json:"logging,omitempty"`
+	MetricsConfig *v1beta2.MetricsConfig `json:"metricsConfig,omitempty"`
+}
+
+type mirrorMakerClient struct {
+	Tls            *v1beta2.ClientTls                 `json:"tls,omitempty"`
+	Authentication *v1beta2.KafkaClientAuthentication `json:"authentication,omitempty"`
+}
+
+// mirrorMakerReferences returns the names of the Secrets and ConfigMaps referenced from the KafkaMirrorMaker resource:
+// the trusted certificates and the credentials used by the consumer and the producer, the logging and metrics
+// configuration, and the references found by the generic scanner in the pod templates and the configuration
+func mirrorMakerReferences(mirrorMaker *unstructured.Unstructured) (secrets []string, configMaps []string, err error) {
+	rawSpec, found, err := unstructured.NestedMap(mirrorMaker.Object, "spec")
+	if err != nil || !found {
+		return nil, nil, err
+	}
+
+	var spec mirrorMakerSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSpec, &spec); err != nil {
+		return nil, nil, err
+	}
+
+	for _, client := range []*mirrorMakerClient{spec.Consumer, spec.Producer} {
+		if client != nil {
+			secrets = append(secrets, clientSecrets(client.Tls, client.Authentication)...)
+		}
+	}
+
+	_, configMaps = configurationReferences(spec.Logging, spec.MetricsConfig, nil)
+	scannedSecrets, scannedConfigMaps := scanReferences(rawSpec, mirrorMaker.GetNamespace())
+
+	return append(secrets, scannedSecrets...), append(configMaps, scannedConfigMaps...), nil
+}
@@ -0,0 +1,355 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultOperatorName is the name of the Cluster Operator Deployment used in the Strimzi installation files
+	DefaultOperatorName = "strimzi-cluster-operator"
+
+	OperatorDeploymentFilename          = "operator-deployment.yaml"
+	OperatorServiceAccountFilename      = "operator-service-account.yaml"
+	OperatorClusterRolesFilename        = "operator-cluster-roles.yaml"
+	OperatorClusterRoleBindingsFilename = "operator-cluster-role-bindings.yaml"
+	OperatorRolesFilename               = "operator-roles.yaml"
+	OperatorRoleBindingsFilename        = "operator-role-bindings.yaml"
+	OperatorConfigMapsFilename          = "operator-config-maps.yaml"
+	OperatorSecretsFilename             = "operator-secrets.yaml"
+	OperatorLeasesFilename              = "operator-leases.yaml"
+)
+
+// OperatorBackuper backs up the installation of the Strimzi Cluster Operator: its Deployment, the ConfigMaps and
+// Secrets used by it, its ServiceAccount, the Roles, ClusterRoles, and their bindings granting the rights to the
+// ServiceAccount, and the Lease used for the leader election. It allows to install the Cluster Operator before the
+// operands are restored when rebuilding the whole environment.
+type OperatorBackuper struct {
+	Backuper
+	references
+
+	serviceAccountName string
+	// Namespaces watched by the Cluster Operator. Contains * when it watches all namespaces.
+	watchedNamespaces []string
+	leaseName         string
+	leaseNamespace    string
+}
+
+func NewOperatorBackuper(cmd *cobra.Command, target Target) (*OperatorBackuper, error) {
+	backuper, err := NewBackuper(cmd, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperatorBackuper{Backuper: *backuper}, nil
+}
+
+// BackupDeployment backs up the Deployment of the Cluster Operator and collects the resources used by it. It has to
+// be called before the other resources are backed up.
+func (b *OperatorBackuper) BackupDeployment() error {
+	start := time.Now()
+
+	slog.Info("Backing up the Cluster Operator Deployment", "name", b.Name)
+
+	deployment, err := b.KubernetesClient.AppsV1().Deployments(b.Namespace).Get(b.ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Cluster Operator Deployment", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	b.collectOperatorConfiguration(&deployment.Spec.Template.Spec)
+
+	deployment.TypeMeta = metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"}
+	deployment.Status = appsv1.DeploymentStatus{}
+
+	if !b.skipMetadataCleansing {
+		utils.CleanseMetadata(&deployment.ObjectMeta)
+		delete(deployment.Annotations, "deployment.kubernetes.io/revision")
+	}
+
+	resourceYaml, err := yaml.Marshal(deployment)
+	if err != nil {
+		slog.Error("Failed to marshal the Cluster Operator Deployment to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(OperatorDeploymentFilename, StreamDescription(OperatorDeploymentFilename), resourceYaml, 1, start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the Cluster Operator Deployment complete", "name", b.Name, "serviceAccount", b.serviceAccountName, "watchedNamespaces", b.watchedNamespaces)
+
+	return nil
+}
+
+// collectOperatorConfiguration collects the ServiceAccount, the watched namespaces, the Lease, the operator version,
+// and the Secrets and ConfigMaps used by the Cluster Operator from its pod template
+func (b *OperatorBackuper) collectOperatorConfiguration(pod *v1.PodSpec) {
+	b.serviceAccountName = pod.ServiceAccountName
+	if b.serviceAccountName == "" {
+		b.serviceAccountName = "default"
+	}
+
+	b.leaseName = DefaultOperatorName
+	b.leaseNamespace = b.Namespace
+
+	for _, container := range pod.Containers {
+		for _, env := range container.Env {
+			// The values set from the Downward API (such as the namespace of the operator) have no value
+			if env.Value == "" {
+				continue
+			}
+
+			switch env.Name {
+			case "STRIMZI_NAMESPACE":
+				for _, namespace := range strings.Split(env.Value, ",") {
+					if namespace = strings.TrimSpace(namespace); namespace != "" && !slices.Contains(b.watchedNamespaces, namespace) {
+						b.watchedNamespaces = append(b.watchedNamespaces, namespace)
+					}
+				}
+			case "STRIMZI_LEADER_ELECTION_LEASE_NAME":
+				b.leaseName = env.Value
+			case "STRIMZI_LEADER_ELECTION_LEASE_NAMESPACE":
+				b.leaseNamespace = env.Value
+			}
+		}
+
+		// The image tag of the operator container identifies the Strimzi version
+		if container.Name == DefaultOperatorName || b.operatorVersion == "" {
+			if index := strings.LastIndex(container.Image, ":"); index > strings.LastIndex(container.Image, "/") {
+				b.operatorVersion = container.Image[index+1:]
+			}
+		}
+	}
+
+	b.addReferences(scanReferences(pod, b.Namespace))
+}
+
+// BackupConfiguration backs up the ConfigMaps and Secrets used by the Cluster Operator, such as the ConfigMap with
+// its logging configuration or the image pull Secrets
+func (b *OperatorBackuper) BackupConfiguration() error {
+	if err := b.backupReferencedConfigMaps(OperatorConfigMapsFilename, b.configMaps); err != nil {
+		return err
+	}
+
+	return b.backupReferencedSecrets(OperatorSecretsFilename, b.secrets)
+}
+
+// BackupRBAC backs up the ServiceAccount of the Cluster Operator and the ClusterRoleBindings and RoleBindings binding
+// it together with the ClusterRoles and Roles they bind. The RoleBindings are looked up in the namespace of the
+// Cluster Operator and in the namespaces watched by it.
+func (b *OperatorBackuper) BackupRBAC() error {
+	start := time.Now()
+
+	slog.Info("Backing up the ServiceAccount, Roles, and RoleBindings of the Cluster Operator", "serviceAccount", b.serviceAccountName)
+
+	serviceAccount, err := b.KubernetesClient.CoreV1().ServiceAccounts(b.Namespace).Get(b.ctx, b.serviceAccountName, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the ServiceAccount of the Cluster Operator", "name", b.serviceAccountName, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	serviceAccount.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"}
+	// The token Secrets are generated for the new ServiceAccount
+	serviceAccount.Secrets = nil
+	if !b.skipMetadataCleansing {
+		utils.CleanseMetadata(&serviceAccount.ObjectMeta)
+	}
+
+	if err := b.writeResources(OperatorServiceAccountFilename, serviceAccount, 1, start); err != nil {
+		return err
+	}
+
+	var clusterRoleNames []string
+
+	start = time.Now()
+	clusterRoleBindings, err := b.KubernetesClient.RbacV1().ClusterRoleBindings().List(b.ctx, metav1.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list the ClusterRoleBindings", "error", err)
+		return err
+	}
+
+	clusterRoleBindings.Items = slices.DeleteFunc(clusterRoleBindings.Items, func(binding rbacv1.ClusterRoleBinding) bool {
+		return !b.bindsServiceAccount(binding.Subjects)
+	})
+
+	for i := range clusterRoleBindings.Items {
+		slog.Debug("Backing up ClusterRoleBinding", "name", clusterRoleBindings.Items[i].Name)
+		clusterRoleNames = append(clusterRoleNames, clusterRoleBindings.Items[i].RoleRef.Name)
+
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&clusterRoleBindings.Items[i].ObjectMeta)
+		}
+	}
+
+	sortByName(clusterRoleBindings.Items)
+	clusterRoleBindings.ListMeta = metav1.ListMeta{}
+	clusterRoleBindings.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBindingList"}
+
+	roleBindings := &rbacv1.RoleBindingList{TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBindingList"}}
+	roles := &rbacv1.RoleList{TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleList"}}
+	backedUpRoles := map[string]bool{}
+
+	namespaces := []string{b.Namespace}
+	for _, namespace := range b.watchedNamespaces {
+		if namespace == "*" {
+			// The rights for all namespaces are granted using the ClusterRoleBindings
+			continue
+		} else if !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		bindings, err := b.KubernetesClient.RbacV1().RoleBindings(namespace).List(b.ctx, metav1.ListOptions{})
+		if err != nil {
+			slog.Error("Failed to list the RoleBindings", "namespace", namespace, "error", err)
+			return err
+		}
+
+		sortByName(bindings.Items)
+
+		for _, binding := range bindings.Items {
+			if !b.bindsServiceAccount(binding.Subjects) {
+				continue
+			}
+
+			slog.Debug("Backing up RoleBinding", "name", binding.Name, "namespace", binding.Namespace)
+
+			if binding.RoleRef.Kind == "ClusterRole" {
+				clusterRoleNames = append(clusterRoleNames, binding.RoleRef.Name)
+			} else {
+				role, err := b.KubernetesClient.RbacV1().Roles(namespace).Get(b.ctx, binding.RoleRef.Name, metav1.GetOptions{})
+				if errors.IsNotFound(err) {
+					slog.Warn("The Role bound to the Cluster Operator does not exist", "name", binding.RoleRef.Name, "namespace", namespace)
+				} else if err != nil {
+					slog.Error("Failed to get the Role bound to the Cluster Operator", "name", binding.RoleRef.Name, "namespace", namespace, "error", err)
+					return err
+				} else if !backedUpRoles[namespace+"/"+role.Name] {
+					backedUpRoles[namespace+"/"+role.Name] = true
+
+					if !b.skipMetadataCleansing {
+						utils.CleanseMetadata(&role.ObjectMeta)
+					}
+
+					roles.Items = append(roles.Items, *role)
+				}
+			}
+
+			if !b.skipMetadataCleansing {
+				utils.CleanseMetadata(&binding.ObjectMeta)
+			}
+
+			roleBindings.Items = append(roleBindings.Items, binding)
+		}
+	}
+
+	clusterRoles := &rbacv1.ClusterRoleList{TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleList"}}
+	for _, name := range uniqueNames(clusterRoleNames) {
+		clusterRole, err := b.KubernetesClient.RbacV1().ClusterRoles().Get(b.ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			slog.Warn("The ClusterRole bound to the Cluster Operator does not exist", "name", name)
+			continue
+		} else if err != nil {
+			slog.Error("Failed to get the ClusterRole bound to the Cluster Operator", "name", name, "error", err)
+			return err
+		}
+
+		slog.Debug("Backing up ClusterRole", "name", clusterRole.Name)
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&clusterRole.ObjectMeta)
+		}
+
+		clusterRoles.Items = append(clusterRoles.Items, *clusterRole)
+	}
+
+	// The roles are written before the bindings, so that they are restored first
+	if err := b.writeResources(OperatorClusterRolesFilename, clusterRoles, len(clusterRoles.Items), start); err != nil {
+		return err
+	}
+
+	if err := b.writeResources(OperatorRolesFilename, roles, len(roles.Items), start); err != nil {
+		return err
+	}
+
+	if err := b.writeResources(OperatorClusterRoleBindingsFilename, clusterRoleBindings, len(clusterRoleBindings.Items), start); err != nil {
+		return err
+	}
+
+	if err := b.writeResources(OperatorRoleBindingsFilename, roleBindings, len(roleBindings.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the ServiceAccount, Roles, and RoleBindings of the Cluster Operator complete", "clusterRoles", len(clusterRoles.Items), "clusterRoleBindings", len(clusterRoleBindings.Items), "roles", len(roles.Items), "roleBindings", len(roleBindings.Items))
+
+	return nil
+}
+
+// bindsServiceAccount checks whether the subjects of a binding include the ServiceAccount of the Cluster Operator
+func (b *OperatorBackuper) bindsServiceAccount(subjects []rbacv1.Subject) bool {
+	return slices.ContainsFunc(subjects, func(subject rbacv1.Subject) bool {
+		return subject.Kind == rbacv1.ServiceAccountKind && subject.Name == b.serviceAccountName && subject.Namespace == b.Namespace
+	})
+}
+
+// BackupLeases backs up the Lease used by the Cluster Operator for the leader election. It is informational only, as
+// the restored Cluster Operator creates a new Lease when it is elected as the leader.
+func (b *OperatorBackuper) BackupLeases() error {
+	start := time.Now()
+
+	leases := &coordinationv1.LeaseList{TypeMeta: metav1.TypeMeta{APIVersion: coordinationv1.SchemeGroupVersion.String(), Kind: "LeaseList"}}
+
+	lease, err := b.KubernetesClient.CoordinationV1().Leases(b.leaseNamespace).Get(b.ctx, b.leaseName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		slog.Info("The Lease of the Cluster Operator does not exist => skipping it", "name", b.leaseName, "namespace", b.leaseNamespace)
+	} else if err != nil {
+		slog.Error("Failed to get the Lease of the Cluster Operator", "name", b.leaseName, "namespace", b.leaseNamespace, "error", err)
+		return err
+	} else {
+		if !b.skipMetadataCleansing {
+			utils.CleanseMetadata(&lease.ObjectMeta)
+		}
+
+		leases.Items = append(leases.Items, *lease)
+	}
+
+	return b.writeResources(OperatorLeasesFilename, leases, len(leases.Items), start)
+}
+
+// writeResources marshals the resources to YAML and writes them into the stream
+func (b *OperatorBackuper) writeResources(stream string, resources any, count int, start time.Time) error {
+	resourcesYaml, err := yaml.Marshal(resources)
+	if err != nil {
+		slog.Error("Failed to marshal the resources to YAML", "stream", stream, "error", err)
+		return err
+	}
+
+	return b.writeStream(stream, StreamDescription(stream), resourcesYaml, count, start)
+}
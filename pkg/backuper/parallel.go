@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Result describes the outcome of the backup of a single Kafka cluster
+type Result struct {
+	Target   Target
+	FileName string
+	Duration time.Duration
+	Err      error
+}
+
+// RunParallel backs up the targets using a pool of workers. At most parallelism backups run at the same time. The
+// results are returned in the same order as the targets.
+func RunParallel(targets []Target, parallelism int, backup func(Target) (string, error)) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]Result, len(targets))
+	workers := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			start := time.Now()
+			fileName, err := backup(target)
+			results[i] = Result{Target: target, FileName: fileName, Duration: time.Since(start), Err: err}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// LogSummary logs the results of the backups and indicates whether all of them succeeded
+func LogSummary(results []Result) bool {
+	succeeded := true
+
+	for _, result := range results {
+		if result.Err != nil {
+			succeeded = false
+			slog.Error("Backup of Kafka cluster failed", "name", result.Target.Name, "namespace", result.Target.Namespace, "filename", result.FileName, "duration", result.Duration.Round(time.Millisecond), "error", result.Err)
+		} else {
+			slog.Info("Backup of Kafka cluster succeeded", "name", result.Target.Name, "namespace", result.Target.Namespace, "filename", result.FileName, "duration", result.Duration.Round(time.Millisecond))
+		}
+	}
+
+	return succeeded
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/phases"
+	"log/slog"
+)
+
+// Backup phases of the backup kafka command
+const (
+	PhaseReadOnlyCheck               = "read-only-check"
+	PhaseQuiesce                     = "quiesce"
+	PhaseBackupKafka                 = "backup-kafka"
+	PhaseBackupKafkaNodePools        = "backup-kafka-node-pools"
+	PhaseBackupCRDs                  = "backup-crds"
+	PhaseBackupCaSecrets             = "backup-ca-secrets"
+	PhaseBackupListenerCertificates  = "backup-listener-certificates"
+	PhaseBackupAuthenticationSecrets = "backup-authentication-secrets"
+	PhaseBackupKafkaConfigMaps       = "backup-kafka-config-maps"
+	PhaseBackupReferencedSecrets     = "backup-referenced-secrets"
+	PhaseBackupClusterLayout         = "backup-cluster-layout"
+	PhaseBackupKafkaTopics           = "backup-kafka-topics"
+	PhaseBackupKafkaUsers            = "backup-kafka-users"
+	PhaseBackupUserSecrets           = "backup-user-secrets"
+	PhaseBackupApicurioRegistry      = "backup-apicurio-registry"
+	PhaseBackupExternalConnectivity  = "backup-external-connectivity"
+	PhaseBackupEntityOperator        = "backup-entity-operator"
+	PhaseBackupRebalances            = "backup-rebalances"
+	PhaseBackupConnect               = "backup-connect"
+	PhaseBackupMirrorMaker2          = "backup-mirrormaker2"
+	PhaseBackupMirrorMaker           = "backup-mirrormaker"
+	PhaseBackupBridge                = "backup-bridge"
+	PhaseCheckStreamAnomalies        = "check-stream-anomalies"
+	PhaseWriteManifest               = "write-manifest"
+	PhaseUnquiesce                   = "unquiesce"
+	PhaseUpload                      = "upload"
+	PhaseAnnotateKafka               = "annotate-kafka"
+	PhaseRecordHistory               = "record-history"
+)
+
+// KafkaBackupOptions selects the optional resources and steps of the Kafka cluster backup
+type KafkaBackupOptions struct {
+	SkipCaSecrets               bool
+	SkipUserSecrets             bool
+	IncludeClusterLayout        bool
+	IncludeApicurioRegistry     bool
+	IncludeExternalConnectivity bool
+	IncludeEntityOperator       bool
+	IncludeRebalances           bool
+	IncludeCRDs                 bool
+	AnnotateKafka               bool
+	TrackHistory                bool
+
+	// The Kafka Connect, Kafka MirrorMaker 2, and Kafka MirrorMaker clusters and the Kafka Bridge backed up together
+	// with the Kafka cluster by the backup all command
+	Connect                 string
+	MirrorMaker2            string
+	MirrorMaker             string
+	Bridge                  string
+	SkipConnectSecrets      bool
+	SkipMirrorMaker2Secrets bool
+	SkipMirrorMakerSecrets  bool
+	SkipBridgeSecrets       bool
+}
+
+// Phases returns the phases of the Kafka cluster backup in the order in which they have to be run. The incomplete
+// backup is discarded when any of the phases writing it fails. Once the manifest is written, the backup is complete
+// and failures of the remaining phases do not discard it.
+func (b *KafkaBackuper) Phases(options KafkaBackupOptions) []phases.Phase {
+	backupPhases := b.clusterPhases(options, b.Discard)
+
+	backupPhases = append(backupPhases, writePhase(PhaseWriteManifest, "Write the backup manifest", "Failed to write the backup manifest", b.Discard, b.WriteManifest))
+
+	backupPhases = append(backupPhases, finishPhase(PhaseUnquiesce, "Resume the reconciliation of the KafkaTopics and KafkaUsers by the Entity Operator", "Failed to resume the Entity Operator", b.Unquiesce))
+	backupPhases = append(backupPhases, finishPhase(PhaseUpload, "Upload the backup to the storage", "Failed to upload the backup to the storage", b.Upload))
+
+	if options.AnnotateKafka {
+		backupPhases = append(backupPhases, finishPhase(PhaseAnnotateKafka, "Annotate the Kafka resource with the last backup", "Failed to annotate the Kafka resource with the last backup", b.AnnotateKafka))
+	}
+
+	if options.TrackHistory {
+		backupPhases = append(backupPhases, finishPhase(PhaseRecordHistory, "Record the backup in the history", "Failed to record the backup in the history", b.RecordHistory))
+	}
+
+	return backupPhases
+}
+
+// writePhase creates a phase writing into the backup. The incomplete backup is discarded when the phase fails.
+func writePhase(name string, description string, failure string, discard func(), run func() error) phases.Phase {
+	return phases.New(name, description, func(ctx context.Context) error {
+		if err := run(); err != nil {
+			slog.Error(failure, "error", err)
+			discard()
+			return err
+		}
+
+		return nil
+	})
+}
+
+// finishPhase creates a phase run after the backup is complete
+func finishPhase(name string, description string, failure string, run func() error) phases.Phase {
+	return phases.New(name, description, func(ctx context.Context) error {
+		if err := run(); err != nil {
+			slog.Error(failure, "error", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// clusterPhases returns the phases backing up the resources of the Kafka cluster. The discard function is called when
+// any of them fails.
+func (b *KafkaBackuper) clusterPhases(options KafkaBackupOptions, discard func()) []phases.Phase {
+	var backupPhases []phases.Phase
+
+	// write adds a phase writing into the backup
+	write := func(name string, description string, failure string, run func() error) {
+		backupPhases = append(backupPhases, writePhase(name, description, failure, discard, run))
+	}
+
+	if b.readOnlyCheck {
+		write(PhaseReadOnlyCheck, "Check that the backup can run with the rights to only read the backed up resources", "The backup cannot run with the rights to only read the backed up resources", func() error {
+			if err := b.checkReadOnlyOptions(options); err != nil {
+				return err
+			}
+
+			return b.CheckReadOnly(KafkaBackupRules(options))
+		})
+	}
+
+	if b.Quiesced {
+		write(PhaseQuiesce, "Pause the reconciliation of the KafkaTopics and KafkaUsers by the Entity Operator", "Failed to quiesce the Entity Operator", b.Quiesce)
+	}
+
+	write(PhaseBackupKafka, "Back up the Kafka resource", "Failed to backup Kafka", b.BackupKafka)
+	write(PhaseBackupKafkaNodePools, "Back up the Kafka Node Pools", "Failed to backup Kafka node pools", b.BackupKafkaNodePools)
+
+	if options.IncludeCRDs {
+		write(PhaseBackupCRDs, "Back up the Strimzi CustomResourceDefinitions", "Failed to backup the Strimzi CustomResourceDefinitions", b.BackupCRDs)
+	}
+
+	if !options.SkipCaSecrets {
+		write(PhaseBackupCaSecrets, "Back up the Cluster and Clients CA Secrets", "Failed to backup CA Secrets", b.BackupCaSecrets)
+	}
+
+	write(PhaseBackupListenerCertificates, "Back up the Secrets with the custom listener certificates", "Failed to backup the custom listener certificates", b.BackupListenerCertificates)
+	write(PhaseBackupAuthenticationSecrets, "Back up the Secrets used by the authentication and authorization", "Failed to backup the Secrets used by the authentication and authorization", b.BackupAuthenticationSecrets)
+	write(PhaseBackupKafkaConfigMaps, "Back up the ConfigMaps referenced from the Kafka cluster", "Failed to backup the ConfigMaps referenced from the Kafka cluster", b.BackupKafkaConfigMaps)
+	write(PhaseBackupReferencedSecrets, "Back up the Secrets referenced from the pod templates and the configuration", "Failed to backup the Secrets referenced from the Kafka cluster", b.BackupKafkaReferencedSecrets)
+
+	if options.IncludeClusterLayout {
+		write(PhaseBackupClusterLayout, "Back up the StrimziPodSets and the Kafka node assignments", "Failed to backup the cluster layout", func() error {
+			if err := b.BackupStrimziPodSets(); err != nil {
+				slog.Error("Failed to backup StrimziPodSets", "error", err)
+				return err
+			}
+
+			if err := b.BackupNodeAssignments(); err != nil {
+				slog.Error("Failed to backup Kafka node assignments", "error", err)
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	write(PhaseBackupKafkaTopics, "Back up the Kafka Topics", "Failed to backup Kafka topics", b.BackupKafkaTopics)
+	write(PhaseBackupKafkaUsers, "Back up the Kafka Users", "Failed to backup Kafka users", b.BackupKafkaUsers)
+
+	if !options.SkipUserSecrets {
+		write(PhaseBackupUserSecrets, "Back up the Kafka User Secrets", "Failed to backup User Secrets", b.BackupUserSecrets)
+	}
+
+	if options.IncludeApicurioRegistry {
+		write(PhaseBackupApicurioRegistry, "Back up the Apicurio Registries and their artifacts", "Failed to backup Apicurio Registries", b.BackupApicurioRegistries)
+	}
+
+	if options.IncludeExternalConnectivity {
+		write(PhaseBackupExternalConnectivity, "Back up the cert-manager, external-dns, and OpenShift resources of the external listeners", "Failed to backup the cert-manager and external-dns resources", b.BackupExternalConnectivity)
+	}
+
+	if options.IncludeEntityOperator {
+		write(PhaseBackupEntityOperator, "Back up the Secrets and RoleBindings of the Entity Operator", "Failed to backup the Secrets and RoleBindings of the Entity Operator", b.BackupEntityOperator)
+	}
+
+	if options.IncludeRebalances {
+		write(PhaseBackupRebalances, "Back up the Kafka Rebalances", "Failed to backup Kafka rebalances", b.BackupKafkaRebalances)
+	}
+
+	if options.Connect != "" {
+		write(PhaseBackupConnect, "Back up the Kafka Connect cluster", "Failed to backup Kafka Connect", func() error {
+			return b.backupConnect(options.Connect, options.SkipConnectSecrets)
+		})
+	}
+
+	if options.MirrorMaker2 != "" {
+		write(PhaseBackupMirrorMaker2, "Back up the Kafka MirrorMaker 2 cluster", "Failed to backup Kafka MirrorMaker 2", func() error {
+			return b.backupMirrorMaker2(options.MirrorMaker2, options.SkipMirrorMaker2Secrets)
+		})
+	}
+
+	if options.MirrorMaker != "" {
+		write(PhaseBackupMirrorMaker, "Back up the legacy Kafka MirrorMaker cluster", "Failed to backup Kafka MirrorMaker", func() error {
+			return b.backupMirrorMaker(options.MirrorMaker, options.SkipMirrorMakerSecrets)
+		})
+	}
+
+	if options.Bridge != "" {
+		write(PhaseBackupBridge, "Back up the Kafka Bridge", "Failed to backup Kafka Bridge", func() error {
+			return b.backupBridge(options.Bridge, options.SkipBridgeSecrets)
+		})
+	}
+
+	if options.TrackHistory {
+		write(PhaseCheckStreamAnomalies, "Compare the backup with the previous backup", "Failed to compare the backup with the previous backup", b.CheckStreamAnomalies)
+	}
+
+	return backupPhases
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const pauseReconciliationAnnotation = "strimzi.io/pause-reconciliation"
+
+// Quiesce pauses the reconciliation of the KafkaTopic and KafkaUser resources by the Entity Operator, so that they do
+// not change while being backed up. Resources which are already paused are left untouched.
+func (b *KafkaBackuper) Quiesce() error {
+	slog.Info("Pausing reconciliation of the KafkaTopic and KafkaUser resources", "labelSelector", "strimzi.io/cluster="+b.Name)
+
+	topics, err := b.StrimziClient.KafkaV1beta2().KafkaTopics(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get KafkaTopics belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	for _, topic := range topics.Items {
+		if topic.Annotations[pauseReconciliationAnnotation] == "true" {
+			slog.Debug("KafkaTopic is already paused", "name", topic.Name)
+			continue
+		}
+
+		b.quiescedTopics = append(b.quiescedTopics, topic.Name)
+
+		if err := setPauseReconciliation(b.ctx, b.StrimziClient.KafkaV1beta2().KafkaTopics(b.Namespace).Patch, "KafkaTopic", b.Namespace, topic.Name, true); err != nil {
+			return err
+		}
+	}
+
+	users, err := b.StrimziClient.KafkaV1beta2().KafkaUsers(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get KafkaUsers belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	for _, user := range users.Items {
+		if user.Annotations[pauseReconciliationAnnotation] == "true" {
+			slog.Debug("KafkaUser is already paused", "name", user.Name)
+			continue
+		}
+
+		b.quiescedUsers = append(b.quiescedUsers, user.Name)
+
+		if err := setPauseReconciliation(b.ctx, b.StrimziClient.KafkaV1beta2().KafkaUsers(b.Namespace).Patch, "KafkaUser", b.Namespace, user.Name, true); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Waiting for the Entity Operator to pause the reconciliation", "topics", len(b.quiescedTopics), "users", len(b.quiescedUsers))
+
+	if err := b.waitForQuiescedResources(b.ctx, true); err != nil {
+		slog.Error("The KafkaTopic and KafkaUser resources were not paused. Please check the Entity Operator logs for more details.", "error", err)
+		return err
+	}
+
+	slog.Info("Reconciliation of the KafkaTopic and KafkaUser resources is paused")
+
+	return nil
+}
+
+// Unquiesce resumes the reconciliation of the resources paused by Quiesce and verifies that it was resumed. It does not
+// use the backup deadline, so that the resources are resumed even when the backup timed out. It is bounded by the
+// quiesce timeout instead.
+func (b *KafkaBackuper) Unquiesce() error {
+	if len(b.quiescedTopics) == 0 && len(b.quiescedUsers) == 0 {
+		return nil
+	}
+
+	slog.Info("Resuming reconciliation of the KafkaTopic and KafkaUser resources", "topics", len(b.quiescedTopics), "users", len(b.quiescedUsers))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(b.quiesceTimeout))
+	defer cancel()
+
+	for _, name := range b.quiescedTopics {
+		if err := setPauseReconciliation(ctx, b.StrimziClient.KafkaV1beta2().KafkaTopics(b.Namespace).Patch, "KafkaTopic", b.Namespace, name, false); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range b.quiescedUsers {
+		if err := setPauseReconciliation(ctx, b.StrimziClient.KafkaV1beta2().KafkaUsers(b.Namespace).Patch, "KafkaUser", b.Namespace, name, false); err != nil {
+			return err
+		}
+	}
+
+	if err := b.waitForQuiescedResources(ctx, false); err != nil {
+		slog.Error("The reconciliation of the KafkaTopic and KafkaUser resources was not resumed. Please check the Entity Operator logs for more details.", "error", err)
+		return err
+	}
+
+	b.quiescedTopics = nil
+	b.quiescedUsers = nil
+
+	slog.Info("Reconciliation of the KafkaTopic and KafkaUser resources was resumed")
+
+	return nil
+}
+
+// Discard resumes the reconciliation of the quiesced resources and removes the incomplete backup file
+func (b *KafkaBackuper) Discard() {
+	if err := b.Unquiesce(); err != nil {
+		slog.Error("Failed to resume reconciliation of the KafkaTopic and KafkaUser resources", "error", err)
+	}
+
+	b.Backuper.Discard()
+}
+
+// removeQuiesceAnnotation removes the pause annotation added by Quiesce so that it is not part of the backup
+func (b *KafkaBackuper) removeQuiesceAnnotation(metadata *metav1.ObjectMeta, quiesced []string) {
+	if slices.Contains(quiesced, metadata.Name) {
+		delete(metadata.Annotations, pauseReconciliationAnnotation)
+	}
+}
+
+func (b *KafkaBackuper) waitForQuiescedResources(ctx context.Context, paused bool) error {
+	return wait.PollUntilContextTimeout(ctx, time.Second, time.Millisecond*time.Duration(b.quiesceTimeout), true, func(ctx context.Context) (bool, error) {
+		topics, err := b.StrimziClient.KafkaV1beta2().KafkaTopics(b.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+		if err != nil {
+			return false, err
+		}
+
+		for _, topic := range topics.Items {
+			if slices.Contains(b.quiescedTopics, topic.Name) && topic.Status != nil && isReconciliationPaused(topic.Status.Conditions) != paused {
+				return false, nil
+			}
+		}
+
+		users, err := b.StrimziClient.KafkaV1beta2().KafkaUsers(b.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+		if err != nil {
+			return false, err
+		}
+
+		for _, user := range users.Items {
+			if slices.Contains(b.quiescedUsers, user.Name) && user.Status != nil && isReconciliationPaused(user.Status.Conditions) != paused {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+}
+
+func isReconciliationPaused(conditions []v1beta2.Condition) bool {
+	for _, condition := range conditions {
+		if condition.Type == "ReconciliationPaused" && condition.Status == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setPauseReconciliation adds or removes the pause annotation. A merge patch is used to make sure the resource is not
+// recreated when it was deleted in the meantime.
+func setPauseReconciliation[T any](ctx context.Context, patch func(context.Context, string, types.PatchType, []byte, metav1.PatchOptions, ...string) (T, error), kind string, namespace string, name string, paused bool) error {
+	data := []byte(`{"metadata":{"annotations":{"` + pauseReconciliationAnnotation + `":null}}}`)
+	if paused {
+		data = []byte(`{"metadata":{"annotations":{"` + pauseReconciliationAnnotation + `":"true"}}}`)
+	}
+
+	if _, err := patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{FieldManager: utils.FieldManager}); err != nil {
+		if !paused && errors.IsNotFound(err) {
+			slog.Warn("Resource was deleted while the backup was running", "kind", kind, "name", name, "namespace", namespace)
+			return nil
+		}
+
+		slog.Error("Failed to update the pause annotation", "kind", kind, "name", name, "namespace", namespace, "paused", paused, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/registry"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"strings"
+)
+
+// readVerbs are the only verbs needed to back up the resources
+var readVerbs = []string{"get", "list"}
+
+// readRule creates the rule allowing to read the resources from the API group
+func readRule(group string, resources ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources, Verbs: readVerbs}
+}
+
+// KafkaBackupRules returns the RBAC rules needed to back up the Kafka cluster with the given options
+func KafkaBackupRules(options KafkaBackupOptions) []rbacv1.PolicyRule {
+	strimziResources := []string{"kafkas", "kafkanodepools", "kafkatopics", "kafkausers"}
+	if options.IncludeRebalances {
+		strimziResources = append(strimziResources, "kafkarebalances")
+	}
+
+	coreResources := []string{"secrets", "configmaps"}
+	if options.IncludeClusterLayout {
+		coreResources = append(coreResources, "pods")
+	}
+
+	rules := []rbacv1.PolicyRule{
+		readRule("kafka.strimzi.io", strimziResources...),
+		readRule("", coreResources...),
+	}
+
+	if options.IncludeClusterLayout {
+		rules = append(rules, readRule(strimziPodSetResource.Group, strimziPodSetResource.Resource))
+	}
+
+	if options.IncludeApicurioRegistry {
+		rules = append(rules, readRule(registry.ApicurioRegistryResource.Group, registry.ApicurioRegistryResource.Resource))
+	}
+
+	if options.IncludeExternalConnectivity {
+		rules = append(rules,
+			readRule(CertManagerCertificateResource.Group, CertManagerCertificateResource.Resource),
+			readRule(ExternalDnsEndpointResource.Group, ExternalDnsEndpointResource.Resource),
+			readRule(OpenShiftRouteResource.Group, OpenShiftRouteResource.Resource),
+		)
+	}
+
+	if options.IncludeEntityOperator {
+		rules = append(rules, readRule(rbacv1.GroupName, "rolebindings"))
+	}
+
+	if options.IncludeCRDs {
+		rules = append(rules, readRule(CustomResourceDefinitionResource.Group, CustomResourceDefinitionResource.Resource))
+	}
+
+	if options.Connect != "" {
+		rules = append(rules, ConnectBackupRules()...)
+	}
+
+	if options.MirrorMaker2 != "" {
+		rules = append(rules, MirrorMaker2BackupRules()...)
+	}
+
+	if options.MirrorMaker != "" {
+		rules = append(rules, MirrorMakerBackupRules()...)
+	}
+
+	if options.Bridge != "" {
+		rules = append(rules, BridgeBackupRules()...)
+	}
+
+	return rules
+}
+
+// ConnectBackupRules returns the RBAC rules needed to back up the Kafka Connect cluster
+func ConnectBackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule("kafka.strimzi.io", "kafkaconnects", "kafkaconnectors"),
+		readRule("", "secrets", "configmaps"),
+	}
+}
+
+// MirrorMaker2BackupRules returns the RBAC rules needed to back up the Kafka MirrorMaker 2 cluster
+func MirrorMaker2BackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule("kafka.strimzi.io", "kafkamirrormaker2s", "kafkamirrormakers"),
+		readRule("", "secrets", "configmaps"),
+	}
+}
+
+// MirrorMakerBackupRules returns the RBAC rules needed to back up the legacy Kafka MirrorMaker cluster
+func MirrorMakerBackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule(utils.KafkaMirrorMakerResource.Group, utils.KafkaMirrorMakerResource.Resource),
+		readRule("", "secrets", "configmaps"),
+	}
+}
+
+// BridgeBackupRules returns the RBAC rules needed to back up the Kafka Bridge
+func BridgeBackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule("kafka.strimzi.io", "kafkabridges"),
+		readRule("", "secrets", "configmaps"),
+	}
+}
+
+// OperatorBackupRules returns the RBAC rules needed to back up the Cluster Operator installation. The ClusterRoles,
+// ClusterRoleBindings, and CustomResourceDefinitions are cluster-scoped and can be read only with the rights granted by
+// a ClusterRole.
+func OperatorBackupRules(includeCRDs bool) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		readRule("apps", "deployments"),
+		readRule("", "serviceaccounts", "secrets", "configmaps"),
+		readRule(rbacv1.GroupName, "roles", "rolebindings", "clusterroles", "clusterrolebindings"),
+		readRule(coordinationv1.GroupName, "leases"),
+	}
+
+	if includeCRDs {
+		rules = append(rules, readRule(CustomResourceDefinitionResource.Group, CustomResourceDefinitionResource.Resource))
+	}
+
+	return rules
+}
+
+// DataBackupRules returns the RBAC rules needed to back up the topic data. The Secrets with the certificates and the
+// credentials are used to connect to the Kafka cluster.
+func DataBackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule("", "secrets"),
+	}
+}
+
+// BackupRules returns the RBAC rules needed by all backup commands with all optional resources included. They allow
+// only reading the resources.
+func BackupRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		readRule("kafka.strimzi.io", "kafkas", "kafkanodepools", "kafkatopics", "kafkausers", "kafkarebalances", "kafkaconnects", "kafkaconnectors", "kafkamirrormaker2s"),
+		readRule("", "secrets", "configmaps", "pods", "serviceaccounts"),
+		readRule(strimziPodSetResource.Group, strimziPodSetResource.Resource),
+		readRule("apps", "deployments"),
+		readRule(rbacv1.GroupName, "roles", "rolebindings"),
+		readRule(coordinationv1.GroupName, "leases"),
+		readRule(registry.ApicurioRegistryResource.Group, registry.ApicurioRegistryResource.Resource),
+		readRule(CertManagerCertificateResource.Group, CertManagerCertificateResource.Resource),
+		readRule(ExternalDnsEndpointResource.Group, ExternalDnsEndpointResource.Resource),
+		readRule(OpenShiftRouteResource.Group, OpenShiftRouteResource.Resource),
+	}
+}
+
+// CheckReadOnly checks that the backup can read all resources allowed by the rules in the namespace of the backed up
+// cluster. Does nothing unless the --read-only-check option is used.
+func (b *Backuper) CheckReadOnly(rules []rbacv1.PolicyRule) error {
+	if !b.readOnlyCheck {
+		return nil
+	}
+
+	slog.Info("Checking that the backup can read all backed up resources", "namespace", b.Namespace)
+
+	var denied []string
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					review := &authorizationv1.SelfSubjectAccessReview{
+						Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: b.Namespace, Verb: verb, Group: group, Resource: resource},
+						},
+					}
+
+					result, err := b.KubernetesClient.AuthorizationV1().SelfSubjectAccessReviews().Create(b.ctx, review, metav1.CreateOptions{})
+					if err != nil {
+						slog.Error("Failed to check the access to the resources", "group", group, "resource", resource, "verb", verb, "error", err)
+						return err
+					}
+
+					if !result.Status.Allowed {
+						slog.Error("The backup is not allowed to read the resources", "group", group, "resource", resource, "verb", verb, "namespace", b.Namespace)
+						denied = append(denied, verb+" "+qualifiedResource(group, resource))
+					}
+				}
+			}
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("the backup is not allowed to %s in namespace %s (use strimzi-backup generate rbac --mode backup to generate the required Role)", strings.Join(denied, ", "), b.Namespace)
+	}
+
+	slog.Info("The backup can read all backed up resources", "namespace", b.Namespace)
+	return nil
+}
+
+// qualifiedResource returns the resource with its API group in the format used by kubectl
+func qualifiedResource(group string, resource string) string {
+	if group == "" {
+		return resource
+	}
+
+	return resource + "." + group
+}
+
+// checkReadOnlyOptions fails when the backup of the Kafka cluster uses options which modify the Kubernetes resources
+func (b *KafkaBackuper) checkReadOnlyOptions(options KafkaBackupOptions) error {
+	var writeOptions []string
+	if b.Quiesced {
+		writeOptions = append(writeOptions, "--quiesce")
+	}
+	if options.AnnotateKafka {
+		writeOptions = append(writeOptions, "--annotate-kafka")
+	}
+	if options.TrackHistory {
+		writeOptions = append(writeOptions, "--track-history")
+	}
+
+	if len(writeOptions) > 0 {
+		slog.Error("The backup uses options which modify the Kubernetes resources and cannot be used together with the --read-only-check option", "options", writeOptions)
+		return fmt.Errorf("the %s options modify the Kubernetes resources and cannot be used together with the --read-only-check option", strings.Join(writeOptions, ", "))
+	}
+
+	return nil
+}
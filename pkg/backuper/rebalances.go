@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+const (
+	KafkaRebalancesFilename = "kafka-rebalances.yaml"
+
+	// RebalanceTemplateAnnotation marks the KafkaRebalance resources used as templates for the auto-rebalancing
+	RebalanceTemplateAnnotation = "strimzi.io/rebalance-template"
+	// rebalanceAnnotation is used to approve, stop, or refresh the rebalance proposal
+	rebalanceAnnotation = "strimzi.io/rebalance"
+)
+
+// isRebalanceTemplate checks if the KafkaRebalance is a template used for the auto-rebalancing
+func isRebalanceTemplate(rebalance *v1beta2.KafkaRebalance) bool {
+	return rebalance.Annotations[RebalanceTemplateAnnotation] == "true"
+}
+
+// isOwnedByKafka checks if the KafkaRebalance was created by the Cluster Operator for the auto-rebalancing. Such
+// resources are created again by the Cluster Operator when needed.
+func isOwnedByKafka(rebalance *v1beta2.KafkaRebalance) bool {
+	for _, owner := range rebalance.OwnerReferences {
+		if owner.Kind == "Kafka" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BackupKafkaRebalances backs up the KafkaRebalance resources belonging to the Kafka cluster. The KafkaRebalances
+// created by the Cluster Operator for the auto-rebalancing are skipped. With the --rebalance-templates-only option,
+// only the templates used by the auto-rebalancing are backed up.
+func (b *KafkaBackuper) BackupKafkaRebalances() error {
+	start := time.Now()
+
+	slog.Info("Backing up the KafkaRebalance resources", "labelSelector", "strimzi.io/cluster="+b.Name, "templatesOnly", b.rebalanceTemplatesOnly)
+
+	resources, err := b.StrimziClient.KafkaV1beta2().KafkaRebalances(b.Namespace).List(b.ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + b.Name})
+	if err != nil {
+		slog.Error("Failed to get KafkaRebalances belonging to the Kafka cluster", "name", b.Name, "namespace", b.Namespace, "error", err)
+		return err
+	}
+
+	rebalances := &v1beta2.KafkaRebalanceList{TypeMeta: metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaRebalanceList"}}
+	for _, resource := range resources.Items {
+		if isOwnedByKafka(&resource) {
+			slog.Debug("Skipping KafkaRebalance created by the Cluster Operator for the auto-rebalancing", "name", resource.Name)
+			continue
+		} else if b.rebalanceTemplatesOnly && !isRebalanceTemplate(&resource) {
+			slog.Debug("Skipping KafkaRebalance which is not a template", "name", resource.Name)
+			continue
+		}
+
+		slog.Debug("Backing up KafkaRebalance", "name", resource.Name)
+
+		// The approval or refresh of a rebalance proposal should not be repeated in the restored Kafka cluster
+		delete(resource.Annotations, rebalanceAnnotation)
+		resource.Status = nil
+
+		rebalances.Items = append(rebalances.Items, resource)
+	}
+
+	sortByName(rebalances.Items)
+
+	if !b.skipMetadataCleansing {
+		// We want to avoid copying the resource, so we use the index
+		for i := range rebalances.Items {
+			utils.CleanseMetadata(&rebalances.Items[i].ObjectMeta)
+		}
+	}
+
+	resourcesYaml, err := yaml.Marshal(rebalances)
+	if err != nil {
+		slog.Error("Failed to marshal the KafkaRebalances to YAML", "error", err)
+		return err
+	}
+
+	if err := b.writeStream(KafkaRebalancesFilename, StreamDescription(KafkaRebalancesFilename), resourcesYaml, len(rebalances.Items), start); err != nil {
+		return err
+	}
+
+	slog.Info("Backup of the KafkaRebalance resources complete", "labelSelector", "strimzi.io/cluster="+b.Name, "rebalances", len(rebalances.Items))
+
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+)
+
+// configProviderReference matches the references of the Kubernetes configuration providers in the form
+// ${secrets:<namespace>/<name>:<key>} and ${configmaps:<namespace>/<name>:<key>}
+var configProviderReference = regexp.MustCompile(`\$\{(secrets|configmaps):([^:/}]+)/([^:}]+)(:[^}]*)?}`)
+
+// scanReferences walks the spec of a custom resource and returns the names of the Secrets and ConfigMaps referenced
+// from it in a generic way: from the additional volumes of the pod templates (including the projected volumes), from
+// the environment variables of the containers, from the image pull Secrets, from the push and pull Secrets of the
+// builds, and from the Kubernetes configuration providers used in the configuration. Only the configuration provider
+// references to the given namespace are included, as the resources from other namespaces are not backed up.
+func scanReferences(spec any, namespace string) (secrets []string, configMaps []string) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, nil
+	}
+
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, nil
+	}
+
+	var walk func(node any)
+	walk = func(node any) {
+		switch value := node.(type) {
+		case map[string]any:
+			if ref, ok := value["secretKeyRef"].(map[string]any); ok {
+				secrets = append(secrets, stringField(ref, "name"))
+			}
+
+			if ref, ok := value["configMapKeyRef"].(map[string]any); ok {
+				configMaps = append(configMaps, stringField(ref, "name"))
+			}
+
+			// Volumes use the secretName field and the projected volume sources the name field
+			if ref, ok := value["secret"].(map[string]any); ok {
+				secrets = append(secrets, stringField(ref, "secretName"), stringField(ref, "name"))
+			}
+
+			if ref, ok := value["configMap"].(map[string]any); ok {
+				configMaps = append(configMaps, stringField(ref, "name"))
+			}
+
+			// The environment variables loaded from whole Secrets and ConfigMaps use the secretRef and configMapRef fields
+			if ref, ok := value["secretRef"].(map[string]any); ok {
+				secrets = append(secrets, stringField(ref, "name"))
+			}
+
+			if ref, ok := value["configMapRef"].(map[string]any); ok {
+				configMaps = append(configMaps, stringField(ref, "name"))
+			}
+
+			if refs, ok := value["imagePullSecrets"].([]any); ok {
+				for _, ref := range refs {
+					if ref, ok := ref.(map[string]any); ok {
+						secrets = append(secrets, stringField(ref, "name"))
+					}
+				}
+			}
+
+			secrets = append(secrets, stringField(value, "pushSecret"), stringField(value, "pullSecret"))
+
+			for _, child := range value {
+				walk(child)
+			}
+		case []any:
+			for _, child := range value {
+				walk(child)
+			}
+		case string:
+			for _, match := range configProviderReference.FindAllStringSubmatch(value, -1) {
+				if match[2] != namespace {
+					continue
+				}
+
+				if match[1] == "secrets" {
+					secrets = append(secrets, match[3])
+				} else {
+					configMaps = append(configMaps, match[3])
+				}
+			}
+		}
+	}
+
+	walk(tree)
+
+	return secrets, configMaps
+}
+
+// stringField returns the string value of the field or an empty string when it is not set or is not a string
+func stringField(object map[string]any, field string) string {
+	value, _ := object[field].(string)
+	return value
+}
+
+// uniqueNames returns the sorted names without duplicates and empty names
+func uniqueNames(names []string) []string {
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return name == ""
+	})
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
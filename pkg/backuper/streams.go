@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"strings"
+)
+
+const (
+	// RestoredByKafka marks the streams restored by the restore kafka command
+	RestoredByKafka = "restore kafka"
+	// RestoredByData marks the streams restored by the restore data command
+	RestoredByData = "restore data"
+	// RestoredByApicurioRegistry marks the streams restored by the restore apicurio-registry command
+	RestoredByApicurioRegistry = "restore apicurio-registry"
+	// RestoredByConnect marks the streams restored by the restore connect command
+	RestoredByConnect = "restore connect"
+	// RestoredByMirrorMaker2 marks the streams restored by the restore mirrormaker2 command
+	RestoredByMirrorMaker2 = "restore mirrormaker2"
+	// RestoredByMirrorMaker marks the streams restored by the restore mirrormaker command
+	RestoredByMirrorMaker = "restore mirrormaker"
+	// RestoredByBridge marks the streams restored by the restore all command as part of the Kafka Bridge
+	RestoredByBridge = "restore bridge"
+	// RestoredByOperator marks the streams restored by the restore operator command
+	RestoredByOperator = "restore operator"
+	// RestoredByCRDs marks the streams restored by the restore crds command
+	RestoredByCRDs = "restore crds"
+)
+
+// StreamInfo describes a stream (a member of the multi-member GZIP archive) of the backup. The streams with a fixed
+// name use the Name field. The streams created for each topic or registry (such as the topic data) use the Prefix and
+// Suffix fields instead.
+type StreamInfo struct {
+	Name        string
+	Prefix      string
+	Suffix      string
+	Description string
+	// APIVersion and Kind of the resources stored in the stream. They are empty for the streams which do not contain
+	// Kubernetes resources.
+	APIVersion string
+	Kind       string
+	// RestoredBy is the command restoring the stream. It is empty for the informational streams which are not restored.
+	RestoredBy string
+}
+
+// Streams lists all streams which can be stored in the backup. External tools reading or writing the backups can use it
+// to share the stream names and their meaning with strimzi-backup.
+var Streams = []StreamInfo{
+	{Name: archive.ManifestFilename, Description: "Backup manifest"},
+	{Name: KafkaFilename, Description: "Kafka cluster", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "Kafka", RestoredBy: RestoredByKafka},
+	{Name: KafkaNodePoolsFilename, Description: "List of Kafka Node Pools", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaNodePool", RestoredBy: RestoredByKafka},
+	{Name: CaSecretsFilename, Description: "List of CA Secrets", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: KafkaListenerCertificatesFilename, Description: "List of Secrets with the custom listener certificates", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: KafkaAuthenticationSecretsFilename, Description: "List of Secrets used by the authentication of the Kafka listeners and by the authorization", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: KafkaReferencedSecretsFilename, Description: "List of Secrets referenced from the pod templates and the configuration of the Kafka cluster", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: KafkaConfigMapsFilename, Description: "List of ConfigMaps referenced from the Kafka cluster", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByKafka},
+	{Name: KafkaTopicsFilename, Description: "List of Kafka Topics", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaTopic", RestoredBy: RestoredByKafka},
+	{Name: KafkaUsersFilename, Description: "List of Kafka Users", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaUser", RestoredBy: RestoredByKafka},
+	{Name: KafkaUserSecretsFilename, Description: "List of User Secrets", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: StrimziPodSetsFilename, Description: "List of StrimziPodSets (informational only)", APIVersion: "core.strimzi.io/v1beta2", Kind: "StrimziPodSet"},
+	{Name: NodeAssignmentsFilename, Description: "Kafka node assignments (informational only)"},
+	{Name: ApicurioRegistryConfigMapsFilename, Description: "List of ConfigMaps used by the Apicurio Registries", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByKafka},
+	{Name: ApicurioRegistriesFilename, Description: "List of Apicurio Registries", RestoredBy: RestoredByKafka},
+	{Prefix: ApicurioRegistryArtifactsPrefix, Suffix: ApicurioRegistryArtifactsSuffix, Description: "Artifacts exported from the Apicurio Registry", RestoredBy: RestoredByApicurioRegistry},
+	{Name: CertManagerCertificatesFilename, Description: "List of cert-manager Certificates of the external listeners", APIVersion: "cert-manager.io/v1", Kind: "Certificate", RestoredBy: RestoredByKafka},
+	{Name: ExternalDnsEndpointsFilename, Description: "List of external-dns DNSEndpoints of the external listeners", APIVersion: "externaldns.k8s.io/v1alpha1", Kind: "DNSEndpoint", RestoredBy: RestoredByKafka},
+	{Name: OpenShiftRoutesFilename, Description: "List of OpenShift Routes of the route listeners", APIVersion: "route.openshift.io/v1", Kind: "Route", RestoredBy: RestoredByKafka},
+	{Name: ListenerCertificatesFilename, Description: "List of Secrets with the custom certificates of the route listeners (older backups)", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: EntityOperatorSecretsFilename, Description: "List of Secrets with the certificates of the Entity Operator", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByKafka},
+	{Name: EntityOperatorRoleBindingsFilename, Description: "List of RoleBindings of the Entity Operator", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding", RestoredBy: RestoredByKafka},
+	{Name: KafkaRebalancesFilename, Description: "List of Kafka Rebalances", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaRebalance", RestoredBy: RestoredByKafka},
+	{Name: KafkaConnectFilename, Description: "Kafka Connect cluster", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaConnect", RestoredBy: RestoredByConnect},
+	{Name: KafkaConnectorsFilename, Description: "List of Kafka Connectors", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaConnector", RestoredBy: RestoredByConnect},
+	{Name: KafkaConnectSecretsFilename, Description: "List of Secrets used by the Kafka Connect cluster", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByConnect},
+	{Name: KafkaConnectConfigMapsFilename, Description: "List of ConfigMaps used by the Kafka Connect cluster", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByConnect},
+	{Name: KafkaMirrorMaker2Filename, Description: "Kafka MirrorMaker 2 cluster", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaMirrorMaker2", RestoredBy: RestoredByMirrorMaker2},
+	{Name: KafkaMirrorMaker2SecretsFilename, Description: "List of Secrets used by the Kafka MirrorMaker 2 cluster", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByMirrorMaker2},
+	{Name: KafkaMirrorMaker2ConfigMapsFilename, Description: "List of ConfigMaps used by the Kafka MirrorMaker 2 cluster", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByMirrorMaker2},
+	{Name: KafkaMirrorMakerFilename, Description: "Legacy Kafka MirrorMaker cluster", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaMirrorMaker", RestoredBy: RestoredByMirrorMaker},
+	{Name: KafkaMirrorMakerSecretsFilename, Description: "List of Secrets used by the legacy Kafka MirrorMaker cluster", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByMirrorMaker},
+	{Name: KafkaMirrorMakerConfigMapsFilename, Description: "List of ConfigMaps used by the legacy Kafka MirrorMaker cluster", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByMirrorMaker},
+	{Name: KafkaBridgeFilename, Description: "Kafka Bridge", APIVersion: "kafka.strimzi.io/v1beta2", Kind: "KafkaBridge", RestoredBy: RestoredByBridge},
+	{Name: KafkaBridgeSecretsFilename, Description: "List of Secrets used by the Kafka Bridge", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByBridge},
+	{Name: KafkaBridgeConfigMapsFilename, Description: "List of ConfigMaps used by the Kafka Bridge", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByBridge},
+	{Name: OperatorDeploymentFilename, Description: "Cluster Operator Deployment", APIVersion: "apps/v1", Kind: "Deployment", RestoredBy: RestoredByOperator},
+	{Name: OperatorServiceAccountFilename, Description: "ServiceAccount of the Cluster Operator", APIVersion: "v1", Kind: "ServiceAccount", RestoredBy: RestoredByOperator},
+	{Name: OperatorClusterRolesFilename, Description: "List of ClusterRoles bound to the Cluster Operator", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", RestoredBy: RestoredByOperator},
+	{Name: OperatorClusterRoleBindingsFilename, Description: "List of ClusterRoleBindings of the Cluster Operator", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding", RestoredBy: RestoredByOperator},
+	{Name: OperatorRolesFilename, Description: "List of Roles bound to the Cluster Operator", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role", RestoredBy: RestoredByOperator},
+	{Name: OperatorRoleBindingsFilename, Description: "List of RoleBindings of the Cluster Operator", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding", RestoredBy: RestoredByOperator},
+	{Name: OperatorConfigMapsFilename, Description: "List of ConfigMaps used by the Cluster Operator", APIVersion: "v1", Kind: "ConfigMap", RestoredBy: RestoredByOperator},
+	{Name: OperatorSecretsFilename, Description: "List of Secrets used by the Cluster Operator", APIVersion: "v1", Kind: "Secret", RestoredBy: RestoredByOperator},
+	{Name: OperatorLeasesFilename, Description: "List of Leases of the Cluster Operator leader election (informational only)", APIVersion: "coordination.k8s.io/v1", Kind: "Lease"},
+	{Name: StrimziCRDsFilename, Description: "List of Strimzi CustomResourceDefinitions", APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", RestoredBy: RestoredByCRDs},
+	{Name: ConnectTopicsFilename, Description: "Kafka Connect internal topics", RestoredBy: RestoredByData},
+	{Prefix: TopicDataStreamPrefix, Suffix: TopicDataStreamSuffix, Description: "Records of topic", RestoredBy: RestoredByData},
+}
+
+// LookupStream finds the description of the stream by its name. The streams of the Kafka clusters in the backups
+// containing multiple Kafka clusters are found without the cluster name.
+func LookupStream(name string) (StreamInfo, bool) {
+	_, name = archive.SplitClusterStreamName(name)
+
+	for _, stream := range Streams {
+		if stream.Name != "" && stream.Name == name {
+			return stream, true
+		} else if stream.Prefix != "" && strings.HasPrefix(name, stream.Prefix) && strings.HasSuffix(name, stream.Suffix) {
+			return stream, true
+		}
+	}
+
+	return StreamInfo{}, false
+}
+
+// StreamDescription returns the description of the stream used as the comment of its GZIP member
+func StreamDescription(name string) string {
+	stream, ok := LookupStream(name)
+	if !ok {
+		return ""
+	}
+
+	return stream.Description
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuper
+
+import (
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+)
+
+// followWatchedNamespaces adds the namespaces watched by the Topic and User Operators of the Kafka cluster to the
+// namespaces from which the KafkaTopics and KafkaUsers are backed up. The namespaces which the backup is not allowed
+// to read are skipped with a warning. Does nothing unless the --follow-watched-namespaces option is used.
+func (b *KafkaBackuper) followWatchedNamespaces(kafka *v1beta2.Kafka) error {
+	if !b.followWatched || kafka.Spec == nil || kafka.Spec.EntityOperator == nil {
+		return nil
+	}
+
+	if topicOperator := kafka.Spec.EntityOperator.TopicOperator; topicOperator != nil {
+		namespace, err := b.watchedNamespace("Topic Operator", topicOperator.WatchedNamespace, "kafkatopics")
+		if err != nil {
+			return err
+		} else if namespace != "" && !slices.Contains(b.topicNamespaces, namespace) {
+			b.topicNamespaces = append(b.topicNamespaces, namespace)
+		}
+	}
+
+	if userOperator := kafka.Spec.EntityOperator.UserOperator; userOperator != nil {
+		namespace, err := b.watchedNamespace("User Operator", userOperator.WatchedNamespace, "kafkausers", "secrets")
+		if err != nil {
+			return err
+		} else if namespace != "" && !slices.Contains(b.userNamespaces, namespace) {
+			b.userNamespaces = append(b.userNamespaces, namespace)
+		}
+	}
+
+	return nil
+}
+
+// watchedNamespace returns the namespace watched by the operator when it differs from the namespace of the Kafka
+// cluster and the backup is allowed to list the resources in it. Otherwise, it returns an empty string.
+func (b *KafkaBackuper) watchedNamespace(operator string, namespace string, resources ...string) (string, error) {
+	if namespace == "" || namespace == b.Namespace {
+		return "", nil
+	}
+
+	for _, resource := range resources {
+		group := "kafka.strimzi.io"
+		if resource == "secrets" {
+			group = ""
+		}
+
+		allowed, err := b.canList(namespace, group, resource)
+		if err != nil {
+			return "", err
+		} else if !allowed {
+			slog.Warn("The backup is not allowed to list the resources in the namespace watched by the "+operator+" and the namespace is skipped", "namespace", namespace, "resource", qualifiedResource(group, resource))
+			return "", nil
+		}
+	}
+
+	slog.Info("Following the namespace watched by the "+operator, "namespace", namespace)
+	return namespace, nil
+}
+
+// canList checks whether the backup is allowed to list the resources in the namespace
+func (b *KafkaBackuper) canList(namespace string, group string, resource string) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: namespace, Verb: "list", Group: group, Resource: resource},
+		},
+	}
+
+	result, err := b.KubernetesClient.AuthorizationV1().SelfSubjectAccessReviews().Create(b.ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		slog.Error("Failed to check the access to the resources", "namespace", namespace, "group", group, "resource", resource, "error", err)
+		return false, err
+	}
+
+	return result.Status.Allowed, nil
+}
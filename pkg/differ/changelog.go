@@ -0,0 +1,268 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differ
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatJson     = "json"
+)
+
+// aclsPath is the path of the ACL rules of the KafkaUser. The ACL rules are compared rule by rule instead of as a field.
+const aclsPath = "spec.authorization.acls"
+
+// changelogKinds maps the streams included in the changelog to the kinds of their resources. The Secrets are not
+// included in the changelog.
+var changelogKinds = map[string]string{
+	backuper.KafkaFilename:          "Kafka",
+	backuper.KafkaNodePoolsFilename: "KafkaNodePool",
+	backuper.KafkaTopicsFilename:    "KafkaTopic",
+	backuper.KafkaUsersFilename:     "KafkaUser",
+}
+
+// Changelog is the human-readable summary of the changes between two backups intended for change-review processes
+type Changelog struct {
+	Old    string           `json:"old"`
+	New    string           `json:"new"`
+	Kafka  []ResourceChange `json:"kafka"`
+	Topics []ResourceChange `json:"topics"`
+	Users  []ResourceChange `json:"users"`
+}
+
+// ResourceChange describes the change of a single resource in the changelog
+type ResourceChange struct {
+	Kind        string        `json:"kind"`
+	Name        string        `json:"name"`
+	Change      string        `json:"change"`
+	Fields      []FieldChange `json:"fields,omitempty"`
+	AddedAcls   []string      `json:"addedAcls,omitempty"`
+	RemovedAcls []string      `json:"removedAcls,omitempty"`
+}
+
+// Changelog writes the changes of the Kafka cluster configuration, the topic configurations, and the user ACLs between
+// the backups in the Markdown or JSON format
+func (d *Differ) Changelog(w io.Writer, format string) error {
+	if format != FormatMarkdown && format != FormatJson {
+		slog.Error("Unsupported value of the --format option", "format", format)
+		return fmt.Errorf("unsupported value %s of the --format option (supported values are %s and %s)", format, FormatMarkdown, FormatJson)
+	}
+
+	changes, err := d.compare()
+	if err != nil {
+		return err
+	}
+
+	changelog := Changelog{Old: d.OldFileName, New: d.NewFileName, Kafka: []ResourceChange{}, Topics: []ResourceChange{}, Users: []ResourceChange{}}
+
+	for _, change := range changes {
+		kind, ok := changelogKinds[change.Stream]
+		if !ok {
+			continue
+		}
+
+		resourceChange := ResourceChange{Kind: kind, Name: change.Name, Change: changeDescription(change.Type)}
+
+		for _, field := range change.Fields {
+			if field.Path == aclsPath || strings.HasPrefix(field.Path, aclsPath+".") {
+				resourceChange.AddedAcls, resourceChange.RemovedAcls = aclChanges(change.oldResource, change.newResource)
+			} else if strings.HasPrefix(field.Path, "spec.") {
+				resourceChange.Fields = append(resourceChange.Fields, field)
+			}
+		}
+
+		if change.Type == Changed && len(resourceChange.Fields) == 0 && len(resourceChange.AddedAcls) == 0 && len(resourceChange.RemovedAcls) == 0 {
+			// Only metadata or status changed => not relevant for the changelog
+			continue
+		}
+
+		switch kind {
+		case "KafkaTopic":
+			changelog.Topics = append(changelog.Topics, resourceChange)
+		case "KafkaUser":
+			changelog.Users = append(changelog.Users, resourceChange)
+		default:
+			changelog.Kafka = append(changelog.Kafka, resourceChange)
+		}
+	}
+
+	if format == FormatJson {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changelog)
+	}
+
+	return writeMarkdown(w, changelog)
+}
+
+func changeDescription(changeType string) string {
+	switch changeType {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// aclChanges returns the ACL rules which were added to and removed from the KafkaUser
+func aclChanges(oldResource any, newResource any) ([]string, []string) {
+	oldAcls := formatAcls(oldResource)
+	newAcls := formatAcls(newResource)
+
+	var added, removed []string
+	for _, acl := range newAcls {
+		if !slices.Contains(oldAcls, acl) {
+			added = append(added, acl)
+		}
+	}
+
+	for _, acl := range oldAcls {
+		if !slices.Contains(newAcls, acl) {
+			removed = append(removed, acl)
+		}
+	}
+
+	return added, removed
+}
+
+func formatAcls(resource any) []string {
+	var acls []string
+
+	for _, rule := range nested[[]any](resource, "spec", "authorization", "acls") {
+		acls = append(acls, formatAcl(rule))
+	}
+
+	sort.Strings(acls)
+
+	return acls
+}
+
+// formatAcl formats the ACL rule as for example "allow Read,Describe on topic my-topic (literal) from host *"
+func formatAcl(rule any) string {
+	aclType := nested[string](rule, "type")
+	if aclType == "" {
+		aclType = "allow"
+	}
+
+	var operations []string
+	for _, operation := range nested[[]any](rule, "operations") {
+		operations = append(operations, fmt.Sprint(operation))
+	}
+	if operation := nested[string](rule, "operation"); operation != "" {
+		operations = append(operations, operation)
+	}
+
+	resourceName := nested[string](rule, "resource", "name")
+	if resourceName == "" {
+		resourceName = "*"
+	}
+
+	patternType := nested[string](rule, "resource", "patternType")
+	if patternType == "" {
+		patternType = "literal"
+	}
+
+	host := nested[string](rule, "host")
+	if host == "" {
+		host = "*"
+	}
+
+	return fmt.Sprintf("%s %s on %s %s (%s) from host %s", strings.ToLower(aclType), strings.Join(operations, ","), nested[string](rule, "resource", "type"), resourceName, patternType, host)
+}
+
+// nested returns the nested field of the unstructured resource or the zero value if it does not exist
+func nested[T any](value any, path ...string) T {
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			var zero T
+			return zero
+		}
+
+		value = m[key]
+	}
+
+	result, _ := value.(T)
+	return result
+}
+
+func writeMarkdown(w io.Writer, changelog Changelog) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Changes between `%s` and `%s`\n", changelog.Old, changelog.New))
+
+	sections := []struct {
+		title   string
+		changes []ResourceChange
+	}{
+		{"Kafka cluster", changelog.Kafka},
+		{"Topics", changelog.Topics},
+		{"Users", changelog.Users},
+	}
+
+	for _, section := range sections {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", section.title))
+
+		if len(section.changes) == 0 {
+			sb.WriteString("No changes.\n")
+			continue
+		}
+
+		for _, change := range section.changes {
+			sb.WriteString(fmt.Sprintf("* %s `%s` %s\n", change.Kind, change.Name, change.Change))
+
+			for _, field := range change.Fields {
+				sb.WriteString(fmt.Sprintf("  * `%s`: %s → %s\n", field.Path, formatValue(field.Old), formatValue(field.New)))
+			}
+
+			for _, acl := range change.AddedAcls {
+				sb.WriteString(fmt.Sprintf("  * ACL added: `%s`\n", acl))
+			}
+
+			for _, acl := range change.RemovedAcls {
+				sb.WriteString(fmt.Sprintf("  * ACL removed: `%s`\n", acl))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatValue(value any) string {
+	if value == nil {
+		return "_not set_"
+	}
+
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("`%v`", value)
+	}
+
+	return "`" + string(formatted) + "`"
+}
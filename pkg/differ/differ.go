@@ -0,0 +1,250 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differ
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/spf13/cobra"
+	"io"
+	"log/slog"
+	"reflect"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+// ignoredPaths are the fields which change with every backup without any change to the cluster configuration
+var ignoredPaths = []string{
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.managedFields",
+	"metadata.creationTimestamp",
+	"metadata.uid",
+}
+
+type Differ struct {
+	OldFileName   string
+	NewFileName   string
+	IncludeStatus bool
+}
+
+// Change describes a single resource which differs between the backups
+type Change struct {
+	Stream      string
+	Name        string
+	Type        string
+	Fields      []FieldChange
+	oldResource any
+	newResource any
+}
+
+// FieldChange describes a single field which differs between the backups
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+const (
+	Added   = "+"
+	Removed = "-"
+	Changed = "~"
+)
+
+func NewDiffer(cmd *cobra.Command, args []string) (*Differ, error) {
+	if len(args) != 2 {
+		slog.Error("Exactly two backup files are required")
+		return nil, fmt.Errorf("exactly two backup files are required")
+	}
+
+	// The --include-status flag is not used by all commands comparing the backups
+	var includeStatus bool
+	if cmd.Flags().Lookup("include-status") != nil {
+		var err error
+		includeStatus, err = cmd.Flags().GetBool("include-status")
+		if err != nil {
+			slog.Error("Failed to get the --include-status flag", "error", err)
+			return nil, err
+		}
+	}
+
+	differ := Differ{
+		OldFileName:   args[0],
+		NewFileName:   args[1],
+		IncludeStatus: includeStatus,
+	}
+
+	return &differ, nil
+}
+
+// Diff compares the resources in the two backups and writes the added, removed, and changed resources to the writer.
+// For changed resources, only the paths of the changed fields are shown, so that the values of Secrets are not
+// revealed.
+func (d *Differ) Diff(w io.Writer) ([]Change, error) {
+	changes, err := d.compare()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		line := fmt.Sprintf("%s %s %s", change.Type, change.Stream, change.Name)
+		if len(change.Fields) > 0 {
+			paths := make([]string, 0, len(change.Fields))
+			for _, field := range change.Fields {
+				paths = append(paths, field.Path)
+			}
+
+			line += ": " + strings.Join(paths, ", ")
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// compare finds the resources which were added, removed, or changed between the backups
+func (d *Differ) compare() ([]Change, error) {
+	oldResources, err := d.readResources(d.OldFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	newResources, err := d.readResources(d.NewFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	for _, key := range sortedKeys(oldResources, newResources) {
+		oldResource, inOld := oldResources[key]
+		newResource, inNew := newResources[key]
+		stream, name, _ := strings.Cut(key, "/")
+
+		if !inOld {
+			changes = append(changes, Change{Stream: stream, Name: name, Type: Added, newResource: newResource})
+		} else if !inNew {
+			changes = append(changes, Change{Stream: stream, Name: name, Type: Removed, oldResource: oldResource})
+		} else if fields := d.changedFields("", oldResource, newResource); len(fields) > 0 {
+			changes = append(changes, Change{Stream: stream, Name: name, Type: Changed, Fields: fields, oldResource: oldResource, newResource: newResource})
+		}
+	}
+
+	return changes, nil
+}
+
+// readResources reads the resources from the backup into a map indexed by the stream and the resource name
+func (d *Differ) readResources(fileName string) (map[string]any, error) {
+	streams, err := archive.ReadStreams(fileName)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", fileName)
+		return nil, err
+	}
+
+	resources := map[string]any{}
+
+	for _, stream := range streams {
+		if stream.Name == archive.ManifestFilename {
+			continue
+		}
+
+		content := map[string]any{}
+		if err := yaml.Unmarshal(stream.Data, &content); err != nil {
+			slog.Error("Failed to unmarshal the stream", "error", err, "file", fileName, "stream", stream.Name)
+			return nil, err
+		}
+
+		items, isList := content["items"].([]any)
+		if !isList {
+			items = []any{content}
+		}
+
+		for i, item := range items {
+			name := fmt.Sprintf("#%d", i)
+			if metadata, ok := item.(map[string]any)["metadata"].(map[string]any); ok {
+				if n, ok := metadata["name"].(string); ok {
+					name = n
+				}
+			}
+
+			resources[stream.Name+"/"+name] = item
+		}
+	}
+
+	return resources, nil
+}
+
+// changedFields returns the fields which differ between the two values
+func (d *Differ) changedFields(path string, oldValue any, newValue any) []FieldChange {
+	if d.isIgnored(path) || reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if !oldIsMap || !newIsMap {
+		return []FieldChange{{Path: path, Old: oldValue, New: newValue}}
+	}
+
+	var fields []FieldChange
+	for _, key := range sortedKeys(oldMap, newMap) {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+
+		fields = append(fields, d.changedFields(childPath, oldMap[key], newMap[key])...)
+	}
+
+	return fields
+}
+
+func (d *Differ) isIgnored(path string) bool {
+	if !d.IncludeStatus && path == "status" {
+		return true
+	}
+
+	for _, ignored := range ignoredPaths {
+		if path == ignored {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(a map[string]any, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+
+	for key := range a {
+		keys = append(keys, key)
+	}
+
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// TypePrefix is the prefix of the types of all CloudEvents emitted by strimzi-backup
+	TypePrefix = "io.github.scholzj.strimzi-backup."
+
+	OperationBackup  = "backup"
+	OperationRestore = "restore"
+
+	PhaseStarted   = "started"
+	PhaseCompleted = "completed"
+	PhaseFailed    = "failed"
+
+	// sendTimeout is the timeout for delivering a single event to the sink
+	sendTimeout = 10 * time.Second
+)
+
+// Data is the payload of the emitted CloudEvents
+type Data struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	FileName  string `json:"filename,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// event is a CloudEvent in the structured JSON format
+type event struct {
+	SpecVersion     string    `json:"specversion"`
+	Id              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            Data      `json:"data"`
+}
+
+// Emitter sends the phase transitions of the backups and restores as CloudEvents to an HTTP sink, so that event-driven
+// automation can react to them without polling. A nil Emitter does not send anything. Failing to deliver an event only
+// logs a warning and never fails the backup or restore.
+type Emitter struct {
+	Sink    string
+	Headers http.Header
+	client  *http.Client
+}
+
+// AddFlags adds the flags configuring the CloudEvents sink
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String("cloudevents-sink", "", "URL of the HTTP sink (for example a Knative Broker) to which the phase transitions of the backup or restore are sent as CloudEvents. If not specified, no events are sent.")
+	flags.StringArray("cloudevents-header", nil, "HTTP header in the <name>: <value> format added to the requests to the CloudEvents sink. Environment variables in the value are expanded. Can be used multiple times.")
+}
+
+// NewEmitterFromFlags creates the emitter from the --cloudevents-sink and --cloudevents-header options. It returns nil
+// when no sink is configured.
+func NewEmitterFromFlags(cmd *cobra.Command) (*Emitter, error) {
+	sink := cmd.Flag("cloudevents-sink").Value.String()
+	if sink == "" {
+		return nil, nil
+	}
+
+	headers, err := cmd.Flags().GetStringArray("cloudevents-header")
+	if err != nil {
+		slog.Error("Failed to get the --cloudevents-header flag", "error", err)
+		return nil, err
+	}
+
+	parsedHeaders := http.Header{}
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			slog.Error("Invalid value of the --cloudevents-header option", "header", header)
+			return nil, fmt.Errorf("invalid CloudEvents header %q (expected format is <name>: <value>)", header)
+		}
+
+		parsedHeaders.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+
+	return &Emitter{Sink: sink, Headers: parsedHeaders, client: &http.Client{Timeout: sendTimeout}}, nil
+}
+
+// Emit sends the event about the phase of the operation
+func (e *Emitter) Emit(operation string, phase string, data Data) {
+	if e == nil {
+		return
+	}
+
+	ce := event{
+		SpecVersion:     "1.0",
+		Id:              uuid.NewString(),
+		Source:          "/strimzi-backup/" + data.Namespace + "/" + data.Name,
+		Type:            TypePrefix + operation + "." + phase,
+		Subject:         data.FileName,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+
+	if err := e.send(ce); err != nil {
+		slog.Warn("Failed to send the CloudEvent", "sink", e.Sink, "type", ce.Type, "error", err)
+	} else {
+		slog.Debug("CloudEvent was sent", "sink", e.Sink, "type", ce.Type, "id", ce.Id)
+	}
+}
+
+// Finished sends the completed or the failed event depending on the result of the operation
+func (e *Emitter) Finished(operation string, data Data, err error) {
+	if err != nil {
+		data.Error = err.Error()
+		e.Emit(operation, PhaseFailed, data)
+	} else {
+		e.Emit(operation, PhaseCompleted, data)
+	}
+}
+
+// send posts the event to the sink in the structured content mode
+func (e *Emitter) send(ce event) error {
+	body, err := json.Marshal(ce)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, e.Sink, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for name, values := range e.Headers {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+	request.Header.Set("Content-Type", "application/cloudevents+json; charset=UTF-8")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	return nil
+}
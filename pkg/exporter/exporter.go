@@ -17,34 +17,68 @@ limitations under the License.
 package exporter
 
 import (
-	"bufio"
-	"compress/gzip"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
 	"github.com/spf13/cobra"
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+)
+
+const (
+	FormatFiles        = "files"
+	FormatHelmValues   = "helm-values"
+	FormatCrossplane   = "crossplane"
+	FormatTerraform    = "terraform"
+	FormatCombinedYaml = "combined-yaml"
+	FormatJson         = "json"
 )
 
 type Exporter struct {
 	BackupFileName  string
 	ExportDirectory string
+	Format          string
+	limits          *archive.Limits
 	backupFile      *os.File
-	bufferedReader  *bufio.Reader
-	gzipReader      *gzip.Reader
+	reader          *archive.Reader
 }
 
 func NewExporter(cmd *cobra.Command) (*Exporter, error) {
 	backupFileName := cmd.Flag("filename").Value.String()
 	exportDirectory := cmd.Flag("target-directory").Value.String()
 
+	format := cmd.Flag("format").Value.String()
+	if format != FormatFiles && format != FormatHelmValues && format != FormatCrossplane && format != FormatTerraform && format != FormatCombinedYaml && format != FormatJson {
+		slog.Error("Unsupported value of the --format option", "format", format)
+		return nil, fmt.Errorf("unsupported value %s of the --format option (supported values are %s, %s, %s, %s, %s, and %s)", format, FormatFiles, FormatHelmValues, FormatCrossplane, FormatTerraform, FormatCombinedYaml, FormatJson)
+	}
+
+	if exportDirectory == "" {
+		slog.Error("--target-directory option is required")
+		return nil, fmt.Errorf("--target-directory option is required")
+	}
+
+	// The absolute path lets Go handle the drive letters and the long paths on Windows
+	exportDirectory, err := filepath.Abs(exportDirectory)
+	if err != nil {
+		slog.Error("Failed to resolve the target directory", "error", err, "directory", cmd.Flag("target-directory").Value.String())
+		return nil, err
+	}
+
+	limits, err := archive.NewLimitsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	backupFile, err := os.OpenFile(backupFileName, os.O_RDONLY, 0644)
 	if err != nil {
 		slog.Error("Failed to open file", "error", err, "file", backupFileName)
 		return nil, err
 	}
 
-	bufferedReader := bufio.NewReader(backupFile)
-	gzipReader, err := gzip.NewReader(bufferedReader)
+	// The streams are exported as they are stored in the backup, so the encrypted streams stay encrypted
+	reader, err := archive.NewReader(backupFile, limits)
 	if err != nil {
 		slog.Error("Failed to read file", "error", err, "file", backupFileName)
 		return nil, err
@@ -58,63 +92,68 @@ func NewExporter(cmd *cobra.Command) (*Exporter, error) {
 	exporter := Exporter{
 		BackupFileName:  backupFileName,
 		ExportDirectory: exportDirectory,
+		Format:          format,
+		limits:          limits,
 		backupFile:      backupFile,
-		bufferedReader:  bufferedReader,
-		gzipReader:      gzipReader,
+		reader:          reader,
 	}
 
 	return &exporter, nil
 }
 
 func (e *Exporter) Export() error {
-	for {
-		e.gzipReader.Multistream(false)
-		slog.Info("Exporting data", "name", e.gzipReader.Name, "comment", e.gzipReader.Comment, "modTime", e.gzipReader.ModTime)
+	switch e.Format {
+	case FormatHelmValues:
+		return e.exportHelmValues()
+	case FormatCrossplane:
+		return e.exportCrossplane()
+	case FormatTerraform:
+		return e.exportTerraform()
+	case FormatCombinedYaml:
+		return e.exportCombinedYaml()
+	}
 
-		exportFilename := e.ExportDirectory + "/" + e.gzipReader.Name
-		exportFile, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
-		if err != nil {
-			slog.Error("Failed to open export file", "error", err, "file", exportFilename)
+	for {
+		member, err := e.reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err, "file", e.BackupFileName)
 			return err
 		}
 
-		bufferedWriter := bufio.NewWriter(exportFile)
+		slog.Info("Exporting data", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
 
-		if _, err := io.Copy(bufferedWriter, e.gzipReader); err != nil {
-			slog.Error("Failed to export data", "error", err, "file", exportFilename)
+		fileName, err := sanitizeFileName(member.Name)
+		if err != nil {
+			slog.Error("Invalid stream name in the backup", "error", err, "name", member.Name)
 			return err
+		} else if fileName != member.Name {
+			slog.Warn("The stream name is not a valid file name and is exported under a different name", "name", member.Name, "file", fileName)
 		}
 
-		if err := e.gzipReader.Reset(e.bufferedReader); err != nil {
-			if err == io.EOF {
-				slog.Info("Exporting data completed", "name", exportFilename)
-
-				// Cleanup after the exported file
-				if err := bufferedWriter.Flush(); err != nil {
-					slog.Error("Failed to flush writer", "error", err, "file", exportFilename)
-					return err
-				}
-				if err := exportFile.Close(); err != nil {
-					slog.Error("Failed to close export file", "error", err, "file", exportFilename)
-					return err
-				}
-
-				break
-			} else {
-				slog.Error("Failed to read the backup", "error", err)
+		data := member.Data
+		if e.Format == FormatJson {
+			data, fileName, err = jsonStream(member.Name, fileName, data)
+			if err != nil {
 				return err
 			}
 		}
+
+		// Every stream is written and closed right away, so that all exported files are complete
+		if err := e.writeExportFile(fileName, data); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (e *Exporter) Close() {
-	if e.gzipReader != nil {
-		err := e.gzipReader.Close()
+	if e.reader != nil {
+		err := e.reader.Close()
 		if err != nil {
-			slog.Error("Failed to close the GZIP reader", "error", err)
+			slog.Error("Failed to close the backup reader", "error", err)
 		}
 	}
 
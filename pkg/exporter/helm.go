@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+)
+
+// HelmValuesFilename is the name of the file with the Helm values
+const HelmValuesFilename = "values.yaml"
+
+// exportHelmValues derives a Helm values snippet with the main settings of the Kafka cluster and its node pools from
+// the backup. It is meant as a starting point for teams templating their Strimzi resources using Helm.
+func (e *Exporter) exportHelmValues() error {
+	streams, err := archive.ReadLimitedStreams(e.BackupFileName, e.limits)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", e.BackupFileName)
+		return err
+	}
+
+	var kafka map[string]any
+	var nodePools map[string]any
+
+	for _, stream := range streams {
+		switch stream.Name {
+		case backuper.KafkaFilename:
+			if err := yaml.Unmarshal(stream.Data, &kafka); err != nil {
+				slog.Error("Failed to unmarshal the Kafka resource", "error", err)
+				return err
+			}
+		case backuper.KafkaNodePoolsFilename:
+			if err := yaml.Unmarshal(stream.Data, &nodePools); err != nil {
+				slog.Error("Failed to unmarshal the KafkaNodePool resources", "error", err)
+				return err
+			}
+		}
+	}
+
+	if kafka == nil {
+		slog.Error("The backup does not contain the Kafka resource", "file", e.BackupFileName)
+		return fmt.Errorf("the backup %s does not contain the Kafka resource", e.BackupFileName)
+	}
+
+	values := helmValues(kafka, nodePools)
+
+	valuesYaml, err := yaml.Marshal(values)
+	if err != nil {
+		slog.Error("Failed to marshal the Helm values to YAML", "error", err)
+		return err
+	}
+
+	return e.writeExportFile(HelmValuesFilename, append([]byte("# Helm values derived from the backup "+filepath.Base(e.BackupFileName)+"\n"), valuesYaml...))
+}
+
+// helmValues picks the cluster name, the versions, and the listener, storage, and node pool settings from the Kafka and
+// KafkaNodePool resources
+func helmValues(kafka map[string]any, nodePools map[string]any) map[string]any {
+	metadata, _ := kafka["metadata"].(map[string]any)
+	spec, _ := kafka["spec"].(map[string]any)
+	kafkaSpec, _ := spec["kafka"].(map[string]any)
+
+	cluster := map[string]any{}
+	copyFields(cluster, metadata, "name", "namespace")
+	copyFields(cluster, kafkaSpec, "version", "metadataVersion", "replicas", "listeners", "storage", "config", "resources", "authorization", "rack")
+
+	values := map[string]any{"kafka": cluster}
+
+	var pools []any
+	items, _ := nodePools["items"].([]any)
+	for _, item := range items {
+		nodePool, _ := item.(map[string]any)
+		nodePoolMetadata, _ := nodePool["metadata"].(map[string]any)
+		nodePoolSpec, _ := nodePool["spec"].(map[string]any)
+
+		pool := map[string]any{}
+		copyFields(pool, nodePoolMetadata, "name")
+		copyFields(pool, nodePoolSpec, "roles", "replicas", "storage", "resources")
+		pools = append(pools, pool)
+	}
+
+	if len(pools) > 0 {
+		values["nodePools"] = pools
+	}
+
+	if entityOperator, ok := spec["entityOperator"].(map[string]any); ok {
+		values["entityOperator"] = map[string]any{
+			"topicOperator": entityOperator["topicOperator"] != nil,
+			"userOperator":  entityOperator["userOperator"] != nil,
+		}
+	}
+
+	return values
+}
+
+// copyFields copies the fields which are set in the source into the target
+func copyFields(target map[string]any, source map[string]any, fields ...string) {
+	for _, field := range fields {
+		if value, ok := source[field]; ok && value != nil {
+			target[field] = value
+		}
+	}
+}
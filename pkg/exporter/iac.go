@@ -0,0 +1,298 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+const (
+	// CrossplaneFilename is the name of the file with the Crossplane Object resources
+	CrossplaneFilename = "crossplane.yaml"
+	// TerraformFilename is the name of the file with the Terraform kubernetes_manifest resources
+	TerraformFilename = "strimzi.tf"
+	// CombinedYamlFilename is the name of the file with all resources as a multi-document YAML
+	CombinedYamlFilename = "all-resources.yaml"
+)
+
+// restorableStreams lists the streams with the resources which are restored. They are listed in the order in which
+// they have to be applied: the CA Secrets, the custom listener certificates, the authentication Secrets, and the other
+// referenced Secrets and ConfigMaps before the Kafka cluster (otherwise the Cluster Operator generates new CAs and
+// listener certificates and fails to reconcile the cluster) and the user Secrets before the Kafka users (so that the
+// User Operator reuses the credentials from the backup).
+var restorableStreams = []string{
+	backuper.CaSecretsFilename,
+	backuper.KafkaListenerCertificatesFilename,
+	backuper.KafkaAuthenticationSecretsFilename,
+	backuper.KafkaConfigMapsFilename,
+	backuper.KafkaReferencedSecretsFilename,
+	backuper.KafkaNodePoolsFilename,
+	backuper.KafkaFilename,
+	backuper.KafkaTopicsFilename,
+	backuper.KafkaUserSecretsFilename,
+	backuper.KafkaUsersFilename,
+}
+
+// pinTopicName records the name of the Kafka topic from the status or the strimzi.io/topic-name annotation in the
+// spec.topicName field of the KafkaTopic resource from an older backup, as the status is not exported
+func pinTopicName(resource map[string]any) {
+	metadata, _ := resource["metadata"].(map[string]any)
+	annotations, _ := metadata["annotations"].(map[string]any)
+	status, _ := resource["status"].(map[string]any)
+	spec, _ := resource["spec"].(map[string]any)
+
+	if name, _ := spec["topicName"].(string); name != "" {
+		return
+	}
+
+	name, _ := annotations[utils.TopicNameAnnotation].(string)
+	if name == "" {
+		name, _ = status["topicName"].(string)
+	}
+
+	if name == "" || name == metadata["name"] {
+		return
+	}
+
+	if spec == nil {
+		spec = make(map[string]any)
+		resource["spec"] = spec
+	}
+
+	spec["topicName"] = name
+}
+
+var invalidTerraformName = regexp.MustCompile("[^a-z0-9_]+")
+
+// exportCrossplane wraps the resources from the backup into Crossplane Object resources of the Kubernetes provider
+func (e *Exporter) exportCrossplane() error {
+	resources, err := e.readRestorableResources()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Crossplane Object resources derived from the backup " + filepath.Base(e.BackupFileName) + "\n")
+
+	for _, resource := range resources {
+		object := map[string]any{
+			"apiVersion": "kubernetes.crossplane.io/v1alpha2",
+			"kind":       "Object",
+			"metadata": map[string]any{
+				"name": strings.ToLower(resourceKind(resource)) + "-" + resourceName(resource),
+			},
+			"spec": map[string]any{
+				"forProvider": map[string]any{
+					"manifest": resource,
+				},
+				"providerConfigRef": map[string]any{
+					"name": "default",
+				},
+			},
+		}
+
+		objectYaml, err := yaml.Marshal(object)
+		if err != nil {
+			slog.Error("Failed to marshal the Crossplane Object to YAML", "error", err)
+			return err
+		}
+
+		out.WriteString("---\n")
+		out.Write(objectYaml)
+	}
+
+	return e.writeExportFile(CrossplaneFilename, out.Bytes())
+}
+
+// exportTerraform wraps the resources from the backup into Terraform kubernetes_manifest resources
+func (e *Exporter) exportTerraform() error {
+	resources, err := e.readRestorableResources()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Terraform kubernetes_manifest resources derived from the backup " + filepath.Base(e.BackupFileName) + "\n")
+
+	for _, resource := range resources {
+		resourceYaml, err := yaml.Marshal(resource)
+		if err != nil {
+			slog.Error("Failed to marshal the resource to YAML", "error", err)
+			return err
+		}
+
+		name := invalidTerraformName.ReplaceAllString(strings.ToLower(resourceKind(resource)+"_"+resourceName(resource)), "_")
+
+		out.WriteString("\nresource \"kubernetes_manifest\" \"" + name + "\" {\n")
+		out.WriteString("  manifest = yamldecode(<<-EOT\n")
+		for _, line := range strings.Split(strings.TrimSuffix(string(resourceYaml), "\n"), "\n") {
+			out.WriteString("    " + line + "\n")
+		}
+		out.WriteString("  EOT\n  )\n}\n")
+	}
+
+	return e.writeExportFile(TerraformFilename, out.Bytes())
+}
+
+// exportCombinedYaml writes all resources from the backup into a single multi-document YAML file in the order in which
+// they should be applied, so that they can be restored with kubectl apply when strimzi-backup cannot be used
+func (e *Exporter) exportCombinedYaml() error {
+	resources, err := e.readRestorableResources()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Resources from the backup " + filepath.Base(e.BackupFileName) + " in the order in which they should be applied\n")
+	out.WriteString("# The Kafka Cluster ID is stored in the status of the Kafka resource and is not restored by kubectl apply\n")
+
+	for _, resource := range resources {
+		// The namespace is removed, so that the resources can be applied into any namespace using kubectl apply -n
+		if metadata, ok := resource["metadata"].(map[string]any); ok {
+			delete(metadata, "namespace")
+		}
+
+		resourceYaml, err := yaml.Marshal(resource)
+		if err != nil {
+			slog.Error("Failed to marshal the resource to YAML", "error", err)
+			return err
+		}
+
+		out.WriteString("---\n")
+		out.Write(resourceYaml)
+	}
+
+	return e.writeExportFile(CombinedYamlFilename, out.Bytes())
+}
+
+// readRestorableResources reads the resources which are restored from the backup. The lists are expanded into their
+// items and the fields which are managed by Kubernetes are removed.
+func (e *Exporter) readRestorableResources() ([]map[string]any, error) {
+	streams, err := archive.ReadLimitedStreams(e.BackupFileName, e.limits)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", e.BackupFileName)
+		return nil, err
+	}
+
+	data := map[string][]byte{}
+	for _, stream := range streams {
+		data[stream.Name] = stream.Data
+	}
+
+	var resources []map[string]any
+	for _, name := range restorableStreams {
+		restorable, _ := backuper.LookupStream(name)
+
+		streamData, ok := data[name]
+		if !ok {
+			continue
+		} else if archive.IsEncrypted(streamData) {
+			slog.Warn("The stream is encrypted and is not exported", "stream", name)
+			continue
+		}
+
+		var parsed map[string]any
+		if err := yaml.Unmarshal(streamData, &parsed); err != nil {
+			slog.Error("Failed to unmarshal the resources", "stream", name, "error", err)
+			return nil, err
+		}
+
+		var items []any
+		if name == backuper.KafkaFilename {
+			items = []any{parsed}
+		} else {
+			items, _ = parsed["items"].([]any)
+		}
+
+		for _, item := range items {
+			resource, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			// Typed lists do not always contain the API version and kind of their items
+			if resource["apiVersion"] == nil {
+				resource["apiVersion"] = restorable.APIVersion
+			}
+			if resource["kind"] == nil {
+				resource["kind"] = restorable.Kind
+			}
+
+			if restorable.Kind == "KafkaTopic" {
+				pinTopicName(resource)
+			}
+
+			delete(resource, "status")
+			if metadata, ok := resource["metadata"].(map[string]any); ok {
+				// Backups taken with --skip-metadata-cleansing still contain the fields identifying the original resources
+				for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields", "ownerReferences"} {
+					delete(metadata, field)
+				}
+			}
+
+			slog.Debug("Exporting resource", "kind", resourceKind(resource), "name", resourceName(resource))
+			resources = append(resources, resource)
+		}
+	}
+
+	if len(resources) == 0 {
+		slog.Error("The backup does not contain any resources which can be exported", "file", e.BackupFileName)
+		return nil, fmt.Errorf("the backup %s does not contain any resources which can be exported", e.BackupFileName)
+	}
+
+	return resources, nil
+}
+
+func (e *Exporter) writeExportFile(name string, data []byte) error {
+	fileName := filepath.Join(e.ExportDirectory, name)
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open export file", "error", err, "file", fileName)
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		slog.Error("Failed to export data", "error", err, "file", fileName)
+		return err
+	}
+
+	slog.Info("Exporting data completed", "name", fileName)
+
+	return file.Close()
+}
+
+func resourceKind(resource map[string]any) string {
+	kind, _ := resource["kind"].(string)
+	return kind
+}
+
+func resourceName(resource map[string]any) string {
+	metadata, _ := resource["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	return name
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// jsonStream converts a YAML stream from the backup into indented JSON for the json export format. The file name gets
+// the .json extension instead of .yaml. The streams which are not YAML (such as the topic data which are stored as
+// JSON lines or the Apicurio Registry artifacts) and the encrypted streams are exported unchanged.
+func jsonStream(name string, fileName string, data []byte) ([]byte, string, error) {
+	if !strings.HasSuffix(fileName, ".yaml") {
+		slog.Debug("The stream is not YAML and is exported unchanged", "name", name)
+		return data, fileName, nil
+	} else if archive.IsEncrypted(data) {
+		slog.Warn("The stream is encrypted and is exported unchanged", "name", name)
+		return data, fileName, nil
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		slog.Error("Failed to convert the stream to JSON", "name", name, "error", err)
+		return nil, "", err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, jsonData, "", "  "); err != nil {
+		slog.Error("Failed to format the stream as JSON", "name", name, "error", err)
+		return nil, "", err
+	}
+	indented.WriteString("\n")
+
+	return indented.Bytes(), strings.TrimSuffix(fileName, ".yaml") + ".json", nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// windowsReservedNames are the device names which cannot be used as file names on Windows regardless of their extension
+var windowsReservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// sanitizeFileName turns the name of a stream from the backup into a file name which is valid on Linux, macOS, and
+// Windows. Path separators, drive letters, and the characters not allowed on Windows are replaced, so that a stream
+// name from a modified backup cannot be used to write outside the export directory.
+func sanitizeFileName(name string) (string, error) {
+	sanitized := strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	// Windows ignores the trailing dots and spaces, so names such as ".. " would still point to the parent directory
+	sanitized = strings.TrimRight(sanitized, ". ")
+	if sanitized == "" {
+		return "", fmt.Errorf("the stream name %q cannot be used as a file name", name)
+	}
+
+	base, _, _ := strings.Cut(sanitized, ".")
+	for _, reserved := range windowsReservedNames {
+		if strings.EqualFold(strings.TrimRight(base, " "), reserved) {
+			sanitized = "_" + sanitized
+			break
+		}
+	}
+
+	return sanitized, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+const (
+	// DefaultImage is the official container image of strimzi-backup
+	DefaultImage = "ghcr.io/scholzj/strimzi-backup:main"
+
+	// nonRootUser is the user of the distroless base image used by the official container image
+	nonRootUser = 65532
+
+	workDirectory          = "/backups"
+	tmpDirectory           = "/tmp"
+	serviceAccountPath     = "/var/run/secrets/kubernetes.io/serviceaccount"
+	serviceAccountTokenTTL = 3600
+)
+
+// JobGenerator generates the Kubernetes Job running strimzi-backup inside the Kubernetes cluster
+type JobGenerator struct {
+	Name               string
+	Namespace          string
+	Image              string
+	ServiceAccountName string
+	PersistentVolume   string
+	TTL                int32
+}
+
+func NewJobGenerator(cmd *cobra.Command) (*JobGenerator, error) {
+	name := cmd.Flag("job-name").Value.String()
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		slog.Error("Invalid --job-name option", "name", name, "errors", errs)
+		return nil, fmt.Errorf("invalid --job-name option %s: %s", name, strings.Join(errs, ", "))
+	}
+
+	ttl, err := cmd.Flags().GetInt32("ttl")
+	if err != nil {
+		slog.Error("Failed to get the --ttl flag", "error", err)
+		return nil, err
+	}
+
+	generator := JobGenerator{
+		Name:               name,
+		Namespace:          cmd.Flag("namespace").Value.String(),
+		Image:              cmd.Flag("image").Value.String(),
+		ServiceAccountName: cmd.Flag("service-account").Value.String(),
+		PersistentVolume:   cmd.Flag("persistent-volume-claim").Value.String(),
+		TTL:                ttl,
+	}
+
+	return &generator, nil
+}
+
+// Generate writes the YAML of the Job running strimzi-backup with the given arguments to the writer
+func (g *JobGenerator) Generate(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		slog.Error("The strimzi-backup command which should be run by the Job is required")
+		return fmt.Errorf("the strimzi-backup command which should be run by the Job is required")
+	}
+
+	jobYaml, err := yaml.Marshal(g.job(args))
+	if err != nil {
+		slog.Error("Failed to marshal the Job to YAML", "error", err)
+		return err
+	}
+
+	_, err = w.Write(jobYaml)
+	return err
+}
+
+// job builds the Job. The container runs as a non-root user with a read-only root filesystem and without any
+// capabilities. The backups are written into the /backups working directory, which is either an emptyDir volume or the
+// PersistentVolumeClaim from the --persistent-volume-claim option. The token of the service account is projected with
+// a limited lifetime instead of using the automatically mounted token.
+func (g *JobGenerator) job(args []string) *batchv1.Job {
+	labels := map[string]string{"app.kubernetes.io/name": "strimzi-backup", "app.kubernetes.io/instance": g.Name}
+
+	workVolume := v1.Volume{Name: "backups", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}
+	if g.PersistentVolume != "" {
+		workVolume.VolumeSource = v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: g.PersistentVolume}}
+	}
+
+	job := batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{Name: g.Name, Namespace: g.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			// The restore is checkpointed and resumed when the Pod is restarted, the backup starts from scratch
+			BackoffLimit: ptr.To(int32(3)),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					RestartPolicy:                v1.RestartPolicyNever,
+					ServiceAccountName:           g.ServiceAccountName,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &v1.PodSecurityContext{
+						RunAsNonRoot:   ptr.To(true),
+						RunAsUser:      ptr.To(int64(nonRootUser)),
+						RunAsGroup:     ptr.To(int64(nonRootUser)),
+						FSGroup:        ptr.To(int64(nonRootUser)),
+						SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []v1.Container{
+						{
+							Name:       "strimzi-backup",
+							Image:      g.Image,
+							Command:    []string{"/strimzi-backup"},
+							Args:       args,
+							WorkingDir: workDirectory,
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+							},
+							SecurityContext: &v1.SecurityContext{
+								ReadOnlyRootFilesystem:   ptr.To(true),
+								AllowPrivilegeEscalation: ptr.To(false),
+								Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "backups", MountPath: workDirectory},
+								{Name: "tmp", MountPath: tmpDirectory},
+								{Name: "service-account-token", MountPath: serviceAccountPath, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						workVolume,
+						{Name: "tmp", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+						{Name: "service-account-token", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{
+							Sources: []v1.VolumeProjection{
+								{ServiceAccountToken: &v1.ServiceAccountTokenProjection{Path: "token", ExpirationSeconds: ptr.To(int64(serviceAccountTokenTTL))}},
+								{ConfigMap: &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "kube-root-ca.crt"}, Items: []v1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}}}},
+								{DownwardAPI: &v1.DownwardAPIProjection{Items: []v1.DownwardAPIVolumeFile{{Path: "namespace", FieldRef: &v1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"}}}}},
+							},
+						}}},
+					},
+				},
+			},
+		},
+	}
+
+	if g.TTL > 0 {
+		job.Spec.TTLSecondsAfterFinished = ptr.To(g.TTL)
+	}
+
+	return &job
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/spf13/cobra"
+	"io"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strings"
+)
+
+const (
+	// RbacModeBackup generates the Role allowing to only read the resources needed by the backup commands
+	RbacModeBackup = "backup"
+	// RbacModeRestore generates the Role allowing to restore the resources with the restore commands
+	RbacModeRestore = "restore"
+)
+
+// rbacModes are the supported values of the --mode option
+var rbacModes = []string{RbacModeBackup, RbacModeRestore}
+
+// RbacGenerator generates the Role and RoleBinding with the rights needed by the backup or restore commands of
+// strimzi-backup
+type RbacGenerator struct {
+	Mode               string
+	Name               string
+	Namespace          string
+	ServiceAccountName string
+}
+
+func NewRbacGenerator(cmd *cobra.Command) (*RbacGenerator, error) {
+	mode := cmd.Flag("mode").Value.String()
+	if !slices.Contains(rbacModes, mode) {
+		slog.Error("Unsupported mode in the --mode option", "mode", mode)
+		return nil, fmt.Errorf("unsupported mode %s in the --mode option (supported are %s)", mode, strings.Join(rbacModes, ", "))
+	}
+
+	name := cmd.Flag("role-name").Value.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		slog.Error("Invalid --role-name option", "name", name, "errors", errs)
+		return nil, fmt.Errorf("invalid --role-name option %s: %s", name, strings.Join(errs, ", "))
+	}
+
+	generator := RbacGenerator{
+		Mode:               mode,
+		Name:               name,
+		Namespace:          cmd.Flag("namespace").Value.String(),
+		ServiceAccountName: cmd.Flag("service-account").Value.String(),
+	}
+
+	return &generator, nil
+}
+
+// Generate writes the YAML of the Role and of the RoleBinding binding it to the service account to the writer
+func (g *RbacGenerator) Generate(w io.Writer) error {
+	rules := backuper.BackupRules()
+	if g.Mode == RbacModeRestore {
+		rules = restorer.RestoreRules()
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": "strimzi-backup", "app.kubernetes.io/instance": g.Name}
+
+	role := rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+		ObjectMeta: metav1.ObjectMeta{Name: g.Name, Namespace: g.Namespace, Labels: labels},
+		Rules:      rules,
+	}
+
+	roleBinding := rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: g.Name, Namespace: g.Namespace, Labels: labels},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: g.ServiceAccountName, Namespace: g.Namespace}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: g.Name},
+	}
+
+	for i, resource := range []any{role, roleBinding} {
+		resourceYaml, err := yaml.Marshal(resource)
+		if err != nil {
+			slog.Error("Failed to marshal the RBAC resources to YAML", "error", err)
+			return err
+		}
+
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.Write(resourceYaml); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
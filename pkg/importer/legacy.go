@@ -0,0 +1,355 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+// LegacyImporter converts the backups created by the older shell-script based Strimzi backup tools (such as the Strimzi
+// cold backup script) into the strimzi-backup format. These backups are directories or ZIP / TAR archives with the
+// resources exported by kubectl as YAML files. The layout of the files does not matter, as the resources are identified
+// by their kind and labels.
+type LegacyImporter struct {
+	Source         string
+	OutputFileName string
+	Name           string
+	resources      []unstructured.Unstructured
+}
+
+func NewLegacyImporter(cmd *cobra.Command) (*LegacyImporter, error) {
+	source := cmd.Flag("source").Value.String()
+	if source == "" {
+		slog.Error("--source option is required")
+		return nil, fmt.Errorf("--source option is required")
+	}
+
+	outputFileName := cmd.Flag("output").Value.String()
+	if outputFileName == "" {
+		slog.Error("--output option is required")
+		return nil, fmt.Errorf("--output option is required")
+	}
+
+	importer := LegacyImporter{
+		Source:         source,
+		OutputFileName: outputFileName,
+		Name:           cmd.Flag("name").Value.String(),
+	}
+
+	return &importer, nil
+}
+
+// Import reads the resources from the legacy backup and writes the resources belonging to the Kafka cluster into a new
+// backup file
+func (i *LegacyImporter) Import() error {
+	if err := i.readSource(); err != nil {
+		slog.Error("Failed to read the legacy backup", "error", err, "source", i.Source)
+		return err
+	}
+
+	kafka, err := i.findKafka()
+	if err != nil {
+		return err
+	}
+
+	var streams []archive.Stream
+	now := time.Now()
+
+	addStream := func(name string, comment string, resource any) error {
+		data, err := yaml.Marshal(resource)
+		if err != nil {
+			slog.Error("Failed to marshal the resources to YAML", "stream", name, "error", err)
+			return err
+		}
+
+		streams = append(streams, archive.Stream{Name: name, Comment: comment, ModTime: now, Data: data})
+		return nil
+	}
+
+	utils.CleanseMetadata(&kafka.ObjectMeta)
+	utils.CleanseAnnotations(&kafka.ObjectMeta, utils.DefaultPreservedAnnotations)
+	if err := addStream(backuper.KafkaFilename, backuper.StreamDescription(backuper.KafkaFilename), kafka); err != nil {
+		return err
+	}
+
+	nodePools := &v1beta2.KafkaNodePoolList{}
+	caSecrets := &v1.SecretList{}
+	topics := &v1beta2.KafkaTopicList{}
+	users := &v1beta2.KafkaUserList{}
+	userSecrets := &v1.SecretList{}
+
+	for _, resource := range i.resources {
+		if resource.GetLabels()["strimzi.io/cluster"] != kafka.Name {
+			continue
+		}
+
+		var err error
+		switch resource.GetKind() {
+		case "KafkaNodePool":
+			nodePools.Items, err = appendConverted(nodePools.Items, resource)
+		case "KafkaTopic":
+			topics.Items, err = appendConverted(topics.Items, resource)
+		case "KafkaUser":
+			users.Items, err = appendConverted(users.Items, resource)
+		case "Secret":
+			if resource.GetLabels()["strimzi.io/component-type"] == "certificate-authority" {
+				caSecrets.Items, err = appendConverted(caSecrets.Items, resource)
+			} else if resource.GetLabels()["strimzi.io/kind"] == "KafkaUser" {
+				userSecrets.Items, err = appendConverted(userSecrets.Items, resource)
+			} else {
+				slog.Debug("Skipping Secret which is not a CA or a KafkaUser Secret", "name", resource.GetName())
+			}
+		default:
+			slog.Debug("Skipping unsupported resource", "kind", resource.GetKind(), "name", resource.GetName())
+		}
+
+		if err != nil {
+			slog.Error("Failed to convert the resource", "kind", resource.GetKind(), "name", resource.GetName(), "error", err)
+			return err
+		}
+	}
+
+	for j := range nodePools.Items {
+		utils.CleanseMetadata(&nodePools.Items[j].ObjectMeta)
+	}
+	for j := range caSecrets.Items {
+		utils.CleanseMetadata(&caSecrets.Items[j].ObjectMeta)
+	}
+	for j := range topics.Items {
+		utils.CleanseMetadata(&topics.Items[j].ObjectMeta)
+	}
+	for j := range users.Items {
+		utils.CleanseMetadata(&users.Items[j].ObjectMeta)
+	}
+	for j := range userSecrets.Items {
+		utils.CleanseMetadata(&userSecrets.Items[j].ObjectMeta)
+	}
+
+	slog.Info("Found resources belonging to the Kafka cluster", "name", kafka.Name, "nodePools", len(nodePools.Items), "caSecrets", len(caSecrets.Items), "topics", len(topics.Items), "users", len(users.Items), "userSecrets", len(userSecrets.Items))
+
+	if err := addStream(backuper.KafkaNodePoolsFilename, backuper.StreamDescription(backuper.KafkaNodePoolsFilename), nodePools); err != nil {
+		return err
+	}
+	if err := addStream(backuper.CaSecretsFilename, backuper.StreamDescription(backuper.CaSecretsFilename), caSecrets); err != nil {
+		return err
+	}
+	if err := addStream(backuper.KafkaTopicsFilename, backuper.StreamDescription(backuper.KafkaTopicsFilename), topics); err != nil {
+		return err
+	}
+	if err := addStream(backuper.KafkaUsersFilename, backuper.StreamDescription(backuper.KafkaUsersFilename), users); err != nil {
+		return err
+	}
+	if err := addStream(backuper.KafkaUserSecretsFilename, backuper.StreamDescription(backuper.KafkaUserSecretsFilename), userSecrets); err != nil {
+		return err
+	}
+
+	if err := archive.WriteStreams(i.OutputFileName, streams); err != nil {
+		slog.Error("Failed to write the backup", "error", err, "file", i.OutputFileName)
+		return err
+	}
+
+	return nil
+}
+
+// findKafka finds the Kafka cluster to import. When no name is specified, the legacy backup has to contain exactly one
+// Kafka cluster.
+func (i *LegacyImporter) findKafka() (*v1beta2.Kafka, error) {
+	var kafkas []unstructured.Unstructured
+	for _, resource := range i.resources {
+		if resource.GetKind() == "Kafka" && (i.Name == "" || resource.GetName() == i.Name) {
+			kafkas = append(kafkas, resource)
+		}
+	}
+
+	if len(kafkas) == 0 {
+		slog.Error("No Kafka cluster found in the legacy backup", "source", i.Source, "name", i.Name)
+		return nil, fmt.Errorf("no Kafka cluster found in the legacy backup %s", i.Source)
+	} else if len(kafkas) > 1 {
+		slog.Error("Multiple Kafka clusters found in the legacy backup, use the --name option to select one of them", "source", i.Source)
+		return nil, fmt.Errorf("multiple Kafka clusters found in the legacy backup %s", i.Source)
+	}
+
+	kafka := &v1beta2.Kafka{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(kafkas[0].Object, kafka); err != nil {
+		slog.Error("Failed to convert the Kafka resource", "name", kafkas[0].GetName(), "error", err)
+		return nil, err
+	}
+
+	return kafka, nil
+}
+
+// appendConverted converts the resource to its typed form and appends it to the list
+func appendConverted[T any](items []T, resource unstructured.Unstructured) ([]T, error) {
+	var item T
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, &item); err != nil {
+		return items, err
+	}
+
+	return append(items, item), nil
+}
+
+// readSource reads the resources from all YAML and JSON files in the legacy backup
+func (i *LegacyImporter) readSource() error {
+	info, err := os.Stat(i.Source)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return filepath.WalkDir(i.Source, func(path string, entry os.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !isResourceFile(path) {
+				return err
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			return i.parse(path, data)
+		})
+	case strings.HasSuffix(i.Source, ".zip"):
+		reader, err := zip.OpenReader(i.Source)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		for _, file := range reader.File {
+			if file.FileInfo().IsDir() || !isResourceFile(file.Name) {
+				continue
+			}
+
+			content, err := file.Open()
+			if err != nil {
+				return err
+			}
+
+			data, err := io.ReadAll(content)
+			_ = content.Close()
+			if err != nil {
+				return err
+			}
+
+			if err := i.parse(file.Name, data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case strings.HasSuffix(i.Source, ".tar.gz") || strings.HasSuffix(i.Source, ".tgz") || strings.HasSuffix(i.Source, ".tar"):
+		file, err := os.Open(i.Source)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		var reader io.Reader = file
+		if !strings.HasSuffix(i.Source, ".tar") {
+			gzipReader, err := gzip.NewReader(file)
+			if err != nil {
+				return err
+			}
+			defer gzipReader.Close()
+
+			reader = gzipReader
+		}
+
+		tarReader := tar.NewReader(reader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if header.Typeflag != tar.TypeReg || !isResourceFile(header.Name) {
+				continue
+			}
+
+			data, err := io.ReadAll(tarReader)
+			if err != nil {
+				return err
+			}
+
+			if err := i.parse(header.Name, data); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported legacy backup %s (supported are directories and .zip, .tar, .tar.gz, and .tgz archives)", i.Source)
+	}
+}
+
+// parse parses all resources from the (possibly multi-document) YAML or JSON file. Lists are expanded into their
+// items.
+func (i *LegacyImporter) parse(fileName string, data []byte) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	for {
+		resource := unstructured.Unstructured{}
+		if err := decoder.Decode(&resource.Object); err == io.EOF {
+			return nil
+		} else if err != nil {
+			slog.Warn("Skipping file which does not contain valid Kubernetes resources", "file", fileName, "error", err)
+			return nil
+		}
+
+		if resource.Object == nil {
+			continue
+		}
+
+		if resource.IsList() {
+			list, err := resource.ToList()
+			if err != nil {
+				slog.Warn("Skipping invalid list of resources", "file", fileName, "error", err)
+				continue
+			}
+
+			i.resources = append(i.resources, list.Items...)
+		} else if resource.GetKind() != "" {
+			i.resources = append(i.resources, resource)
+		}
+	}
+}
+
+func isResourceFile(fileName string) bool {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	return extension == ".yaml" || extension == ".yml" || extension == ".json"
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspector
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/spf13/cobra"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OutputText       = "text"
+	OutputPrometheus = "prometheus"
+)
+
+type Inspector struct {
+	BackupFileName string
+	Output         string
+}
+
+func NewInspector(cmd *cobra.Command) (*Inspector, error) {
+	backupFileName := cmd.Flag("filename").Value.String()
+
+	output := cmd.Flag("output").Value.String()
+	if output != OutputText && output != OutputPrometheus {
+		slog.Error("Unsupported value of the --output option", "output", output)
+		return nil, fmt.Errorf("unsupported value %s of the --output option (supported values are %s and %s)", output, OutputText, OutputPrometheus)
+	}
+
+	inspector := Inspector{
+		BackupFileName: backupFileName,
+		Output:         output,
+	}
+
+	return &inspector, nil
+}
+
+// Inspect writes the composition of the backup to the writer. It uses the manifest from the backup when available.
+// For backups without a manifest, the statistics are calculated from the backup itself.
+func (i *Inspector) Inspect(w io.Writer) error {
+	streams, err := archive.ReadStreams(i.BackupFileName)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", i.BackupFileName)
+		return err
+	}
+
+	manifest, err := archive.FindManifest(streams)
+	if err != nil {
+		return err
+	}
+
+	if manifest == nil {
+		slog.Warn("The backup does not contain any manifest. Compressed sizes and durations are not available.", "file", i.BackupFileName)
+		manifest = &archive.Manifest{}
+
+		for _, stream := range streams {
+			manifest.Streams = append(manifest.Streams, archive.StreamStats{
+				Name:              stream.Name,
+				Resources:         archive.CountResources(stream.Data),
+				UncompressedBytes: int64(len(stream.Data)),
+				Encrypted:         archive.IsEncrypted(stream.Data),
+			})
+		}
+	}
+
+	if i.Output == OutputPrometheus {
+		return archive.WritePrometheusMetrics(w, manifest)
+	}
+
+	return writeText(w, manifest)
+}
+
+func writeText(w io.Writer, manifest *archive.Manifest) error {
+	if manifest.Name != "" {
+		if _, err := fmt.Fprintf(w, "Kafka cluster:  %s/%s\nCreated at:     %s\nCreated by:     strimzi-backup %s\n", manifest.Namespace, manifest.Name, manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"), manifest.Version); err != nil {
+			return err
+		}
+
+		if manifest.OperatorVersion != "" {
+			if _, err := fmt.Fprintf(w, "Operator:       Strimzi %s\n", manifest.OperatorVersion); err != nil {
+				return err
+			}
+		}
+
+		if len(manifest.Clusters) > 0 {
+			var clusters []string
+			for _, cluster := range manifest.Clusters {
+				clusters = append(clusters, cluster.Name)
+			}
+
+			if _, err := fmt.Fprintf(w, "Clusters:       %s\n", strings.Join(clusters, ", ")); err != nil {
+				return err
+			}
+		}
+
+		if manifest.HMAC != "" {
+			if _, err := fmt.Fprintf(w, "Signed:         HMAC-SHA256\n"); err != nil {
+				return err
+			}
+		}
+
+		if manifest.Usage != nil {
+			if _, err := fmt.Fprintf(w, "Duration:       %dms\n", manifest.Usage.DurationMillis); err != nil {
+				return err
+			}
+		}
+
+		var encrypted []string
+		for _, stream := range manifest.Streams {
+			if stream.Encrypted {
+				encrypted = append(encrypted, stream.Name)
+			}
+		}
+
+		if len(encrypted) > 0 {
+			if _, err := fmt.Fprintf(w, "Encrypted:      %s\n", strings.Join(encrypted, ", ")); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STREAM\tRESOURCES\tUNCOMPRESSED\tCOMPRESSED\tDURATION")
+
+	for _, stream := range manifest.Streams {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%dms\n", stream.Name, stream.Resources, stream.UncompressedBytes, stream.CompressedBytes, stream.DurationMillis)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(manifest.Findings) > 0 {
+		if _, err := fmt.Fprintf(w, "\nLint findings:\n"); err != nil {
+			return err
+		}
+
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SEVERITY\tKIND\tNAME\tMESSAGE")
+
+		for _, finding := range manifest.Findings {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", finding.Severity, finding.Kind, finding.Name, finding.Message)
+		}
+
+		return tw.Flush()
+	}
+
+	return nil
+}
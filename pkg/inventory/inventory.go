@@ -0,0 +1,361 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OutputMarkdown = "md"
+	OutputHtml     = "html"
+
+	// certificateExpirationWarning is how long before their expiration the certificates are reported as expiring
+	certificateExpirationWarning = 30 * 24 * time.Hour
+)
+
+// Inventory is the inventory of the Kafka cluster stored in the backup intended for audits and documentation
+type Inventory struct {
+	FileName     string
+	CreatedAt    time.Time
+	Cluster      Cluster
+	NodePools    []NodePool
+	Topics       []Topic
+	Users        []User
+	Certificates []Certificate
+	Encrypted    []string
+}
+
+// Cluster describes the Kafka cluster
+type Cluster struct {
+	Name            string
+	Namespace       string
+	ClusterId       string
+	KafkaVersion    string
+	MetadataVersion string
+	Listeners       []string
+}
+
+// NodePool describes a Kafka Node Pool
+type NodePool struct {
+	Name     string
+	Replicas int32
+	Roles    string
+	Storage  string
+}
+
+// Topic describes a Kafka topic
+type Topic struct {
+	Name       string
+	Partitions string
+	Replicas   string
+}
+
+// User describes a Kafka user
+type User struct {
+	Name           string
+	Authentication string
+	Authorization  string
+	Acls           int
+}
+
+// Certificate describes a CA or user certificate and its expiration
+type Certificate struct {
+	Secret   string
+	Subject  string
+	NotAfter time.Time
+	Status   string
+}
+
+type Reporter struct {
+	BackupFileName string
+	Output         string
+	limits         *archive.Limits
+}
+
+func NewReporter(cmd *cobra.Command) (*Reporter, error) {
+	output := cmd.Flag("output").Value.String()
+	if output != OutputMarkdown && output != OutputHtml {
+		slog.Error("Unsupported value of the --output option", "output", output)
+		return nil, fmt.Errorf("unsupported value %s of the --output option (supported values are %s and %s)", output, OutputMarkdown, OutputHtml)
+	}
+
+	limits, err := archive.NewLimitsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter := Reporter{
+		BackupFileName: cmd.Flag("filename").Value.String(),
+		Output:         output,
+		limits:         limits,
+	}
+
+	return &reporter, nil
+}
+
+// Report writes the inventory report of the backup to the writer. The report is generated only from the backup, so it
+// does not need access to the Kubernetes cluster.
+func (r *Reporter) Report(w io.Writer) error {
+	inventory, err := r.collect()
+	if err != nil {
+		return err
+	}
+
+	if r.Output == OutputHtml {
+		return writeHtml(w, inventory)
+	}
+
+	return writeMarkdown(w, inventory)
+}
+
+// collect reads the resources from the backup and collects the inventory
+func (r *Reporter) collect() (*Inventory, error) {
+	streams, err := archive.ReadLimitedStreams(r.BackupFileName, r.limits)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", r.BackupFileName)
+		return nil, err
+	}
+
+	inventory := Inventory{FileName: filepath.Base(r.BackupFileName)}
+
+	manifest, err := archive.FindManifest(streams)
+	if err != nil {
+		return nil, err
+	} else if manifest != nil {
+		inventory.CreatedAt = manifest.CreatedAt
+		inventory.Cluster.Name = manifest.Name
+		inventory.Cluster.Namespace = manifest.Namespace
+	}
+
+	for _, stream := range streams {
+		if archive.IsEncrypted(stream.Data) {
+			slog.Warn("The stream is encrypted and is not included in the report", "stream", stream.Name)
+			inventory.Encrypted = append(inventory.Encrypted, stream.Name)
+			continue
+		}
+
+		switch stream.Name {
+		case backuper.KafkaFilename:
+			err = collectKafka(&inventory, stream.Data)
+		case backuper.KafkaNodePoolsFilename:
+			err = collectNodePools(&inventory, stream.Data)
+		case backuper.KafkaTopicsFilename:
+			err = collectTopics(&inventory, stream.Data)
+		case backuper.KafkaUsersFilename:
+			err = collectUsers(&inventory, stream.Data)
+		case backuper.CaSecretsFilename:
+			err = collectCertificates(&inventory, stream.Data, "ca.crt")
+		case backuper.KafkaUserSecretsFilename:
+			err = collectCertificates(&inventory, stream.Data, "user.crt")
+		}
+
+		if err != nil {
+			slog.Error("Failed to read the resources from the backup", "stream", stream.Name, "error", err)
+			return nil, err
+		}
+	}
+
+	if inventory.Cluster.Name == "" {
+		slog.Error("The backup does not contain any Kafka cluster", "file", r.BackupFileName)
+		return nil, fmt.Errorf("the backup %s does not contain any Kafka cluster", r.BackupFileName)
+	}
+
+	sort.Slice(inventory.NodePools, func(i, j int) bool { return inventory.NodePools[i].Name < inventory.NodePools[j].Name })
+	sort.Slice(inventory.Topics, func(i, j int) bool { return inventory.Topics[i].Name < inventory.Topics[j].Name })
+	sort.Slice(inventory.Users, func(i, j int) bool { return inventory.Users[i].Name < inventory.Users[j].Name })
+	sort.Slice(inventory.Certificates, func(i, j int) bool {
+		return inventory.Certificates[i].NotAfter.Before(inventory.Certificates[j].NotAfter)
+	})
+
+	return &inventory, nil
+}
+
+func collectKafka(inventory *Inventory, data []byte) error {
+	var kafka v1beta2.Kafka
+	if err := yaml.Unmarshal(data, &kafka); err != nil {
+		return err
+	}
+
+	inventory.Cluster.Name = kafka.Name
+	if kafka.Namespace != "" {
+		inventory.Cluster.Namespace = kafka.Namespace
+	}
+
+	if kafka.Status != nil {
+		inventory.Cluster.ClusterId = kafka.Status.ClusterId
+		inventory.Cluster.KafkaVersion = kafka.Status.KafkaVersion
+		inventory.Cluster.MetadataVersion = kafka.Status.KafkaMetadataVersion
+	}
+
+	if kafka.Spec == nil || kafka.Spec.Kafka == nil {
+		return nil
+	}
+
+	if kafka.Spec.Kafka.Version != "" {
+		inventory.Cluster.KafkaVersion = kafka.Spec.Kafka.Version
+	}
+	if kafka.Spec.Kafka.MetadataVersion != "" {
+		inventory.Cluster.MetadataVersion = kafka.Spec.Kafka.MetadataVersion
+	}
+
+	for _, listener := range kafka.Spec.Kafka.Listeners {
+		authentication := "none"
+		if listener.Authentication != nil {
+			authentication = string(listener.Authentication.Type)
+		}
+
+		inventory.Cluster.Listeners = append(inventory.Cluster.Listeners, fmt.Sprintf("%s (port %d, type %s, TLS %t, authentication %s)", listener.Name, listener.Port, listener.Type, listener.Tls, authentication))
+	}
+
+	return nil
+}
+
+func collectNodePools(inventory *Inventory, data []byte) error {
+	var nodePools v1beta2.KafkaNodePoolList
+	if err := yaml.Unmarshal(data, &nodePools); err != nil {
+		return err
+	}
+
+	for _, nodePool := range nodePools.Items {
+		if nodePool.Spec == nil {
+			continue
+		}
+
+		var roles []string
+		for _, role := range nodePool.Spec.Roles {
+			roles = append(roles, string(role))
+		}
+
+		storage := ""
+		if nodePool.Spec.Storage != nil {
+			storage = string(nodePool.Spec.Storage.Type)
+			if nodePool.Spec.Storage.Size != "" {
+				storage += " " + nodePool.Spec.Storage.Size
+			}
+			for _, volume := range nodePool.Spec.Storage.Volumes {
+				storage += fmt.Sprintf(" [%s %s]", volume.Type, volume.Size)
+			}
+		}
+
+		inventory.NodePools = append(inventory.NodePools, NodePool{Name: nodePool.Name, Replicas: nodePool.Spec.Replicas, Roles: strings.Join(roles, ", "), Storage: storage})
+	}
+
+	return nil
+}
+
+func collectTopics(inventory *Inventory, data []byte) error {
+	var topics v1beta2.KafkaTopicList
+	if err := yaml.Unmarshal(data, &topics); err != nil {
+		return err
+	}
+
+	for _, topic := range topics.Items {
+		name := utils.KafkaTopicName(&topic)
+		partitions := "default"
+		replicas := "default"
+
+		if topic.Spec != nil {
+			if topic.Spec.Partitions > 0 {
+				partitions = fmt.Sprint(topic.Spec.Partitions)
+			}
+			if topic.Spec.Replicas > 0 {
+				replicas = fmt.Sprint(topic.Spec.Replicas)
+			}
+		}
+
+		inventory.Topics = append(inventory.Topics, Topic{Name: name, Partitions: partitions, Replicas: replicas})
+	}
+
+	return nil
+}
+
+func collectUsers(inventory *Inventory, data []byte) error {
+	var users v1beta2.KafkaUserList
+	if err := yaml.Unmarshal(data, &users); err != nil {
+		return err
+	}
+
+	for _, user := range users.Items {
+		inventoryUser := User{Name: user.Name, Authentication: "none", Authorization: "none"}
+
+		if user.Spec != nil && user.Spec.Authentication != nil {
+			inventoryUser.Authentication = string(user.Spec.Authentication.Type)
+		}
+		if user.Spec != nil && user.Spec.Authorization != nil {
+			inventoryUser.Authorization = string(user.Spec.Authorization.Type)
+			inventoryUser.Acls = len(user.Spec.Authorization.Acls)
+		}
+
+		inventory.Users = append(inventory.Users, inventoryUser)
+	}
+
+	return nil
+}
+
+// collectCertificates reads the expiration of the certificates stored in the Secrets under the key
+func collectCertificates(inventory *Inventory, data []byte, key string) error {
+	var secrets v1.SecretList
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		certificateData, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+
+		block, _ := pem.Decode(certificateData)
+		if block == nil {
+			slog.Warn("Failed to decode the certificate", "secret", secret.Name)
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			slog.Warn("Failed to parse the certificate", "secret", secret.Name, "error", err)
+			continue
+		}
+
+		status := "valid"
+		if time.Now().After(certificate.NotAfter) {
+			status = "expired"
+		} else if time.Now().Add(certificateExpirationWarning).After(certificate.NotAfter) {
+			status = "expires soon"
+		}
+
+		inventory.Certificates = append(inventory.Certificates, Certificate{Secret: secret.Name, Subject: certificate.Subject.CommonName, NotAfter: certificate.NotAfter, Status: status})
+	}
+
+	return nil
+}
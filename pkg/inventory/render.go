@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// htmlTemplate is the template of the HTML report. It uses only inline styles, so that the report can be attached to
+// audit tickets or sent by e-mail as a single file.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"date": formatDate}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Inventory of the Kafka cluster {{ .Cluster.Namespace }}/{{ .Cluster.Name }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #eee; }
+.expired { color: #b00; font-weight: bold; }
+.expires-soon { color: #b60; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Inventory of the Kafka cluster {{ .Cluster.Namespace }}/{{ .Cluster.Name }}</h1>
+<p>Generated from the backup <code>{{ .FileName }}</code>{{ if not .CreatedAt.IsZero }} taken at {{ date .CreatedAt }}{{ end }}.</p>
+{{- if .Encrypted }}
+<p>The following streams are encrypted and are not included in the report: {{ range $i, $name := .Encrypted }}{{ if $i }}, {{ end }}<code>{{ $name }}</code>{{ end }}</p>
+{{- end }}
+
+<h2>Kafka cluster</h2>
+<table>
+<tr><th>Name</th><td>{{ .Cluster.Name }}</td></tr>
+<tr><th>Namespace</th><td>{{ .Cluster.Namespace }}</td></tr>
+<tr><th>Cluster ID</th><td>{{ .Cluster.ClusterId }}</td></tr>
+<tr><th>Kafka version</th><td>{{ .Cluster.KafkaVersion }}</td></tr>
+<tr><th>Metadata version</th><td>{{ .Cluster.MetadataVersion }}</td></tr>
+<tr><th>Listeners</th><td>{{ range $i, $listener := .Cluster.Listeners }}{{ if $i }}<br>{{ end }}{{ $listener }}{{ end }}</td></tr>
+</table>
+
+<h2>Node pools ({{ len .NodePools }})</h2>
+{{- if .NodePools }}
+<table>
+<tr><th>Name</th><th>Replicas</th><th>Roles</th><th>Storage</th></tr>
+{{- range .NodePools }}
+<tr><td>{{ .Name }}</td><td>{{ .Replicas }}</td><td>{{ .Roles }}</td><td>{{ .Storage }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>No node pools.</p>
+{{- end }}
+
+<h2>Topics ({{ len .Topics }})</h2>
+{{- if .Topics }}
+<table>
+<tr><th>Name</th><th>Partitions</th><th>Replication factor</th></tr>
+{{- range .Topics }}
+<tr><td>{{ .Name }}</td><td>{{ .Partitions }}</td><td>{{ .Replicas }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>No topics.</p>
+{{- end }}
+
+<h2>Users ({{ len .Users }})</h2>
+{{- if .Users }}
+<table>
+<tr><th>Name</th><th>Authentication</th><th>Authorization</th><th>ACL rules</th></tr>
+{{- range .Users }}
+<tr><td>{{ .Name }}</td><td>{{ .Authentication }}</td><td>{{ .Authorization }}</td><td>{{ .Acls }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>No users.</p>
+{{- end }}
+
+<h2>Certificates ({{ len .Certificates }})</h2>
+{{- if .Certificates }}
+<table>
+<tr><th>Secret</th><th>Subject</th><th>Expires</th><th>Status</th></tr>
+{{- range .Certificates }}
+<tr><td>{{ .Secret }}</td><td>{{ .Subject }}</td><td>{{ date .NotAfter }}</td><td class="{{ if eq .Status "expired" }}expired{{ else if eq .Status "expires soon" }}expires-soon{{ end }}">{{ .Status }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>No certificates.</p>
+{{- end }}
+</body>
+</html>
+`))
+
+func writeHtml(w io.Writer, inventory *Inventory) error {
+	return htmlTemplate.Execute(w, inventory)
+}
+
+func writeMarkdown(w io.Writer, inventory *Inventory) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Inventory of the Kafka cluster `%s/%s`\n\n", inventory.Cluster.Namespace, inventory.Cluster.Name))
+	sb.WriteString(fmt.Sprintf("Generated from the backup `%s`", inventory.FileName))
+	if !inventory.CreatedAt.IsZero() {
+		sb.WriteString(" taken at " + formatDate(inventory.CreatedAt))
+	}
+	sb.WriteString(".\n")
+
+	if len(inventory.Encrypted) > 0 {
+		sb.WriteString(fmt.Sprintf("\nThe following streams are encrypted and are not included in the report: `%s`\n", strings.Join(inventory.Encrypted, "`, `")))
+	}
+
+	sb.WriteString("\n## Kafka cluster\n\n")
+	sb.WriteString(fmt.Sprintf("* Name: `%s`\n", inventory.Cluster.Name))
+	sb.WriteString(fmt.Sprintf("* Namespace: `%s`\n", inventory.Cluster.Namespace))
+	sb.WriteString(fmt.Sprintf("* Cluster ID: `%s`\n", inventory.Cluster.ClusterId))
+	sb.WriteString(fmt.Sprintf("* Kafka version: %s\n", inventory.Cluster.KafkaVersion))
+	sb.WriteString(fmt.Sprintf("* Metadata version: %s\n", inventory.Cluster.MetadataVersion))
+	sb.WriteString("* Listeners:\n")
+	for _, listener := range inventory.Cluster.Listeners {
+		sb.WriteString(fmt.Sprintf("  * %s\n", listener))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Node pools (%d)\n\n", len(inventory.NodePools)))
+	if len(inventory.NodePools) == 0 {
+		sb.WriteString("No node pools.\n")
+	} else {
+		sb.WriteString("| Name | Replicas | Roles | Storage |\n|------|----------|-------|---------|\n")
+		for _, nodePool := range inventory.NodePools {
+			sb.WriteString(fmt.Sprintf("| `%s` | %d | %s | %s |\n", nodePool.Name, nodePool.Replicas, nodePool.Roles, nodePool.Storage))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Topics (%d)\n\n", len(inventory.Topics)))
+	if len(inventory.Topics) == 0 {
+		sb.WriteString("No topics.\n")
+	} else {
+		sb.WriteString("| Name | Partitions | Replication factor |\n|------|------------|--------------------|\n")
+		for _, topic := range inventory.Topics {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", topic.Name, topic.Partitions, topic.Replicas))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Users (%d)\n\n", len(inventory.Users)))
+	if len(inventory.Users) == 0 {
+		sb.WriteString("No users.\n")
+	} else {
+		sb.WriteString("| Name | Authentication | Authorization | ACL rules |\n|------|----------------|---------------|-----------|\n")
+		for _, user := range inventory.Users {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %d |\n", user.Name, user.Authentication, user.Authorization, user.Acls))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Certificates (%d)\n\n", len(inventory.Certificates)))
+	if len(inventory.Certificates) == 0 {
+		sb.WriteString("No certificates.\n")
+	} else {
+		sb.WriteString("| Secret | Subject | Expires | Status |\n|--------|---------|---------|--------|\n")
+		for _, certificate := range inventory.Certificates {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", certificate.Secret, certificate.Subject, formatDate(certificate.NotAfter), certificate.Status))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
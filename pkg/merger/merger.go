@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merger
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+type Merger struct {
+	BackupFileNames []string
+	OutputFileName  string
+}
+
+func NewMerger(cmd *cobra.Command, args []string) (*Merger, error) {
+	if len(args) < 2 {
+		slog.Error("At least two backup files are required")
+		return nil, fmt.Errorf("at least two backup files are required")
+	}
+
+	outputFileName := cmd.Flag("output").Value.String()
+	if outputFileName == "" {
+		slog.Error("--output option is required")
+		return nil, fmt.Errorf("--output option is required")
+	}
+
+	merger := Merger{
+		BackupFileNames: args,
+		OutputFileName:  outputFileName,
+	}
+
+	return &merger, nil
+}
+
+// Merge combines the streams from all backup files into a single backup file. When the same stream is present in
+// multiple backups, the stream with the newest modification time is used. The streams keep the order in which they
+// first appeared in the backups.
+func (m *Merger) Merge() error {
+	var merged []archive.Stream
+	index := map[string]int{}
+
+	for _, backupFileName := range m.BackupFileNames {
+		streams, err := archive.ReadStreams(backupFileName)
+		if err != nil {
+			slog.Error("Failed to read the backup", "error", err, "file", backupFileName)
+			return err
+		}
+
+		for _, stream := range streams {
+			if stream.Name == archive.ManifestFilename {
+				// The manifest describes only the original backup and does not apply to the merged backup
+				slog.Info("Skipping the manifest of the original backup", "file", backupFileName)
+				continue
+			}
+
+			if i, ok := index[stream.Name]; !ok {
+				slog.Info("Adding stream", "name", stream.Name, "file", backupFileName, "modTime", stream.ModTime)
+				index[stream.Name] = len(merged)
+				merged = append(merged, stream)
+			} else if stream.ModTime.After(merged[i].ModTime) {
+				slog.Info("Replacing stream with a newer version", "name", stream.Name, "file", backupFileName, "modTime", stream.ModTime, "replacedModTime", merged[i].ModTime)
+				merged[i] = stream
+			} else {
+				slog.Info("Skipping older version of stream", "name", stream.Name, "file", backupFileName, "modTime", stream.ModTime, "keptModTime", merged[i].ModTime)
+			}
+		}
+	}
+
+	if err := archive.WriteStreams(m.OutputFileName, merged); err != nil {
+		slog.Error("Failed to write the merged backup", "error", err, "file", m.OutputFileName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Phase is a single named step of the backup or restore. Tools embedding Strimzi Backup can execute, skip, or insert
+// additional phases instead of running the whole backup or restore at once.
+type Phase interface {
+	// Name returns the unique name of the phase
+	Name() string
+	// Describe returns the human-readable description of what the phase does
+	Describe() string
+	// Run executes the phase
+	Run(ctx context.Context) error
+}
+
+type funcPhase struct {
+	name        string
+	description string
+	run         func(ctx context.Context) error
+}
+
+func (p *funcPhase) Name() string {
+	return p.name
+}
+
+func (p *funcPhase) Describe() string {
+	return p.description
+}
+
+func (p *funcPhase) Run(ctx context.Context) error {
+	return p.run(ctx)
+}
+
+// New creates a phase running the function
+func New(name string, description string, run func(ctx context.Context) error) Phase {
+	return &funcPhase{name: name, description: description, run: run}
+}
+
+// Run executes the phases in order and stops at the first phase which fails. The context is checked before each phase,
+// so a cancelled context stops the execution between the phases.
+func Run(ctx context.Context, phases []Phase) error {
+	for _, phase := range phases {
+		if err := ctx.Err(); err != nil {
+			slog.Error("Stopping before the phase as the context was cancelled", "phase", phase.Name(), "error", err)
+			return err
+		}
+
+		start := time.Now()
+		slog.Debug("Running phase", "phase", phase.Name(), "description", phase.Describe())
+
+		if err := phase.Run(ctx); err != nil {
+			slog.Debug("Phase failed", "phase", phase.Name(), "duration", time.Since(start).String(), "error", err)
+			return err
+		}
+
+		slog.Debug("Phase completed", "phase", phase.Name(), "duration", time.Since(start).String())
+	}
+
+	return nil
+}
+
+// Names returns the names of the phases
+func Names(phases []Phase) []string {
+	names := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		names = append(names, phase.Name())
+	}
+
+	return names
+}
+
+// Skip returns the phases without the phases with the given names
+func Skip(phases []Phase, names ...string) []Phase {
+	return slices.DeleteFunc(slices.Clone(phases), func(phase Phase) bool {
+		return slices.Contains(names, phase.Name())
+	})
+}
+
+// InsertBefore returns the phases with the additional phases inserted before the phase with the given name
+func InsertBefore(phases []Phase, name string, additional ...Phase) ([]Phase, error) {
+	i := slices.IndexFunc(phases, func(phase Phase) bool { return phase.Name() == name })
+	if i < 0 {
+		return nil, fmt.Errorf("phase %s not found", name)
+	}
+
+	return slices.Insert(slices.Clone(phases), i, additional...), nil
+}
+
+// InsertAfter returns the phases with the additional phases inserted after the phase with the given name
+func InsertAfter(phases []Phase, name string, additional ...Phase) ([]Phase, error) {
+	i := slices.IndexFunc(phases, func(phase Phase) bool { return phase.Name() == name })
+	if i < 0 {
+		return nil, fmt.Errorf("phase %s not found", name)
+	}
+
+	return slices.Insert(slices.Clone(phases), i+1, additional...), nil
+}
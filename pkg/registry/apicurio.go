@@ -0,0 +1,218 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// ApicurioRegistryKind is the kind of the custom resource managed by the Apicurio Registry 2.x operator
+	ApicurioRegistryKind = "ApicurioRegistry"
+	// ApicurioRegistry3Kind is the kind of the custom resource managed by the Apicurio Registry 3.x operator
+	ApicurioRegistry3Kind = "ApicurioRegistry3"
+)
+
+var (
+	ApicurioRegistryResource  = schema.GroupVersionResource{Group: "registry.apicur.io", Version: "v1", Resource: "apicurioregistries"}
+	ApicurioRegistry3Resource = schema.GroupVersionResource{Group: "registry.apicur.io", Version: "v1", Resource: "apicurioregistries3"}
+)
+
+// Resource returns the Kubernetes resource of the Apicurio Registry custom resource kind
+func Resource(kind string) (schema.GroupVersionResource, error) {
+	switch kind {
+	case ApicurioRegistryKind:
+		return ApicurioRegistryResource, nil
+	case ApicurioRegistry3Kind:
+		return ApicurioRegistry3Resource, nil
+	default:
+		return schema.GroupVersionResource{}, fmt.Errorf("unsupported Apicurio Registry kind %s", kind)
+	}
+}
+
+// specPaths returns the paths of the storage type, the Kafka bootstrap servers, and the environment variables in the
+// spec of the Apicurio Registry custom resource. They differ between the 2.x and 3.x operators.
+func specPaths(kind string) ([]string, []string, []string) {
+	if kind == ApicurioRegistry3Kind {
+		return []string{"spec", "app", "storage", "type"},
+			[]string{"spec", "app", "storage", "kafkasql", "bootstrapServers"},
+			[]string{"spec", "app", "env"}
+	}
+
+	return []string{"spec", "configuration", "persistence"},
+		[]string{"spec", "configuration", "kafkasql", "bootstrapServers"},
+		[]string{"spec", "configuration", "env"}
+}
+
+// UsesKafkaCluster indicates whether the Apicurio Registry stores its data in the Kafka cluster with the given name and
+// namespace using the KafkaSQL storage
+func UsesKafkaCluster(registry *unstructured.Unstructured, name string, namespace string) bool {
+	storagePath, bootstrapPath, _ := specPaths(registry.GetKind())
+
+	if storage, _, _ := unstructured.NestedString(registry.Object, storagePath...); storage != "kafkasql" {
+		return false
+	}
+
+	bootstrapServers, _, _ := unstructured.NestedString(registry.Object, bootstrapPath...)
+
+	return utils.UsesBootstrapService(bootstrapServers, registry.GetNamespace(), name, namespace)
+}
+
+// UpdateKafkaCluster points the KafkaSQL storage of the Apicurio Registry to the Kafka cluster with a new name or in
+// a new namespace
+func UpdateKafkaCluster(registry *unstructured.Unstructured, oldName string, oldNamespace string, name string, namespace string) error {
+	_, bootstrapPath, _ := specPaths(registry.GetKind())
+
+	bootstrapServers, found, _ := unstructured.NestedString(registry.Object, bootstrapPath...)
+	if !found {
+		return nil
+	}
+
+	bootstrapServers = strings.ReplaceAll(bootstrapServers, oldName+"-kafka-bootstrap."+oldNamespace+".", name+"-kafka-bootstrap."+namespace+".")
+	bootstrapServers = strings.ReplaceAll(bootstrapServers, oldName+"-kafka-bootstrap:", name+"-kafka-bootstrap:")
+
+	return unstructured.SetNestedField(registry.Object, bootstrapServers, bootstrapPath...)
+}
+
+// ConfigMapNames returns the names of the ConfigMaps referenced from the environment variables of the Apicurio Registry
+func ConfigMapNames(registry *unstructured.Unstructured) []string {
+	_, _, envPath := specPaths(registry.GetKind())
+
+	env, _, _ := unstructured.NestedSlice(registry.Object, envPath...)
+
+	var names []string
+	for _, envVar := range env {
+		if envVarMap, ok := envVar.(map[string]interface{}); ok {
+			if name, found, _ := unstructured.NestedString(envVarMap, "valueFrom", "configMapKeyRef", "name"); found && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// DefaultURL returns the URL of the REST API of the Apicurio Registry using the Kubernetes Service created by its
+// operator. It works only from within the Kubernetes cluster.
+func DefaultURL(kind string, name string, namespace string) string {
+	if kind == ApicurioRegistry3Kind {
+		return "http://" + name + "-app-service." + namespace + ".svc:8080"
+	}
+
+	return "http://" + name + "-service." + namespace + ".svc:8080"
+}
+
+// Client exports and imports the artifacts using the admin REST API of the Apicurio Registry
+type Client struct {
+	URL        string
+	APIVersion string
+	Headers    http.Header
+	client     *http.Client
+}
+
+// NewClient creates the Apicurio Registry client. The headers are in the <name>: <value> format. Environment variables
+// in the header values are expanded, so that credentials do not need to be passed on the command line.
+func NewClient(url string, kind string, headers []string) (*Client, error) {
+	parsedHeaders := http.Header{}
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid Apicurio Registry header %q (expected format is <name>: <value>)", header)
+		}
+
+		parsedHeaders.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+
+	apiVersion := "v2"
+	if kind == ApicurioRegistry3Kind {
+		apiVersion = "v3"
+	}
+
+	return &Client{URL: strings.TrimSuffix(url, "/"), APIVersion: apiVersion, Headers: parsedHeaders, client: http.DefaultClient}, nil
+}
+
+// Export downloads the ZIP file with all artifacts, their versions, and the rules from the Apicurio Registry
+func (c *Client) Export(ctx context.Context) ([]byte, error) {
+	response, err := c.do(ctx, http.MethodGet, "/apis/registry/"+c.APIVersion+"/admin/export", nil, map[string]string{"Accept": "application/zip"})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := checkStatus(response); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+// Import uploads the ZIP file exported from the Apicurio Registry. The global and content IDs are preserved, so that
+// the records serialized with the IDs from the original registry can still be deserialized.
+func (c *Client) Import(ctx context.Context, data []byte) error {
+	headers := map[string]string{
+		"Content-Type":                  "application/zip",
+		"X-Registry-Preserve-GlobalId":  "true",
+		"X-Registry-Preserve-ContentId": "true",
+	}
+
+	response, err := c.do(ctx, http.MethodPost, "/apis/registry/"+c.APIVersion+"/admin/import", bytes.NewReader(data), headers)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkStatus(response)
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range c.Headers {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	slog.Debug("Sending Apicurio Registry request", "method", method, "url", request.URL.Redacted())
+
+	return c.client.Do(request)
+}
+
+func checkStatus(response *http.Response) error {
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status %s from %s %s", response.Status, response.Request.Method, response.Request.URL.Redacted())
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// resourceAttributes are the log attributes identifying the resource the log record belongs to
+var resourceAttributes = []string{"kind", "namespace", "name", "stream", "file", "filename", "secret", "user"}
+
+// Entry is a single warning or error collected during the run
+type Entry struct {
+	Time     time.Time
+	Level    slog.Level
+	Message  string
+	Resource string
+	Error    string
+}
+
+// Collector is a log handler which passes all records to the wrapped handler and collects the warnings and errors, so
+// that they can be printed in a report at the end of the run. It is safe to use from multiple goroutines, for example
+// when backing up multiple Kafka clusters in parallel.
+type Collector struct {
+	handler slog.Handler
+	attrs   []slog.Attr
+	entries *entries
+}
+
+type entries struct {
+	lock  sync.Mutex
+	items []Entry
+}
+
+var collector *Collector
+
+// Install wraps the handler of the default logger with the collector
+func Install() *Collector {
+	collector = &Collector{handler: slog.Default().Handler(), entries: &entries{}}
+	slog.SetDefault(slog.New(collector))
+
+	// Setting the default logger redirects the log package into slog. The wrapped default handler writes through the
+	// log package, so it has to be pointed back to the standard error output to avoid a deadlock.
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+
+	return collector
+}
+
+func (c *Collector) Enabled(ctx context.Context, level slog.Level) bool {
+	return c.handler.Enabled(ctx, level)
+}
+
+func (c *Collector) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelWarn {
+		c.collect(record)
+	}
+
+	return c.handler.Handle(ctx, record)
+}
+
+func (c *Collector) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Collector{handler: c.handler.WithAttrs(attrs), attrs: append(append([]slog.Attr{}, c.attrs...), attrs...), entries: c.entries}
+}
+
+func (c *Collector) WithGroup(name string) slog.Handler {
+	return &Collector{handler: c.handler.WithGroup(name), attrs: c.attrs, entries: c.entries}
+}
+
+func (c *Collector) collect(record slog.Record) {
+	values := map[string]string{}
+	errorMessage := ""
+
+	collectAttr := func(attr slog.Attr) bool {
+		if attr.Key == "error" {
+			errorMessage = attr.Value.String()
+		} else {
+			values[attr.Key] = attr.Value.String()
+		}
+
+		return true
+	}
+
+	for _, attr := range c.attrs {
+		collectAttr(attr)
+	}
+	record.Attrs(collectAttr)
+
+	var resource []string
+	for _, key := range resourceAttributes {
+		if value, ok := values[key]; ok && value != "" {
+			resource = append(resource, key+"="+value)
+		}
+	}
+
+	c.entries.lock.Lock()
+	defer c.entries.lock.Unlock()
+
+	c.entries.items = append(c.entries.items, Entry{Time: record.Time, Level: record.Level, Message: record.Message, Resource: strings.Join(resource, " "), Error: errorMessage})
+}
+
+// Entries returns the warnings and errors collected so far
+func (c *Collector) Entries() []Entry {
+	c.entries.lock.Lock()
+	defer c.entries.lock.Unlock()
+
+	return append([]Entry{}, c.entries.items...)
+}
+
+// WriteReport writes the report with all collected warnings and errors. Nothing is written when there are none.
+func (c *Collector) WriteReport(w io.Writer) error {
+	items := c.Entries()
+	if len(items) == 0 {
+		return nil
+	}
+
+	var errors, warnings int
+	for _, item := range items {
+		if item.Level >= slog.LevelError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "\nRun report: %d error(s), %d warning(s)\n\n", errors, warnings); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LEVEL\tRESOURCE\tMESSAGE\tERROR")
+
+	for _, item := range items {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Level, item.Resource, item.Message, item.Error)
+	}
+
+	return tw.Flush()
+}
+
+// Print writes the report of the installed collector to the writer
+func Print(w io.Writer) {
+	if collector == nil {
+		return
+	}
+
+	if err := collector.WriteReport(w); err != nil {
+		slog.Error("Failed to write the run report", "error", err)
+	}
+}
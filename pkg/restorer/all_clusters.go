@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"log/slog"
+)
+
+// restoresAllClusters indicates whether all Kafka clusters from the backup created with the --all-clusters option are restored.
+// The option is available only in the restore kafka command.
+func restoresAllClusters(cmd *cobra.Command) (bool, error) {
+	if cmd.Flags().Lookup("all-clusters") == nil {
+		return false, nil
+	}
+
+	allClusters, err := cmd.Flags().GetBool("all-clusters")
+	if err != nil {
+		slog.Error("Failed to get the --all-clusters flag", "error", err)
+		return false, err
+	}
+
+	return allClusters, nil
+}
+
+// BackupClusters returns the names of the Kafka clusters from the index of the backup created with the --all-clusters
+// option. They are restored one after another with the --all-clusters option of the restore kafka command.
+func BackupClusters(cmd *cobra.Command) ([]string, error) {
+	if cmd.Flag("name").Value.String() != "" || cmd.Flag("source-cluster").Value.String() != "" {
+		slog.Error("--all-clusters option cannot be used together with the --name and --source-cluster options")
+		return nil, fmt.Errorf("--all-clusters option cannot be used together with the --name and --source-cluster options")
+	}
+
+	_, _, _, namespace, err := utils.CreateKubernetesClients(cmd)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes clients", "error", err)
+		return nil, err
+	}
+
+	storageNamespace := namespace
+	if sourceNamespace := cmd.Flag("source-namespace").Value.String(); sourceNamespace != "" {
+		storageNamespace = sourceNamespace
+	}
+
+	backupFileName := cmd.Flag("filename").Value.String()
+	backupFile, err := openBackupFile(cmd, storageNamespace, backuper.AllClustersName, backupFileName)
+	if err != nil {
+		slog.Error("Failed to open file", "error", err, "file", backupFileName)
+		return nil, err
+	}
+	defer backupFile.Close()
+
+	limits, err := archive.NewLimitsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := archive.ReadManifest(backupFile, limits)
+	if err != nil {
+		slog.Error("Failed to read the backup manifest", "error", err, "file", backupFileName)
+		return nil, err
+	} else if manifest == nil || len(manifest.Clusters) == 0 {
+		slog.Error("The backup does not contain multiple Kafka clusters", "file", backupFileName)
+		return nil, fmt.Errorf("the backup %s does not contain multiple Kafka clusters. Use the --name option to restore it", backupFileName)
+	}
+
+	return manifest.ClusterNames(), nil
+}
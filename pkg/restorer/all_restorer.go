@@ -0,0 +1,187 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/spf13/cobra"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// Component is a cluster found in a combined backup which is restored by the restore all command
+type Component struct {
+	Kind     string
+	Name     string
+	Filename string
+	SkipFlag string
+}
+
+// allComponents lists the components restored by the restore all command in the order in which they have to be
+// restored. The Kafka cluster is restored first as the other components connect to it.
+var allComponents = []Component{
+	{Kind: "kafka", Filename: backuper.KafkaFilename, SkipFlag: "skip-kafka"},
+	{Kind: "connect", Filename: backuper.KafkaConnectFilename, SkipFlag: "skip-connect"},
+	{Kind: "mirrormaker2", Filename: backuper.KafkaMirrorMaker2Filename, SkipFlag: "skip-mirrormaker2"},
+	{Kind: "mirrormaker", Filename: backuper.KafkaMirrorMakerFilename, SkipFlag: "skip-mirrormaker"},
+	{Kind: "bridge", Filename: backuper.KafkaBridgeFilename, SkipFlag: "skip-bridge"},
+}
+
+// AllRestorer restores all clusters from a combined backup (created with the backup all command or with the merge
+// command). The Kafka cluster is restored first and the Kafka Connect, Kafka MirrorMaker 2, and Kafka MirrorMaker
+// clusters and the Kafka Bridge are restored only once it is ready. Each cluster is restored by its own restorer, one after another.
+type AllRestorer struct {
+	cmd        *cobra.Command
+	Components []Component
+}
+
+// NewAllRestorer reads the backup to find the clusters it contains and their names
+func NewAllRestorer(cmd *cobra.Command) (*AllRestorer, error) {
+	r, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	names, err := r.componentNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	for _, component := range allComponents {
+		name, ok := names[component.Filename]
+		if !ok {
+			slog.Info("No cluster found in the backup", "kind", component.Kind, "file", r.BackupFileName)
+			continue
+		}
+
+		skip, err := cmd.Flags().GetBool(component.SkipFlag)
+		if err != nil {
+			slog.Error("Failed to get the --"+component.SkipFlag+" flag", "error", err)
+			return nil, err
+		}
+
+		if skip {
+			slog.Info("Skipping the cluster found in the backup", "kind", component.Kind, "name", name)
+			continue
+		}
+
+		if component.Kind == "kafka" {
+			// The Kafka cluster is restored under the name from the --name option
+			name = r.Name
+		}
+
+		component.Name = name
+		components = append(components, component)
+	}
+
+	if len(components) == 0 {
+		slog.Error("No clusters to restore found in the backup", "file", r.BackupFileName)
+		return nil, fmt.Errorf("no clusters to restore found in the backup %s", r.BackupFileName)
+	}
+
+	return &AllRestorer{cmd: cmd, Components: components}, nil
+}
+
+// componentNames returns the names of the clusters found in the backup indexed by the names of the streams with their
+// custom resources
+func (r *Restorer) componentNames() (map[string]string, error) {
+	names := make(map[string]string)
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return nil, err
+		}
+
+		for _, component := range allComponents {
+			if member.Name != component.Filename {
+				continue
+			}
+
+			var resource metav1.PartialObjectMetadata
+			if err := yaml.Unmarshal(member.Data, &resource); err != nil {
+				slog.Error("Failed to unmarshall the resource", "name", member.Name, "error", err)
+				return nil, err
+			}
+
+			names[component.Filename] = resource.Name
+		}
+	}
+
+	return names, nil
+}
+
+// Restore restores the component. The Kafka cluster is restored under the name from the --name option and the other
+// clusters under their names from the backup.
+func (r *AllRestorer) Restore(component Component) error {
+	switch component.Kind {
+	case "kafka":
+		kr, err := NewKafkaRestorer(r.cmd)
+		if err != nil {
+			return err
+		}
+		defer kr.Close()
+
+		return kr.RestoreKafka()
+	case "connect":
+		cr, err := NewConnectRestorer(r.cmd)
+		if err != nil {
+			return err
+		}
+		defer cr.Close()
+
+		cr.Name = component.Name
+		return cr.RestoreConnect()
+	case "mirrormaker2":
+		mr, err := NewMirrorMaker2Restorer(r.cmd)
+		if err != nil {
+			return err
+		}
+		defer mr.Close()
+
+		mr.Name = component.Name
+		return mr.RestoreMirrorMaker2()
+	case "mirrormaker":
+		mr, err := NewMirrorMakerRestorer(r.cmd)
+		if err != nil {
+			return err
+		}
+		defer mr.Close()
+
+		mr.Name = component.Name
+		return mr.RestoreMirrorMaker()
+	case "bridge":
+		br, err := NewBridgeRestorer(r.cmd)
+		if err != nil {
+			return err
+		}
+		defer br.Close()
+
+		br.Name = component.Name
+		return br.RestoreBridge()
+	default:
+		return fmt.Errorf("unknown component %s", component.Kind)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/registry"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+func (r *KafkaRestorer) restoreApicurioRegistryConfigMaps(resources []byte) error {
+	var configMaps *v1.ConfigMapList
+
+	if err := yaml.Unmarshal(resources, &configMaps); err != nil {
+		slog.Error("Failed to unmarshall the ConfigMap resources", "error", err)
+		return err
+	}
+
+	for _, configMap := range configMaps.Items {
+		slog.Info("Restoring ConfigMap", "name", configMap.Name, "namespace", r.Namespace)
+
+		utils.CleanseMetadata(&configMap.ObjectMeta)
+		configMap.Namespace = r.Namespace
+
+		r.markRestored(&configMap.ObjectMeta)
+		configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.CoreV1().ConfigMaps(r.Namespace).Get, "ConfigMap", configMap.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().ConfigMaps(r.Namespace).Patch, configMap.Name, &configMap, r.Force); err != nil {
+			slog.Error("Failed to restore the ConfigMap resource", "name", configMap.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreApicurioRegistries restores the Apicurio Registry custom resources. When the Kafka cluster is restored under a
+// different name or into a different namespace, the KafkaSQL storage of the registries is pointed to the restored
+// cluster.
+func (r *KafkaRestorer) restoreApicurioRegistries(resources []byte) error {
+	var registries *unstructured.UnstructuredList
+
+	if err := yaml.Unmarshal(resources, &registries); err != nil {
+		slog.Error("Failed to unmarshall the Apicurio Registry resources", "error", err)
+		return err
+	}
+
+	for _, item := range registries.Items {
+		slog.Info("Restoring Apicurio Registry", "kind", item.GetKind(), "name", item.GetName(), "namespace", r.Namespace)
+
+		resource, err := registry.Resource(item.GetKind())
+		if err != nil {
+			slog.Error("Failed to restore the Apicurio Registry resource", "kind", item.GetKind(), "name", item.GetName(), "error", err)
+			return err
+		}
+
+		if r.backedUpName != "" && (r.backedUpName != r.Name || r.backedUpNamespace != r.Namespace) {
+			if err := registry.UpdateKafkaCluster(&item, r.backedUpName, r.backedUpNamespace, r.Name, r.Namespace); err != nil {
+				slog.Error("Failed to update the Kafka cluster used by the Apicurio Registry", "name", item.GetName(), "error", err)
+				return err
+			}
+		}
+
+		item.SetManagedFields(nil)
+		item.SetResourceVersion("")
+		item.SetUID("")
+		item.SetGeneration(0)
+		item.SetCreationTimestamp(metav1.Time{})
+		item.SetOwnerReferences(nil)
+		item.SetNamespace(r.Namespace)
+		unstructured.RemoveNestedField(item.Object, "status")
+
+		annotations := item.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[RestoredFromAnnotation] = filepath.Base(r.BackupFileName)
+		item.SetAnnotations(annotations)
+
+		client := r.DynamicClient.Resource(resource).Namespace(r.Namespace)
+		get := func(ctx context.Context, name string, options metav1.GetOptions) (*unstructured.Unstructured, error) {
+			return client.Get(ctx, name, options)
+		}
+
+		if err := checkOwnership(&r.Restorer, get, item.GetKind(), item.GetName()); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(client.Patch, item.GetName(), &item, r.Force); err != nil {
+			slog.Error("Failed to restore the Apicurio Registry resource", "kind", item.GetKind(), "name", item.GetName(), "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApicurioRegistryRestorer imports the artifacts exported from the Apicurio Registries during the backup. The
+// registries have to be restored and running already.
+type ApicurioRegistryRestorer struct {
+	Restorer
+
+	url     string
+	headers []string
+}
+
+func NewApicurioRegistryRestorer(cmd *cobra.Command) (*ApicurioRegistryRestorer, error) {
+	headers, err := cmd.Flags().GetStringArray("apicurio-registry-header")
+	if err != nil {
+		slog.Error("Failed to get the --apicurio-registry-header flag", "error", err)
+		return nil, err
+	}
+
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApicurioRegistryRestorer{Restorer: *restorer, url: cmd.Flag("apicurio-registry-url").Value.String(), headers: headers}, nil
+}
+
+// RestoreArtifacts imports the artifacts of all Apicurio Registries stored in the backup
+func (r *ApicurioRegistryRestorer) RestoreArtifacts() error {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if r.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*time.Duration(r.Timeout))
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+
+	// The Apicurio Registry custom resources are stored before the artifacts, so the kinds are known when the
+	// artifacts are imported
+	kinds := map[string]string{}
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if member.Name == backuper.ApicurioRegistriesFilename {
+			var registries *unstructured.UnstructuredList
+			if err := yaml.Unmarshal(member.Data, &registries); err != nil {
+				slog.Error("Failed to unmarshall the Apicurio Registry resources", "error", err)
+				return err
+			}
+
+			for _, item := range registries.Items {
+				kinds[item.GetName()] = item.GetKind()
+			}
+		} else if registryName, ok := backuper.RegistryFromArtifactsStreamName(member.Name); !ok {
+			slog.Debug("Skipping resources which are not Apicurio Registry artifacts", "name", member.Name)
+		} else if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping Apicurio Registry artifacts which were already restored before the restore was interrupted", "registry", registryName)
+			restored++
+		} else {
+			if err := r.importArtifacts(ctx, kinds[registryName], registryName, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if restored == 0 {
+		slog.Warn("No Apicurio Registry artifacts to restore found in the backup", "file", r.BackupFileName)
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// importArtifacts imports the artifacts exported from a single Apicurio Registry
+func (r *ApicurioRegistryRestorer) importArtifacts(ctx context.Context, kind string, name string, data []byte) error {
+	if kind == "" {
+		slog.Warn("The Apicurio Registry custom resource was not found in the backup => assuming Apicurio Registry 2.x", "registry", name)
+		kind = registry.ApicurioRegistryKind
+	}
+
+	url := registry.DefaultURL(kind, name, r.Namespace)
+	if r.url != "" {
+		url = strings.NewReplacer("{namespace}", r.Namespace, "{name}", name).Replace(r.url)
+	}
+
+	slog.Info("Importing the artifacts into the Apicurio Registry", "registry", name, "url", url)
+
+	client, err := registry.NewClient(url, kind, r.headers)
+	if err != nil {
+		slog.Error("Failed to create the Apicurio Registry client", "registry", name, "error", err)
+		return err
+	}
+
+	if err := client.Import(ctx, data); err != nil {
+		slog.Error("Failed to import the artifacts into the Apicurio Registry", "registry", name, "url", url, "error", err)
+		return err
+	}
+
+	slog.Info("Artifacts of the Apicurio Registry were restored", "registry", name)
+
+	return nil
+}
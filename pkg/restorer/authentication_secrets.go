@@ -0,0 +1,36 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"log/slog"
+)
+
+// restoreAuthenticationSecretsStream restores the Secrets referenced from the authentication of the Kafka listeners
+// and from the authorization of the Kafka cluster. They are restored before the Kafka cluster is unpaused, as the
+// Cluster Operator cannot configure the OAuth authentication and authorization without them.
+func (r *KafkaRestorer) restoreAuthenticationSecretsStream(resources []byte) error {
+	slog.Info("Restoring the Secrets used by the authentication and authorization")
+
+	if err := r.restoreReferencedSecrets(resources); err != nil {
+		slog.Error("Failed to restore the Secrets used by the authentication and authorization", "error", err)
+		return err
+	}
+
+	slog.Info("The Secrets used by the authentication and authorization were restored")
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// BridgeRestorer restores a Kafka Bridge from a backup created with the backup all command. The KafkaBridge resource is
+// restored paused and unpaused only once the Secrets and ConfigMaps referenced from it are restored.
+type BridgeRestorer struct {
+	Restorer
+}
+
+func NewBridgeRestorer(cmd *cobra.Command) (*BridgeRestorer, error) {
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BridgeRestorer{Restorer: *restorer}, nil
+}
+
+// bridgeStreamRestorers maps the streams restored together with the Kafka Bridge to the functions restoring them
+var bridgeStreamRestorers = map[string]func(r *BridgeRestorer, resources []byte) error{
+	backuper.KafkaBridgeSecretsFilename:    (*BridgeRestorer).restoreReferencedSecrets,
+	backuper.KafkaBridgeConfigMapsFilename: (*BridgeRestorer).restoreReferencedConfigMaps,
+}
+
+// RestoreBridge restores the Kafka Bridge, unpauses it, and waits for it to get ready. The KafkaBridge resource is
+// restored only after the Secrets and ConfigMaps with its credentials and certificates.
+func (r *BridgeRestorer) RestoreBridge() error {
+	var bridge []byte // The KafkaBridge resource is restored only once its Secrets and ConfigMaps are restored
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+			restored++
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByBridge {
+			slog.Debug("Skipping resources which are not part of the Kafka Bridge", "name", member.Name)
+		} else if stream.Name == backuper.KafkaBridgeFilename {
+			bridge = member.Data
+		} else {
+			if err := bridgeStreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if bridge != nil {
+		if err := r.restoreKafkaBridge(bridge); err != nil {
+			return err
+		}
+
+		if err := r.checkpoint.MarkCompleted(backuper.KafkaBridgeFilename); err != nil {
+			slog.Error("Failed to record the restore progress", "name", backuper.KafkaBridgeFilename, "error", err)
+			return err
+		}
+
+		restored++
+	}
+
+	if restored == 0 {
+		slog.Error("No Kafka Bridge found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Kafka Bridge found in the backup %s", r.BackupFileName)
+	}
+
+	if err := r.unpauseKafkaBridgeAndWaitForReadiness(); err != nil {
+		slog.Error("Failed to unpause Kafka Bridge and get it into the Ready state", "error", err)
+		return err
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreKafkaBridge restores the KafkaBridge resource with paused reconciliation, so that the Cluster Operator does
+// not deploy the Kafka Bridge before the resources referenced from it are restored
+func (r *BridgeRestorer) restoreKafkaBridge(resource []byte) error {
+	var bridge *v1beta2.KafkaBridge
+
+	if err := yaml.Unmarshal(resource, &bridge); err != nil {
+		slog.Error("Failed to unmarshall the KafkaBridge resource", "error", err)
+		return err
+	}
+
+	if err := r.checkSourceNamespace(bridge.Namespace); err != nil {
+		return err
+	}
+
+	if bridge.Namespace != "" && bridge.Namespace != r.Namespace {
+		slog.Warn("The Kafka Bridge is restored into a different namespace. Its bootstrap servers are not updated and might need to be changed to point to the right Kafka cluster.", "backupNamespace", bridge.Namespace, "namespace", r.Namespace)
+	}
+
+	slog.Info("Restoring paused KafkaBridge resource", "name", r.Name, "namespace", r.Namespace)
+
+	// We update the metadata and pause the resource
+	utils.CleanseMetadata(&bridge.ObjectMeta)
+	bridge.Namespace = r.Namespace
+	bridge.Name = r.Name
+	if bridge.Annotations == nil {
+		bridge.Annotations = map[string]string{"strimzi.io/pause-reconciliation": "true"}
+	} else {
+		bridge.Annotations["strimzi.io/pause-reconciliation"] = "true"
+	}
+
+	r.markRestored(&bridge.ObjectMeta)
+	bridge.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaBridge"}
+	bridge.Status = nil
+
+	if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().KafkaBridges(r.Namespace).Get, "KafkaBridge", bridge.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaBridges(r.Namespace).Patch, bridge.Name, bridge, r.Force); err != nil {
+		slog.Error("Failed to restore the KafkaBridge resource", "error", err)
+		return err
+	}
+
+	// Wait for the paused reconciliation to be confirmed
+	if _, err := utils.WaitUntilBridgeReconciliationPaused(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.PauseTimeout); err != nil {
+		slog.Error("The KafkaBridge resource was not paused. Please check the Cluster Operator logs for more details.", "error", err)
+		return err
+	}
+
+	slog.Info("KafkaBridge resource was restored in paused state")
+
+	return nil
+}
+
+// unpauseKafkaBridgeAndWaitForReadiness unpauses the restored Kafka Bridge and waits until it is ready
+func (r *BridgeRestorer) unpauseKafkaBridgeAndWaitForReadiness() error {
+	bridge, err := r.StrimziClient.KafkaV1beta2().KafkaBridges(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the KafkaBridge resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if utils.IsBridgeReconciliationPaused(bridge) {
+		slog.Info("Unpausing the Kafka Bridge", "name", r.Name, "namespace", r.Namespace)
+		unpausedBridge := bridge.DeepCopy()
+		unpausedBridge.Annotations["strimzi.io/pause-reconciliation"] = "false"
+
+		// The whole resource is applied again as fields missing in the applied configuration would be removed
+		utils.CleanseMetadata(&unpausedBridge.ObjectMeta)
+		unpausedBridge.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaBridge"}
+		unpausedBridge.Status = nil
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaBridges(r.Namespace).Patch, unpausedBridge.Name, unpausedBridge, r.Force); err != nil {
+			slog.Error("Failed to unpause the KafkaBridge resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	} else if utils.IsBridgeReady(bridge) {
+		slog.Warn("The Kafka Bridge is already ready and does not need to be unpaused", "name", r.Name, "namespace", r.Namespace)
+		return nil
+	} else {
+		slog.Warn("The Kafka Bridge is not paused, but it is not ready. Waiting for the Kafka Bridge to get ready.", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	slog.Info("Waiting for the Kafka Bridge to get ready", "name", r.Name, "namespace", r.Namespace)
+	if _, err := utils.WaitUntilBridgeReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.ReadyTimeout); err != nil {
+		slog.Error("The Kafka Bridge did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The Kafka Bridge is ready", "name", r.Name, "namespace", r.Namespace)
+
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	checkpointCompletedStreamsKey = "completed-streams"
+	checkpointClusterIdKey        = "cluster-id"
+)
+
+var invalidCheckpointNameCharacters = regexp.MustCompile("[^a-z0-9-]+")
+
+// Checkpoint stores the progress of the restore in a ConfigMap, so that an interrupted restore (for example when the
+// Pod of the restore Job is restarted) can be resumed without restoring the already restored streams again.
+type Checkpoint struct {
+	client    *kubernetes.Clientset
+	configMap *v1.ConfigMap
+}
+
+// CheckpointName returns the name of the checkpoint ConfigMap used for restoring the Kafka cluster from given backup file.
+// The same backup can be restored into multiple Kafka clusters in the same namespace (for example the clusters from the
+// backups created with the --all-clusters option), so each of them has its own checkpoint.
+func CheckpointName(clusterName string, backupFileName string) string {
+	name := strings.ToLower(clusterName + "-" + filepath.Base(backupFileName))
+	name = invalidCheckpointNameCharacters.ReplaceAllString(name, "-")
+	name = strings.Trim("strimzi-backup-restore-"+name, "-")
+
+	if len(name) > 253 {
+		name = strings.Trim(name[:253], "-")
+	}
+
+	return name
+}
+
+// LoadCheckpoint finds the existing checkpoint ConfigMap or creates a new one if it does not exist yet
+func LoadCheckpoint(client *kubernetes.Clientset, namespace string, name string) (*Checkpoint, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			slog.Error("Failed to get the restore checkpoint", "name", name, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		slog.Info("Creating new restore checkpoint", "name", name, "namespace", namespace)
+
+		checkpoint := &Checkpoint{
+			client: client,
+			configMap: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Data:       map[string]string{},
+			},
+		}
+
+		if err := checkpoint.apply(checkpoint.configMap); err != nil {
+			return nil, err
+		}
+
+		return checkpoint, nil
+	} else {
+		slog.Info("Resuming restore from existing checkpoint", "name", name, "namespace", namespace, "completedStreams", configMap.Data[checkpointCompletedStreamsKey])
+
+		return &Checkpoint{client: client, configMap: configMap}, nil
+	}
+}
+
+// IsCompleted indicates whether the stream was already restored
+func (c *Checkpoint) IsCompleted(stream string) bool {
+	if c == nil {
+		return false
+	}
+
+	return slices.Contains(c.completedStreams(), stream)
+}
+
+// MarkCompleted records the stream as restored
+func (c *Checkpoint) MarkCompleted(stream string) error {
+	if c == nil || c.IsCompleted(stream) {
+		return nil
+	}
+
+	return c.update(checkpointCompletedStreamsKey, strings.Join(append(c.completedStreams(), stream), "\n"))
+}
+
+// ClusterId returns the Kafka Cluster ID recorded in the checkpoint
+func (c *Checkpoint) ClusterId() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.configMap.Data[checkpointClusterIdKey]
+}
+
+// SetClusterId records the Kafka Cluster ID that should be restored at the end of the restore
+func (c *Checkpoint) SetClusterId(clusterId string) error {
+	if c == nil || clusterId == "" {
+		return nil
+	}
+
+	return c.update(checkpointClusterIdKey, clusterId)
+}
+
+// Delete removes the checkpoint once the restore is complete
+func (c *Checkpoint) Delete() error {
+	if c == nil {
+		return nil
+	}
+
+	slog.Info("Deleting the restore checkpoint", "name", c.configMap.Name, "namespace", c.configMap.Namespace)
+
+	err := c.client.CoreV1().ConfigMaps(c.configMap.Namespace).Delete(context.TODO(), c.configMap.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		slog.Error("Failed to delete the restore checkpoint", "name", c.configMap.Name, "namespace", c.configMap.Namespace, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) completedStreams() []string {
+	if c.configMap.Data[checkpointCompletedStreamsKey] == "" {
+		return []string{}
+	}
+
+	return strings.Split(c.configMap.Data[checkpointCompletedStreamsKey], "\n")
+}
+
+func (c *Checkpoint) update(key string, value string) error {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: c.configMap.Name, Namespace: c.configMap.Namespace},
+		Data:       map[string]string{},
+	}
+
+	for k, v := range c.configMap.Data {
+		configMap.Data[k] = v
+	}
+	configMap.Data[key] = value
+
+	return c.apply(configMap)
+}
+
+func (c *Checkpoint) apply(configMap *v1.ConfigMap) error {
+	configMap.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "ConfigMap"}
+	configMap.Labels = map[string]string{"app.kubernetes.io/managed-by": "strimzi-backup"}
+
+	applied, err := utils.Apply(c.client.CoreV1().ConfigMaps(configMap.Namespace).Patch, configMap.Name, configMap, false)
+	if err != nil {
+		slog.Error("Failed to update the restore checkpoint", "name", configMap.Name, "namespace", configMap.Namespace, "error", err)
+		return err
+	}
+
+	c.configMap = applied
+
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// registryCheckTimeout is the timeout for checking that the container registry of the Kafka Connect build is
+	// reachable
+	registryCheckTimeout = 10 * time.Second
+
+	// defaultRegistry is the container registry used for the images without a registry host
+	defaultRegistry = "registry-1.docker.io"
+)
+
+// checkBuildRegistry verifies that the container registry into which the Kafka Connect build pushes the new container
+// image is reachable and that its push Secret exists. Otherwise, the build would fail only once the Kafka Connect
+// cluster is unpaused.
+func (r *ConnectRestorer) checkBuildRegistry(connect *v1beta2.KafkaConnect) error {
+	if connect.Spec == nil || connect.Spec.Build == nil || connect.Spec.Build.Output == nil {
+		return nil
+	}
+
+	output := connect.Spec.Build.Output
+	if output.PushSecret != "" {
+		if _, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get(context.TODO(), output.PushSecret, metav1.GetOptions{}); errors.IsNotFound(err) {
+			slog.Error("The push Secret of the Kafka Connect build does not exist", "secret", output.PushSecret, "namespace", r.Namespace)
+			return fmt.Errorf("the push Secret %s of the Kafka Connect build does not exist in the namespace %s", output.PushSecret, r.Namespace)
+		} else if err != nil {
+			slog.Error("Failed to get the push Secret of the Kafka Connect build", "secret", output.PushSecret, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	if output.Type != v1beta2.DOCKER_OUTPUTTYPE {
+		slog.Debug("The Kafka Connect build does not push into a container registry", "type", output.Type)
+		return nil
+	} else if r.skipRegistryCheck {
+		slog.Warn("Skipping the check of the container registry of the Kafka Connect build", "image", output.Image)
+		return nil
+	}
+
+	registry := registryHost(output.Image)
+	if strings.HasSuffix(strings.Split(registry, ":")[0], ".svc") || strings.Contains(registry, ".svc.") {
+		if !utils.IsRunningInCluster() {
+			slog.Warn("The container registry of the Kafka Connect build is reachable only from inside the Kubernetes cluster and is not checked", "registry", registry, "image", output.Image)
+			return nil
+		}
+	}
+
+	slog.Info("Checking that the container registry of the Kafka Connect build is reachable", "registry", registry, "image", output.Image)
+	if err := pingRegistry(registry); err != nil {
+		slog.Error("The container registry of the Kafka Connect build is not reachable. Use the --skip-registry-check option to restore the Kafka Connect cluster anyway.", "registry", registry, "image", output.Image, "error", err)
+		return fmt.Errorf("the container registry %s of the Kafka Connect build is not reachable: %w", registry, err)
+	}
+
+	return nil
+}
+
+// registryHost returns the host of the container registry from the image reference. The first part of the reference is
+// the registry host only when it looks like a hostname. Otherwise, the image is from Docker Hub.
+func registryHost(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return defaultRegistry
+	} else if host == "docker.io" || host == "index.docker.io" {
+		return defaultRegistry
+	}
+
+	return host
+}
+
+// pingRegistry calls the base endpoint of the container registry API. Any HTTP response, including the 401 status
+// code returned by the registries requiring authentication, means the registry is reachable.
+func pingRegistry(registry string) error {
+	client := &http.Client{Timeout: registryCheckTimeout}
+
+	resp, err := client.Get("https://" + registry + "/v2/")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("the registry responded with the status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
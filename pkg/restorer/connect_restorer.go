@@ -0,0 +1,327 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// ConnectRestorer restores a Kafka Connect cluster from a backup created with the backup connect command. The
+// KafkaConnect resource is restored paused and unpaused only once the Secrets and ConfigMaps referenced from it are
+// restored. The KafkaConnectors are restored once the Kafka Connect cluster is ready.
+type ConnectRestorer struct {
+	Restorer
+
+	skipRegistryCheck bool
+}
+
+func NewConnectRestorer(cmd *cobra.Command) (*ConnectRestorer, error) {
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	skipRegistryCheck := false
+	if cmd.Flags().Lookup("skip-registry-check") != nil {
+		skipRegistryCheck, err = cmd.Flags().GetBool("skip-registry-check")
+		if err != nil {
+			slog.Error("Failed to get the --skip-registry-check flag", "error", err)
+			return nil, err
+		}
+	}
+
+	return &ConnectRestorer{Restorer: *restorer, skipRegistryCheck: skipRegistryCheck}, nil
+}
+
+// connectStreamRestorers maps the streams restored by the restore connect command to the functions restoring them
+var connectStreamRestorers = map[string]func(r *ConnectRestorer, resources []byte) error{
+	backuper.KafkaConnectFilename:           (*ConnectRestorer).restoreKafkaConnect,
+	backuper.KafkaConnectSecretsFilename:    (*ConnectRestorer).restoreReferencedSecrets,
+	backuper.KafkaConnectConfigMapsFilename: (*ConnectRestorer).restoreReferencedConfigMaps,
+}
+
+// RestoreConnect restores the Kafka Connect cluster, unpauses it, waits for it to get ready, and restores its
+// connectors
+func (r *ConnectRestorer) RestoreConnect() error {
+	var connectors []byte // The connectors are restored only once the Kafka Connect cluster is ready
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+			restored++
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByConnect {
+			slog.Debug("Skipping resources which are not part of the Kafka Connect cluster", "name", member.Name)
+		} else if stream.Name == backuper.KafkaConnectorsFilename {
+			connectors = member.Data
+		} else {
+			if err := connectStreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if restored == 0 {
+		slog.Error("No Kafka Connect cluster found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Kafka Connect cluster found in the backup %s", r.BackupFileName)
+	}
+
+	if err := r.unpauseKafkaConnectAndWaitForReadiness(); err != nil {
+		slog.Error("Failed to unpause Kafka Connect cluster and get it into the Ready state", "error", err)
+		return err
+	}
+
+	if connectors != nil {
+		if err := r.restoreKafkaConnectors(connectors); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreKafkaConnect restores the KafkaConnect resource with paused reconciliation, so that the Cluster Operator does
+// not deploy the Kafka Connect cluster before the resources referenced from it are restored
+func (r *ConnectRestorer) restoreKafkaConnect(resource []byte) error {
+	var connect *v1beta2.KafkaConnect
+
+	if err := yaml.Unmarshal(resource, &connect); err != nil {
+		slog.Error("Failed to unmarshall the KafkaConnect resource", "error", err)
+		return err
+	}
+
+	if err := r.checkSourceNamespace(connect.Namespace); err != nil {
+		return err
+	}
+
+	if connect.Namespace != "" && connect.Namespace != r.Namespace {
+		slog.Warn("The Kafka Connect cluster is restored into a different namespace. Its bootstrap servers are not updated and might need to be changed to point to the right Kafka cluster.", "backupNamespace", connect.Namespace, "namespace", r.Namespace)
+	}
+
+	slog.Info("Restoring paused KafkaConnect resource", "name", r.Name, "namespace", r.Namespace)
+
+	// We update the metadata and pause the resource
+	utils.CleanseMetadata(&connect.ObjectMeta)
+	connect.Namespace = r.Namespace
+	connect.Name = r.Name
+	if connect.Annotations == nil {
+		connect.Annotations = map[string]string{"strimzi.io/pause-reconciliation": "true"}
+	} else {
+		connect.Annotations["strimzi.io/pause-reconciliation"] = "true"
+	}
+
+	r.markRestored(&connect.ObjectMeta)
+	connect.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaConnect"}
+	connect.Status = nil
+
+	if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().KafkaConnects(r.Namespace).Get, "KafkaConnect", connect.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaConnects(r.Namespace).Patch, connect.Name, connect, r.Force); err != nil {
+		slog.Error("Failed to restore the KafkaConnect resource", "error", err)
+		return err
+	}
+
+	// Wait for the paused reconciliation to be confirmed
+	if _, err := utils.WaitUntilConnectReconciliationPaused(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.PauseTimeout); err != nil {
+		slog.Error("The KafkaConnect resource was not paused. Please check the Cluster Operator logs for more details.", "error", err)
+		return err
+	}
+
+	slog.Info("KafkaConnect resource was restored in paused state")
+
+	return nil
+}
+
+// restoreKafkaConnectors restores the KafkaConnector resources and assigns them to the restored Kafka Connect cluster
+func (r *ConnectRestorer) restoreKafkaConnectors(resources []byte) error {
+	var connectors *v1beta2.KafkaConnectorList
+
+	if err := yaml.Unmarshal(resources, &connectors); err != nil {
+		slog.Error("Failed to unmarshall the KafkaConnector resources", "error", err)
+		return err
+	}
+
+	for _, connector := range connectors.Items {
+		slog.Info("Restoring KafkaConnector", "name", connector.Name, "namespace", r.Namespace)
+
+		utils.CleanseMetadata(&connector.ObjectMeta)
+		connector.Namespace = r.Namespace
+		if connector.Labels == nil {
+			connector.Labels = map[string]string{"strimzi.io/cluster": r.Name}
+		} else {
+			connector.Labels["strimzi.io/cluster"] = r.Name
+		}
+
+		r.markRestored(&connector.ObjectMeta)
+		connector.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaConnector"}
+		connector.Status = nil
+
+		if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().KafkaConnectors(r.Namespace).Get, "KafkaConnector", connector.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaConnectors(r.Namespace).Patch, connector.Name, &connector, r.Force); err != nil {
+			slog.Error("Failed to restore the KafkaConnector resource", "name", connector.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreReferencedSecrets restores the Secrets referenced from the KafkaConnect or KafkaMirrorMaker2 resource
+func (r *Restorer) restoreReferencedSecrets(resources []byte) error {
+	var secrets *v1.SecretList
+
+	if err := yaml.Unmarshal(resources, &secrets); err != nil {
+		slog.Error("Failed to unmarshall the Secret resources", "error", err)
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		slog.Info("Restoring Secret", "name", secret.Name, "namespace", r.Namespace)
+
+		utils.CleanseMetadata(&secret.ObjectMeta)
+		secret.Namespace = r.Namespace
+
+		r.markRestored(&secret.ObjectMeta)
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+
+		if err := checkOwnership(r, r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get, "Secret", secret.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().Secrets(r.Namespace).Patch, secret.Name, &secret, r.Force); err != nil {
+			slog.Error("Failed to restore the Secret", "name", secret.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreReferencedConfigMaps restores the ConfigMaps referenced from the restored custom resources, such as the
+// KafkaConnect, KafkaMirrorMaker2, or Kafka resources
+func (r *Restorer) restoreReferencedConfigMaps(resources []byte) error {
+	var configMaps *v1.ConfigMapList
+
+	if err := yaml.Unmarshal(resources, &configMaps); err != nil {
+		slog.Error("Failed to unmarshall the ConfigMap resources", "error", err)
+		return err
+	}
+
+	for _, configMap := range configMaps.Items {
+		slog.Info("Restoring ConfigMap", "name", configMap.Name, "namespace", r.Namespace)
+
+		utils.CleanseMetadata(&configMap.ObjectMeta)
+		configMap.Namespace = r.Namespace
+
+		r.markRestored(&configMap.ObjectMeta)
+		configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+
+		if err := checkOwnership(r, r.KubernetesClient.CoreV1().ConfigMaps(r.Namespace).Get, "ConfigMap", configMap.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().ConfigMaps(r.Namespace).Patch, configMap.Name, &configMap, r.Force); err != nil {
+			slog.Error("Failed to restore the ConfigMap resource", "name", configMap.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unpauseKafkaConnectAndWaitForReadiness unpauses the restored Kafka Connect cluster and waits until it is ready
+func (r *ConnectRestorer) unpauseKafkaConnectAndWaitForReadiness() error {
+	connect, err := r.StrimziClient.KafkaV1beta2().KafkaConnects(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the KafkaConnect resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if utils.IsConnectReconciliationPaused(connect) {
+		if err := r.checkBuildRegistry(connect); err != nil {
+			return err
+		}
+
+		slog.Info("Unpausing the Kafka Connect cluster", "name", r.Name, "namespace", r.Namespace)
+		unpausedConnect := connect.DeepCopy()
+		unpausedConnect.Annotations["strimzi.io/pause-reconciliation"] = "false"
+
+		// The whole resource is applied again as fields missing in the applied configuration would be removed
+		utils.CleanseMetadata(&unpausedConnect.ObjectMeta)
+		unpausedConnect.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaConnect"}
+		unpausedConnect.Status = nil
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaConnects(r.Namespace).Patch, unpausedConnect.Name, unpausedConnect, r.Force); err != nil {
+			slog.Error("Failed to unpause the KafkaConnect resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	} else if utils.IsConnectReady(connect) {
+		slog.Warn("The Kafka Connect cluster is already ready and does not need to be unpaused", "name", r.Name, "namespace", r.Namespace)
+		return nil
+	} else {
+		slog.Warn("The Kafka Connect cluster is not paused, but it is not ready. Waiting for the Kafka Connect cluster to get ready.", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	slog.Info("Waiting for the Kafka Connect cluster to get ready", "name", r.Name, "namespace", r.Namespace)
+	if _, err := utils.WaitUntilConnectReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.ReadyTimeout); err != nil {
+		slog.Error("The Kafka Connect cluster did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The Kafka Connect cluster is ready", "name", r.Name, "namespace", r.Namespace)
+
+	return nil
+}
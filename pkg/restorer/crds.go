@@ -0,0 +1,198 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"time"
+)
+
+// CRDRestorer restores the Strimzi CustomResourceDefinitions from a backup created with the --include-crds option. It
+// is used to prepare a bare Kubernetes cluster before the Cluster Operator and the operands are restored.
+type CRDRestorer struct {
+	Restorer
+}
+
+func NewCRDRestorer(cmd *cobra.Command) (*CRDRestorer, error) {
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CRDRestorer{Restorer: *restorer}, nil
+}
+
+// RestoreCRDs restores the Strimzi CustomResourceDefinitions and waits until they are established
+func (r *CRDRestorer) RestoreCRDs() error {
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+			restored++
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByCRDs {
+			slog.Debug("Skipping resources which are not Strimzi CustomResourceDefinitions", "name", member.Name)
+		} else {
+			if err := r.restoreCRDs(member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if restored == 0 {
+		slog.Error("No Strimzi CustomResourceDefinitions found in the backup. Use the --include-crds option when creating the backup to include them.", "file", r.BackupFileName)
+		return fmt.Errorf("no Strimzi CustomResourceDefinitions found in the backup %s", r.BackupFileName)
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreCRDs applies the Strimzi CustomResourceDefinitions from the backup and waits until the Kubernetes API server
+// establishes them. The versions in which the custom resources were stored in the source cluster have to be still
+// part of the restored CRDs, otherwise the custom resources from the backup might not be restorable.
+func (r *Restorer) restoreCRDs(resources []byte) error {
+	var crds *unstructured.UnstructuredList
+
+	if err := yaml.Unmarshal(resources, &crds); err != nil {
+		slog.Error("Failed to unmarshall the CustomResourceDefinition resources", "error", err)
+		return err
+	}
+
+	client := r.DynamicClient.Resource(backuper.CustomResourceDefinitionResource)
+	get := func(ctx context.Context, name string, options metav1.GetOptions) (*unstructured.Unstructured, error) {
+		return client.Get(ctx, name, options)
+	}
+
+	var names []string
+	for _, crd := range crds.Items {
+		slog.Info("Restoring CustomResourceDefinition", "name", crd.GetName())
+
+		storedVersions, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+		var versionNames []string
+		for _, version := range versions {
+			if version, ok := version.(map[string]interface{}); ok {
+				if name, ok := version["name"].(string); ok {
+					versionNames = append(versionNames, name)
+				}
+			}
+		}
+
+		for _, storedVersion := range storedVersions {
+			if !slices.Contains(versionNames, storedVersion) {
+				slog.Warn("The version in which the custom resources were stored in the source cluster is not part of the restored CustomResourceDefinition", "name", crd.GetName(), "storedVersion", storedVersion, "versions", versionNames)
+			}
+		}
+
+		crd.SetManagedFields(nil)
+		crd.SetResourceVersion("")
+		crd.SetUID("")
+		crd.SetGeneration(0)
+		crd.SetCreationTimestamp(metav1.Time{})
+		crd.SetOwnerReferences(nil)
+		// The stored versions are managed by the Kubernetes API server
+		unstructured.RemoveNestedField(crd.Object, "status")
+
+		annotations := crd.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[RestoredFromAnnotation] = filepath.Base(r.BackupFileName)
+		crd.SetAnnotations(annotations)
+
+		if err := checkOwnership(r, get, "CustomResourceDefinition", crd.GetName()); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(client.Patch, crd.GetName(), &crd, r.Force); err != nil {
+			slog.Error("Failed to restore the CustomResourceDefinition", "name", crd.GetName(), "error", err)
+			return err
+		}
+
+		names = append(names, crd.GetName())
+	}
+
+	slog.Info("Waiting for the CustomResourceDefinitions to get established", "crds", len(names))
+
+	for _, name := range names {
+		err := wait.PollUntilContextTimeout(context.Background(), time.Second, time.Millisecond*time.Duration(r.ReadyTimeout), true, func(ctx context.Context) (bool, error) {
+			current, err := client.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			return isEstablished(current), nil
+		})
+		if err != nil {
+			slog.Error("The CustomResourceDefinition did not get established", "name", name, "error", err)
+			return err
+		}
+	}
+
+	slog.Info("The Strimzi CustomResourceDefinitions are established", "crds", len(names))
+
+	return nil
+}
+
+// isEstablished checks whether the Kubernetes API server serves the custom resources defined by the CRD
+func isEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if condition, ok := condition.(map[string]interface{}); ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
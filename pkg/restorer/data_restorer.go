@@ -0,0 +1,276 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"io"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+// dataRestoreBatchSize is the number of records produced at once when restoring the topic data
+const dataRestoreBatchSize = 1000
+
+// DataRestorer produces the records of the topics stored in the backup by the DataBackuper back into the Kafka cluster.
+// The records keep their partition, key, value, headers, and timestamp. Their offsets are not preserved.
+type DataRestorer struct {
+	Restorer
+
+	KafkaClient *kgo.Client
+	Topics      *regexp.Regexp
+
+	connectTopics map[string]backuper.ConnectTopic
+}
+
+func NewDataRestorer(cmd *cobra.Command) (*DataRestorer, error) {
+	var topicsRegexp *regexp.Regexp
+	if topics := cmd.Flag("topics").Value.String(); topics != "" {
+		// The regular expression has to match the whole topic name
+		var err error
+		topicsRegexp, err = regexp.Compile("^(?:" + topics + ")$")
+		if err != nil {
+			slog.Error("Failed to parse the --topics option", "topics", topics, "error", err)
+			return nil, err
+		}
+	}
+
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	kafkaClient, err := utils.CreateKafkaClient(cmd, restorer.KubernetesClient, restorer.Namespace, restorer.Name, kgo.RecordPartitioner(kgo.ManualPartitioner()))
+	if err != nil {
+		restorer.Close()
+		return nil, err
+	}
+
+	return &DataRestorer{Restorer: *restorer, KafkaClient: kafkaClient, Topics: topicsRegexp, connectTopics: map[string]backuper.ConnectTopic{}}, nil
+}
+
+func (r *DataRestorer) Close() {
+	r.KafkaClient.Close()
+	r.Restorer.Close()
+}
+
+// RestoreTopicData restores the records of all topics stored in the backup (or only of the topics matching the --topics
+// option). The topics have to exist already, for example because their KafkaTopic resources were restored before.
+func (r *DataRestorer) RestoreTopicData() error {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if r.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*time.Duration(r.Timeout))
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+
+	admin := kadm.NewClient(r.KafkaClient)
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if member.Name == backuper.ConnectTopicsFilename {
+			if err := r.loadConnectTopics(member.Data); err != nil {
+				return err
+			}
+		} else if topic, ok := backuper.TopicFromDataStreamName(member.Name); !ok {
+			slog.Debug("Skipping resources which are not topic data", "name", member.Name)
+		} else if r.Topics != nil && !r.Topics.MatchString(topic) {
+			slog.Info("Skipping topic data not matching the --topics option", "topic", topic)
+		} else if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping topic data which were already restored before the restore was interrupted", "topic", topic)
+			restored++
+		} else {
+			if mapped := r.nameMapping.Topic(topic); mapped != topic {
+				slog.Info("Restoring the topic data into a renamed topic", "topic", topic, "newTopic", mapped)
+				topic = mapped
+			}
+
+			if err := r.restoreTopic(ctx, admin, topic, member.Data); err != nil {
+				slog.Error("Failed to restore the topic data", "topic", topic, "error", err)
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if restored == 0 {
+		slog.Warn("No topic data to restore found in the backup", "file", r.BackupFileName)
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreTopic produces the records of a single topic
+func (r *DataRestorer) restoreTopic(ctx context.Context, admin *kadm.Client, topic string, data []byte) error {
+	var records []*kgo.Record
+	var maxPartition int32 = -1
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var topicRecord backuper.TopicRecord
+		if err := decoder.Decode(&topicRecord); err != nil {
+			slog.Error("Failed to unmarshal the record from JSON", "topic", topic, "error", err)
+			return err
+		}
+
+		record := &kgo.Record{
+			Topic:     topic,
+			Partition: topicRecord.Partition,
+			Timestamp: topicRecord.Timestamp,
+			Key:       topicRecord.Key,
+			Value:     topicRecord.Value,
+		}
+		for _, header := range topicRecord.Headers {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: header.Key, Value: header.Value})
+		}
+
+		records = append(records, record)
+		maxPartition = max(maxPartition, topicRecord.Partition)
+	}
+
+	details, err := admin.ListTopics(ctx, topic)
+	if err != nil {
+		slog.Error("Failed to get the details of the topic", "topic", topic, "error", err)
+		return err
+	}
+
+	topicDetail, ok := details[topic]
+	if connectTopic, isConnectTopic := r.connectTopics[topic]; isConnectTopic && (!ok || errors.Is(topicDetail.Err, kerr.UnknownTopicOrPartition)) {
+		if err := r.createConnectTopic(ctx, admin, connectTopic); err != nil {
+			return err
+		}
+
+		// Reload the details of the created topic
+		details, err = admin.ListTopics(ctx, topic)
+		if err != nil {
+			slog.Error("Failed to get the details of the topic", "topic", topic, "error", err)
+			return err
+		}
+		topicDetail, ok = details[topic]
+	}
+
+	if !ok || topicDetail.Err != nil {
+		slog.Error("The topic does not exist in the Kafka cluster. Restore the KafkaTopic resources first.", "topic", topic)
+		return fmt.Errorf("topic %s does not exist in the Kafka cluster", topic)
+	}
+
+	if int32(len(topicDetail.Partitions)) <= maxPartition {
+		slog.Error("The topic has fewer partitions than in the backup", "topic", topic, "partitions", len(topicDetail.Partitions), "backupPartitions", maxPartition+1)
+		return fmt.Errorf("topic %s has %d partitions, but the backup contains records of %d partitions", topic, len(topicDetail.Partitions), maxPartition+1)
+	}
+
+	slog.Info("Restoring the records of the topic", "topic", topic, "records", len(records))
+
+	for start := 0; start < len(records); start += dataRestoreBatchSize {
+		end := min(start+dataRestoreBatchSize, len(records))
+
+		if err := r.KafkaClient.ProduceSync(ctx, records[start:end]...).FirstErr(); err != nil {
+			slog.Error("Failed to produce the records of the topic", "topic", topic, "produced", start, "error", err)
+			return err
+		}
+	}
+
+	slog.Info("Records of the topic were restored", "topic", topic, "records", len(records))
+
+	return nil
+}
+
+// loadConnectTopics loads the descriptions of the Kafka Connect internal topics, so that the topics can be created
+// when they do not exist yet. Kafka Connect reads the offsets only when it starts, so it warns when the Kafka Connect
+// cluster is already deployed.
+func (r *DataRestorer) loadConnectTopics(data []byte) error {
+	var connectTopics []backuper.ConnectTopic
+	if err := yaml.Unmarshal(data, &connectTopics); err != nil {
+		slog.Error("Failed to unmarshal the Kafka Connect internal topics", "error", err)
+		return err
+	}
+
+	connects := map[string]bool{}
+	for _, connectTopic := range connectTopics {
+		connectTopic.Topic = r.nameMapping.Topic(connectTopic.Topic)
+		r.connectTopics[connectTopic.Topic] = connectTopic
+		connects[connectTopic.Connect] = true
+	}
+
+	for connect := range connects {
+		_, err := r.StrimziClient.KafkaV1beta2().KafkaConnects(r.Namespace).Get(context.TODO(), connect, metav1.GetOptions{})
+		if err == nil {
+			slog.Warn("The KafkaConnect resource already exists. Kafka Connect reads its offsets only when it starts, so restart it after the restore or restore the topic data before deploying it.", "connect", connect)
+		} else if !k8serrors.IsNotFound(err) {
+			slog.Warn("Failed to check whether the KafkaConnect resource exists", "connect", connect, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// createConnectTopic creates the Kafka Connect internal topic with the partitions, replication factor, and
+// configuration from the backup
+func (r *DataRestorer) createConnectTopic(ctx context.Context, admin *kadm.Client, connectTopic backuper.ConnectTopic) error {
+	slog.Info("Creating the Kafka Connect internal topic", "connect", connectTopic.Connect, "role", connectTopic.Role, "topic", connectTopic.Topic, "partitions", connectTopic.Partitions, "replicationFactor", connectTopic.ReplicationFactor)
+
+	configs := map[string]*string{}
+	for key, value := range connectTopic.Config {
+		configs[key] = &value
+	}
+
+	response, err := admin.CreateTopic(ctx, connectTopic.Partitions, connectTopic.ReplicationFactor, configs, connectTopic.Topic)
+	if err == nil {
+		err = response.Err
+	}
+	if err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+		slog.Error("Failed to create the Kafka Connect internal topic", "topic", connectTopic.Topic, "error", err)
+		return err
+	}
+
+	return nil
+}
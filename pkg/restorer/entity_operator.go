@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+func (r *KafkaRestorer) restoreEntityOperatorSecretsStream(resources []byte) error {
+	if !r.restoreEntityOperator {
+		slog.Info("Skipping restoring Entity Operator Secrets (use the --restore-entity-operator option to restore them)")
+		return nil
+	} else if r.skipCaSecrets {
+		// The certificates are signed by the CAs from the backup and would not be trusted by the Kafka cluster
+		slog.Warn("Skipping restoring Entity Operator Secrets as the CA Secrets are not restored")
+		return nil
+	}
+
+	slog.Info("Restoring Entity Operator Secrets")
+
+	if err := r.restoreEntityOperatorSecrets(resources); err != nil {
+		slog.Error("Failed to restore Entity Operator Secrets", "error", err)
+		return err
+	}
+
+	slog.Info("Entity Operator Secrets were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreEntityOperatorRoleBindingsStream(resources []byte) error {
+	if !r.restoreEntityOperator {
+		slog.Info("Skipping restoring Entity Operator RoleBindings (use the --restore-entity-operator option to restore them)")
+		return nil
+	}
+
+	slog.Info("Restoring Entity Operator RoleBindings")
+
+	if err := r.restoreEntityOperatorRoleBindings(resources); err != nil {
+		slog.Error("Failed to restore Entity Operator RoleBindings", "error", err)
+		return err
+	}
+
+	slog.Info("Entity Operator RoleBindings were restored")
+	return nil
+}
+
+// entityOperatorName renames the resource of the Entity Operator when the Kafka cluster is restored under a different
+// name. The names of the Entity Operator resources start with the name of the Kafka cluster followed by -entity-.
+func (r *KafkaRestorer) entityOperatorName(name string) string {
+	if index := strings.Index(name, "-entity-"); index > 0 {
+		return r.Name + name[index:]
+	}
+
+	return name
+}
+
+func (r *KafkaRestorer) restoreEntityOperatorSecrets(resources []byte) error {
+	var secrets *v1.SecretList
+
+	if err := yaml.Unmarshal(resources, &secrets); err != nil {
+		slog.Error("Failed to unmarshall the Entity Operator Secret resources", "error", err)
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		slog.Info("Restoring Entity Operator Secret", "name", secret.Name, "namespace", secret.Namespace)
+
+		secret.Name = r.entityOperatorName(secret.Name)
+
+		utils.CleanseMetadata(&secret.ObjectMeta)
+		r.updateNamespaceAndClusterName(&secret.ObjectMeta)
+
+		r.markRestored(&secret.ObjectMeta)
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Secret"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get, "Secret", secret.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().Secrets(r.Namespace).Patch, secret.Name, &secret, r.Force); err != nil {
+			slog.Error("Failed to restore the Secret", "name", secret.Name, "namespace", secret.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreEntityOperatorRoleBindings restores the RoleBindings of the Topic and User Operators. The RoleBindings from
+// the namespace of the Kafka cluster are restored into the namespace of the restored Kafka cluster. The RoleBindings
+// from the namespaces watched by the operators are restored into the same namespaces.
+func (r *KafkaRestorer) restoreEntityOperatorRoleBindings(resources []byte) error {
+	var roleBindings *rbacv1.RoleBindingList
+
+	if err := yaml.Unmarshal(resources, &roleBindings); err != nil {
+		slog.Error("Failed to unmarshall the Entity Operator RoleBinding resources", "error", err)
+		return err
+	}
+
+	for _, roleBinding := range roleBindings.Items {
+		slog.Info("Restoring Entity Operator RoleBinding", "name", roleBinding.Name, "namespace", roleBinding.Namespace)
+
+		namespace := roleBinding.Namespace
+		if namespace == "" || namespace == r.backedUpNamespace {
+			namespace = r.Namespace
+		}
+
+		roleBinding.Name = r.entityOperatorName(roleBinding.Name)
+
+		utils.CleanseMetadata(&roleBinding.ObjectMeta)
+		r.updateNamespaceAndClusterName(&roleBinding.ObjectMeta)
+		roleBinding.Namespace = namespace
+		if name, ok := roleBinding.Labels["strimzi.io/name"]; ok {
+			roleBinding.Labels["strimzi.io/name"] = r.entityOperatorName(name)
+		}
+
+		// The RoleBindings are bound to the ServiceAccount of the Entity Operator of the restored Kafka cluster
+		for i := range roleBinding.Subjects {
+			if roleBinding.Subjects[i].Kind == rbacv1.ServiceAccountKind {
+				roleBinding.Subjects[i].Name = r.entityOperatorName(roleBinding.Subjects[i].Name)
+				roleBinding.Subjects[i].Namespace = r.Namespace
+			}
+		}
+
+		r.markRestored(&roleBinding.ObjectMeta)
+		roleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.RbacV1().RoleBindings(namespace).Get, "RoleBinding", roleBinding.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.RbacV1().RoleBindings(namespace).Patch, roleBinding.Name, &roleBinding, r.Force); err != nil {
+			slog.Error("Failed to restore the RoleBinding", "name", roleBinding.Name, "namespace", namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+func (r *KafkaRestorer) restoreCertManagerCertificatesStream(resources []byte) error {
+	if !r.restoreExternalConnectivity {
+		slog.Info("Skipping restoring cert-manager Certificates (use the --restore-external-connectivity option to restore them)")
+		return nil
+	}
+
+	slog.Info("Restoring cert-manager Certificates")
+
+	if err := r.restoreExternalConnectivityResources(resources, backuper.CertManagerCertificateResource, nil); err != nil {
+		slog.Error("Failed to restore cert-manager Certificates", "error", err)
+		return err
+	}
+
+	slog.Info("cert-manager Certificates were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreExternalDnsEndpointsStream(resources []byte) error {
+	if !r.restoreExternalConnectivity {
+		slog.Info("Skipping restoring external-dns DNSEndpoints (use the --restore-external-connectivity option to restore them)")
+		return nil
+	}
+
+	slog.Info("Restoring external-dns DNSEndpoints")
+
+	if err := r.restoreExternalConnectivityResources(resources, backuper.ExternalDnsEndpointResource, nil); err != nil {
+		slog.Error("Failed to restore external-dns DNSEndpoints", "error", err)
+		return err
+	}
+
+	// The DNSEndpoints contain the targets (such as the load balancer addresses) of the original Kafka cluster
+	slog.Warn("The external-dns DNSEndpoints were restored with the targets from the backup. Update them when the addresses of the restored external listeners differ.")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreOpenShiftRoutesStream(resources []byte) error {
+	if !r.restoreExternalConnectivity {
+		slog.Info("Skipping restoring OpenShift Routes (use the --restore-external-connectivity option to restore them)")
+		return nil
+	} else if r.backedUpName != "" && r.backedUpName != r.Name {
+		// The Routes are named after the Kafka cluster and point to its Services. The operator creates new Routes for
+		// the renamed cluster.
+		slog.Warn("Skipping restoring OpenShift Routes as the Kafka cluster is restored under a different name")
+		return nil
+	}
+
+	slog.Info("Restoring OpenShift Routes")
+
+	if err := r.restoreExternalConnectivityResources(resources, backuper.OpenShiftRouteResource, r.rewriteRoute); err != nil {
+		slog.Error("Failed to restore OpenShift Routes", "error", err)
+		return err
+	}
+
+	slog.Info("OpenShift Routes were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreListenerCertificatesStream(resources []byte) error {
+	if !r.restoreExternalConnectivity {
+		slog.Info("Skipping restoring custom listener certificates (use the --restore-external-connectivity option to restore them)")
+		return nil
+	}
+
+	slog.Info("Restoring custom listener certificates")
+
+	if err := r.restoreListenerCertificates(resources); err != nil {
+		slog.Error("Failed to restore custom listener certificates", "error", err)
+		return err
+	}
+
+	if r.routeDomain != "" {
+		// The custom certificates were issued for the hostnames from the backup
+		slog.Warn("The custom listener certificates were restored from the backup. Make sure they are valid for the hostnames in the new Route domain.", "routeDomain", r.routeDomain)
+	}
+
+	slog.Info("Custom listener certificates were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreListenerCertificates(resources []byte) error {
+	var secrets *v1.SecretList
+
+	if err := yaml.Unmarshal(resources, &secrets); err != nil {
+		slog.Error("Failed to unmarshall the listener certificate Secret resources", "error", err)
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		slog.Info("Restoring listener certificate Secret", "name", secret.Name, "namespace", secret.Namespace)
+
+		utils.CleanseMetadata(&secret.ObjectMeta)
+		secret.Namespace = r.Namespace
+
+		r.markRestored(&secret.ObjectMeta)
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Secret"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get, "Secret", secret.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().Secrets(r.Namespace).Patch, secret.Name, &secret, r.Force); err != nil {
+			slog.Error("Failed to restore the Secret", "name", secret.Name, "namespace", secret.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewriteRoute moves the host of the Route into the domain configured with the --route-domain option
+func (r *KafkaRestorer) rewriteRoute(item *unstructured.Unstructured) error {
+	if r.routeDomain == "" {
+		return nil
+	}
+
+	host, found, err := unstructured.NestedString(item.Object, "spec", "host")
+	if err != nil {
+		slog.Error("Failed to get the host of the Route", "name", item.GetName(), "error", err)
+		return err
+	} else if !found || host == "" {
+		return nil
+	}
+
+	newHost := rewriteRouteHost(host, r.routeDomain)
+	slog.Info("Rewriting the host of the Route", "name", item.GetName(), "host", host, "newHost", newHost)
+
+	return unstructured.SetNestedField(item.Object, newHost, "spec", "host")
+}
+
+// rewriteRouteListenerHosts moves the hosts of the route listeners configured in the Kafka resource into the domain
+// configured with the --route-domain option
+func (r *KafkaRestorer) rewriteRouteListenerHosts(kafka *v1beta2.Kafka) {
+	if r.routeDomain == "" || kafka.Spec == nil || kafka.Spec.Kafka == nil {
+		return
+	}
+
+	for _, listener := range kafka.Spec.Kafka.Listeners {
+		if listener.Type != v1beta2.ROUTE_KAFKALISTENERTYPE || listener.Configuration == nil {
+			continue
+		}
+
+		if bootstrap := listener.Configuration.Bootstrap; bootstrap != nil && bootstrap.Host != "" {
+			bootstrap.Host = rewriteRouteHost(bootstrap.Host, r.routeDomain)
+			slog.Info("Rewriting the bootstrap host of the route listener", "listener", listener.Name, "host", bootstrap.Host)
+		}
+
+		// We want to update the brokers in place, so we use the index
+		for i := range listener.Configuration.Brokers {
+			broker := &listener.Configuration.Brokers[i]
+			if broker.Host != "" {
+				broker.Host = rewriteRouteHost(broker.Host, r.routeDomain)
+				slog.Info("Rewriting the broker host of the route listener", "listener", listener.Name, "broker", broker.Broker, "host", broker.Host)
+			}
+
+			if broker.AdvertisedHost != "" {
+				broker.AdvertisedHost = rewriteRouteHost(broker.AdvertisedHost, r.routeDomain)
+			}
+		}
+	}
+}
+
+// rewriteRouteHost keeps the first label of the hostname and replaces the rest with the new domain. For example, the
+// host my-cluster-kafka-bootstrap-myproject.apps.old.example.com with the domain apps.new.example.com becomes
+// my-cluster-kafka-bootstrap-myproject.apps.new.example.com.
+func rewriteRouteHost(host string, domain string) string {
+	label, _, _ := strings.Cut(host, ".")
+	return label + "." + strings.TrimPrefix(domain, ".")
+}
+
+// restoreExternalConnectivityResources restores the cert-manager, external-dns, or OpenShift resources into the
+// namespace of the restored Kafka cluster. The optional update function is called for each resource before it is
+// restored.
+func (r *KafkaRestorer) restoreExternalConnectivityResources(resources []byte, resource schema.GroupVersionResource, update func(item *unstructured.Unstructured) error) error {
+	var items *unstructured.UnstructuredList
+
+	if err := yaml.Unmarshal(resources, &items); err != nil {
+		slog.Error("Failed to unmarshall the resources", "resource", resource.GroupResource().String(), "error", err)
+		return err
+	}
+
+	client := r.DynamicClient.Resource(resource).Namespace(r.Namespace)
+	get := func(ctx context.Context, name string, options metav1.GetOptions) (*unstructured.Unstructured, error) {
+		return client.Get(ctx, name, options)
+	}
+
+	for _, item := range items.Items {
+		slog.Info("Restoring resource", "kind", item.GetKind(), "name", item.GetName(), "namespace", r.Namespace)
+
+		item.SetManagedFields(nil)
+		item.SetResourceVersion("")
+		item.SetUID("")
+		item.SetGeneration(0)
+		item.SetCreationTimestamp(metav1.Time{})
+		item.SetOwnerReferences(nil)
+		item.SetNamespace(r.Namespace)
+		unstructured.RemoveNestedField(item.Object, "status")
+
+		annotations := item.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[RestoredFromAnnotation] = filepath.Base(r.BackupFileName)
+		item.SetAnnotations(annotations)
+
+		if update != nil {
+			if err := update(&item); err != nil {
+				return err
+			}
+		}
+
+		if err := checkOwnership(&r.Restorer, get, item.GetKind(), item.GetName()); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(client.Patch, item.GetName(), &item, r.Force); err != nil {
+			slog.Error("Failed to restore the resource", "kind", item.GetKind(), "name", item.GetName(), "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"log/slog"
+)
+
+// restoreKafkaConfigMapsStream restores the ConfigMaps referenced from the Kafka cluster and its node pools. They are
+// restored before the Kafka cluster is unpaused, as the Cluster Operator fails to reconcile the Kafka cluster when they
+// are missing.
+func (r *KafkaRestorer) restoreKafkaConfigMapsStream(resources []byte) error {
+	slog.Info("Restoring the ConfigMaps referenced from the Kafka cluster")
+
+	if err := r.restoreReferencedConfigMaps(resources); err != nil {
+		slog.Error("Failed to restore the ConfigMaps referenced from the Kafka cluster", "error", err)
+		return err
+	}
+
+	slog.Info("The ConfigMaps referenced from the Kafka cluster were restored")
+	return nil
+}
+
+// restoreKafkaReferencedSecretsStream restores the Secrets referenced from the pod templates and the configuration of
+// the Kafka cluster and its node pools. They are restored before the Kafka cluster is unpaused, as the Kafka Pods
+// cannot start when they are missing.
+func (r *KafkaRestorer) restoreKafkaReferencedSecretsStream(resources []byte) error {
+	slog.Info("Restoring the Secrets referenced from the Kafka cluster")
+
+	if err := r.restoreReferencedSecrets(resources); err != nil {
+		slog.Error("Failed to restore the Secrets referenced from the Kafka cluster", "error", err)
+		return err
+	}
+
+	slog.Info("The Secrets referenced from the Kafka cluster were restored")
+	return nil
+}
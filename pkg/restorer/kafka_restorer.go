@@ -19,7 +19,9 @@ package restorer
 import (
 	"context"
 	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
 	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/phases"
 	"github.com/scholzj/strimzi-backup/pkg/utils"
 	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
 	"github.com/spf13/cobra"
@@ -37,6 +39,46 @@ type KafkaRestorer struct {
 	skipCaSecrets   bool
 	skipUserSecrets bool
 	skipClusterID   bool
+
+	// Name and namespace of the Kafka cluster in the backup
+	backedUpName      string
+	backedUpNamespace string
+	// Cluster ID from the backup which is restored once all resources are restored
+	backedUpClusterId string
+
+	failOnNodeIdChange   bool
+	skipNodeIdAssignment bool
+	backedUpNodeIds      map[string][]int32
+
+	skipVolumeCheck  bool
+	volumeCheckImage string
+
+	mergeIntoExisting    bool
+	mergeTargetClusterId string
+
+	restoreExternalConnectivity bool
+	restoreEntityOperator       bool
+	// Domain of the OpenShift Routes of the restored Kafka cluster
+	routeDomain string
+
+	// KafkaTopics and KafkaUsers from other namespaces than the namespace of the Kafka cluster are restored into their
+	// original namespaces or into the namespaces they are mapped to. Otherwise, all of them are restored into the
+	// namespace of the Kafka cluster.
+	namespaceAwareTopics bool
+	topicNamespaces      map[string]string
+	namespaceAwareUsers  bool
+	userNamespaces       map[string]string
+
+	rekeyUserSecrets         bool
+	credentialReportFileName string
+	credentialReport         []CredentialReportEntry
+
+	// Resources from the backup which are restored only by the later restore phases
+	mergedUsers []byte
+	rebalances  []byte
+
+	// MinimalResources indicates that the restored cluster should use minimal resources (used for rehearsals)
+	MinimalResources bool
 }
 
 func NewKafkaRestorer(cmd *cobra.Command) (*KafkaRestorer, error) {
@@ -63,131 +105,476 @@ func NewKafkaRestorer(cmd *cobra.Command) (*KafkaRestorer, error) {
 		return nil, err
 	}
 
+	failOnNodeIdChange, err := cmd.Flags().GetBool("fail-on-node-id-change")
+	if err != nil {
+		slog.Error("Failed to get the --fail-on-node-id-change flag", "error", err)
+		return nil, err
+	}
+
+	skipNodeIdAssignment, err := cmd.Flags().GetBool("skip-node-id-assignment")
+	if err != nil {
+		slog.Error("Failed to get the --skip-node-id-assignment flag", "error", err)
+		return nil, err
+	}
+
+	rekeyUserSecrets, err := cmd.Flags().GetBool("rekey-user-secrets")
+	if err != nil {
+		slog.Error("Failed to get the --rekey-user-secrets flag", "error", err)
+		return nil, err
+	}
+
+	skipVolumeCheck, err := cmd.Flags().GetBool("skip-volume-check")
+	if err != nil {
+		slog.Error("Failed to get the --skip-volume-check flag", "error", err)
+		return nil, err
+	}
+
+	// The merge mode is available only in the restore kafka command
+	var mergeIntoExisting bool
+	if cmd.Flags().Lookup("merge-into-existing") != nil {
+		mergeIntoExisting, err = cmd.Flags().GetBool("merge-into-existing")
+		if err != nil {
+			slog.Error("Failed to get the --merge-into-existing flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// The external connectivity resources are restored only by the restore kafka command, never in rehearsals
+	var restoreExternalConnectivity bool
+	if cmd.Flags().Lookup("restore-external-connectivity") != nil {
+		restoreExternalConnectivity, err = cmd.Flags().GetBool("restore-external-connectivity")
+		if err != nil {
+			slog.Error("Failed to get the --restore-external-connectivity flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// The Route domain is used only by the restore kafka command
+	var routeDomain string
+	if cmd.Flags().Lookup("route-domain") != nil {
+		routeDomain = cmd.Flag("route-domain").Value.String()
+	}
+
+	// The Entity Operator resources are restored only by the restore kafka command, never in rehearsals
+	var restoreEntityOperator bool
+	if cmd.Flags().Lookup("restore-entity-operator") != nil {
+		restoreEntityOperator, err = cmd.Flags().GetBool("restore-entity-operator")
+		if err != nil {
+			slog.Error("Failed to get the --restore-entity-operator flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// The KafkaTopics are restored into other namespaces only by the restore kafka and restore all commands, never in
+	// rehearsals
+	var namespaceAwareTopics bool
+	var topicNamespaces map[string]string
+	if cmd.Flags().Lookup("topic-namespaces") != nil {
+		namespaceAwareTopics = true
+
+		topicNamespaces, err = cmd.Flags().GetStringToString("topic-namespaces")
+		if err != nil {
+			slog.Error("Failed to get the --topic-namespaces flag", "error", err)
+			return nil, err
+		}
+	}
+
+	// The same applies to the KafkaUsers and their Secrets
+	var namespaceAwareUsers bool
+	var userNamespaces map[string]string
+	if cmd.Flags().Lookup("user-namespaces") != nil {
+		namespaceAwareUsers = true
+
+		userNamespaces, err = cmd.Flags().GetStringToString("user-namespaces")
+		if err != nil {
+			slog.Error("Failed to get the --user-namespaces flag", "error", err)
+			return nil, err
+		}
+	}
+
 	kafkaRestorer := &KafkaRestorer{
-		Restorer:        *restorer,
-		skipCaSecrets:   skipCaSecrets,
-		skipUserSecrets: skipUserSecrets,
-		skipClusterID:   skipClusterId,
+		Restorer:                    *restorer,
+		skipCaSecrets:               skipCaSecrets,
+		skipUserSecrets:             skipUserSecrets,
+		skipClusterID:               skipClusterId,
+		failOnNodeIdChange:          failOnNodeIdChange,
+		skipNodeIdAssignment:        skipNodeIdAssignment,
+		backedUpNodeIds:             map[string][]int32{},
+		skipVolumeCheck:             skipVolumeCheck,
+		volumeCheckImage:            cmd.Flag("volume-check-image").Value.String(),
+		mergeIntoExisting:           mergeIntoExisting,
+		restoreExternalConnectivity: restoreExternalConnectivity,
+		restoreEntityOperator:       restoreEntityOperator,
+		routeDomain:                 routeDomain,
+		namespaceAwareTopics:        namespaceAwareTopics,
+		topicNamespaces:             topicNamespaces,
+		namespaceAwareUsers:         namespaceAwareUsers,
+		userNamespaces:              userNamespaces,
+		rekeyUserSecrets:            rekeyUserSecrets,
+		credentialReportFileName:    cmd.Flag("credential-report").Value.String(),
 	}
 
 	return kafkaRestorer, nil
 }
 
+// Restore phases of the restore kafka command
+const (
+	PhasePrepareMerge          = "prepare-merge"
+	PhaseRestoreResources      = "restore-resources"
+	PhaseWriteCredentialReport = "write-credential-report"
+	PhaseMergeUsers            = "merge-users"
+	PhaseCheckVolumes          = "check-volumes"
+	PhaseRestoreClusterId      = "restore-cluster-id"
+	PhaseUnpause               = "unpause"
+	PhaseVerifyNodeIds         = "verify-node-ids"
+	PhaseRestoreRebalances     = "restore-rebalances"
+	PhaseDeleteCheckpoint      = "delete-checkpoint"
+)
+
+// RestoreKafka restores the Kafka cluster by running all its restore phases
 func (r *KafkaRestorer) RestoreKafka() error {
-	var clusterId string // Is used later to restore the cluster ID
+	return phases.Run(context.TODO(), r.Phases())
+}
 
-	for {
-		r.gzipReader.Multistream(false)
+// Phases returns the phases of the Kafka cluster restore in the order in which they have to be run
+func (r *KafkaRestorer) Phases() []phases.Phase {
+	var restorePhases []phases.Phase
 
-		resources, err := io.ReadAll(r.gzipReader)
-		if err != nil {
-			slog.Error("Failed to read from the backup file", "error", err)
-			return err
-		}
+	if r.mergeIntoExisting {
+		restorePhases = append(restorePhases, phases.New(PhasePrepareMerge, "Check the existing Kafka cluster into which the backup is merged", func(ctx context.Context) error {
+			return r.prepareMerge()
+		}))
+	}
 
-		switch r.gzipReader.Name {
-		case backuper.KafkaFilename:
-			slog.Info("Restoring paused Kafka resource")
+	restorePhases = append(restorePhases, phases.New(PhaseRestoreResources, "Restore the resources from the backup with the Kafka cluster paused", func(ctx context.Context) error {
+		return r.restoreResources()
+	}))
 
-			clusterId, err = r.restoreKafka(resources)
-			if err != nil {
-				slog.Error("Failed to restore Kafka resource", "error", err)
-				return err
-			}
+	if r.rekeyUserSecrets {
+		restorePhases = append(restorePhases, phases.New(PhaseWriteCredentialReport, "Report the changed credentials of the Kafka Users", func(ctx context.Context) error {
+			return r.writeCredentialReport()
+		}))
+	}
 
-			slog.Info("Kafka resource was restored in paused state")
+	if r.mergeIntoExisting {
+		return append(restorePhases,
+			phases.New(PhaseMergeUsers, "Add the Kafka Users after their Secrets to the existing Kafka cluster", func(ctx context.Context) error {
+				return r.restoreMergedUsers()
+			}),
+			phases.New(PhaseDeleteCheckpoint, "Delete the restore checkpoint", func(ctx context.Context) error {
+				return r.checkpoint.Delete()
+			}),
+		)
+	}
 
-			break
-		case backuper.CaSecretsFilename:
-			if r.skipCaSecrets {
-				slog.Warn("Skipping restoring CA Secrets")
-			} else {
-				slog.Info("Restoring CA Secrets")
-
-				if err := r.restoreCaSecrets(resources); err != nil {
-					slog.Error("Failed to restore CA Secrets", "error", err)
-					return err
-				}
-
-				slog.Info("CA Secrets were restored")
+	return append(restorePhases,
+		phases.New(PhaseCheckVolumes, "Check the Cluster ID stored on the existing volumes of the Kafka nodes", func(ctx context.Context) error {
+			return r.checkVolumes()
+		}),
+		phases.New(PhaseRestoreClusterId, "Restore the Cluster ID of the Kafka cluster", func(ctx context.Context) error {
+			// We restore the Cluster ID only now to avoid the race condition from https://github.com/scholzj/strimzi-backup/issues/19
+			if err := r.restoreKafkaClusterId(r.clusterId()); err != nil {
+				slog.Error("Failed to restore Kafka Cluster ID", "error", err)
+				return err
 			}
 
-			break
-		case backuper.KafkaNodePoolsFilename:
-			slog.Info("Restoring Kafka Node Pools")
-
-			if err := r.restoreKafkaNodePools(resources); err != nil {
-				slog.Error("Failed to restore Kafka Node Pool resources", "error", err)
+			return nil
+		}),
+		phases.New(PhaseUnpause, "Unpause the Kafka cluster and wait for it to get ready", func(ctx context.Context) error {
+			if err := r.unpauseKafkaClusterAndWaitForReadiness(); err != nil {
+				slog.Error("Failed to unpause Kafka cluster and get it into the Ready state", "error", err)
 				return err
 			}
 
-			slog.Info("Kafka Node Pools were restored")
-			break
-		case backuper.KafkaUsersFilename:
-			slog.Info("Restoring Kafka Users")
-
-			if err := r.restoreKafkaUsers(resources); err != nil {
-				slog.Error("Failed to restore Kafka Users resources", "error", err)
+			return nil
+		}),
+		phases.New(PhaseVerifyNodeIds, "Verify that the restored Kafka nodes use the node IDs from the backup", func(ctx context.Context) error {
+			if err := r.verifyNodeIds(); err != nil {
+				slog.Error("The node IDs of the restored Kafka cluster differ from the backup", "error", err)
 				return err
 			}
 
-			slog.Info("Kafka Users were restored")
-			break
-		case backuper.KafkaTopicsFilename:
-			slog.Info("Restoring Kafka Topics")
-
-			if err := r.restoreKafkaTopics(resources); err != nil {
-				slog.Error("Failed to restore Kafka Topic resources", "error", err)
+			return nil
+		}),
+		phases.New(PhaseRestoreRebalances, "Restore the Kafka Rebalances once the Kafka cluster is ready", func(ctx context.Context) error {
+			return r.restoreDeferredRebalances()
+		}),
+		phases.New(PhaseDeleteCheckpoint, "Delete the restore checkpoint", func(ctx context.Context) error {
+			if err := r.checkpoint.Delete(); err != nil {
+				slog.Error("Failed to delete the restore checkpoint", "error", err)
 				return err
 			}
 
-			slog.Info("Kafka Topics were restored")
-			break
-		case backuper.KafkaUserSecretsFilename:
-			if r.skipCaSecrets {
-				slog.Warn("Skipping restoring Kafka User Secrets")
-			} else {
-				slog.Info("Restoring Kafka User Secrets")
-
-				if err := r.restoreSecrets(resources); err != nil {
-					slog.Error("Failed to restore Kafka User Secrets", "error", err)
-					return err
-				}
-
-				slog.Info("Kafka User Secrets were restored")
-			}
+			return nil
+		}),
+	)
+}
 
+// restoreResources restores the resources from the backup one stream after another. The Kafka Users merged into an
+// existing cluster and the Kafka Rebalances are only kept to be restored by the later phases.
+func (r *KafkaRestorer) restoreResources() error {
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
 			break
-		default:
-			slog.Error("Unknown resources found in backup", "name", r.gzipReader.Name, "comment", r.gzipReader.Comment, "modTime", r.gzipReader.ModTime)
-			return fmt.Errorf("unknown resources %v found in backup", r.gzipReader.Name)
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
 		}
 
-		if err := r.gzipReader.Reset(r.bufferedReader); err != nil {
-			if err == io.EOF {
-				slog.Info("Restoring data completed")
-				break
-			} else {
-				slog.Error("Failed to read the backup", "error", err)
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+		} else if r.mergeIntoExisting && member.Name == backuper.KafkaFilename {
+			if err := r.checkMergeClusterId(member.Data); err != nil {
+				return err
+			}
+		} else if r.mergeIntoExisting && member.Name == backuper.KafkaUsersFilename {
+			// The User Operator of the existing cluster would generate new credentials for the users added before their
+			// Secrets, so the users are added at the end
+			r.mergedUsers = member.Data
+		} else if r.mergeIntoExisting && member.Name != backuper.KafkaTopicsFilename && member.Name != backuper.KafkaUsersFilename && member.Name != backuper.KafkaUserSecretsFilename {
+			// The Kafka cluster, its node pools, and its CAs already exist and are never modified in the merge mode
+			slog.Info("Skipping resources which are not merged into the existing Kafka cluster", "name", member.Name)
+		} else if member.Name == backuper.KafkaRebalancesFilename {
+			// The stream is marked as completed in the checkpoint only once the rebalances are restored
+			r.rebalances = member.Data
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != "" && stream.RestoredBy != backuper.RestoredByKafka {
+			// The streams restored by other commands are not marked as completed in the checkpoint, so that they are not
+			// skipped by them
+			slog.Info("Skipping resources which are restored using a different command", "name", member.Name, "command", stream.RestoredBy)
+		} else {
+			if stream.RestoredBy == "" {
+				slog.Info("Skipping informational data which are not restored", "name", member.Name)
+			} else if err := kafkaStreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
 				return err
 			}
 		}
 	}
 
-	// We restore the Cluster ID only now to avoid the race condition from https://github.com/scholzj/strimzi-backup/issues/19
-	if err := r.restoreKafkaClusterId(clusterId); err != nil {
-		slog.Error("Failed to restore Kafka Cluster ID", "error", err)
+	r.reportMissingSecretStreams()
+
+	return nil
+}
+
+// restoreMergedUsers adds the Kafka Users to the existing Kafka cluster after their Secrets were restored
+func (r *KafkaRestorer) restoreMergedUsers() error {
+	if r.mergedUsers != nil {
+		if err := r.restoreKafkaUsersStream(r.mergedUsers); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("The backup was merged into the existing Kafka cluster", "name", r.Name, "namespace", r.Namespace)
+	return nil
+}
+
+// clusterId returns the Cluster ID from the backup
+func (r *KafkaRestorer) clusterId() string {
+	if r.backedUpClusterId != "" {
+		return r.backedUpClusterId
+	}
+
+	// The Kafka resource might have been restored before the restore was interrupted
+	return r.checkpoint.ClusterId()
+}
+
+// checkVolumes checks the existing volumes before the Kafka cluster is unpaused and its nodes try to use them
+func (r *KafkaRestorer) checkVolumes() error {
+	restoredClusterId := r.clusterId()
+	if r.skipClusterID {
+		restoredClusterId = ""
+	}
+
+	if r.skipVolumeCheck {
+		slog.Warn("Skipping the check of the Cluster ID stored on the existing volumes")
+	} else if err := r.checkVolumeClusterIds(restoredClusterId); err != nil {
+		slog.Error("Failed to check the Cluster ID stored on the existing volumes", "error", err)
 		return err
 	}
 
-	if err := r.unpauseKafkaClusterAndWaitForReadiness(); err != nil {
-		slog.Error("Failed to unpause Kafka cluster and get it into the Ready state", "error", err)
+	return nil
+}
+
+// restoreDeferredRebalances restores the Kafka Rebalances kept from the restore of the resources
+func (r *KafkaRestorer) restoreDeferredRebalances() error {
+	if r.rebalances == nil {
+		return nil
+	}
+
+	if err := r.restoreKafkaRebalancesStream(r.rebalances); err != nil {
+		return err
+	}
+
+	if err := r.checkpoint.MarkCompleted(backuper.KafkaRebalancesFilename); err != nil {
+		slog.Error("Failed to record the restore progress", "name", backuper.KafkaRebalancesFilename, "error", err)
 		return err
 	}
 
 	return nil
 }
 
+// reportMissingSecretStreams warns about the CA Secrets and the Kafka User Secrets missing in the backup, for example
+// because it was taken with the --skip-ca-secrets or --skip-user-secrets options. The Strimzi operators generate new
+// CAs and credentials instead of them, which the clients have to pick up. The warnings are included in the run report.
+func (r *KafkaRestorer) reportMissingSecretStreams() {
+	if !r.reader.Seen(backuper.CaSecretsFilename) && !r.skipCaSecrets && !r.mergeIntoExisting {
+		slog.Warn("The backup does not contain the CA Secrets (it was probably taken with the --skip-ca-secrets option) => the Cluster Operator will generate new Cluster and Clients CAs and the clients have to trust the new Cluster CA certificate", "stream", backuper.CaSecretsFilename)
+	}
+
+	if !r.reader.Seen(backuper.KafkaUserSecretsFilename) && !r.skipUserSecrets && !r.rekeyUserSecrets {
+		slog.Warn("The backup does not contain the Kafka User Secrets (it was probably taken with the --skip-user-secrets option) => the User Operator will generate new credentials for the restored Kafka Users and the clients have to use them", "stream", backuper.KafkaUserSecretsFilename)
+	}
+}
+
+// kafkaStreamRestorers maps the streams restored by the restore kafka command to the functions restoring them
+var kafkaStreamRestorers = map[string]func(r *KafkaRestorer, resources []byte) error{
+	backuper.KafkaFilename:                      (*KafkaRestorer).restoreKafkaStream,
+	backuper.CaSecretsFilename:                  (*KafkaRestorer).restoreCaSecretsStream,
+	backuper.KafkaListenerCertificatesFilename:  (*KafkaRestorer).restoreKafkaListenerCertificatesStream,
+	backuper.KafkaNodePoolsFilename:             (*KafkaRestorer).restoreKafkaNodePoolsStream,
+	backuper.KafkaAuthenticationSecretsFilename: (*KafkaRestorer).restoreAuthenticationSecretsStream,
+	backuper.KafkaConfigMapsFilename:            (*KafkaRestorer).restoreKafkaConfigMapsStream,
+	backuper.KafkaReferencedSecretsFilename:     (*KafkaRestorer).restoreKafkaReferencedSecretsStream,
+	backuper.KafkaTopicsFilename:                (*KafkaRestorer).restoreKafkaTopicsStream,
+	backuper.KafkaUsersFilename:                 (*KafkaRestorer).restoreKafkaUsersStream,
+	backuper.KafkaUserSecretsFilename:           (*KafkaRestorer).restoreUserSecretsStream,
+	backuper.ApicurioRegistryConfigMapsFilename: (*KafkaRestorer).restoreApicurioRegistryConfigMapsStream,
+	backuper.ApicurioRegistriesFilename:         (*KafkaRestorer).restoreApicurioRegistriesStream,
+	backuper.CertManagerCertificatesFilename:    (*KafkaRestorer).restoreCertManagerCertificatesStream,
+	backuper.ExternalDnsEndpointsFilename:       (*KafkaRestorer).restoreExternalDnsEndpointsStream,
+	backuper.OpenShiftRoutesFilename:            (*KafkaRestorer).restoreOpenShiftRoutesStream,
+	backuper.ListenerCertificatesFilename:       (*KafkaRestorer).restoreListenerCertificatesStream,
+	backuper.EntityOperatorSecretsFilename:      (*KafkaRestorer).restoreEntityOperatorSecretsStream,
+	backuper.EntityOperatorRoleBindingsFilename: (*KafkaRestorer).restoreEntityOperatorRoleBindingsStream,
+}
+
+func (r *KafkaRestorer) restoreKafkaStream(resources []byte) error {
+	slog.Info("Restoring paused Kafka resource")
+
+	clusterId, err := r.restoreKafka(resources)
+	if err != nil {
+		slog.Error("Failed to restore Kafka resource", "error", err)
+		return err
+	}
+	r.backedUpClusterId = clusterId
+
+	if err := r.checkpoint.SetClusterId(clusterId); err != nil {
+		slog.Error("Failed to record the Kafka Cluster ID in the restore checkpoint", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka resource was restored in paused state")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreCaSecretsStream(resources []byte) error {
+	if r.skipCaSecrets {
+		slog.Warn("Skipping restoring CA Secrets")
+		return nil
+	}
+
+	slog.Info("Restoring CA Secrets")
+
+	if err := r.restoreCaSecrets(resources); err != nil {
+		slog.Error("Failed to restore CA Secrets", "error", err)
+		return err
+	}
+
+	slog.Info("CA Secrets were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreKafkaNodePoolsStream(resources []byte) error {
+	if archive.CountResources(resources) == 0 {
+		slog.Warn("No Kafka Node Pools found in the backup => the node IDs are not verified. Kafka clusters without node pools are not supported by Strimzi 0.46 and newer.")
+		return nil
+	}
+
+	slog.Info("Restoring Kafka Node Pools")
+
+	if err := r.restoreKafkaNodePools(resources); err != nil {
+		slog.Error("Failed to restore Kafka Node Pool resources", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka Node Pools were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreKafkaTopicsStream(resources []byte) error {
+	slog.Info("Restoring Kafka Topics")
+
+	if err := r.restoreKafkaTopics(resources); err != nil {
+		slog.Error("Failed to restore Kafka Topic resources", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka Topics were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreKafkaUsersStream(resources []byte) error {
+	slog.Info("Restoring Kafka Users")
+
+	if err := r.restoreKafkaUsers(resources); err != nil {
+		slog.Error("Failed to restore Kafka Users resources", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka Users were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreUserSecretsStream(resources []byte) error {
+	if r.skipUserSecrets {
+		slog.Warn("Skipping restoring Kafka User Secrets")
+		return nil
+	}
+
+	slog.Info("Restoring Kafka User Secrets")
+
+	if err := r.restoreSecrets(resources); err != nil {
+		slog.Error("Failed to restore Kafka User Secrets", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka User Secrets were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreApicurioRegistryConfigMapsStream(resources []byte) error {
+	slog.Info("Restoring ConfigMaps used by the Apicurio Registries")
+
+	if err := r.restoreApicurioRegistryConfigMaps(resources); err != nil {
+		slog.Error("Failed to restore ConfigMaps used by the Apicurio Registries", "error", err)
+		return err
+	}
+
+	slog.Info("ConfigMaps used by the Apicurio Registries were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreApicurioRegistriesStream(resources []byte) error {
+	slog.Info("Restoring Apicurio Registries")
+
+	if err := r.restoreApicurioRegistries(resources); err != nil {
+		slog.Error("Failed to restore Apicurio Registries", "error", err)
+		return err
+	}
+
+	slog.Info("Apicurio Registries were restored")
+	return nil
+}
+
 func (r *KafkaRestorer) restoreKafka(resource []byte) (string, error) {
 	var kafka *v1beta2.Kafka
 
@@ -196,6 +583,20 @@ func (r *KafkaRestorer) restoreKafka(resource []byte) (string, error) {
 		return "", err
 	}
 
+	r.backedUpName = kafka.Name
+	r.backedUpNamespace = kafka.Namespace
+
+	if err := r.checkSourceNamespace(kafka.Namespace); err != nil {
+		return "", err
+	}
+
+	// The version of the operator is recorded also in the manifest, but that is the last stream of the backup
+	operatorVersion := ""
+	if kafka.Status != nil {
+		operatorVersion = kafka.Status.OperatorLastSuccessfulVersion
+	}
+	r.checkOperatorVersionSkew(operatorVersion)
+
 	// We update the metadata and pause the resource
 	utils.CleanseMetadata(&kafka.ObjectMeta)
 	kafka.Namespace = r.Namespace
@@ -206,13 +607,27 @@ func (r *KafkaRestorer) restoreKafka(resource []byte) (string, error) {
 		kafka.Annotations["strimzi.io/pause-reconciliation"] = "true"
 	}
 
-	if _, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Create(context.TODO(), kafka, metav1.CreateOptions{}); err != nil {
+	if r.MinimalResources {
+		minimizeKafka(kafka)
+	}
+
+	r.rewriteRouteListenerHosts(kafka)
+	r.updateWatchedNamespaces(kafka)
+
+	r.markRestored(&kafka.ObjectMeta)
+	kafka.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "Kafka"}
+
+	if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get, "Kafka", kafka.Name); err != nil {
+		return "", err
+	}
+
+	if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Patch, kafka.Name, kafka, r.Force); err != nil {
 		slog.Error("Failed to restore the Kafka resource", "error", err)
 		return "", err
 	}
 
 	// Wait for the paused reconciliation to be confirmed
-	_, err := utils.WaitUntilReconciliationPaused(r.StrimziClient, r.Name, r.Namespace, r.Timeout)
+	_, err := utils.WaitUntilReconciliationPaused(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.PauseTimeout)
 	if err != nil {
 		slog.Error("The Kafka resource was not paused. Please check the Cluster Operator logs for more details.", "error", err)
 		return "", err
@@ -239,10 +654,13 @@ func (r *KafkaRestorer) restoreKafkaClusterId(clusterId string) error {
 		// We restore the Cluster ID
 		if clusterId != "" {
 			slog.Info("Restoring Kafka Cluster ID", "clusterId", clusterId)
-			kafkaWithClusterId := kafka.DeepCopy()
-			kafkaWithClusterId.Status.ClusterId = clusterId
+			kafkaWithClusterId := &v1beta2.Kafka{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "Kafka"},
+				ObjectMeta: metav1.ObjectMeta{Name: kafka.Name, Namespace: kafka.Namespace},
+				Status:     &v1beta2.KafkaStatus{ClusterId: clusterId},
+			}
 
-			if _, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).UpdateStatus(context.TODO(), kafkaWithClusterId, metav1.UpdateOptions{}); err != nil {
+			if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Patch, kafka.Name, kafkaWithClusterId, r.Force, "status"); err != nil {
 				slog.Error("Failed to update the status of the Kafka resource and set the Cluster ID", "error", err)
 				return err
 			}
@@ -271,14 +689,19 @@ func (r *KafkaRestorer) unpauseKafkaClusterAndWaitForReadiness() error {
 			unpausedKafka.Annotations["strimzi.io/pause-reconciliation"] = "false"
 		}
 
-		_, err = r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Update(context.TODO(), unpausedKafka, metav1.UpdateOptions{})
+		// The whole resource is applied again as fields missing in the applied configuration would be removed
+		utils.CleanseMetadata(&unpausedKafka.ObjectMeta)
+		unpausedKafka.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "Kafka"}
+		unpausedKafka.Status = nil
+
+		_, err = utils.Apply(r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Patch, unpausedKafka.Name, unpausedKafka, r.Force)
 		if err != nil {
 			slog.Error("Failed to unpause the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
 			return err
 		}
 
 		slog.Info("Waiting for the Kafka cluster to get ready", "name", r.Name, "namespace", r.Namespace)
-		_, err = utils.WaitUntilReady(r.StrimziClient, r.Name, r.Namespace, r.Timeout)
+		_, err = utils.WaitUntilReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.ReadyTimeout, r.StallTimeout)
 		if err != nil {
 			slog.Error("The Kafka cluster did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
 			return err
@@ -289,7 +712,7 @@ func (r *KafkaRestorer) unpauseKafkaClusterAndWaitForReadiness() error {
 		slog.Warn("The Kafka cluster is already ready and does not need to be unpaused", "name", r.Name, "namespace", r.Namespace)
 	} else {
 		slog.Warn("The Kafka cluster is not paused, but it is not ready. Waiting for the Kafka cluster to get ready.", "name", r.Name, "namespace", r.Namespace)
-		_, err = utils.WaitUntilReady(r.StrimziClient, r.Name, r.Namespace, r.Timeout)
+		_, err = utils.WaitUntilReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.ReadyTimeout, r.StallTimeout)
 		if err != nil {
 			slog.Error("The Kafka cluster did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
 			return err
@@ -321,10 +744,31 @@ func (r *KafkaRestorer) restoreKafkaNodePools(resources []byte) error {
 	for _, nodePool := range nodePools.Items {
 		slog.Info("Restoring Kafka Node Pool", "name", nodePool.Name, "namespace", nodePool.Namespace)
 
+		if nodePool.Status != nil && len(nodePool.Status.NodeIds) > 0 {
+			r.backedUpNodeIds[nodePool.Name] = nodePool.Status.NodeIds
+		}
+
 		utils.CleanseMetadata(&nodePool.ObjectMeta)
 		r.updateNamespaceAndClusterName(&nodePool.ObjectMeta)
 
-		if _, err := r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).Create(context.TODO(), &nodePool, metav1.CreateOptions{}); err != nil {
+		if skip, err := skipExisting(r, r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).Get, "KafkaNodePool", nodePool.Name); err != nil {
+			return err
+		} else if skip {
+			continue
+		}
+
+		if !r.skipNodeIdAssignment {
+			r.assignNodeIds(&nodePool)
+		}
+
+		if r.MinimalResources {
+			minimizeKafkaNodePool(&nodePool)
+		}
+
+		r.markRestored(&nodePool.ObjectMeta)
+		nodePool.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaNodePool"}
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).Patch, nodePool.Name, &nodePool, r.Force); err != nil {
 			slog.Error("Failed to restore the Kafka Node Pool resource", "name", nodePool.Name, "namespace", nodePool.Namespace, "error", err)
 			return err
 		}
@@ -341,18 +785,37 @@ func (r *KafkaRestorer) restoreKafkaUsers(resources []byte) error {
 		return err
 	}
 
+	progress := NewProgress("KafkaUser", len(users.Items))
 	for _, user := range users.Items {
-		slog.Info("Restoring Kafka User", "name", user.Name, "namespace", user.Namespace)
+		progress.Restoring("Restoring Kafka User", "name", user.Name, "namespace", user.Namespace)
 
+		backedUpNamespace := user.Namespace
 		utils.CleanseMetadata(&user.ObjectMeta)
 		r.updateNamespaceAndClusterName(&user.ObjectMeta)
+		user.Namespace = r.userNamespace(backedUpNamespace)
+
+		if err := r.nameMapping.mapUser(&user); err != nil {
+			slog.Error("Failed to rename the Kafka User", "name", user.Name, "error", err)
+			return err
+		}
+
+		if skip, err := skipExisting(r, r.StrimziClient.KafkaV1beta2().KafkaUsers(user.Namespace).Get, "KafkaUser", user.Name); err != nil {
+			return err
+		} else if skip {
+			continue
+		}
 
-		if _, err := r.StrimziClient.KafkaV1beta2().KafkaUsers(r.Namespace).Create(context.TODO(), &user, metav1.CreateOptions{}); err != nil {
+		r.markRestored(&user.ObjectMeta)
+		user.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaUser"}
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaUsers(user.Namespace).Patch, user.Name, &user, r.Force); err != nil {
 			slog.Error("Failed to restore the Kafka User resource", "name", user.Name, "namespace", user.Namespace, "error", err)
 			return err
 		}
 	}
 
+	progress.Done()
+
 	return nil
 }
 
@@ -364,18 +827,42 @@ func (r *KafkaRestorer) restoreKafkaTopics(resources []byte) error {
 		return err
 	}
 
+	progress := NewProgress("KafkaTopic", len(topics.Items))
 	for _, topic := range topics.Items {
-		slog.Info("Restoring Kafka Topic", "name", topic.Name, "namespace", topic.Namespace)
+		progress.Restoring("Restoring Kafka Topic", "name", topic.Name, "namespace", topic.Namespace)
 
+		backedUpNamespace := topic.Namespace
 		utils.CleanseMetadata(&topic.ObjectMeta)
 		r.updateNamespaceAndClusterName(&topic.ObjectMeta)
+		topic.Namespace = r.topicNamespace(backedUpNamespace)
+
+		// Backups taken by older versions might have the name of the Kafka topic only in the status or in the annotation
+		if utils.PinKafkaTopicName(&topic) {
+			slog.Info("Restoring the Kafka Topic with the name of the Kafka topic from the backup", "name", topic.Name, "topicName", topic.Spec.TopicName)
+		}
 
-		if _, err := r.StrimziClient.KafkaV1beta2().KafkaTopics(r.Namespace).Create(context.TODO(), &topic, metav1.CreateOptions{}); err != nil {
+		if err := r.nameMapping.mapTopic(&topic); err != nil {
+			slog.Error("Failed to rename the Kafka Topic", "name", topic.Name, "error", err)
+			return err
+		}
+
+		if skip, err := skipExisting(r, r.StrimziClient.KafkaV1beta2().KafkaTopics(topic.Namespace).Get, "KafkaTopic", topic.Name); err != nil {
+			return err
+		} else if skip {
+			continue
+		}
+
+		r.markRestored(&topic.ObjectMeta)
+		topic.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaTopic"}
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaTopics(topic.Namespace).Patch, topic.Name, &topic, r.Force); err != nil {
 			slog.Error("Failed to restore the Kafka Topic resource", "name", topic.Name, "namespace", topic.Namespace, "error", err)
 			return err
 		}
 	}
 
+	progress.Done()
+
 	return nil
 }
 
@@ -404,7 +891,14 @@ func (r *KafkaRestorer) restoreCaSecrets(resources []byte) error {
 		utils.CleanseMetadata(&secret.ObjectMeta)
 		r.updateNamespaceAndClusterName(&secret.ObjectMeta)
 
-		if _, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).Create(context.TODO(), &secret, metav1.CreateOptions{}); err != nil {
+		r.markRestored(&secret.ObjectMeta)
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Secret"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get, "Secret", secret.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().Secrets(r.Namespace).Patch, secret.Name, &secret, r.Force); err != nil {
 			slog.Error("Failed to restore the Secret", "name", secret.Name, "namespace", secret.Namespace, "error", err)
 			return err
 		}
@@ -421,18 +915,45 @@ func (r *KafkaRestorer) restoreSecrets(resources []byte) error {
 		return err
 	}
 
+	if r.rekeyUserSecrets {
+		rekeyed, err := r.rekeySecrets(secrets.Items)
+		if err != nil {
+			return err
+		}
+
+		secrets.Items = rekeyed
+	}
+
+	progress := NewProgress("Secret", len(secrets.Items))
 	for _, secret := range secrets.Items {
-		slog.Info("Restoring Secret", "name", secret.Name, "namespace", secret.Namespace)
+		if !r.nameMapping.mapUserSecret(&secret) {
+			continue
+		}
+
+		progress.Restoring("Restoring Secret", "name", secret.Name, "namespace", secret.Namespace)
 
+		backedUpNamespace := secret.Namespace
 		utils.CleanseMetadata(&secret.ObjectMeta)
 		r.updateNamespaceAndClusterName(&secret.ObjectMeta)
+		secret.Namespace = r.userNamespace(backedUpNamespace)
 
-		if _, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).Create(context.TODO(), &secret, metav1.CreateOptions{}); err != nil {
+		if skip, err := skipExisting(r, r.KubernetesClient.CoreV1().Secrets(secret.Namespace).Get, "Secret", secret.Name); err != nil {
+			return err
+		} else if skip {
+			continue
+		}
+
+		r.markRestored(&secret.ObjectMeta)
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Secret"}
+
+		if _, err := utils.Apply(r.KubernetesClient.CoreV1().Secrets(secret.Namespace).Patch, secret.Name, &secret, r.Force); err != nil {
 			slog.Error("Failed to restore the Secret", "name", secret.Name, "namespace", secret.Namespace, "error", err)
 			return err
 		}
 	}
 
+	progress.Done()
+
 	return nil
 }
 
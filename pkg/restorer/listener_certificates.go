@@ -0,0 +1,41 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"log/slog"
+)
+
+// restoreKafkaListenerCertificatesStream restores the Secrets with the custom listener certificates. They are restored
+// before the Kafka cluster is unpaused, so that the Cluster Operator uses them instead of generating new listener
+// certificates.
+func (r *KafkaRestorer) restoreKafkaListenerCertificatesStream(resources []byte) error {
+	slog.Info("Restoring custom listener certificates")
+
+	if err := r.restoreListenerCertificates(resources); err != nil {
+		slog.Error("Failed to restore custom listener certificates", "error", err)
+		return err
+	}
+
+	if r.routeDomain != "" {
+		// The custom certificates were issued for the hostnames from the backup
+		slog.Warn("The custom listener certificates were restored from the backup. Make sure they are valid for the hostnames in the new Route domain.", "routeDomain", r.routeDomain)
+	}
+
+	slog.Info("Custom listener certificates were restored")
+	return nil
+}
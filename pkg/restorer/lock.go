@@ -0,0 +1,201 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RestoreLock is a Lease in the namespace of the restored Kafka cluster which prevents two overlapping restores of the
+// same Kafka cluster. The Lease is renewed while the restore is running and released when it finishes. When the restore
+// is killed without releasing the lock, it expires after its TTL.
+type RestoreLock struct {
+	client    *kubernetes.Clientset
+	namespace string
+	name      string
+	holder    string
+	ttl       time.Duration
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// LockName returns the name of the Lease used to lock the restore of given Kafka cluster
+func LockName(name string) string {
+	return "strimzi-backup-lock-" + name
+}
+
+// AcquireLock creates the Lease locking the restore of the Kafka cluster. It fails when the Lease is held by another
+// restore and has not expired yet.
+func AcquireLock(client *kubernetes.Clientset, namespace string, clusterName string, ttl time.Duration) (*RestoreLock, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	lock := &RestoreLock{
+		client:    client,
+		namespace: namespace,
+		name:      LockName(clusterName),
+		holder:    hostname + "-" + strconv.Itoa(os.Getpid()),
+		ttl:       ttl,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(ttl.Seconds())
+	spec := coordinationv1.LeaseSpec{
+		HolderIdentity:       &lock.holder,
+		LeaseDurationSeconds: &durationSeconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lock.name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "strimzi-backup", "strimzi.io/cluster": clusterName},
+		},
+		Spec: spec,
+	}
+
+	_, err = client.CoordinationV1().Leases(namespace).Create(context.TODO(), lease, metav1.CreateOptions{FieldManager: utils.FieldManager})
+	if errors.IsAlreadyExists(err) {
+		existing, err := client.CoordinationV1().Leases(namespace).Get(context.TODO(), lock.name, metav1.GetOptions{})
+		if err != nil {
+			slog.Error("Failed to get the restore lock", "name", lock.name, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		if expiresAt, expired := leaseExpiration(existing); !expired {
+			slog.Error("Another restore of the Kafka cluster is in progress", "name", clusterName, "namespace", namespace, "lock", lock.name, "holder", holderIdentity(existing), "expiresAt", expiresAt.Format(time.RFC3339))
+			return nil, fmt.Errorf("another restore of the Kafka cluster %s is in progress (held by %s in the %s Lease until %s)", clusterName, holderIdentity(existing), lock.name, expiresAt.Format(time.RFC3339))
+		}
+
+		slog.Warn("Taking over an expired restore lock", "name", lock.name, "namespace", namespace, "previousHolder", holderIdentity(existing))
+
+		// The update uses the resource version of the expired Lease, so only one of the competing restores can take it over
+		existing.Spec = spec
+		if _, err := client.CoordinationV1().Leases(namespace).Update(context.TODO(), existing, metav1.UpdateOptions{FieldManager: utils.FieldManager}); err != nil {
+			slog.Error("Failed to take over the expired restore lock", "name", lock.name, "namespace", namespace, "error", err)
+			return nil, err
+		}
+	} else if err != nil {
+		slog.Error("Failed to create the restore lock", "name", lock.name, "namespace", namespace, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Acquired the restore lock", "name", lock.name, "namespace", namespace, "holder", lock.holder, "ttl", ttl)
+
+	go lock.renew()
+
+	return lock, nil
+}
+
+// Release stops renewing the Lease and deletes it unless it was already taken over by another restore
+func (l *RestoreLock) Release() {
+	if l == nil {
+		return
+	}
+
+	close(l.stop)
+	<-l.stopped
+
+	lease, err := l.client.CoordinationV1().Leases(l.namespace).Get(context.TODO(), l.name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			slog.Error("Failed to get the restore lock", "name", l.name, "namespace", l.namespace, "error", err)
+		}
+
+		return
+	}
+
+	if holderIdentity(lease) != l.holder {
+		slog.Warn("The restore lock is held by another restore and is not released", "name", l.name, "namespace", l.namespace, "holder", holderIdentity(lease))
+		return
+	}
+
+	err = l.client.CoordinationV1().Leases(l.namespace).Delete(context.TODO(), l.name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion}})
+	if err != nil && !errors.IsNotFound(err) {
+		slog.Error("Failed to release the restore lock", "name", l.name, "namespace", l.namespace, "error", err)
+		return
+	}
+
+	slog.Info("Released the restore lock", "name", l.name, "namespace", l.namespace)
+}
+
+// renew periodically renews the Lease until the lock is released
+func (l *RestoreLock) renew() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			lease, err := l.client.CoordinationV1().Leases(l.namespace).Get(context.TODO(), l.name, metav1.GetOptions{})
+			if err != nil {
+				slog.Warn("Failed to renew the restore lock", "name", l.name, "namespace", l.namespace, "error", err)
+				continue
+			}
+
+			if holderIdentity(lease) != l.holder {
+				slog.Warn("The restore lock was taken over by another restore", "name", l.name, "namespace", l.namespace, "holder", holderIdentity(lease))
+				return
+			}
+
+			now := metav1.NewMicroTime(time.Now())
+			lease.Spec.RenewTime = &now
+			if _, err := l.client.CoordinationV1().Leases(l.namespace).Update(context.TODO(), lease, metav1.UpdateOptions{FieldManager: utils.FieldManager}); err != nil {
+				slog.Warn("Failed to renew the restore lock", "name", l.name, "namespace", l.namespace, "error", err)
+			}
+		}
+	}
+}
+
+// leaseExpiration returns the time when the Lease expires and whether it already expired
+func leaseExpiration(lease *coordinationv1.Lease) (time.Time, bool) {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return time.Time{}, true
+	}
+
+	expiresAt := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+
+	return expiresAt, time.Now().After(expiresAt)
+}
+
+func holderIdentity(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+
+	return *lease.Spec.HolderIdentity
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// prepareMerge checks the existing Kafka cluster into which the topics, users, and their Secrets are merged in the
+// merge mode
+func (r *KafkaRestorer) prepareMerge() error {
+	kafka, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			slog.Error("The Kafka cluster to merge the backup into does not exist", "name", r.Name, "namespace", r.Namespace)
+			return fmt.Errorf("the Kafka cluster %s in namespace %s does not exist and the --merge-into-existing option requires an existing Kafka cluster", r.Name, r.Namespace)
+		}
+
+		slog.Error("Failed to get the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if !utils.IsReady(kafka) {
+		slog.Warn("The Kafka cluster to merge the backup into is not ready", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	if kafka.Status != nil {
+		r.mergeTargetClusterId = kafka.Status.ClusterId
+	}
+
+	slog.Info("Merging the topics, users, and their Secrets from the backup into the existing Kafka cluster", "name", r.Name, "namespace", r.Namespace, "clusterId", r.mergeTargetClusterId)
+
+	return nil
+}
+
+// checkMergeClusterId compares the Cluster ID of the Kafka cluster from the backup with the Cluster ID of the existing
+// Kafka cluster. The same Cluster ID means that the backup is merged back into the cluster it was taken from.
+func (r *KafkaRestorer) checkMergeClusterId(resource []byte) error {
+	var kafka *v1beta2.Kafka
+
+	if err := yaml.Unmarshal(resource, &kafka); err != nil {
+		slog.Error("Failed to unmarshall the Kafka resource", "error", err)
+		return err
+	}
+
+	r.backedUpName = kafka.Name
+	r.backedUpNamespace = kafka.Namespace
+
+	if err := r.checkSourceNamespace(kafka.Namespace); err != nil {
+		return err
+	}
+
+	// The version of the operator is recorded also in the manifest, but that is the last stream of the backup
+	operatorVersion := ""
+	if kafka.Status != nil {
+		operatorVersion = kafka.Status.OperatorLastSuccessfulVersion
+	}
+	r.checkOperatorVersionSkew(operatorVersion)
+
+	if kafka.Status == nil || kafka.Status.ClusterId == "" || r.mergeTargetClusterId == "" {
+		slog.Warn("Cannot compare the Cluster IDs of the Kafka cluster from the backup and of the existing Kafka cluster")
+	} else if kafka.Status.ClusterId == r.mergeTargetClusterId {
+		slog.Warn("The existing Kafka cluster has the same Cluster ID as the Kafka cluster from the backup. The backup is merged back into the cluster it was taken from.", "clusterId", r.mergeTargetClusterId)
+	} else {
+		slog.Info("The existing Kafka cluster has a different Cluster ID than the Kafka cluster from the backup", "clusterId", r.mergeTargetClusterId, "backupClusterId", kafka.Status.ClusterId)
+	}
+
+	return nil
+}
+
+// skipExisting indicates whether the resource should be skipped because it already exists. In the merge mode, only the
+// missing resources are added and the existing resources are never updated. Otherwise, the ownership of the existing
+// resource is checked, so that the resource is fetched only once before it is restored.
+func skipExisting[T metav1.Object](r *KafkaRestorer, get func(context.Context, string, metav1.GetOptions) (T, error), kind string, name string) (bool, error) {
+	existing, err := get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		slog.Error("Failed to check the existing resource", "kind", kind, "name", name, "namespace", r.Namespace, "error", err)
+		return false, err
+	}
+
+	if r.mergeIntoExisting {
+		slog.Info("Skipping resource which already exists in the Kafka cluster", "kind", kind, "name", name, "namespace", r.Namespace)
+		return true, nil
+	}
+
+	return false, checkExistingOwnership(&r.Restorer, existing, kind, name)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// MirrorMaker2Restorer restores a Kafka MirrorMaker 2 cluster from a backup created with the backup mirrormaker2
+// command. The KafkaMirrorMaker2 resource is restored paused and unpaused only once the Secrets and ConfigMaps
+// referenced from it are restored.
+type MirrorMaker2Restorer struct {
+	Restorer
+
+	skipStart bool
+}
+
+func NewMirrorMaker2Restorer(cmd *cobra.Command) (*MirrorMaker2Restorer, error) {
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	skipStart := false
+	if cmd.Flags().Lookup("skip-mm2-start") != nil {
+		skipStart, err = cmd.Flags().GetBool("skip-mm2-start")
+		if err != nil {
+			slog.Error("Failed to get the --skip-mm2-start flag", "error", err)
+			return nil, err
+		}
+	}
+
+	return &MirrorMaker2Restorer{Restorer: *restorer, skipStart: skipStart}, nil
+}
+
+// mirrorMaker2StreamRestorers maps the streams restored by the restore mirrormaker2 command to the functions restoring
+// them
+var mirrorMaker2StreamRestorers = map[string]func(r *MirrorMaker2Restorer, resources []byte) error{
+	backuper.KafkaMirrorMaker2SecretsFilename:    (*MirrorMaker2Restorer).restoreReferencedSecrets,
+	backuper.KafkaMirrorMaker2ConfigMapsFilename: (*MirrorMaker2Restorer).restoreReferencedConfigMaps,
+}
+
+// RestoreMirrorMaker2 restores the Kafka MirrorMaker 2 cluster, unpauses it, and waits for it to get ready. The
+// KafkaMirrorMaker2 resource is restored only after the Secrets and ConfigMaps with the credentials and certificates of
+// its source and target clusters, so that the cluster aliases can be resolved once it is created.
+func (r *MirrorMaker2Restorer) RestoreMirrorMaker2() error {
+	var mirrorMaker2 []byte // The KafkaMirrorMaker2 resource is restored only once its Secrets and ConfigMaps are restored
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+			restored++
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByMirrorMaker2 {
+			slog.Debug("Skipping resources which are not part of the Kafka MirrorMaker 2 cluster", "name", member.Name)
+		} else if stream.Name == backuper.KafkaMirrorMaker2Filename {
+			mirrorMaker2 = member.Data
+		} else {
+			if err := mirrorMaker2StreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if mirrorMaker2 != nil {
+		if err := r.restoreKafkaMirrorMaker2(mirrorMaker2); err != nil {
+			return err
+		}
+
+		if err := r.checkpoint.MarkCompleted(backuper.KafkaMirrorMaker2Filename); err != nil {
+			slog.Error("Failed to record the restore progress", "name", backuper.KafkaMirrorMaker2Filename, "error", err)
+			return err
+		}
+
+		restored++
+	}
+
+	if restored == 0 {
+		slog.Error("No Kafka MirrorMaker 2 cluster found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Kafka MirrorMaker 2 cluster found in the backup %s", r.BackupFileName)
+	}
+
+	if r.skipStart {
+		slog.Warn("The Kafka MirrorMaker 2 cluster is left paused. Unpause it once the replication direction is confirmed by setting the strimzi.io/pause-reconciliation annotation to false.", "name", r.Name, "namespace", r.Namespace)
+	} else if err := r.unpauseKafkaMirrorMaker2AndWaitForReadiness(); err != nil {
+		slog.Error("Failed to unpause Kafka MirrorMaker 2 cluster and get it into the Ready state", "error", err)
+		return err
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreKafkaMirrorMaker2 restores the KafkaMirrorMaker2 resource with paused reconciliation, so that the Cluster
+// Operator does not deploy the Kafka MirrorMaker 2 cluster before the resources referenced from it are restored
+func (r *MirrorMaker2Restorer) restoreKafkaMirrorMaker2(resource []byte) error {
+	var mirrorMaker2 *v1beta2.KafkaMirrorMaker2
+
+	if err := yaml.Unmarshal(resource, &mirrorMaker2); err != nil {
+		slog.Error("Failed to unmarshall the KafkaMirrorMaker2 resource", "error", err)
+		return err
+	}
+
+	if err := r.checkSourceNamespace(mirrorMaker2.Namespace); err != nil {
+		return err
+	}
+
+	if mirrorMaker2.Namespace != "" && mirrorMaker2.Namespace != r.Namespace {
+		slog.Warn("The Kafka MirrorMaker 2 cluster is restored into a different namespace. The bootstrap servers of its source and target clusters are not updated and might need to be changed to point to the right Kafka clusters.", "backupNamespace", mirrorMaker2.Namespace, "namespace", r.Namespace)
+	}
+
+	slog.Info("Restoring paused KafkaMirrorMaker2 resource", "name", r.Name, "namespace", r.Namespace)
+
+	// We update the metadata and pause the resource
+	utils.CleanseMetadata(&mirrorMaker2.ObjectMeta)
+	mirrorMaker2.Namespace = r.Namespace
+	mirrorMaker2.Name = r.Name
+	if mirrorMaker2.Annotations == nil {
+		mirrorMaker2.Annotations = map[string]string{"strimzi.io/pause-reconciliation": "true"}
+	} else {
+		mirrorMaker2.Annotations["strimzi.io/pause-reconciliation"] = "true"
+	}
+
+	r.markRestored(&mirrorMaker2.ObjectMeta)
+	mirrorMaker2.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaMirrorMaker2"}
+	mirrorMaker2.Status = nil
+
+	if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(r.Namespace).Get, "KafkaMirrorMaker2", mirrorMaker2.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(r.Namespace).Patch, mirrorMaker2.Name, mirrorMaker2, r.Force); err != nil {
+		slog.Error("Failed to restore the KafkaMirrorMaker2 resource", "error", err)
+		return err
+	}
+
+	// Wait for the paused reconciliation to be confirmed
+	if _, err := utils.WaitUntilMirrorMaker2ReconciliationPaused(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.PauseTimeout); err != nil {
+		slog.Error("The KafkaMirrorMaker2 resource was not paused. Please check the Cluster Operator logs for more details.", "error", err)
+		return err
+	}
+
+	slog.Info("KafkaMirrorMaker2 resource was restored in paused state")
+
+	return nil
+}
+
+// unpauseKafkaMirrorMaker2AndWaitForReadiness unpauses the restored Kafka MirrorMaker 2 cluster and waits until it is
+// ready
+func (r *MirrorMaker2Restorer) unpauseKafkaMirrorMaker2AndWaitForReadiness() error {
+	mirrorMaker2, err := r.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the KafkaMirrorMaker2 resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if utils.IsMirrorMaker2ReconciliationPaused(mirrorMaker2) {
+		slog.Info("Unpausing the Kafka MirrorMaker 2 cluster", "name", r.Name, "namespace", r.Namespace)
+		unpausedMirrorMaker2 := mirrorMaker2.DeepCopy()
+		unpausedMirrorMaker2.Annotations["strimzi.io/pause-reconciliation"] = "false"
+
+		// The whole resource is applied again as fields missing in the applied configuration would be removed
+		utils.CleanseMetadata(&unpausedMirrorMaker2.ObjectMeta)
+		unpausedMirrorMaker2.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaMirrorMaker2"}
+		unpausedMirrorMaker2.Status = nil
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaMirrorMaker2s(r.Namespace).Patch, unpausedMirrorMaker2.Name, unpausedMirrorMaker2, r.Force); err != nil {
+			slog.Error("Failed to unpause the KafkaMirrorMaker2 resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	} else if utils.IsMirrorMaker2Ready(mirrorMaker2) {
+		slog.Warn("The Kafka MirrorMaker 2 cluster is already ready and does not need to be unpaused", "name", r.Name, "namespace", r.Namespace)
+		return nil
+	} else {
+		slog.Warn("The Kafka MirrorMaker 2 cluster is not paused, but it is not ready. Waiting for the Kafka MirrorMaker 2 cluster to get ready.", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	slog.Info("Waiting for the Kafka MirrorMaker 2 cluster to get ready", "name", r.Name, "namespace", r.Namespace)
+	if _, err := utils.WaitUntilMirrorMaker2Ready(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.ReadyTimeout); err != nil {
+		slog.Error("The Kafka MirrorMaker 2 cluster did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The Kafka MirrorMaker 2 cluster is ready", "name", r.Name, "namespace", r.Namespace)
+
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// MirrorMakerRestorer restores a legacy Kafka MirrorMaker cluster from a backup created with the backup mirrormaker
+// command. The KafkaMirrorMaker resource is restored paused and unpaused only once the Secrets and ConfigMaps
+// referenced from it are restored. It can be restored only with Strimzi versions which still support the
+// KafkaMirrorMaker resource.
+type MirrorMakerRestorer struct {
+	Restorer
+}
+
+func NewMirrorMakerRestorer(cmd *cobra.Command) (*MirrorMakerRestorer, error) {
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MirrorMakerRestorer{Restorer: *restorer}, nil
+}
+
+// mirrorMakerStreamRestorers maps the streams restored by the restore mirrormaker command to the functions restoring
+// them
+var mirrorMakerStreamRestorers = map[string]func(r *MirrorMakerRestorer, resources []byte) error{
+	backuper.KafkaMirrorMakerFilename:           (*MirrorMakerRestorer).restoreKafkaMirrorMaker,
+	backuper.KafkaMirrorMakerSecretsFilename:    (*MirrorMakerRestorer).restoreReferencedSecrets,
+	backuper.KafkaMirrorMakerConfigMapsFilename: (*MirrorMakerRestorer).restoreReferencedConfigMaps,
+}
+
+// RestoreMirrorMaker restores the legacy Kafka MirrorMaker cluster, unpauses it, and waits for it to get ready
+func (r *MirrorMakerRestorer) RestoreMirrorMaker() error {
+	restored := 0
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+			restored++
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByMirrorMaker {
+			slog.Debug("Skipping resources which are not part of the Kafka MirrorMaker cluster", "name", member.Name)
+		} else {
+			if err := mirrorMakerStreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+
+			restored++
+		}
+	}
+
+	if restored == 0 {
+		slog.Error("No Kafka MirrorMaker cluster found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Kafka MirrorMaker cluster found in the backup %s", r.BackupFileName)
+	}
+
+	if err := r.unpauseKafkaMirrorMakerAndWaitForReadiness(); err != nil {
+		slog.Error("Failed to unpause Kafka MirrorMaker cluster and get it into the Ready state", "error", err)
+		return err
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// restoreKafkaMirrorMaker restores the KafkaMirrorMaker resource with paused reconciliation, so that the Cluster
+// Operator does not deploy the Kafka MirrorMaker cluster before the resources referenced from it are restored
+func (r *MirrorMakerRestorer) restoreKafkaMirrorMaker(resource []byte) error {
+	mirrorMaker := &unstructured.Unstructured{}
+
+	if err := yaml.Unmarshal(resource, &mirrorMaker.Object); err != nil {
+		slog.Error("Failed to unmarshall the KafkaMirrorMaker resource", "error", err)
+		return err
+	}
+
+	metadata, err := utils.UnstructuredObjectMeta(mirrorMaker)
+	if err != nil {
+		slog.Error("Failed to read the metadata of the KafkaMirrorMaker resource", "error", err)
+		return err
+	}
+
+	if err := r.checkSourceNamespace(metadata.Namespace); err != nil {
+		return err
+	}
+
+	if metadata.Namespace != "" && metadata.Namespace != r.Namespace {
+		slog.Warn("The Kafka MirrorMaker cluster is restored into a different namespace. The bootstrap servers of its consumer and producer are not updated and might need to be changed to point to the right Kafka clusters.", "backupNamespace", metadata.Namespace, "namespace", r.Namespace)
+	}
+
+	slog.Info("Restoring paused KafkaMirrorMaker resource", "name", r.Name, "namespace", r.Namespace)
+
+	// We update the metadata and pause the resource
+	utils.CleanseMetadata(metadata)
+	metadata.Namespace = r.Namespace
+	metadata.Name = r.Name
+	if metadata.Annotations == nil {
+		metadata.Annotations = map[string]string{"strimzi.io/pause-reconciliation": "true"}
+	} else {
+		metadata.Annotations["strimzi.io/pause-reconciliation"] = "true"
+	}
+
+	r.markRestored(metadata)
+	if err := utils.SetUnstructuredObjectMeta(mirrorMaker, metadata); err != nil {
+		slog.Error("Failed to update the metadata of the KafkaMirrorMaker resource", "error", err)
+		return err
+	}
+	mirrorMaker.SetAPIVersion(utils.KafkaMirrorMakerResource.GroupVersion().String())
+	mirrorMaker.SetKind("KafkaMirrorMaker")
+	unstructured.RemoveNestedField(mirrorMaker.Object, "status")
+
+	client := r.DynamicClient.Resource(utils.KafkaMirrorMakerResource).Namespace(r.Namespace)
+	get := func(ctx context.Context, name string, options metav1.GetOptions) (*unstructured.Unstructured, error) {
+		return client.Get(ctx, name, options)
+	}
+
+	if err := checkOwnership(&r.Restorer, get, "KafkaMirrorMaker", r.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(client.Patch, r.Name, mirrorMaker, r.Force); errors.IsNotFound(err) {
+		slog.Error("The KafkaMirrorMaker custom resource is not installed. It was removed in Strimzi 0.46, so the Kafka MirrorMaker cluster can be restored only with older Strimzi versions. Please migrate to Kafka MirrorMaker 2 to use newer Strimzi versions.", "error", err)
+		return fmt.Errorf("the KafkaMirrorMaker custom resource is not installed in the Kubernetes cluster: %v", err)
+	} else if err != nil {
+		slog.Error("Failed to restore the KafkaMirrorMaker resource", "error", err)
+		return err
+	}
+
+	// Wait for the paused reconciliation to be confirmed
+	if _, err := utils.WaitUntilMirrorMakerReconciliationPaused(r.KubernetesClient, r.DynamicClient, r.Name, r.Namespace, r.PauseTimeout); err != nil {
+		slog.Error("The KafkaMirrorMaker resource was not paused. Please check the Cluster Operator logs for more details.", "error", err)
+		return err
+	}
+
+	slog.Info("KafkaMirrorMaker resource was restored in paused state")
+
+	return nil
+}
+
+// unpauseKafkaMirrorMakerAndWaitForReadiness unpauses the restored Kafka MirrorMaker cluster and waits until it is
+// ready
+func (r *MirrorMakerRestorer) unpauseKafkaMirrorMakerAndWaitForReadiness() error {
+	client := r.DynamicClient.Resource(utils.KafkaMirrorMakerResource).Namespace(r.Namespace)
+
+	mirrorMaker, err := client.Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the KafkaMirrorMaker resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if utils.IsMirrorMakerReconciliationPaused(mirrorMaker) {
+		slog.Info("Unpausing the Kafka MirrorMaker cluster", "name", r.Name, "namespace", r.Namespace)
+		unpausedMirrorMaker := mirrorMaker.DeepCopy()
+
+		// The whole resource is applied again as fields missing in the applied configuration would be removed
+		metadata, err := utils.UnstructuredObjectMeta(unpausedMirrorMaker)
+		if err != nil {
+			slog.Error("Failed to read the metadata of the KafkaMirrorMaker resource", "error", err)
+			return err
+		}
+
+		metadata.Annotations["strimzi.io/pause-reconciliation"] = "false"
+		utils.CleanseMetadata(metadata)
+
+		if err := utils.SetUnstructuredObjectMeta(unpausedMirrorMaker, metadata); err != nil {
+			slog.Error("Failed to update the metadata of the KafkaMirrorMaker resource", "error", err)
+			return err
+		}
+		unstructured.RemoveNestedField(unpausedMirrorMaker.Object, "status")
+
+		if _, err := utils.Apply(client.Patch, r.Name, unpausedMirrorMaker, r.Force); err != nil {
+			slog.Error("Failed to unpause the KafkaMirrorMaker resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	} else if utils.IsMirrorMakerReady(mirrorMaker) {
+		slog.Warn("The Kafka MirrorMaker cluster is already ready and does not need to be unpaused", "name", r.Name, "namespace", r.Namespace)
+		return nil
+	} else {
+		slog.Warn("The Kafka MirrorMaker cluster is not paused, but it is not ready. Waiting for the Kafka MirrorMaker cluster to get ready.", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	slog.Info("Waiting for the Kafka MirrorMaker cluster to get ready", "name", r.Name, "namespace", r.Namespace)
+	if _, err := utils.WaitUntilMirrorMakerReady(r.KubernetesClient, r.DynamicClient, r.Name, r.Namespace, r.ReadyTimeout); err != nil {
+		slog.Error("The Kafka MirrorMaker cluster did not become ready. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The Kafka MirrorMaker cluster is ready", "name", r.Name, "namespace", r.Namespace)
+
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"log/slog"
+	"os"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// NameMapping renames the topics and users while restoring them. It is used to restore the backup into a shared
+// multi-tenant cluster where the original names would collide with the existing topics and users. The exact names are
+// mapped first. The names without an exact mapping are mapped using the longest matching prefix.
+type NameMapping struct {
+	// Topics maps the exact names of the topics
+	Topics map[string]string `json:"topics,omitempty"`
+	// Users maps the exact names of the users
+	Users map[string]string `json:"users,omitempty"`
+	// Prefixes maps the prefixes of the topics, users, consumer groups, and transactional IDs
+	Prefixes map[string]string `json:"prefixes,omitempty"`
+}
+
+// LoadNameMapping loads the name mapping from the YAML file
+func LoadNameMapping(fileName string) (*NameMapping, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping NameMapping
+	if err := yaml.UnmarshalStrict(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse the name mapping file %s: %w", fileName, err)
+	}
+
+	for from, to := range mapping.Prefixes {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("the name mapping file %s contains an empty prefix", fileName)
+		}
+	}
+
+	return &mapping, nil
+}
+
+// Topic returns the name of the topic in the restored cluster
+func (m *NameMapping) Topic(name string) string {
+	if m == nil {
+		return name
+	} else if mapped, ok := m.Topics[name]; ok {
+		return mapped
+	}
+
+	return m.prefixed(name)
+}
+
+// User returns the name of the user in the restored cluster
+func (m *NameMapping) User(name string) string {
+	if m == nil {
+		return name
+	} else if mapped, ok := m.Users[name]; ok {
+		return mapped
+	}
+
+	return m.prefixed(name)
+}
+
+// prefixed replaces the longest matching prefix of the name
+func (m *NameMapping) prefixed(name string) string {
+	longest := ""
+	for from := range m.Prefixes {
+		if strings.HasPrefix(name, from) && len(from) > len(longest) {
+			longest = from
+		}
+	}
+
+	if longest == "" {
+		return name
+	}
+
+	return m.Prefixes[longest] + strings.TrimPrefix(name, longest)
+}
+
+// mapTopic renames the KafkaTopic resource and the topic it manages
+func (m *NameMapping) mapTopic(topic *v1beta2.KafkaTopic) error {
+	if m == nil {
+		return nil
+	}
+
+	name := m.Topic(topic.Name)
+	if name != topic.Name {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("the KafkaTopic %s cannot be renamed to %s: %s", topic.Name, name, strings.Join(errs, ", "))
+		}
+
+		slog.Info("Renaming Kafka Topic", "name", topic.Name, "newName", name)
+		topic.Name = name
+	}
+
+	if topic.Spec != nil && topic.Spec.TopicName != "" {
+		topic.Spec.TopicName = m.Topic(topic.Spec.TopicName)
+	}
+
+	return nil
+}
+
+// mapUser renames the KafkaUser resource and the topics, consumer groups, and transactional IDs in its ACL rules
+func (m *NameMapping) mapUser(user *v1beta2.KafkaUser) error {
+	if m == nil {
+		return nil
+	}
+
+	name := m.User(user.Name)
+	if name != user.Name {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("the KafkaUser %s cannot be renamed to %s: %s", user.Name, name, strings.Join(errs, ", "))
+		}
+
+		slog.Info("Renaming Kafka User", "name", user.Name, "newName", name)
+		user.Name = name
+	}
+
+	if user.Spec == nil || user.Spec.Authorization == nil {
+		return nil
+	}
+
+	for _, rule := range user.Spec.Authorization.Acls {
+		if rule.Resource == nil || rule.Resource.Name == "*" {
+			continue
+		}
+
+		switch rule.Resource.Type {
+		case v1beta2.TOPIC_ACLRULERESOURCETYPE:
+			if rule.Resource.PatternType == v1beta2.PREFIX_ACLRESOURCEPATTERNTYPE {
+				rule.Resource.Name = m.prefixed(rule.Resource.Name)
+			} else {
+				rule.Resource.Name = m.Topic(rule.Resource.Name)
+			}
+		case v1beta2.GROUP_ACLRULERESOURCETYPE, v1beta2.TRANSACTIONALID_ACLRULERESOURCETYPE:
+			rule.Resource.Name = m.prefixed(rule.Resource.Name)
+		}
+	}
+
+	return nil
+}
+
+// mapUserSecret renames the Secret of a renamed user and updates the username in its SASL JAAS configuration. The
+// certificates of the TLS users are issued for the original username, so their Secrets are not restored and the User
+// Operator issues new certificates instead. Returns false when the Secret should not be restored.
+func (m *NameMapping) mapUserSecret(secret *v1.Secret) bool {
+	if m == nil {
+		return true
+	}
+
+	user := secret.Labels["strimzi.io/name"]
+	if user == "" {
+		user = secret.Name
+	}
+
+	name := m.User(user)
+	if name == user {
+		return true
+	}
+
+	if _, ok := secret.Data["user.crt"]; ok {
+		slog.Warn("Skipping the certificate of the renamed Kafka user, a new certificate will be issued by the User Operator", "user", user, "newUser", name, "secret", secret.Name)
+		return false
+	}
+
+	// The Secret name might contain the secret prefix configured in the User Operator
+	if strings.HasSuffix(secret.Name, user) {
+		secret.Name = strings.TrimSuffix(secret.Name, user) + name
+	}
+	if secret.Labels["strimzi.io/name"] != "" {
+		secret.Labels["strimzi.io/name"] = name
+	}
+	if jaasConfig, ok := secret.Data["sasl.jaas.config"]; ok {
+		secret.Data["sasl.jaas.config"] = []byte(strings.Replace(string(jaasConfig), "username=\""+user+"\"", "username=\""+name+"\"", 1))
+	}
+
+	slog.Info("Renaming the Secret of the Kafka user", "user", user, "newUser", name, "secret", secret.Name)
+
+	return true
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const nextNodeIdsAnnotation = "strimzi.io/next-node-ids"
+
+// assignNodeIds sets the strimzi.io/next-node-ids annotation based on the node IDs from the backup, so that the Cluster
+// Operator assigns the same node IDs to the restored node pool. This is required to reuse the original data volumes.
+func (r *KafkaRestorer) assignNodeIds(nodePool *v1beta2.KafkaNodePool) {
+	if nodePool.Status == nil || len(nodePool.Status.NodeIds) == 0 {
+		slog.Warn("No node IDs found in the backup of the Kafka Node Pool => the Cluster Operator will assign new node IDs", "name", nodePool.Name)
+		return
+	}
+
+	nodeIds := slices.Clone(nodePool.Status.NodeIds)
+	slices.Sort(nodeIds)
+
+	formatted := make([]string, 0, len(nodeIds))
+	for _, nodeId := range nodeIds {
+		formatted = append(formatted, strconv.Itoa(int(nodeId)))
+	}
+	nextNodeIds := "[" + strings.Join(formatted, ",") + "]"
+
+	if nodePool.Annotations == nil {
+		nodePool.Annotations = map[string]string{nextNodeIdsAnnotation: nextNodeIds}
+	} else {
+		if existing, ok := nodePool.Annotations[nextNodeIdsAnnotation]; ok && existing != nextNodeIds {
+			slog.Warn("Replacing the next node IDs annotation from the backup with the node IDs used by the Kafka Node Pool", "name", nodePool.Name, "backup", existing, "nodeIds", nextNodeIds)
+		}
+
+		nodePool.Annotations[nextNodeIdsAnnotation] = nextNodeIds
+	}
+
+	slog.Info("Kafka Node Pool will use the node IDs from the backup", "name", nodePool.Name, "nodeIds", nextNodeIds)
+}
+
+// verifyNodeIds compares the node IDs assigned by the Cluster Operator to the restored node pools with the node IDs
+// recorded in the backup. Changed node IDs prevent the Kafka nodes from reusing their original data volumes.
+func (r *KafkaRestorer) verifyNodeIds() error {
+	if len(r.backedUpNodeIds) == 0 {
+		slog.Info("No node IDs recorded in the backup => skipping node ID verification")
+		return nil
+	}
+
+	nodePools, err := r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + r.Name})
+	if err != nil {
+		slog.Error("Failed to get the restored Kafka Node Pools", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	var mismatches []string
+	for _, nodePool := range nodePools.Items {
+		expected, ok := r.backedUpNodeIds[nodePool.Name]
+		if !ok {
+			continue
+		}
+
+		var actual []int32
+		if nodePool.Status != nil {
+			actual = slices.Clone(nodePool.Status.NodeIds)
+		}
+
+		slices.Sort(expected)
+		slices.Sort(actual)
+
+		if !slices.Equal(expected, actual) {
+			slog.Warn("Node IDs of the restored Kafka Node Pool differ from the backup", "name", nodePool.Name, "namespace", nodePool.Namespace, "backup", expected, "restored", actual)
+			mismatches = append(mismatches, nodePool.Name)
+		} else {
+			slog.Info("Node IDs of the restored Kafka Node Pool match the backup", "name", nodePool.Name, "namespace", nodePool.Namespace, "nodeIds", actual)
+		}
+	}
+
+	if len(mismatches) > 0 && r.failOnNodeIdChange {
+		return fmt.Errorf("node IDs of the Kafka Node Pools %v differ from the backup", mismatches)
+	}
+
+	return nil
+}
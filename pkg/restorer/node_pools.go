@@ -0,0 +1,206 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"slices"
+)
+
+// NodePoolRestorer restores only the Kafka Node Pools from the backup into an existing Kafka cluster, for example to
+// recover from an accidentally deleted node pool. The existing node pools are never modified.
+type NodePoolRestorer struct {
+	KafkaRestorer
+
+	// Names of the node pools which should be restored. All node pools are restored when empty.
+	nodePools []string
+	// Cluster ID of the existing Kafka cluster
+	clusterId string
+}
+
+func NewNodePoolRestorer(cmd *cobra.Command) (*NodePoolRestorer, error) {
+	nodePools, err := cmd.Flags().GetStringSlice("node-pool")
+	if err != nil {
+		slog.Error("Failed to get the --node-pool flag", "error", err)
+		return nil, err
+	}
+
+	skipNodeIdAssignment, err := cmd.Flags().GetBool("skip-node-id-assignment")
+	if err != nil {
+		slog.Error("Failed to get the --skip-node-id-assignment flag", "error", err)
+		return nil, err
+	}
+
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodePoolRestorer{
+		KafkaRestorer: KafkaRestorer{
+			Restorer:             *restorer,
+			skipNodeIdAssignment: skipNodeIdAssignment,
+			backedUpNodeIds:      map[string][]int32{},
+			// Only the missing node pools are added, the same way as in the merge mode
+			mergeIntoExisting: true,
+		},
+		nodePools: nodePools,
+	}, nil
+}
+
+// RestoreNodePools restores the Kafka Node Pools from the backup which do not exist in the Kafka cluster anymore. The
+// restore checkpoint is not used as the node pools are restored from a single stream.
+func (r *NodePoolRestorer) RestoreNodePools() error {
+	if err := r.checkExistingKafka(); err != nil {
+		return err
+	}
+
+	found := false
+
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		switch member.Name {
+		case backuper.KafkaFilename:
+			if err := r.checkBackedUpKafka(member.Data); err != nil {
+				return err
+			}
+		case backuper.KafkaNodePoolsFilename:
+			found = true
+
+			if err := r.restoreSelectedNodePools(member.Data); err != nil {
+				return err
+			}
+		default:
+			slog.Debug("Skipping resources which are not Kafka Node Pools", "name", member.Name)
+		}
+	}
+
+	if !found {
+		slog.Error("No Kafka Node Pools found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Kafka Node Pools found in the backup %s", r.BackupFileName)
+	}
+
+	return nil
+}
+
+// checkExistingKafka checks that the Kafka cluster into which the node pools are restored exists and uses node pools
+func (r *NodePoolRestorer) checkExistingKafka() error {
+	kafka, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			slog.Error("The Kafka cluster to restore the node pools into does not exist", "name", r.Name, "namespace", r.Namespace)
+			return fmt.Errorf("the Kafka cluster %s in namespace %s does not exist. Use the restore kafka command to restore the whole Kafka cluster", r.Name, r.Namespace)
+		}
+
+		slog.Error("Failed to get the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	usesNodePools, err := utils.UsesNodePools(context.TODO(), r.StrimziClient, kafka)
+	if err != nil {
+		slog.Error("Failed to get the KafkaNodePool resources", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	} else if !usesNodePools {
+		slog.Error("The Kafka cluster does not use node pools", "name", r.Name, "namespace", r.Namespace)
+		return fmt.Errorf("the Kafka cluster %s in namespace %s does not use node pools (it has no KafkaNodePool resources and the strimzi.io/node-pools annotation is not set to enabled)", r.Name, r.Namespace)
+	}
+
+	if utils.IsReconciliationPaused(kafka) {
+		slog.Warn("The reconciliation of the Kafka cluster is paused => the Cluster Operator will not create the nodes of the restored node pools until it is unpaused", "name", r.Name, "namespace", r.Namespace)
+	}
+
+	if kafka.Status != nil {
+		r.clusterId = kafka.Status.ClusterId
+	}
+
+	return nil
+}
+
+// checkBackedUpKafka checks that the backup was taken from the Kafka cluster into which the node pools are restored.
+// The nodes of node pools from a different Kafka cluster would not be able to join it when reusing their volumes.
+func (r *NodePoolRestorer) checkBackedUpKafka(resource []byte) error {
+	var kafka *v1beta2.Kafka
+
+	if err := yaml.Unmarshal(resource, &kafka); err != nil {
+		slog.Error("Failed to unmarshall the Kafka resource", "error", err)
+		return err
+	}
+
+	r.backedUpName = kafka.Name
+	r.backedUpNamespace = kafka.Namespace
+
+	if err := r.checkSourceNamespace(kafka.Namespace); err != nil {
+		return err
+	}
+
+	if kafka.Status == nil || kafka.Status.ClusterId == "" || r.clusterId == "" {
+		slog.Warn("Cannot compare the Cluster IDs of the Kafka cluster from the backup and of the existing Kafka cluster")
+	} else if kafka.Status.ClusterId != r.clusterId {
+		slog.Warn("The existing Kafka cluster has a different Cluster ID than the Kafka cluster from the backup => the backup was probably taken from a different Kafka cluster", "clusterId", r.clusterId, "backupClusterId", kafka.Status.ClusterId)
+	}
+
+	return nil
+}
+
+// restoreSelectedNodePools restores the node pools selected with the --node-pool option or all node pools when no
+// node pools were selected
+func (r *NodePoolRestorer) restoreSelectedNodePools(resources []byte) error {
+	var nodePools *v1beta2.KafkaNodePoolList
+
+	if err := yaml.Unmarshal(resources, &nodePools); err != nil {
+		slog.Error("Failed to unmarshall the Kafka Node Pool resources", "error", err)
+		return err
+	}
+
+	if len(r.nodePools) > 0 {
+		for _, name := range r.nodePools {
+			if !slices.ContainsFunc(nodePools.Items, func(nodePool v1beta2.KafkaNodePool) bool { return nodePool.Name == name }) {
+				slog.Error("The Kafka Node Pool was not found in the backup", "name", name)
+				return fmt.Errorf("the Kafka Node Pool %s was not found in the backup", name)
+			}
+		}
+
+		nodePools.Items = slices.DeleteFunc(nodePools.Items, func(nodePool v1beta2.KafkaNodePool) bool {
+			return !slices.Contains(r.nodePools, nodePool.Name)
+		})
+	}
+
+	selected, err := yaml.Marshal(nodePools)
+	if err != nil {
+		slog.Error("Failed to marshal the Kafka Node Pools to YAML", "error", err)
+		return err
+	}
+
+	return r.restoreKafkaNodePoolsStream(selected)
+}
@@ -0,0 +1,405 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/spf13/cobra"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+// OperatorRestorer restores the Strimzi Cluster Operator installation from a backup created with the backup operator
+// command. The Deployment is applied only once its ServiceAccount, roles, bindings, ConfigMaps, Secrets, and
+// optionally the Strimzi CRDs are restored. The Roles and RoleBindings from the namespace of the Cluster Operator are restored into the target
+// namespace and the ones from the watched namespaces into the same namespaces.
+type OperatorRestorer struct {
+	Restorer
+
+	backedUpNamespace  string
+	serviceAccountName string
+	deployment         *appsv1.Deployment
+	// Restores also the Strimzi CRDs included in the backup before the Cluster Operator installation
+	withCRDs bool
+}
+
+func NewOperatorRestorer(cmd *cobra.Command) (*OperatorRestorer, error) {
+	withCRDs, err := cmd.Flags().GetBool("restore-crds")
+	if err != nil {
+		slog.Error("Failed to get the --restore-crds flag", "error", err)
+		return nil, err
+	}
+
+	restorer, err := NewRestorer(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperatorRestorer{Restorer: *restorer, withCRDs: withCRDs}, nil
+}
+
+// operatorStreamRestorers maps the streams restored by the restore operator command to the functions restoring them
+var operatorStreamRestorers = map[string]func(r *OperatorRestorer, resources []byte) error{
+	backuper.OperatorDeploymentFilename:          (*OperatorRestorer).readDeployment,
+	backuper.OperatorServiceAccountFilename:      (*OperatorRestorer).restoreServiceAccount,
+	backuper.OperatorClusterRolesFilename:        (*OperatorRestorer).restoreClusterRoles,
+	backuper.OperatorClusterRoleBindingsFilename: (*OperatorRestorer).restoreClusterRoleBindings,
+	backuper.OperatorRolesFilename:               (*OperatorRestorer).restoreRoles,
+	backuper.OperatorRoleBindingsFilename:        (*OperatorRestorer).restoreRoleBindings,
+	backuper.OperatorConfigMapsFilename:          (*OperatorRestorer).restoreReferencedConfigMaps,
+	backuper.OperatorSecretsFilename:             (*OperatorRestorer).restoreReferencedSecrets,
+	backuper.StrimziCRDsFilename:                 (*OperatorRestorer).restoreCRDs,
+}
+
+// RestoreOperator restores the Cluster Operator installation and waits for its Deployment to get available
+func (r *OperatorRestorer) RestoreOperator() error {
+	for {
+		member, err := r.reader.Next()
+		if err == io.EOF {
+			slog.Info("Restoring data completed")
+			break
+		} else if err != nil {
+			slog.Error("Failed to read from the backup file", "error", err)
+			return err
+		}
+
+		if r.checkpoint.IsCompleted(member.Name) && member.Name != backuper.OperatorDeploymentFilename {
+			slog.Info("Skipping resources which were already restored before the restore was interrupted", "name", member.Name)
+		} else if stream, ok := backuper.LookupStream(member.Name); !ok {
+			slog.Error("Unknown resources found in backup", "name", member.Name, "comment", member.Comment, "modTime", member.ModTime)
+			return fmt.Errorf("unknown resources %v found in backup", member.Name)
+		} else if stream.RestoredBy == backuper.RestoredByCRDs && !r.withCRDs {
+			slog.Info("Skipping the Strimzi CustomResourceDefinitions. Use the --restore-crds option to restore them.", "name", member.Name)
+		} else if stream.RestoredBy != backuper.RestoredByOperator && stream.RestoredBy != backuper.RestoredByCRDs {
+			slog.Debug("Skipping resources which are not part of the Cluster Operator installation", "name", member.Name)
+		} else {
+			if err := operatorStreamRestorers[stream.Name](r, member.Data); err != nil {
+				return err
+			}
+
+			if err := r.checkpoint.MarkCompleted(member.Name); err != nil {
+				slog.Error("Failed to record the restore progress", "name", member.Name, "error", err)
+				return err
+			}
+		}
+	}
+
+	if r.deployment == nil {
+		slog.Error("No Cluster Operator Deployment found in the backup", "file", r.BackupFileName)
+		return fmt.Errorf("no Cluster Operator Deployment found in the backup %s", r.BackupFileName)
+	}
+
+	if err := r.restoreDeploymentAndWaitForAvailability(); err != nil {
+		return err
+	}
+
+	if err := r.checkpoint.Delete(); err != nil {
+		slog.Error("Failed to delete the restore checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// readDeployment reads the Cluster Operator Deployment from the backup. It is restored only after all other resources,
+// so that the Cluster Operator starts with all the rights and configuration it needs.
+func (r *OperatorRestorer) readDeployment(resource []byte) error {
+	if err := yaml.Unmarshal(resource, &r.deployment); err != nil {
+		slog.Error("Failed to unmarshall the Deployment resource", "error", err)
+		return err
+	}
+
+	if err := r.checkSourceNamespace(r.deployment.Namespace); err != nil {
+		return err
+	}
+
+	if r.backedUpNamespace == "" {
+		r.backedUpNamespace = r.deployment.Namespace
+	}
+
+	if r.serviceAccountName == "" {
+		r.serviceAccountName = r.deployment.Spec.Template.Spec.ServiceAccountName
+	}
+
+	if r.serviceAccountName == "" {
+		r.serviceAccountName = "default"
+	}
+
+	return nil
+}
+
+// namespace returns the namespace into which the namespaced resource backed up from the given namespace is restored
+func (r *OperatorRestorer) namespace(namespace string) string {
+	if namespace == "" || namespace == r.backedUpNamespace {
+		return r.Namespace
+	}
+
+	return namespace
+}
+
+// updateSubjects binds the restored roles to the ServiceAccount of the restored Cluster Operator
+func (r *OperatorRestorer) updateSubjects(subjects []rbacv1.Subject) {
+	for i := range subjects {
+		if subjects[i].Kind == rbacv1.ServiceAccountKind && subjects[i].Name == r.serviceAccountName && subjects[i].Namespace == r.backedUpNamespace {
+			subjects[i].Namespace = r.Namespace
+		}
+	}
+}
+
+func (r *OperatorRestorer) restoreServiceAccount(resource []byte) error {
+	var serviceAccount *v1.ServiceAccount
+
+	if err := yaml.Unmarshal(resource, &serviceAccount); err != nil {
+		slog.Error("Failed to unmarshall the ServiceAccount resource", "error", err)
+		return err
+	}
+
+	slog.Info("Restoring ServiceAccount", "name", serviceAccount.Name, "namespace", r.Namespace)
+
+	if r.backedUpNamespace == "" {
+		r.backedUpNamespace = serviceAccount.Namespace
+	}
+	r.serviceAccountName = serviceAccount.Name
+
+	utils.CleanseMetadata(&serviceAccount.ObjectMeta)
+	serviceAccount.Namespace = r.Namespace
+	serviceAccount.Secrets = nil
+
+	r.markRestored(&serviceAccount.ObjectMeta)
+	serviceAccount.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"}
+
+	if err := checkOwnership(&r.Restorer, r.KubernetesClient.CoreV1().ServiceAccounts(r.Namespace).Get, "ServiceAccount", serviceAccount.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(r.KubernetesClient.CoreV1().ServiceAccounts(r.Namespace).Patch, serviceAccount.Name, serviceAccount, r.Force); err != nil {
+		slog.Error("Failed to restore the ServiceAccount", "name", serviceAccount.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *OperatorRestorer) restoreClusterRoles(resources []byte) error {
+	var clusterRoles *rbacv1.ClusterRoleList
+
+	if err := yaml.Unmarshal(resources, &clusterRoles); err != nil {
+		slog.Error("Failed to unmarshall the ClusterRole resources", "error", err)
+		return err
+	}
+
+	for _, clusterRole := range clusterRoles.Items {
+		slog.Info("Restoring ClusterRole", "name", clusterRole.Name)
+
+		utils.CleanseMetadata(&clusterRole.ObjectMeta)
+
+		r.markRestored(&clusterRole.ObjectMeta)
+		clusterRole.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.RbacV1().ClusterRoles().Get, "ClusterRole", clusterRole.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.RbacV1().ClusterRoles().Patch, clusterRole.Name, &clusterRole, r.Force); err != nil {
+			slog.Error("Failed to restore the ClusterRole", "name", clusterRole.Name, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OperatorRestorer) restoreClusterRoleBindings(resources []byte) error {
+	var clusterRoleBindings *rbacv1.ClusterRoleBindingList
+
+	if err := yaml.Unmarshal(resources, &clusterRoleBindings); err != nil {
+		slog.Error("Failed to unmarshall the ClusterRoleBinding resources", "error", err)
+		return err
+	}
+
+	for _, clusterRoleBinding := range clusterRoleBindings.Items {
+		slog.Info("Restoring ClusterRoleBinding", "name", clusterRoleBinding.Name)
+
+		utils.CleanseMetadata(&clusterRoleBinding.ObjectMeta)
+		r.updateSubjects(clusterRoleBinding.Subjects)
+
+		r.markRestored(&clusterRoleBinding.ObjectMeta)
+		clusterRoleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.RbacV1().ClusterRoleBindings().Get, "ClusterRoleBinding", clusterRoleBinding.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.RbacV1().ClusterRoleBindings().Patch, clusterRoleBinding.Name, &clusterRoleBinding, r.Force); err != nil {
+			slog.Error("Failed to restore the ClusterRoleBinding", "name", clusterRoleBinding.Name, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OperatorRestorer) restoreRoles(resources []byte) error {
+	var roles *rbacv1.RoleList
+
+	if err := yaml.Unmarshal(resources, &roles); err != nil {
+		slog.Error("Failed to unmarshall the Role resources", "error", err)
+		return err
+	}
+
+	for _, role := range roles.Items {
+		namespace := r.namespace(role.Namespace)
+		slog.Info("Restoring Role", "name", role.Name, "namespace", namespace)
+
+		utils.CleanseMetadata(&role.ObjectMeta)
+		role.Namespace = namespace
+
+		r.markRestored(&role.ObjectMeta)
+		role.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.RbacV1().Roles(namespace).Get, "Role", role.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.RbacV1().Roles(namespace).Patch, role.Name, &role, r.Force); err != nil {
+			slog.Error("Failed to restore the Role", "name", role.Name, "namespace", namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OperatorRestorer) restoreRoleBindings(resources []byte) error {
+	var roleBindings *rbacv1.RoleBindingList
+
+	if err := yaml.Unmarshal(resources, &roleBindings); err != nil {
+		slog.Error("Failed to unmarshall the RoleBinding resources", "error", err)
+		return err
+	}
+
+	for _, roleBinding := range roleBindings.Items {
+		namespace := r.namespace(roleBinding.Namespace)
+		slog.Info("Restoring RoleBinding", "name", roleBinding.Name, "namespace", namespace)
+
+		utils.CleanseMetadata(&roleBinding.ObjectMeta)
+		roleBinding.Namespace = namespace
+		r.updateSubjects(roleBinding.Subjects)
+
+		r.markRestored(&roleBinding.ObjectMeta)
+		roleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"}
+
+		if err := checkOwnership(&r.Restorer, r.KubernetesClient.RbacV1().RoleBindings(namespace).Get, "RoleBinding", roleBinding.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.KubernetesClient.RbacV1().RoleBindings(namespace).Patch, roleBinding.Name, &roleBinding, r.Force); err != nil {
+			slog.Error("Failed to restore the RoleBinding", "name", roleBinding.Name, "namespace", namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreDeploymentAndWaitForAvailability restores the Cluster Operator Deployment and waits until all its replicas
+// are available. The namespace of the Cluster Operator is updated in its configuration when it is restored into a
+// different namespace.
+func (r *OperatorRestorer) restoreDeploymentAndWaitForAvailability() error {
+	deployment := r.deployment
+
+	slog.Info("Restoring the Cluster Operator Deployment", "name", r.Name, "namespace", r.Namespace)
+
+	utils.CleanseMetadata(&deployment.ObjectMeta)
+	deployment.Namespace = r.Namespace
+	deployment.Name = r.Name
+	deployment.Status = appsv1.DeploymentStatus{}
+
+	if r.backedUpNamespace != "" && r.backedUpNamespace != r.Namespace {
+		for i := range deployment.Spec.Template.Spec.Containers {
+			r.updateOperatorNamespaces(deployment.Spec.Template.Spec.Containers[i].Env)
+		}
+	}
+
+	r.markRestored(&deployment.ObjectMeta)
+	deployment.TypeMeta = metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"}
+
+	if err := checkOwnership(&r.Restorer, r.KubernetesClient.AppsV1().Deployments(r.Namespace).Get, "Deployment", r.Name); err != nil {
+		return err
+	}
+
+	if _, err := utils.Apply(r.KubernetesClient.AppsV1().Deployments(r.Namespace).Patch, r.Name, deployment, r.Force); err != nil {
+		slog.Error("Failed to restore the Cluster Operator Deployment", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("Waiting for the Cluster Operator Deployment to get available", "name", r.Name, "namespace", r.Namespace)
+
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second, time.Millisecond*time.Duration(r.ReadyTimeout), true, func(ctx context.Context) (bool, error) {
+		current, err := r.KubernetesClient.AppsV1().Deployments(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		replicas := int32(1)
+		if current.Spec.Replicas != nil {
+			replicas = *current.Spec.Replicas
+		}
+
+		return current.Status.ObservedGeneration >= current.Generation && current.Status.AvailableReplicas >= replicas, nil
+	})
+	if err != nil {
+		slog.Error("The Cluster Operator Deployment did not get available. Please check the Cluster Operator logs for more details.", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("The Cluster Operator Deployment is available", "name", r.Name, "namespace", r.Namespace)
+
+	return nil
+}
+
+// updateOperatorNamespaces replaces the namespace from which the Cluster Operator was backed up with the namespace into
+// which it is restored in the watched namespaces and in the namespace of the leader election Lease
+func (r *OperatorRestorer) updateOperatorNamespaces(env []v1.EnvVar) {
+	for i := range env {
+		switch env[i].Name {
+		case "STRIMZI_NAMESPACE", "STRIMZI_LEADER_ELECTION_LEASE_NAMESPACE":
+			namespaces := strings.Split(env[i].Value, ",")
+			for j := range namespaces {
+				if strings.TrimSpace(namespaces[j]) == r.backedUpNamespace {
+					namespaces[j] = r.Namespace
+				}
+			}
+
+			if value := strings.Join(namespaces, ","); value != env[i].Value {
+				slog.Info("Updating the namespace in the Cluster Operator configuration", "variable", env[i].Name, "value", env[i].Value, "newValue", value)
+				env[i].Value = value
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"path/filepath"
+)
+
+// RestoredFromAnnotation marks the resources created by the restore with the name of the backup they were restored from
+const RestoredFromAnnotation = "strimzi-backup/restored-from"
+
+// gitOpsOwnershipMarkers are the labels and annotations used by Argo CD and Flux to track the resources they manage
+var gitOpsOwnershipMarkers = []string{
+	"argocd.argoproj.io/instance",
+	"argocd.argoproj.io/tracking-id",
+	"kustomize.toolkit.fluxcd.io/name",
+	"kustomize.toolkit.fluxcd.io/namespace",
+	"helm.toolkit.fluxcd.io/name",
+	"helm.toolkit.fluxcd.io/namespace",
+}
+
+// markRestored adds the annotation identifying the backup the resource was restored from
+func (r *Restorer) markRestored(metadata *metav1.ObjectMeta) {
+	if metadata.Annotations == nil {
+		metadata.Annotations = map[string]string{RestoredFromAnnotation: filepath.Base(r.BackupFileName)}
+	} else {
+		metadata.Annotations[RestoredFromAnnotation] = filepath.Base(r.BackupFileName)
+	}
+}
+
+// checkOwnership verifies that an already existing resource can be updated by the restore. Resources not created by
+// strimzi-backup or managed by GitOps tools are updated only when the --force option is used.
+func checkOwnership[T metav1.Object](r *Restorer, get func(context.Context, string, metav1.GetOptions) (T, error), kind string, name string) error {
+	existing, err := get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		slog.Error("Failed to check the existing resource", "kind", kind, "name", name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	return checkExistingOwnership(r, existing, kind, name)
+}
+
+// checkExistingOwnership verifies that the existing resource which was already fetched can be updated by the restore
+func checkExistingOwnership(r *Restorer, existing metav1.Object, kind string, name string) error {
+	var reason string
+	if marker := gitOpsOwnershipMarker(existing); marker != "" {
+		reason = fmt.Sprintf("it is managed by a GitOps tool (%s)", marker)
+	} else if _, ok := existing.GetAnnotations()[RestoredFromAnnotation]; !ok {
+		reason = fmt.Sprintf("it does not have the %s annotation", RestoredFromAnnotation)
+	} else {
+		return nil
+	}
+
+	if r.Force {
+		slog.Warn("Updating existing resource because of the --force option", "kind", kind, "name", name, "namespace", r.Namespace, "reason", reason)
+		return nil
+	}
+
+	slog.Error("Refusing to update existing resource. Use the --force option to update it anyway.", "kind", kind, "name", name, "namespace", r.Namespace, "reason", reason)
+	return fmt.Errorf("%s %s in namespace %s already exists and %s", kind, name, r.Namespace, reason)
+}
+
+func gitOpsOwnershipMarker(resource metav1.Object) string {
+	for _, marker := range gitOpsOwnershipMarkers {
+		if _, ok := resource.GetLabels()[marker]; ok {
+			return marker
+		} else if _, ok := resource.GetAnnotations()[marker]; ok {
+			return marker
+		}
+	}
+
+	return ""
+}
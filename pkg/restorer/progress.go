@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// detailedProgressLimit is the maximal number of resources of the same kind which are logged one by one. Larger
+	// numbers of resources are logged one by one only with the --verbose option to not flood the logging pipelines.
+	detailedProgressLimit = 100
+	// progressInterval is how often the progress of restoring large numbers of resources is logged
+	progressInterval = 10 * time.Second
+)
+
+// Progress logs the progress of restoring the resources of the same kind. When restoring tens of thousands of resources,
+// the individual resources are logged only on the debug level and the number of restored resources is logged
+// periodically instead.
+type Progress struct {
+	kind     string
+	total    int
+	restored int
+	detailed bool
+	lastLog  time.Time
+}
+
+func NewProgress(kind string, total int) *Progress {
+	progress := &Progress{kind: kind, total: total, detailed: total <= detailedProgressLimit, lastLog: time.Now()}
+
+	if !progress.detailed {
+		slog.Info("Restoring large number of resources => the individual resources are logged only with the --verbose option", "kind", kind, "resources", total)
+	}
+
+	return progress
+}
+
+// Restoring logs the resource which is about to be restored and, for large numbers of resources, the progress
+func (p *Progress) Restoring(message string, args ...any) {
+	level := slog.LevelInfo
+	if !p.detailed {
+		level = slog.LevelDebug
+	}
+	slog.Log(context.Background(), level, message, args...)
+
+	p.restored++
+	if !p.detailed && time.Since(p.lastLog) >= progressInterval {
+		slog.Info("Restore in progress", "kind", p.kind, "restored", p.restored, "total", p.total)
+		p.lastLog = time.Now()
+	}
+}
+
+// Done logs the number of restored resources
+func (p *Progress) Done() {
+	if !p.detailed {
+		slog.Info("Restored all resources", "kind", p.kind, "resources", p.total)
+	}
+}
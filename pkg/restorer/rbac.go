@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/registry"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"slices"
+	"strings"
+)
+
+// restoreVerbs are the verbs needed to check the ownership of the restored resources and to restore them using the
+// server-side apply
+var restoreVerbs = []string{"get", "list", "create", "patch", "update"}
+
+// RestoreRules returns the RBAC rules needed by all restore commands. The rules for the restored resources are derived
+// from the streams restored by the restore commands, so that every restored kind is covered. The remaining rules cover
+// the status of the Kafka cluster, waiting for the restored clusters, the restore lock and checkpoint, and the volume
+// check.
+func RestoreRules() []rbacv1.PolicyRule {
+	var groups []string
+	resources := map[string][]string{}
+
+	add := func(group string, resource string) {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+
+		if !slices.Contains(resources[group], resource) {
+			resources[group] = append(resources[group], resource)
+		}
+	}
+
+	for _, stream := range backuper.Streams {
+		if stream.RestoredBy == "" || stream.Kind == "" {
+			continue
+		} else if stream.Kind == "ClusterRole" || stream.Kind == "ClusterRoleBinding" || stream.Kind == "CustomResourceDefinition" {
+			// The cluster-scoped resources of the Cluster Operator and the CRDs cannot be granted by the generated Role
+			continue
+		}
+
+		groupVersion, err := schema.ParseGroupVersion(stream.APIVersion)
+		if err != nil {
+			continue
+		}
+
+		add(groupVersion.Group, pluralResource(stream.Kind))
+	}
+
+	// The stream with the Apicurio Registries contains two different kinds
+	add(registry.ApicurioRegistryResource.Group, registry.ApicurioRegistryResource.Resource)
+	add(registry.ApicurioRegistry3Resource.Group, registry.ApicurioRegistry3Resource.Resource)
+
+	var rules []rbacv1.PolicyRule
+	for _, group := range groups {
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources[group], Verbs: restoreVerbs})
+	}
+
+	return append(rules,
+		// The cluster ID is restored in the status of the Kafka resource
+		rbacv1.PolicyRule{APIGroups: []string{"kafka.strimzi.io"}, Resources: []string{"kafkas/status"}, Verbs: []string{"patch"}},
+		rbacv1.PolicyRule{APIGroups: []string{"kafka.strimzi.io"}, Resources: []string{"kafkas", "kafkaconnects", "kafkamirrormaker2s", utils.KafkaMirrorMakerResource.Resource}, Verbs: []string{"watch"}},
+		// The Entity Operator and Cluster Operator RoleBindings can be restored only with the right to bind their roles
+		rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"roles", "clusterroles"}, Verbs: []string{"bind"}},
+		rbacv1.PolicyRule{APIGroups: []string{coordinationv1.GroupName}, Resources: []string{"leases"}, Verbs: []string{"get", "create", "update", "delete"}},
+		rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"delete"}},
+		rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "create", "delete"}},
+		rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+		rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims", "events"}, Verbs: []string{"list"}},
+	)
+}
+
+// pluralResource returns the name of the Kubernetes resource of the kind
+func pluralResource(kind string) string {
+	resource := strings.ToLower(kind)
+	if strings.HasSuffix(resource, "y") {
+		return strings.TrimSuffix(resource, "y") + "ies"
+	}
+
+	return resource + "s"
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"sigs.k8s.io/yaml"
+)
+
+// restoreKafkaRebalancesStream restores the KafkaRebalances. They are restored only once the Kafka cluster is ready, as
+// the Cluster Operator would otherwise fail to request the rebalance proposals from Cruise Control.
+func (r *KafkaRestorer) restoreKafkaRebalancesStream(resources []byte) error {
+	kafka, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if kafka.Spec == nil || kafka.Spec.CruiseControl == nil {
+		slog.Warn("Skipping restoring Kafka Rebalances as Cruise Control is not enabled in the restored Kafka cluster", "name", r.Name, "namespace", r.Namespace)
+		return nil
+	}
+
+	slog.Info("Restoring Kafka Rebalances")
+
+	if err := r.restoreKafkaRebalances(resources); err != nil {
+		slog.Error("Failed to restore Kafka Rebalances", "error", err)
+		return err
+	}
+
+	slog.Info("Kafka Rebalances were restored")
+	return nil
+}
+
+func (r *KafkaRestorer) restoreKafkaRebalances(resources []byte) error {
+	var rebalances *v1beta2.KafkaRebalanceList
+
+	if err := yaml.Unmarshal(resources, &rebalances); err != nil {
+		slog.Error("Failed to unmarshall the Kafka Rebalance resources", "error", err)
+		return err
+	}
+
+	for _, rebalance := range rebalances.Items {
+		slog.Info("Restoring Kafka Rebalance", "name", rebalance.Name, "namespace", r.Namespace, "template", rebalance.Annotations[backuper.RebalanceTemplateAnnotation] == "true")
+
+		utils.CleanseMetadata(&rebalance.ObjectMeta)
+		r.updateNamespaceAndClusterName(&rebalance.ObjectMeta)
+
+		r.markRestored(&rebalance.ObjectMeta)
+		rebalance.TypeMeta = metav1.TypeMeta{APIVersion: v1beta2.SchemeGroupVersion.String(), Kind: "KafkaRebalance"}
+		rebalance.Status = nil
+
+		if err := checkOwnership(&r.Restorer, r.StrimziClient.KafkaV1beta2().KafkaRebalances(r.Namespace).Get, "KafkaRebalance", rebalance.Name); err != nil {
+			return err
+		}
+
+		if _, err := utils.Apply(r.StrimziClient.KafkaV1beta2().KafkaRebalances(r.Namespace).Patch, rebalance.Name, &rebalance, r.Force); err != nil {
+			slog.Error("Failed to restore the Kafka Rebalance resource", "name", rebalance.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"path/filepath"
+)
+
+// Rehearse restores the Kafka cluster and verifies that it becomes ready. It is used to test the backups periodically
+// against a test cluster.
+func (r *KafkaRestorer) Rehearse() error {
+	if err := r.RestoreKafka(); err != nil {
+		return err
+	}
+
+	kafka, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if !utils.IsReady(kafka) {
+		slog.Error("The restored Kafka cluster is not ready", "name", r.Name, "namespace", r.Namespace)
+		return fmt.Errorf("the restored Kafka cluster %s is not ready", r.Name)
+	}
+
+	return nil
+}
+
+// Cleanup deletes the resources restored from the backup. Only the resources with the annotation pointing to this backup
+// are deleted. The KafkaTopics and KafkaUsers are deleted first while the Entity Operator is still running to process
+// their finalizers.
+func (r *KafkaRestorer) Cleanup() error {
+	slog.Info("Deleting the restored resources", "name", r.Name, "namespace", r.Namespace)
+
+	listOptions := metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + r.Name}
+
+	topics, err := r.StrimziClient.KafkaV1beta2().KafkaTopics(r.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		slog.Error("Failed to list the KafkaTopics", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	for _, topic := range topics.Items {
+		if err := r.deleteRestored(r.StrimziClient.KafkaV1beta2().KafkaTopics(r.Namespace).Delete, "KafkaTopic", &topic.ObjectMeta); err != nil {
+			return err
+		}
+	}
+
+	users, err := r.StrimziClient.KafkaV1beta2().KafkaUsers(r.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		slog.Error("Failed to list the KafkaUsers", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	for _, user := range users.Items {
+		if err := r.deleteRestored(r.StrimziClient.KafkaV1beta2().KafkaUsers(r.Namespace).Delete, "KafkaUser", &user.ObjectMeta); err != nil {
+			return err
+		}
+	}
+
+	secrets, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		slog.Error("Failed to list the Secrets", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		if err := r.deleteRestored(r.KubernetesClient.CoreV1().Secrets(r.Namespace).Delete, "Secret", &secret.ObjectMeta); err != nil {
+			return err
+		}
+	}
+
+	nodePools, err := r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		slog.Error("Failed to list the KafkaNodePools", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	for _, nodePool := range nodePools.Items {
+		if err := r.deleteRestored(r.StrimziClient.KafkaV1beta2().KafkaNodePools(r.Namespace).Delete, "KafkaNodePool", &nodePool.ObjectMeta); err != nil {
+			return err
+		}
+	}
+
+	kafka, err := r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Get(context.TODO(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		slog.Error("Failed to get the Kafka resource", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	return r.deleteRestored(r.StrimziClient.KafkaV1beta2().Kafkas(r.Namespace).Delete, "Kafka", &kafka.ObjectMeta)
+}
+
+func (r *KafkaRestorer) deleteRestored(del func(context.Context, string, metav1.DeleteOptions) error, kind string, metadata *metav1.ObjectMeta) error {
+	if metadata.Annotations[RestoredFromAnnotation] != filepath.Base(r.BackupFileName) {
+		slog.Debug("Skipping resource which was not restored from this backup", "kind", kind, "name", metadata.Name)
+		return nil
+	}
+
+	if err := del(context.TODO(), metadata.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		slog.Error("Failed to delete the restored resource", "kind", kind, "name", metadata.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	slog.Info("Deleted restored resource", "kind", kind, "name", metadata.Name, "namespace", r.Namespace)
+
+	return nil
+}
+
+// minimizeKafka removes the resource requests and limits and the JVM options and replaces persistent storage with
+// ephemeral storage, so that the restored cluster fits into small test clusters
+func minimizeKafka(kafka *v1beta2.Kafka) {
+	if kafka.Spec == nil {
+		return
+	}
+
+	if kafka.Spec.Kafka != nil {
+		kafka.Spec.Kafka.Resources = nil
+		kafka.Spec.Kafka.JvmOptions = nil
+		kafka.Spec.Kafka.Storage = ephemeralStorage(kafka.Spec.Kafka.Storage)
+	}
+
+	if kafka.Spec.EntityOperator != nil {
+		if kafka.Spec.EntityOperator.TopicOperator != nil {
+			kafka.Spec.EntityOperator.TopicOperator.Resources = nil
+		}
+
+		if kafka.Spec.EntityOperator.UserOperator != nil {
+			kafka.Spec.EntityOperator.UserOperator.Resources = nil
+		}
+	}
+
+	if kafka.Spec.CruiseControl != nil {
+		kafka.Spec.CruiseControl.Resources = nil
+	}
+
+	if kafka.Spec.KafkaExporter != nil {
+		kafka.Spec.KafkaExporter.Resources = nil
+	}
+}
+
+// minimizeKafkaNodePool removes the resource requests and limits and the JVM options and replaces persistent storage
+// with ephemeral storage. The number of replicas is kept to preserve the node IDs.
+func minimizeKafkaNodePool(nodePool *v1beta2.KafkaNodePool) {
+	if nodePool.Spec == nil {
+		return
+	}
+
+	nodePool.Spec.Resources = nil
+	nodePool.Spec.JvmOptions = nil
+	nodePool.Spec.Storage = ephemeralStorage(nodePool.Spec.Storage)
+}
+
+func ephemeralStorage(storage *v1beta2.Storage) *v1beta2.Storage {
+	if storage == nil {
+		return nil
+	}
+
+	if storage.Type == v1beta2.JBOD_STORAGETYPE {
+		volumes := make([]v1beta2.SingleVolumeStorage, 0, len(storage.Volumes))
+		for _, volume := range storage.Volumes {
+			volumes = append(volumes, v1beta2.SingleVolumeStorage{Id: volume.Id, Type: v1beta2.EPHEMERAL_SINGLEVOLUMESTORAGETYPE, KraftMetadata: volume.KraftMetadata})
+		}
+
+		return &v1beta2.Storage{Type: v1beta2.JBOD_STORAGETYPE, Volumes: volumes}
+	}
+
+	return &v1beta2.Storage{Type: v1beta2.EPHEMERAL_STORAGETYPE, KraftMetadata: storage.KraftMetadata}
+}
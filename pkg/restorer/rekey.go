@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"crypto/rand"
+	v1 "k8s.io/api/core/v1"
+	"log/slog"
+	"math/big"
+	"os"
+	"regexp"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// rekeyedPasswordLength is the length of the newly generated SCRAM-SHA-512 passwords (same as used by the Strimzi
+	// User Operator)
+	rekeyedPasswordLength = 32
+	passwordCharacters    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var jaasUsername = regexp.MustCompile(`username="([^"]*)"`)
+
+// CredentialReportEntry describes how the credentials of a single Kafka user were changed during the restore
+type CredentialReportEntry struct {
+	User           string `json:"user"`
+	Secret         string `json:"secret"`
+	Authentication string `json:"authentication"`
+	Action         string `json:"action"`
+}
+
+// rekeySecrets replaces the credentials from the backup with new ones, so that the original credentials are not
+// reused in the restored environment. The usernames and the KafkaUser resources with their ACLs are kept.
+//   - SCRAM-SHA-512 users get a newly generated password which is then used by the User Operator
+//   - The Secrets of TLS users are not restored, so that the User Operator issues new user certificates
+//
+// The Secrets which should be restored are returned.
+func (r *KafkaRestorer) rekeySecrets(secrets []v1.Secret) ([]v1.Secret, error) {
+	var rekeyed []v1.Secret
+
+	for _, secret := range secrets {
+		user := secret.Labels["strimzi.io/name"]
+		if user == "" {
+			user = secret.Name
+		}
+
+		if _, ok := secret.Data["password"]; ok {
+			password, err := generatePassword()
+			if err != nil {
+				slog.Error("Failed to generate a new password", "secret", secret.Name, "error", err)
+				return nil, err
+			}
+
+			username := secret.Name
+			if match := jaasUsername.FindSubmatch(secret.Data["sasl.jaas.config"]); match != nil {
+				username = string(match[1])
+			}
+
+			secret.Data["password"] = []byte(password)
+			secret.Data["sasl.jaas.config"] = []byte("org.apache.kafka.common.security.scram.ScramLoginModule required username=\"" + username + "\" password=\"" + password + "\";")
+
+			slog.Info("Generated a new password for the Kafka user", "user", user, "secret", secret.Name)
+			r.credentialReport = append(r.credentialReport, CredentialReportEntry{User: user, Secret: secret.Name, Authentication: "scram-sha-512", Action: "new password generated"})
+
+			rekeyed = append(rekeyed, secret)
+		} else if _, ok := secret.Data["user.crt"]; ok {
+			slog.Info("Skipping the certificate of the Kafka user, a new certificate will be issued by the User Operator", "user", user, "secret", secret.Name)
+			r.credentialReport = append(r.credentialReport, CredentialReportEntry{User: user, Secret: secret.Name, Authentication: "tls", Action: "new certificate issued by the User Operator"})
+		} else {
+			slog.Warn("Unknown type of Kafka user Secret, restoring it without changes", "user", user, "secret", secret.Name)
+			r.credentialReport = append(r.credentialReport, CredentialReportEntry{User: user, Secret: secret.Name, Authentication: "unknown", Action: "restored without changes"})
+
+			rekeyed = append(rekeyed, secret)
+		}
+	}
+
+	return rekeyed, nil
+}
+
+// writeCredentialReport writes the report of the changed credentials into the file. When no file is configured, the
+// report is only logged.
+func (r *KafkaRestorer) writeCredentialReport() error {
+	if r.credentialReportFileName == "" {
+		for _, entry := range r.credentialReport {
+			slog.Info("Credentials of the Kafka user changed", "user", entry.User, "secret", entry.Secret, "authentication", entry.Authentication, "action", entry.Action)
+		}
+
+		return nil
+	}
+
+	report, err := yaml.Marshal(map[string]any{"users": r.credentialReport})
+	if err != nil {
+		slog.Error("Failed to marshal the credential report to YAML", "error", err)
+		return err
+	}
+
+	if err := os.WriteFile(r.credentialReportFileName, report, 0600); err != nil {
+		slog.Error("Failed to write the credential report", "file", r.credentialReportFileName, "error", err)
+		return err
+	}
+
+	slog.Info("Credential report written", "file", r.credentialReportFileName, "users", len(r.credentialReport))
+
+	return nil
+}
+
+// generatePassword generates a random alphanumeric password
+func generatePassword() (string, error) {
+	password := make([]byte, rekeyedPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordCharacters))))
+		if err != nil {
+			return "", err
+		}
+
+		password[i] = passwordCharacters[n.Int64()]
+	}
+
+	return string(password), nil
+}
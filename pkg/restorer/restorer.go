@@ -17,26 +17,44 @@ limitations under the License.
 package restorer
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
 	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/scholzj/strimzi-backup/pkg/storage"
 	"github.com/scholzj/strimzi-backup/pkg/utils"
 	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 )
 
 type Restorer struct {
 	KubernetesClient *kubernetes.Clientset
 	StrimziClient    *strimzi.Clientset
+	DynamicClient    *dynamic.DynamicClient
 	Namespace        string
+	SourceNamespace  string
+	SourceCluster    string
 	Name             string
 	Timeout          uint32
-	backupFile       *os.File
-	bufferedReader   *bufio.Reader
-	gzipReader       *gzip.Reader
+	PauseTimeout     uint32
+	ReadyTimeout     uint32
+	StallTimeout     uint32
+	Resume           bool
+	Force            bool
+	BackupFileName   string
+	backupFile       io.ReadCloser
+	reader           *archive.Reader
+	checkpoint       *Checkpoint
+	lock             *RestoreLock
+	nameMapping      *NameMapping
 }
 
 func NewRestorer(cmd *cobra.Command) (*Restorer, error) {
@@ -52,52 +70,323 @@ func NewRestorer(cmd *cobra.Command) (*Restorer, error) {
 		return nil, err
 	}
 
-	kubeClient, strimziClient, namespace, err := utils.CreateKubernetesClients(cmd)
+	pauseTimeout, err := cmd.Flags().GetUint32("pause-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --pause-timeout flag", "error", err)
+		return nil, err
+	}
+
+	readyTimeout, err := cmd.Flags().GetUint32("ready-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --ready-timeout flag", "error", err)
+		return nil, err
+	}
+
+	// The --timeout option was used also for waiting for the cluster before the separate options were added. When it
+	// is set explicitly, it keeps applying to the waiting unless the new options are set as well.
+	if cmd.Flags().Changed("timeout") {
+		if !cmd.Flags().Changed("pause-timeout") {
+			pauseTimeout = timeout
+		}
+
+		if !cmd.Flags().Changed("ready-timeout") {
+			readyTimeout = timeout
+		}
+	}
+
+	stallTimeout, err := cmd.Flags().GetUint32("stall-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --stall-timeout flag", "error", err)
+		return nil, err
+	}
+
+	kubeClient, strimziClient, dynamicClient, namespace, err := utils.CreateKubernetesClients(cmd)
 	if err != nil {
 		slog.Error("Failed to create Kubernetes clients", "error", err)
 		return nil, err
 	}
 
+	// The source namespace is used to find the backup in the storage and to check that the backup was taken in the
+	// expected namespace. When not set, the backup is expected in the storage under the target namespace.
+	sourceNamespace := cmd.Flag("source-namespace").Value.String()
+	storageNamespace := namespace
+	if sourceNamespace != "" {
+		if errs := validation.IsDNS1123Label(sourceNamespace); len(errs) > 0 {
+			slog.Error("Invalid --source-namespace option", "namespace", sourceNamespace, "errors", errs)
+			return nil, fmt.Errorf("invalid --source-namespace option %s: %s", sourceNamespace, strings.Join(errs, ", "))
+		}
+
+		storageNamespace = sourceNamespace
+		slog.Info("Restoring the backup from the source namespace into the target namespace", "sourceNamespace", sourceNamespace, "targetNamespace", namespace, "name", name)
+	}
+
+	// The source cluster selects the Kafka cluster from the backups containing multiple Kafka clusters. When not set,
+	// the Kafka cluster with the same name as the restored cluster is selected.
+	sourceCluster := cmd.Flag("source-cluster").Value.String()
+	if sourceCluster == "" {
+		sourceCluster = name
+	}
+
+	resume, err := cmd.Flags().GetBool("resume")
+	if err != nil {
+		slog.Error("Failed to get the --resume flag", "error", err)
+		return nil, err
+	}
+
+	if !resume && utils.IsRunningInCluster() {
+		slog.Info("Running inside a Kubernetes cluster => the restore progress will be checkpointed and resumed automatically")
+		resume = true
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		slog.Error("Failed to get the --force flag", "error", err)
+		return nil, err
+	}
+
+	// The backups of all Kafka clusters in the namespace are stored under the all-clusters name instead of the name of
+	// the Kafka cluster
+	allClusters, err := restoresAllClusters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	storageName := name
+	if allClusters {
+		storageName = backuper.AllClustersName
+	}
+
 	backupFileName := cmd.Flag("filename").Value.String()
-	backupFile, err := os.OpenFile(backupFileName, os.O_RDONLY, 0644)
+	backupFile, err := openBackupFile(cmd, storageNamespace, storageName, backupFileName)
 	if err != nil {
 		slog.Error("Failed to open file", "error", err, "file", backupFileName)
 		return nil, err
 	}
 
-	bufferedReader := bufio.NewReader(backupFile)
-	gzipReader, err := gzip.NewReader(bufferedReader)
+	// The backup file and the restore lock are released when the restorer is not created
+	var lock *RestoreLock
+	created := false
+	defer func() {
+		if !created {
+			if backupFile != nil {
+				_ = backupFile.Close()
+			}
+
+			lock.Release()
+		}
+	}()
+
+	limits, err := archive.NewLimitsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if hmacKeyFile := cmd.Flag("hmac-key-file").Value.String(); hmacKeyFile != "" {
+		backupFile, err = verifyHMAC(backupFile, backupFileName, hmacKeyFile, *limits)
+		if err != nil {
+			slog.Error("Failed to verify the integrity of the backup", "error", err, "file", backupFileName)
+			return nil, err
+		}
+	}
+
+	if err := checkSourceCluster(backupFile, sourceCluster, cmd.Flags().Changed("source-cluster"), *limits); err != nil {
+		slog.Error("Failed to select the Kafka cluster from the backup", "error", err, "file", backupFileName)
+		return nil, err
+	}
+
+	encryptionKey, err := archive.ReadPassphrase(cmd, false)
+	if err != nil {
+		slog.Error("Failed to read the encryption passphrase", "error", err)
+		return nil, err
+	}
+
+	var nameMapping *NameMapping
+	if nameMappingFile := cmd.Flag("name-mapping-file").Value.String(); nameMappingFile != "" {
+		nameMapping, err = LoadNameMapping(nameMappingFile)
+		if err != nil {
+			slog.Error("Failed to load the name mapping", "error", err, "file", nameMappingFile)
+			return nil, err
+		}
+	}
+
+	// The encrypted streams are decrypted and decompressed by the pipeline of the archive reader
+	reader, err := archive.NewReader(backupFile, limits, &archive.CompressionStage{MaxSize: limits.MaxStreamSize}, &archive.EncryptionStage{Passphrase: encryptionKey})
 	if err != nil {
 		slog.Error("Failed to read file", "error", err, "file", backupFileName)
 		return nil, err
 	}
 
+	// From the backups of all Kafka clusters in the namespace, only the streams of the restored Kafka cluster are read
+	reader.SelectCluster(sourceCluster)
+
+	lockTTL, err := cmd.Flags().GetDuration("lock-ttl")
+	if err != nil {
+		slog.Error("Failed to get the --lock-ttl flag", "error", err)
+		return nil, err
+	}
+
+	if lockTTL > 0 {
+		lock, err = AcquireLock(kubeClient, namespace, name, lockTTL)
+		if err != nil {
+			slog.Error("Failed to acquire the restore lock", "error", err)
+			return nil, err
+		}
+	} else {
+		slog.Warn("The restore lock is disabled => concurrent restores of the same Kafka cluster are not prevented")
+	}
+
+	var checkpoint *Checkpoint
+	if resume {
+		checkpoint, err = LoadCheckpoint(kubeClient, namespace, CheckpointName(name, backupFileName))
+		if err != nil {
+			slog.Error("Failed to load the restore checkpoint", "error", err)
+			return nil, err
+		}
+	}
+
 	restorer := Restorer{
 		KubernetesClient: kubeClient,
 		StrimziClient:    strimziClient,
+		DynamicClient:    dynamicClient,
 		Namespace:        namespace,
+		SourceNamespace:  sourceNamespace,
+		SourceCluster:    sourceCluster,
 		Name:             name,
 		Timeout:          timeout,
+		PauseTimeout:     pauseTimeout,
+		ReadyTimeout:     readyTimeout,
+		StallTimeout:     stallTimeout,
+		Resume:           resume,
+		Force:            force,
+		BackupFileName:   backupFileName,
 		backupFile:       backupFile,
-		bufferedReader:   bufferedReader,
-		gzipReader:       gzipReader,
+		reader:           reader,
+		checkpoint:       checkpoint,
+		lock:             lock,
+		nameMapping:      nameMapping,
 	}
+	created = true
 
 	return &restorer, nil
 }
 
+// checkSourceNamespace checks that the Kafka cluster from the backup was backed up from the namespace set with the
+// --source-namespace option. Backups taken in a different namespace are rejected to make sure the resources from the
+// intended backup land in the target namespace.
+func (r *Restorer) checkSourceNamespace(backedUpNamespace string) error {
+	if r.SourceNamespace == "" || backedUpNamespace == "" || backedUpNamespace == r.SourceNamespace {
+		return nil
+	}
+
+	slog.Error("The Kafka cluster in the backup was backed up from a different namespace than the source namespace", "backupNamespace", backedUpNamespace, "sourceNamespace", r.SourceNamespace)
+	return fmt.Errorf("the Kafka cluster in the backup was backed up from the namespace %s and not from the source namespace %s. Use the --source-namespace option to set the namespace from which the backup was taken", backedUpNamespace, r.SourceNamespace)
+}
+
+// openBackupFile opens the backup file either from the local filesystem or, when the --storage option is used, from the
+// storage
+// checkSourceCluster checks that the Kafka cluster selected from the backup is in the index of the backups containing
+// multiple Kafka clusters. Otherwise, none of the streams would be selected and nothing would be restored. The backups
+// with a single Kafka cluster are checked only when the source cluster is set explicitly. The manifest is the last
+// stream of the backup, so the backup is read twice.
+func checkSourceCluster(backupFile io.ReadCloser, sourceCluster string, explicit bool, limits archive.Limits) error {
+	seeker, ok := backupFile.(io.Seeker)
+	if !ok {
+		return nil
+	}
+
+	manifest, err := archive.ReadManifest(backupFile, &limits)
+	if err != nil {
+		return err
+	}
+
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if manifest == nil {
+		return nil
+	} else if len(manifest.Clusters) == 0 {
+		if explicit && manifest.Name != sourceCluster {
+			return fmt.Errorf("the backup contains the Kafka cluster %s and not the source cluster %s", manifest.Name, sourceCluster)
+		}
+
+		return nil
+	}
+
+	if !slices.Contains(manifest.ClusterNames(), sourceCluster) {
+		return fmt.Errorf("the Kafka cluster %s is not in the backup which contains the Kafka clusters %s. Use the --source-cluster option to select the Kafka cluster from the backup", sourceCluster, strings.Join(manifest.ClusterNames(), ", "))
+	}
+
+	return nil
+}
+
+func openBackupFile(cmd *cobra.Command, namespace string, name string, backupFileName string) (io.ReadCloser, error) {
+	backupStorage, err := storage.NewFromFlags(cmd, namespace, name)
+	if err != nil {
+		slog.Error("Failed to configure the storage", "storage", cmd.Flag("storage").Value.String(), "error", err)
+		return nil, err
+	} else if backupStorage == nil {
+		return os.OpenFile(backupFileName, os.O_RDONLY, 0644)
+	}
+
+	slog.Info("Reading the backup from the storage", "file", backupFileName)
+
+	return storage.OpenVerified(context.Background(), backupStorage, backupFileName)
+}
+
+// verifyHMAC verifies the HMAC of the backup before anything is restored. The backup is verified stream by stream
+// while it is copied into a temporary file, so it does not have to fit into memory. The temporary file with the verified
+// backup is returned instead of the original backup and is removed once it is closed.
+func verifyHMAC(backupFile io.ReadCloser, backupFileName string, hmacKeyFile string, limits archive.Limits) (io.ReadCloser, error) {
+	defer backupFile.Close()
+
+	key, err := archive.ReadHMACKey(hmacKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := storage.CreateTempBackupFile()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := io.TeeReader(backupFile, verified)
+	if err := archive.VerifyHMAC(reader, key, &limits); err != nil {
+		_ = verified.Close()
+		return nil, err
+	}
+
+	// Copy any data remaining after the last stream, so that the verified backup is complete
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		_ = verified.Close()
+		return nil, err
+	}
+
+	if _, err := verified.Seek(0, io.SeekStart); err != nil {
+		_ = verified.Close()
+		return nil, err
+	}
+
+	slog.Info("HMAC of the backup verified", "file", backupFileName)
+
+	return verified, nil
+}
+
 func (r *Restorer) Close() {
-	if r.gzipReader != nil {
-		err := r.gzipReader.Close()
+	if r.reader != nil {
+		err := r.reader.Close()
 		if err != nil {
-			slog.Error("Failed to close the GZIP reader", "error", err)
+			slog.Error("Failed to close the backup reader", "error", err)
 		}
 	}
 
 	if r.backupFile != nil {
 		err := r.backupFile.Close()
 		if err != nil {
-			slog.Error("Failed to close the backup file", "error", err, "backupFile", r.backupFile.Name())
+			slog.Error("Failed to close the backup file", "error", err, "backupFile", r.BackupFileName)
 		}
 	}
+
+	r.lock.Release()
+	r.lock = nil
 }
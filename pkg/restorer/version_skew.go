@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StrimziUpgradeDocs links to the Strimzi documentation about upgrading and downgrading the Cluster Operator
+const StrimziUpgradeDocs = "https://strimzi.io/docs/operators/latest/deploying#assembly-upgrade-str"
+
+// StrimziChangelog links to the Strimzi changelog describing the changes of each Strimzi version
+const StrimziChangelog = "https://github.com/strimzi/strimzi-kafka-operator/blob/main/CHANGELOG.md"
+
+// operatorVersionConsiderations are the Strimzi versions with changes which need attention when restoring a backup
+// across them
+var operatorVersionConsiderations = []struct {
+	version string
+	message string
+}{
+	{"0.46.0", "Strimzi 0.46 removed the support for ZooKeeper-based Kafka clusters. Only KRaft-based Kafka clusters using Kafka Node Pools can be restored with it."},
+	{"0.49.0", "Strimzi 0.49 introduced the v1 API of the Strimzi custom resources and deprecated the v1beta2 API used by the backup. Deprecated fields might be rejected or ignored."},
+}
+
+// checkOperatorVersionSkew compares the version of the Strimzi Cluster Operator which managed the backed up Kafka
+// cluster with the version of the Cluster Operator running in the target Kubernetes cluster. The differences are only
+// reported as warnings, as restoring into a different Strimzi version is often intended (for example when migrating
+// to a new Kubernetes cluster).
+func (r *Restorer) checkOperatorVersionSkew(backupVersion string) {
+	if backupVersion == "" {
+		slog.Info("The backup does not contain the version of the Strimzi Cluster Operator => skipping the version skew check")
+		return
+	}
+
+	targetVersion := r.detectOperatorVersion()
+	if targetVersion == "" {
+		slog.Info("Cannot determine the version of the Strimzi Cluster Operator in the target cluster => skipping the version skew check", "backupOperatorVersion", backupVersion)
+		return
+	}
+
+	backupMajorMinor, backupOk := parseMajorMinor(backupVersion)
+	targetMajorMinor, targetOk := parseMajorMinor(targetVersion)
+	if !backupOk || !targetOk {
+		slog.Info("Cannot compare the versions of the Strimzi Cluster Operator => skipping the version skew check", "backupOperatorVersion", backupVersion, "operatorVersion", targetVersion)
+		return
+	}
+
+	switch slices.Compare(backupMajorMinor[:], targetMajorMinor[:]) {
+	case 0:
+		slog.Info("The backup was taken with the same version of the Strimzi Cluster Operator as the one running in the target cluster", "operatorVersion", targetVersion)
+		return
+	case -1:
+		slog.Warn("The Strimzi Cluster Operator in the target cluster is newer than the one which managed the backed up Kafka cluster. Check the upgrade considerations of the Strimzi versions in between.", "backupOperatorVersion", backupVersion, "operatorVersion", targetVersion, "docs", StrimziUpgradeDocs)
+	case 1:
+		slog.Warn("The Strimzi Cluster Operator in the target cluster is older than the one which managed the backed up Kafka cluster. The backed up resources might use features which are not supported by the older version.", "backupOperatorVersion", backupVersion, "operatorVersion", targetVersion, "docs", StrimziUpgradeDocs)
+	}
+
+	lower, upper := backupMajorMinor, targetMajorMinor
+	if slices.Compare(lower[:], upper[:]) > 0 {
+		lower, upper = upper, lower
+	}
+
+	for _, consideration := range operatorVersionConsiderations {
+		version, _ := parseMajorMinor(consideration.version)
+		if slices.Compare(lower[:], version[:]) < 0 && slices.Compare(version[:], upper[:]) <= 0 {
+			slog.Warn(consideration.message, "version", consideration.version, "docs", StrimziChangelog)
+		}
+	}
+}
+
+// detectOperatorVersion returns the version of the Strimzi Cluster Operator running in the Kubernetes cluster based on
+// the image tag of its Pods. It returns an empty string when the version cannot be determined (for example when
+// strimzi-backup is not allowed to list Pods in other namespaces and no Cluster Operator runs in the namespace of the
+// restored Kafka cluster).
+func (r *Restorer) detectOperatorVersion() string {
+	listOptions := metav1.ListOptions{LabelSelector: "strimzi.io/kind=cluster-operator"}
+
+	pods, err := r.KubernetesClient.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), listOptions)
+	if err != nil {
+		slog.Debug("Failed to list the Strimzi Cluster Operator Pods in all namespaces", "error", err)
+
+		pods, err = r.KubernetesClient.CoreV1().Pods(r.Namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			slog.Debug("Failed to list the Strimzi Cluster Operator Pods", "namespace", r.Namespace, "error", err)
+			return ""
+		}
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if version := imageTag(container.Image); version != "" {
+				slog.Debug("Found the Strimzi Cluster Operator", "pod", pod.Name, "namespace", pod.Namespace, "version", version)
+				return version
+			}
+		}
+	}
+
+	return ""
+}
+
+// imageTag returns the tag of the container image or an empty string when the image does not have any tag
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	index := strings.LastIndex(image, ":")
+	if index < 0 || strings.Contains(image[index+1:], "/") {
+		return ""
+	}
+
+	return image[index+1:]
+}
+
+// parseMajorMinor parses the major and minor version from versions such as 0.45.0, 0.45.0-rc1, or v1.0.0
+func parseMajorMinor(version string) ([2]int, bool) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+
+	return [2]int{major, minor}, true
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// VolumeClusterIdAnnotation can be set on the PersistentVolumeClaims of the Kafka nodes to provide the Cluster ID
+	// stored on the volume without inspecting the volume with a helper Pod
+	VolumeClusterIdAnnotation = "strimzi-backup/cluster-id"
+
+	// DefaultVolumeCheckImage is the container image used by the helper Pods reading the Cluster ID from the volumes
+	DefaultVolumeCheckImage = "registry.access.redhat.com/ubi9/ubi-minimal:latest"
+)
+
+// checkVolumeClusterIds verifies that the existing data volumes of the Kafka nodes, which will be reused by the restored
+// Kafka cluster, contain the same Cluster ID as the one being restored. Kafka nodes refuse to start with volumes
+// containing a different Cluster ID, so it is better to fail before the Kafka cluster is unpaused.
+func (r *KafkaRestorer) checkVolumeClusterIds(clusterId string) error {
+	pvcs, err := r.KubernetesClient.CoreV1().PersistentVolumeClaims(r.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + r.Name + ",strimzi.io/kind=Kafka"})
+	if err != nil {
+		slog.Error("Failed to list the PersistentVolumeClaims of the Kafka cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+
+		volumeClusterIds, err := r.readVolumeClusterIds(pvc)
+		if err != nil {
+			slog.Error("Failed to read the Cluster ID from the volume", "pvc", pvc.Name, "namespace", r.Namespace, "error", err)
+			return err
+		}
+
+		for _, volumeClusterId := range volumeClusterIds {
+			if clusterId == "" {
+				slog.Error("The existing volume contains Kafka data, but no Cluster ID is restored", "pvc", pvc.Name, "volumeClusterId", volumeClusterId)
+				return fmt.Errorf("the volume of the PersistentVolumeClaim %s contains Kafka data of the cluster with the Cluster ID %s, but no Cluster ID is restored and the Kafka nodes would refuse to start", pvc.Name, volumeClusterId)
+			} else if volumeClusterId != clusterId {
+				slog.Error("The existing volume contains Kafka data with a different Cluster ID", "pvc", pvc.Name, "volumeClusterId", volumeClusterId, "clusterId", clusterId)
+				return fmt.Errorf("the volume of the PersistentVolumeClaim %s contains Kafka data of the cluster with the Cluster ID %s, but the Cluster ID %s is restored and the Kafka nodes would refuse to start", pvc.Name, volumeClusterId, clusterId)
+			}
+		}
+
+		if len(volumeClusterIds) > 0 {
+			slog.Info("The existing volume contains Kafka data with the restored Cluster ID", "pvc", pvc.Name, "clusterId", clusterId)
+		}
+	}
+
+	return nil
+}
+
+// readVolumeClusterIds reads the Cluster IDs from the meta.properties files of the Kafka log directories stored on the
+// volume. The Cluster ID from the annotation is used when available. Otherwise, the volume is inspected by a helper Pod.
+func (r *KafkaRestorer) readVolumeClusterIds(pvc corev1.PersistentVolumeClaim) ([]string, error) {
+	if clusterId := pvc.Annotations[VolumeClusterIdAnnotation]; clusterId != "" {
+		return []string{clusterId}, nil
+	}
+
+	slog.Info("Inspecting the existing volume of the Kafka cluster", "pvc", pvc.Name, "namespace", r.Namespace)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "strimzi-backup-volume-check-",
+			Namespace:    r.Namespace,
+			Labels:       map[string]string{"app.kubernetes.io/name": "strimzi-backup", "strimzi-backup/volume-check": pvc.Name},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{{
+				Name:    "volume-check",
+				Image:   r.volumeCheckImage,
+				Command: []string{"sh", "-c", "cat /data/kafka-log*/meta.properties 2>/dev/null || true"},
+				SecurityContext: &corev1.SecurityContext{
+					RunAsUser:                ptr.To(int64(1001)),
+					AllowPrivilegeEscalation: ptr.To(false),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data", ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name, ReadOnly: true}},
+			}},
+		},
+	}
+
+	pod, err := r.KubernetesClient.CoreV1().Pods(r.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := r.KubernetesClient.CoreV1().Pods(r.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			slog.Warn("Failed to delete the volume check Pod", "pod", pod.Name, "namespace", r.Namespace, "error", err)
+		}
+	}()
+
+	err = wait.PollUntilContextTimeout(context.Background(), time.Second, time.Millisecond*time.Duration(r.Timeout), true, func(ctx context.Context) (bool, error) {
+		current, err := r.KubernetesClient.CoreV1().Pods(r.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		switch current.Status.Phase {
+		case corev1.PodSucceeded:
+			return true, nil
+		case corev1.PodFailed:
+			return false, fmt.Errorf("the volume check Pod %s failed", pod.Name)
+		default:
+			return false, nil
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect the volume of the PersistentVolumeClaim %s: %w", pvc.Name, err)
+	}
+
+	logs, err := r.KubernetesClient.CoreV1().Pods(r.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var clusterIds []string
+	scanner := bufio.NewScanner(strings.NewReader(string(logs)))
+	for scanner.Scan() {
+		if clusterId, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "cluster.id="); ok && !slices.Contains(clusterIds, clusterId) {
+			clusterIds = append(clusterIds, clusterId)
+		}
+	}
+
+	return clusterIds, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restorer
+
+import (
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	"log/slog"
+)
+
+// topicNamespace returns the namespace into which the KafkaTopic backed up from the given namespace is restored. The
+// KafkaTopics from the namespace of the Kafka cluster are restored into the namespace of the restored Kafka cluster.
+// The KafkaTopics from the other namespaces watched by the Topic Operator are restored into the namespaces they are
+// mapped to with the --topic-namespaces option or into their original namespaces.
+func (r *KafkaRestorer) topicNamespace(namespace string) string {
+	return r.watchedNamespace(r.namespaceAwareTopics, r.topicNamespaces, namespace)
+}
+
+// userNamespace returns the namespace into which the KafkaUser or User Secret backed up from the given namespace is
+// restored. The resources from the namespace watched by the User Operator are restored into the namespace they are
+// mapped to with the --user-namespaces option or into their original namespace.
+func (r *KafkaRestorer) userNamespace(namespace string) string {
+	return r.watchedNamespace(r.namespaceAwareUsers, r.userNamespaces, namespace)
+}
+
+// watchedNamespace maps the namespace from which a resource was backed up to the namespace into which it is restored
+func (r *KafkaRestorer) watchedNamespace(namespaceAware bool, mapping map[string]string, namespace string) string {
+	if !namespaceAware || namespace == "" {
+		return r.Namespace
+	}
+
+	if target, ok := mapping[namespace]; ok {
+		return target
+	} else if r.backedUpNamespace == "" || namespace == r.backedUpNamespace {
+		return r.Namespace
+	}
+
+	return namespace
+}
+
+// updateWatchedNamespaces updates the namespaces watched by the Topic and User Operators of the restored Kafka cluster
+// when the KafkaTopics or KafkaUsers from them are restored into different namespaces, so that the operators manage
+// the restored resources
+func (r *KafkaRestorer) updateWatchedNamespaces(kafka *v1beta2.Kafka) {
+	if kafka.Spec == nil || kafka.Spec.EntityOperator == nil {
+		return
+	}
+
+	if topicOperator := kafka.Spec.EntityOperator.TopicOperator; topicOperator != nil && topicOperator.WatchedNamespace != "" {
+		if namespace := r.topicNamespace(topicOperator.WatchedNamespace); namespace != topicOperator.WatchedNamespace {
+			slog.Info("Updating the namespace watched by the Topic Operator", "watchedNamespace", topicOperator.WatchedNamespace, "newWatchedNamespace", namespace)
+			topicOperator.WatchedNamespace = namespace
+		}
+	}
+
+	if userOperator := kafka.Spec.EntityOperator.UserOperator; userOperator != nil && userOperator.WatchedNamespace != "" {
+		if namespace := r.userNamespace(userOperator.WatchedNamespace); namespace != userOperator.WatchedNamespace {
+			slog.Info("Updating the namespace watched by the User Operator", "watchedNamespace", userOperator.WatchedNamespace, "newWatchedNamespace", namespace)
+			userOperator.WatchedNamespace = namespace
+		}
+	}
+}
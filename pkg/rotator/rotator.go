@@ -0,0 +1,258 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/restorer"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"time"
+)
+
+const (
+	// ForceReplaceAnnotation makes the Strimzi Cluster Operator replace the CA private key and certificate
+	ForceReplaceAnnotation = "strimzi.io/force-replace"
+	// ForceRenewAnnotation makes the Strimzi Cluster Operator renew the CA certificate while keeping the private key
+	ForceRenewAnnotation = "strimzi.io/force-renew"
+)
+
+var kafkaResource = schema.GroupVersionResource{Group: "kafka.strimzi.io", Version: "v1beta2", Resource: "kafkas"}
+
+type Rotator struct {
+	KubernetesClient *kubernetes.Clientset
+	StrimziClient    *strimzi.Clientset
+	DynamicClient    *dynamic.DynamicClient
+	Namespace        string
+	Name             string
+	Timeout          uint32
+	StallTimeout     uint32
+	AfterRestore     bool
+	RenewOnly        bool
+	ClusterCa        bool
+	ClientsCa        bool
+}
+
+func NewRotator(cmd *cobra.Command) (*Rotator, error) {
+	name := cmd.Flag("name").Value.String()
+	if name == "" {
+		slog.Error("--name option is required")
+		return nil, fmt.Errorf("--name option is required")
+	}
+
+	kubeClient, strimziClient, dynamicClient, namespace, err := utils.CreateKubernetesClients(cmd)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes clients", "error", err)
+		return nil, err
+	}
+
+	timeout, err := cmd.Flags().GetUint32("timeout")
+	if err != nil {
+		slog.Error("Failed to get the --timeout flag", "error", err)
+		return nil, err
+	}
+
+	stallTimeout, err := cmd.Flags().GetUint32("stall-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --stall-timeout flag", "error", err)
+		return nil, err
+	}
+
+	afterRestore, err := cmd.Flags().GetBool("after-restore")
+	if err != nil {
+		slog.Error("Failed to get the --after-restore flag", "error", err)
+		return nil, err
+	}
+
+	renewOnly, err := cmd.Flags().GetBool("renew-only")
+	if err != nil {
+		slog.Error("Failed to get the --renew-only flag", "error", err)
+		return nil, err
+	}
+
+	skipClusterCa, err := cmd.Flags().GetBool("skip-cluster-ca")
+	if err != nil {
+		slog.Error("Failed to get the --skip-cluster-ca flag", "error", err)
+		return nil, err
+	}
+
+	skipClientsCa, err := cmd.Flags().GetBool("skip-clients-ca")
+	if err != nil {
+		slog.Error("Failed to get the --skip-clients-ca flag", "error", err)
+		return nil, err
+	}
+
+	if skipClusterCa && skipClientsCa {
+		slog.Error("--skip-cluster-ca and --skip-clients-ca options cannot be used together")
+		return nil, fmt.Errorf("--skip-cluster-ca and --skip-clients-ca options cannot be used together")
+	}
+
+	rotator := Rotator{
+		KubernetesClient: kubeClient,
+		StrimziClient:    strimziClient,
+		DynamicClient:    dynamicClient,
+		Namespace:        namespace,
+		Name:             name,
+		Timeout:          timeout,
+		StallTimeout:     stallTimeout,
+		AfterRestore:     afterRestore,
+		RenewOnly:        renewOnly,
+		ClusterCa:        !skipClusterCa,
+		ClientsCa:        !skipClientsCa,
+	}
+
+	return &rotator, nil
+}
+
+// RotateCa triggers the renewal or the replacement of the Strimzi-managed CAs using the Strimzi annotations and waits
+// until the Cluster Operator processes them and the Kafka cluster is ready again
+func (r *Rotator) RotateCa() error {
+	kafka, err := r.DynamicClient.Resource(kafkaResource).Namespace(r.Namespace).Get(context.Background(), r.Name, metav1.GetOptions{})
+	if err != nil {
+		slog.Error("Failed to get the Kafka cluster", "name", r.Name, "namespace", r.Namespace, "error", err)
+		return err
+	}
+
+	if r.AfterRestore {
+		if kafka.GetAnnotations()[restorer.RestoredFromAnnotation] == "" {
+			slog.Error("The Kafka cluster was not restored by strimzi-backup", "name", r.Name, "namespace", r.Namespace)
+			return fmt.Errorf("the Kafka cluster %s in namespace %s was not restored by strimzi-backup (the %s annotation is missing)", r.Name, r.Namespace, restorer.RestoredFromAnnotation)
+		}
+
+		// The restore has to be complete before the CAs are rotated
+		slog.Info("Waiting for the restored Kafka cluster to be ready", "name", r.Name, "namespace", r.Namespace)
+		if _, err := utils.WaitUntilReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.Timeout, r.StallTimeout); err != nil {
+			slog.Error("The restored Kafka cluster is not ready", "error", err)
+			return err
+		}
+	}
+
+	annotation := ForceReplaceAnnotation
+	if r.RenewOnly {
+		annotation = ForceRenewAnnotation
+	}
+
+	var secrets []string
+	if r.ClusterCa {
+		if secret, ok := r.caSecret(kafka, "clusterCa", "cluster-ca"); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+	if r.ClientsCa {
+		if secret, ok := r.caSecret(kafka, "clientsCa", "clients-ca"); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	if len(secrets) == 0 {
+		slog.Error("No Strimzi-managed CA to rotate", "name", r.Name, "namespace", r.Namespace)
+		return fmt.Errorf("no Strimzi-managed CA to rotate in the Kafka cluster %s in namespace %s", r.Name, r.Namespace)
+	}
+
+	for _, secret := range secrets {
+		if err := r.annotateSecret(secret, annotation); err != nil {
+			return err
+		}
+	}
+
+	for _, secret := range secrets {
+		if err := r.waitForAnnotationRemoval(secret, annotation); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Waiting for the Kafka cluster to be ready after the CA rotation", "name", r.Name, "namespace", r.Namespace)
+	if _, err := utils.WaitUntilReady(r.KubernetesClient, r.StrimziClient, r.Name, r.Namespace, r.Timeout, r.StallTimeout); err != nil {
+		slog.Error("The Kafka cluster is not ready after the CA rotation", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// caSecret returns the name of the Secret which should be annotated to rotate the CA. CAs provided by the user cannot
+// be rotated by the Cluster Operator and are skipped.
+func (r *Rotator) caSecret(kafka *unstructured.Unstructured, field string, suffix string) (string, bool) {
+	generate, found, _ := unstructured.NestedBool(kafka.Object, "spec", field, "generateCertificateAuthority")
+	if found && !generate {
+		slog.Warn("Skipping CA which is provided by the user and cannot be rotated by the Cluster Operator", "ca", field)
+		return "", false
+	}
+
+	if r.RenewOnly {
+		// The certificate is renewed by annotating the Secret with the CA certificate
+		return r.Name + "-" + suffix + "-cert", true
+	}
+
+	// The key is replaced by annotating the Secret with the CA private key
+	return r.Name + "-" + suffix, true
+}
+
+func (r *Rotator) annotateSecret(name string, annotation string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				annotation: "true",
+			},
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to marshal the annotation of the CA Secret", "error", err)
+		return err
+	}
+
+	if _, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: utils.FieldManager}); err != nil {
+		slog.Error("Failed to annotate the CA Secret", "name", name, "namespace", r.Namespace, "annotation", annotation, "error", err)
+		return err
+	}
+
+	slog.Info("Annotated the CA Secret", "name", name, "namespace", r.Namespace, "annotation", annotation)
+
+	return nil
+}
+
+// waitForAnnotationRemoval waits until the Cluster Operator processes the annotation and removes it from the Secret
+func (r *Rotator) waitForAnnotationRemoval(name string, annotation string) error {
+	slog.Info("Waiting for the Cluster Operator to process the CA Secret annotation", "name", name, "annotation", annotation)
+
+	err := wait.PollUntilContextTimeout(context.Background(), 5*time.Second, time.Millisecond*time.Duration(r.Timeout), true, func(ctx context.Context) (bool, error) {
+		secret, err := r.KubernetesClient.CoreV1().Secrets(r.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		_, annotated := secret.Annotations[annotation]
+		return !annotated, nil
+	})
+	if err != nil {
+		slog.Error("The Cluster Operator did not process the CA Secret annotation", "name", name, "annotation", annotation, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package splitter
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/backuper"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SplitByKind   = "kind"
+	SplitByStream = "stream"
+)
+
+// kindGroups maps the streams to the archives they belong to when splitting by kind
+var kindGroups = map[string]string{
+	backuper.KafkaFilename:                      "kafka",
+	backuper.KafkaNodePoolsFilename:             "kafka",
+	backuper.KafkaConfigMapsFilename:            "kafka",
+	backuper.KafkaTopicsFilename:                "topics",
+	backuper.KafkaUsersFilename:                 "users",
+	backuper.KafkaRebalancesFilename:            "kafka",
+	backuper.CaSecretsFilename:                  "secrets",
+	backuper.KafkaUserSecretsFilename:           "secrets",
+	backuper.EntityOperatorSecretsFilename:      "secrets",
+	backuper.ListenerCertificatesFilename:       "secrets",
+	backuper.KafkaListenerCertificatesFilename:  "secrets",
+	backuper.KafkaAuthenticationSecretsFilename: "secrets",
+	backuper.KafkaReferencedSecretsFilename:     "secrets",
+	backuper.StrimziPodSetsFilename:             "informational",
+	backuper.NodeAssignmentsFilename:            "informational",
+	backuper.ConnectTopicsFilename:              "data",
+
+	backuper.ApicurioRegistriesFilename:         "apicurio-registry",
+	backuper.ApicurioRegistryConfigMapsFilename: "apicurio-registry",
+
+	backuper.KafkaConnectFilename:           "connect",
+	backuper.KafkaConnectorsFilename:        "connect",
+	backuper.KafkaConnectSecretsFilename:    "secrets",
+	backuper.KafkaConnectConfigMapsFilename: "connect",
+
+	backuper.KafkaMirrorMaker2Filename:           "mirrormaker2",
+	backuper.KafkaMirrorMaker2SecretsFilename:    "secrets",
+	backuper.KafkaMirrorMaker2ConfigMapsFilename: "mirrormaker2",
+
+	backuper.KafkaMirrorMakerFilename:           "mirrormaker",
+	backuper.KafkaMirrorMakerSecretsFilename:    "secrets",
+	backuper.KafkaMirrorMakerConfigMapsFilename: "mirrormaker",
+
+	backuper.KafkaBridgeFilename:           "bridge",
+	backuper.KafkaBridgeSecretsFilename:    "secrets",
+	backuper.KafkaBridgeConfigMapsFilename: "bridge",
+
+	backuper.OperatorDeploymentFilename:          "operator",
+	backuper.OperatorServiceAccountFilename:      "operator",
+	backuper.OperatorClusterRolesFilename:        "operator",
+	backuper.OperatorClusterRoleBindingsFilename: "operator",
+	backuper.OperatorRolesFilename:               "operator",
+	backuper.OperatorRoleBindingsFilename:        "operator",
+	backuper.OperatorConfigMapsFilename:          "operator",
+	backuper.OperatorSecretsFilename:             "secrets",
+	backuper.OperatorLeasesFilename:              "informational",
+
+	backuper.StrimziCRDsFilename: "crds",
+}
+
+type Splitter struct {
+	BackupFileName  string
+	TargetDirectory string
+	SplitBy         string
+}
+
+func NewSplitter(cmd *cobra.Command) (*Splitter, error) {
+	backupFileName := cmd.Flag("filename").Value.String()
+	targetDirectory := cmd.Flag("target-directory").Value.String()
+
+	splitBy := cmd.Flag("by").Value.String()
+	if splitBy != SplitByKind && splitBy != SplitByStream {
+		slog.Error("Unsupported value of the --by option", "by", splitBy)
+		return nil, fmt.Errorf("unsupported value %s of the --by option (supported values are %s and %s)", splitBy, SplitByKind, SplitByStream)
+	}
+
+	if err := os.MkdirAll(targetDirectory, 0755); err != nil {
+		slog.Error("Failed to create target directory", "error", err, "directory", targetDirectory)
+		return nil, err
+	}
+
+	splitter := Splitter{
+		BackupFileName:  backupFileName,
+		TargetDirectory: targetDirectory,
+		SplitBy:         splitBy,
+	}
+
+	return &splitter, nil
+}
+
+// Split writes the streams from the backup into separate backup files, so that they can be stored and access
+// controlled independently
+func (s *Splitter) Split() error {
+	streams, err := archive.ReadStreams(s.BackupFileName)
+	if err != nil {
+		slog.Error("Failed to read the backup", "error", err, "file", s.BackupFileName)
+		return err
+	}
+
+	var groups []string
+	grouped := map[string][]archive.Stream{}
+
+	for _, stream := range streams {
+		if stream.Name == archive.ManifestFilename {
+			// The manifest describes the whole backup and does not apply to the split backups
+			continue
+		}
+
+		group := s.group(stream.Name)
+
+		if _, ok := grouped[group]; !ok {
+			groups = append(groups, group)
+		}
+
+		grouped[group] = append(grouped[group], stream)
+	}
+
+	for _, group := range groups {
+		fileName := filepath.Join(s.TargetDirectory, group+".gz")
+
+		slog.Info("Writing split backup", "file", fileName, "streams", len(grouped[group]))
+
+		if err := archive.WriteStreams(fileName, grouped[group]); err != nil {
+			slog.Error("Failed to write the split backup", "error", err, "file", fileName)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Splitter) group(name string) string {
+	if s.SplitBy == SplitByStream {
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	if group, ok := kindGroups[name]; ok {
+		return group
+	}
+
+	if _, ok := backuper.TopicFromDataStreamName(name); ok {
+		return "data"
+	}
+
+	if _, ok := backuper.RegistryFromArtifactsStreamName(name); ok {
+		return "apicurio-registry"
+	}
+
+	return "other"
+}
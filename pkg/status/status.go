@@ -0,0 +1,203 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"github.com/scholzj/strimzi-backup/pkg/storage"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Checker struct {
+	Storage     storage.Storage
+	Location    string
+	Namespace   string
+	Name        string
+	MaxAge      time.Duration
+	Pushgateway string
+}
+
+// Backup describes the newest backup found in the storage
+type Backup struct {
+	FileName  string
+	CreatedAt time.Time
+}
+
+func NewChecker(cmd *cobra.Command) (*Checker, error) {
+	location := cmd.Flag("storage").Value.String()
+	if location == "" {
+		slog.Error("--storage option is required")
+		return nil, fmt.Errorf("--storage option is required")
+	}
+
+	backupStorage, err := storage.NewFromFlags(cmd, cmd.Flag("namespace").Value.String(), cmd.Flag("name").Value.String())
+	if err != nil {
+		slog.Error("Failed to configure the storage", "storage", location, "error", err)
+		return nil, err
+	}
+
+	maxAge, err := cmd.Flags().GetDuration("max-age")
+	if err != nil {
+		slog.Error("Failed to get the --max-age flag", "error", err)
+		return nil, err
+	}
+
+	checker := Checker{
+		Storage:     backupStorage,
+		Location:    location,
+		Namespace:   cmd.Flag("namespace").Value.String(),
+		Name:        cmd.Flag("name").Value.String(),
+		MaxAge:      maxAge,
+		Pushgateway: cmd.Flag("pushgateway").Value.String(),
+	}
+
+	return &checker, nil
+}
+
+// Check finds the newest backup of the Kafka cluster and verifies that it is not older than the maximal age
+func (c *Checker) Check() (*Backup, error) {
+	newest, err := c.findNewestBackup()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Pushgateway != "" {
+		if err := c.pushMetrics(newest); err != nil {
+			slog.Error("Failed to push the metrics to the Prometheus Pushgateway", "pushgateway", c.Pushgateway, "error", err)
+			return newest, err
+		}
+	}
+
+	if newest == nil {
+		slog.Error("No backup found", "storage", c.Location, "name", c.Name, "namespace", c.Namespace)
+		return nil, fmt.Errorf("no backup found in %s", c.Location)
+	}
+
+	age := time.Since(newest.CreatedAt)
+	if age > c.MaxAge {
+		slog.Error("The newest backup is too old", "filename", newest.FileName, "createdAt", newest.CreatedAt, "age", age.Round(time.Second), "maxAge", c.MaxAge)
+		return newest, fmt.Errorf("the newest backup %s is %s old which is more than %s", newest.FileName, age.Round(time.Second), c.MaxAge)
+	}
+
+	return newest, nil
+}
+
+// findNewestBackup finds the newest backup in the storage. The time and the Kafka cluster are taken from the manifest.
+// Backups without a manifest use the modification time of the stored object and cannot be matched to a Kafka cluster.
+// The backups are read from the newest one, so that remote storages do not need to download all of them.
+func (c *Checker) findNewestBackup() (*Backup, error) {
+	objects, err := c.Storage.List(context.Background())
+	if err != nil {
+		slog.Error("Failed to list the backups", "storage", c.Location, "error", err)
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ModTime.After(objects[j].ModTime)
+	})
+
+	for _, object := range objects {
+		streams, err := c.readStreams(object.Name)
+		if err != nil {
+			slog.Warn("Skipping file which is not a valid backup", "filename", object.Name, "error", err)
+			continue
+		}
+
+		manifest, err := archive.FindManifest(streams)
+		if err != nil {
+			slog.Warn("Skipping backup with invalid manifest", "filename", object.Name, "error", err)
+			continue
+		}
+
+		if manifest != nil {
+			if (c.Name != "" && manifest.Name != c.Name) || (c.Namespace != "" && manifest.Namespace != c.Namespace) {
+				continue
+			}
+
+			return &Backup{FileName: object.Name, CreatedAt: manifest.CreatedAt}, nil
+		} else {
+			if c.Name != "" || c.Namespace != "" {
+				slog.Debug("Skipping backup without a manifest", "filename", object.Name)
+				continue
+			}
+
+			return &Backup{FileName: object.Name, CreatedAt: object.ModTime}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readStreams reads the streams of the stored backup
+func (c *Checker) readStreams(name string) ([]archive.Stream, error) {
+	reader, err := c.Storage.Open(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	streams, err := archive.ReadStreamsFrom(reader, name)
+	if closeErr := reader.Close(); err == nil && closeErr != nil {
+		return nil, closeErr
+	}
+
+	return streams, err
+}
+
+// pushMetrics pushes the time of the newest backup to the Prometheus Pushgateway
+func (c *Checker) pushMetrics(newest *Backup) error {
+	var timestamp int64
+	if newest != nil {
+		timestamp = newest.CreatedAt.Unix()
+	}
+
+	body := fmt.Sprintf("# HELP strimzi_backup_last_backup_timestamp_seconds Time of the newest backup\n# TYPE strimzi_backup_last_backup_timestamp_seconds gauge\nstrimzi_backup_last_backup_timestamp_seconds %d\n", timestamp)
+
+	target := strings.TrimSuffix(c.Pushgateway, "/") + "/metrics/job/strimzi-backup-status"
+	if c.Namespace != "" {
+		target += "/namespace/" + url.PathEscape(c.Namespace)
+	}
+	if c.Name != "" {
+		target += "/name/" + url.PathEscape(c.Name)
+	}
+
+	request, err := http.NewRequest(http.MethodPut, target, bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	return nil
+}
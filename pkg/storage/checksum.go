@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumSuffix is the suffix of the checksum file stored next to the backup. The checksum file uses the same format
+// as the sha256sum utility, so it can be verified also without strimzi-backup.
+const ChecksumSuffix = ".sha256"
+
+// UploadWithChecksum uploads the backup file together with its SHA-256 checksum
+func UploadWithChecksum(ctx context.Context, s Storage, name string, file *os.File) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to calculate the checksum of the backup %s: %v", name, err)
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := s.Upload(ctx, name, file); err != nil {
+		return err
+	}
+
+	return uploadChecksum(ctx, s, name, digest)
+}
+
+// uploadChecksum uploads the checksum file of the backup
+func uploadChecksum(ctx context.Context, s Storage, name string, digest string) error {
+	return s.Upload(ctx, name+ChecksumSuffix, strings.NewReader(digest+"  "+filepath.Base(name)+"\n"))
+}
+
+// OpenVerified downloads the backup into a temporary file and verifies it against its checksum before returning it.
+// This makes sure that a backup corrupted in the storage or in transit is not restored. Backups without a checksum are
+// not verified. Any other failure to read the checksum is returned as an error, so that the verification is not skipped
+// because of a transient storage error. The temporary file is removed when the returned reader is closed.
+func OpenVerified(ctx context.Context, s Storage, name string) (io.ReadCloser, error) {
+	expected, err := readChecksum(ctx, s, name)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("No checksum found for the backup, it will not be verified", "file", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the checksum of the backup %s: %w", name, err)
+	}
+
+	downloaded, err := CreateTempBackupFile()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := s.Open(ctx, name)
+	if err != nil {
+		_ = downloaded.Close()
+		return nil, err
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(downloaded, hash), reader)
+	if closeErr := reader.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = downloaded.Close()
+		return nil, fmt.Errorf("failed to download the backup %s: %v", name, err)
+	}
+
+	if expected != "" {
+		actual := hex.EncodeToString(hash.Sum(nil))
+		if actual != expected {
+			_ = downloaded.Close()
+			return nil, fmt.Errorf("checksum of the backup %s does not match (expected %s, got %s): the backup was corrupted in the storage or while downloading it", name, expected, actual)
+		}
+
+		slog.Info("Checksum of the backup verified", "file", name, "sha256", actual)
+	}
+
+	if _, err := downloaded.Seek(0, io.SeekStart); err != nil {
+		_ = downloaded.Close()
+		return nil, err
+	}
+
+	return downloaded, nil
+}
+
+// readChecksum reads the checksum of the backup from the checksum file
+func readChecksum(ctx context.Context, s Storage, name string) (string, error) {
+	reader, err := s.Open(ctx, name+ChecksumSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(reader)
+	if closeErr := reader.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file %s is empty", name+ChecksumSuffix)
+	}
+
+	return strings.ToLower(fields[0]), nil
+}
+
+// TempBackupFile is a temporary copy of the backup which is removed when closed
+type TempBackupFile struct {
+	*os.File
+}
+
+// CreateTempBackupFile creates a temporary file for a copy of the backup
+func CreateTempBackupFile() (*TempBackupFile, error) {
+	tempFile, err := os.CreateTemp("", "strimzi-backup-*.gz")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TempBackupFile{File: tempFile}, nil
+}
+
+func (f *TempBackupFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.File.Name())
+	return err
+}
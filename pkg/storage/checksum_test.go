@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenVerifiedWithoutChecksum(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "backup.gz"), testData, 0o600); err != nil {
+		t.Fatalf("failed to write the backup: %v", err)
+	}
+
+	reader, err := OpenVerified(context.Background(), &LocalStorage{Directory: dir}, "backup.gz")
+	if err != nil {
+		t.Fatalf("backup without checksum should be opened: %v", err)
+	}
+	_ = reader.Close()
+}
+
+func TestOpenVerifiedChecksumNotReadable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "backup.gz"), testData, 0o600); err != nil {
+		t.Fatalf("failed to write the backup: %v", err)
+	}
+
+	// A directory in place of the checksum file cannot be read, but it exists
+	if err := os.Mkdir(filepath.Join(dir, "backup.gz"+ChecksumSuffix), 0o700); err != nil {
+		t.Fatalf("failed to create the directory: %v", err)
+	}
+
+	if _, err := OpenVerified(context.Background(), &LocalStorage{Directory: dir}, "backup.gz"); err == nil {
+		t.Fatalf("unreadable checksum should fail the verification")
+	}
+}
+
+func TestOpenVerifiedHTTPChecksumFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/backup.gz":
+			_, _ = w.Write(testData)
+		case "/backup.gz" + ChecksumSuffix:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewHTTPStorage(server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create the storage: %v", err)
+	}
+
+	if _, err := OpenVerified(context.Background(), s, "backup.gz"); err == nil {
+		t.Fatalf("failure to read the checksum should fail the verification")
+	}
+
+	if reader, err := OpenVerified(context.Background(), s, "other.gz"); err == nil {
+		_ = reader.Close()
+		t.Fatalf("missing backup should fail")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import "syscall"
+
+// freeSpace returns the number of bytes available to unprivileged users in the filesystem of the directory
+func freeSpace(directory string) (uint64, bool, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(directory, &stat); err != nil {
+		return 0, false, err
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
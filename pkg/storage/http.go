@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// httpConnectTimeout is the timeout for connecting to the HTTP storage including the TLS handshake
+	httpConnectTimeout = 30 * time.Second
+
+	// httpResponseTimeout is the timeout for the HTTP storage to respond once the whole request is sent
+	httpResponseTimeout = 2 * time.Minute
+)
+
+// HTTPStorage uploads the backups using HTTP PUT requests and downloads them using HTTP GET requests. This works with
+// WebDAV servers as well as with Nexus or Artifactory style repositories. Listing the backups uses the WebDAV PROPFIND
+// method and is supported only by WebDAV servers.
+type HTTPStorage struct {
+	URL     string
+	Headers http.Header
+	client  *http.Client
+}
+
+// NewHTTPStorage creates the HTTP storage. The headers are in the <name>: <value> format. Environment variables in the
+// header values are expanded, so that credentials do not need to be passed on the command line.
+func NewHTTPStorage(location string, headers []string) (*HTTPStorage, error) {
+	parsedHeaders := http.Header{}
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid storage header %q (expected format is <name>: <value>)", header)
+		}
+
+		parsedHeaders.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+
+	return &HTTPStorage{URL: strings.TrimSuffix(location, "/"), Headers: parsedHeaders, client: newHTTPClient()}, nil
+}
+
+// newHTTPClient creates the HTTP client used for the storage requests. The client does not use an overall timeout,
+// because it would apply also to uploading and downloading the backups which might take long for big backups. Instead,
+// connecting to the storage and waiting for its response are bound, so that a stuck storage does not block forever.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: httpConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = httpConnectTimeout
+	transport.ResponseHeaderTimeout = httpResponseTimeout
+
+	return &http.Client{Transport: transport}
+}
+
+func (s *HTTPStorage) Upload(ctx context.Context, name string, data io.Reader) error {
+	response, err := s.do(ctx, http.MethodPut, s.URL+"/"+url.PathEscape(name), data, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkStatus(response)
+}
+
+func (s *HTTPStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	response, err := s.do(ctx, http.MethodGet, s.URL+"/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkStatus(response); err != nil {
+		_ = response.Body.Close()
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+func (s *HTTPStorage) List(ctx context.Context) ([]Object, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><getlastmodified/><getcontentlength/><resourcetype/></prop></propfind>`
+
+	response, err := s.do(ctx, "PROPFIND", s.URL+"/", strings.NewReader(body), map[string]string{"Depth": "1", "Content-Type": "application/xml"})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusMethodNotAllowed || response.StatusCode == http.StatusNotImplemented {
+		return nil, fmt.Errorf("listing the backups requires a WebDAV server: %v", checkStatus(response))
+	} else if err := checkStatus(response); err != nil {
+		return nil, err
+	}
+
+	multistatus := webdavMultistatus{}
+	if err := xml.NewDecoder(response.Body).Decode(&multistatus); err != nil {
+		return nil, fmt.Errorf("failed to parse the WebDAV response: %v", err)
+	}
+
+	var objects []Object
+	for _, entry := range multistatus.Responses {
+		if entry.Prop.ResourceType.Collection != nil {
+			continue
+		}
+
+		href, err := url.PathUnescape(entry.Href)
+		if err != nil {
+			href = entry.Href
+		}
+
+		name := path.Base(href)
+		if !strings.HasSuffix(name, ".gz") {
+			continue
+		}
+
+		modTime, err := http.ParseTime(entry.Prop.LastModified)
+		if err != nil {
+			slog.Warn("Failed to parse the modification time of the backup", "filename", name, "error", err)
+		}
+
+		objects = append(objects, Object{Name: name, ModTime: modTime, Size: entry.Prop.ContentLength})
+	}
+
+	return objects, nil
+}
+
+func (s *HTTPStorage) do(ctx context.Context, method string, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range s.Headers {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	slog.Debug("Sending storage request", "method", method, "url", request.URL.Redacted())
+
+	return s.client.Do(request)
+}
+
+func checkStatus(response *http.Response) error {
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("unexpected response status %s from %s %s: %w", response.Status, response.Request.Method, response.Request.URL.Redacted(), os.ErrNotExist)
+	} else if response.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status %s from %s %s", response.Status, response.Request.Method, response.Request.URL.Redacted())
+	}
+
+	return nil
+}
+
+// webdavMultistatus is the subset of the WebDAV PROPFIND response used to list the backups
+type webdavMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			LastModified  string `xml:"getlastmodified"`
+			ContentLength int64  `xml:"getcontentlength"`
+			ResourceType  struct {
+				Collection *struct{} `xml:"collection"`
+			} `xml:"resourcetype"`
+		} `xml:"propstat>prop"`
+	} `xml:"response"`
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores the backups in a local directory. This is also used for PersistentVolumeClaims or NFS shares
+// mounted into the Pod when running inside a Kubernetes cluster.
+type LocalStorage struct {
+	Directory string
+}
+
+func (s *LocalStorage) Upload(_ context.Context, name string, data io.Reader) error {
+	if err := os.MkdirAll(s.Directory, 0755); err != nil {
+		return err
+	}
+
+	if err := s.checkFreeSpace(data); err != nil {
+		return err
+	}
+
+	fileName := filepath.Join(s.Directory, filepath.Base(name))
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	// The backup is synced to the disk, so that it is not lost when the node or the NFS server fails right after the
+	// backup finished
+	_, err = io.Copy(file, data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Incomplete backups should not stay in the storage
+		_ = os.Remove(fileName)
+		return err
+	}
+
+	return syncDirectory(s.Directory)
+}
+
+// checkFreeSpace checks that there is enough free space for the backup before writing it. The check is done only when
+// the size of the backup is known.
+func (s *LocalStorage) checkFreeSpace(data io.Reader) error {
+	file, ok := data.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil
+	}
+
+	available, supported, err := freeSpace(s.Directory)
+	if err != nil {
+		slog.Warn("Failed to check the free space in the storage", "directory", s.Directory, "error", err)
+		return nil
+	} else if !supported {
+		return nil
+	}
+
+	if uint64(info.Size()) > available {
+		return fmt.Errorf("not enough free space in %s: %d bytes are needed but only %d bytes are available", s.Directory, info.Size(), available)
+	}
+
+	return nil
+}
+
+// syncDirectory syncs the directory, so that the newly created backup file is persisted in it
+func syncDirectory(directory string) error {
+	dir, err := os.Open(directory)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		// Some filesystems and platforms do not support syncing directories
+		slog.Debug("Failed to sync the storage directory", "directory", directory, "error", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Directory, filepath.Base(name)))
+}
+
+func (s *LocalStorage) List(_ context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(s.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []Object
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".gz") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, Object{Name: entry.Name(), ModTime: info.ModTime(), Size: info.Size()})
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PluginPrefix is the prefix of the names of the storage plugin binaries. The plugin for the scheme foo:// is called
+// strimzi-backup-storage-foo and has to be available on the PATH.
+const PluginPrefix = "strimzi-backup-storage-"
+
+// PluginLocationEnv is the environment variable with the storage location passed to the storage plugins. The location
+// might contain credentials, so it is not passed on the command line where it would be visible to other users.
+const PluginLocationEnv = "STRIMZI_BACKUP_STORAGE_LOCATION"
+
+// PluginNotFoundExitCode is the exit code used by the storage plugins to indicate that the backup does not exist
+const PluginNotFoundExitCode = 3
+
+// PluginStorage delegates to an external plugin binary, similarly to the Git or Docker credential helpers. This allows
+// adding proprietary storage targets without changing strimzi-backup. The plugin is called with the operation as
+// arguments and with the storage location in the PluginLocationEnv environment variable:
+//
+//   - put <name> stores the backup read from the standard input
+//   - get <name> writes the stored backup to the standard output
+//   - list writes the stored backups as JSON objects with name, modTime, and size fields to the standard output (one
+//     object per line)
+//
+// The plugin indicates failures with a non-zero exit code and a message on the standard error output. The exit code
+// PluginNotFoundExitCode indicates that the requested backup does not exist.
+type PluginStorage struct {
+	Plugin   string
+	Location string
+}
+
+func NewPluginStorage(scheme string, location string) (*PluginStorage, error) {
+	plugin, err := exec.LookPath(PluginPrefix + scheme)
+	if err != nil {
+		return nil, fmt.Errorf("storage type %s is not supported: storage plugin %s was not found on the PATH", scheme, PluginPrefix+scheme)
+	}
+
+	return &PluginStorage{Plugin: plugin, Location: location}, nil
+}
+
+func (s *PluginStorage) Upload(ctx context.Context, name string, data io.Reader) error {
+	cmd := s.command(ctx, "put", name)
+	cmd.Stdin = data
+
+	return s.run(cmd)
+}
+
+func (s *PluginStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	cmd := s.command(ctx, "get", name)
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &pluginReader{ReadCloser: stdout, cmd: cmd, stderr: stderr}, nil
+}
+
+func (s *PluginStorage) List(ctx context.Context) ([]Object, error) {
+	cmd := s.command(ctx, "list")
+
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+
+	if err := s.run(cmd); err != nil {
+		return nil, err
+	}
+
+	var objects []Object
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		object := Object{}
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			return nil, fmt.Errorf("failed to parse the output of the storage plugin %s: %v", s.Plugin, err)
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, scanner.Err()
+}
+
+// command creates the command running the plugin with the storage location in the environment
+func (s *PluginStorage) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, s.Plugin, args...)
+	cmd.Env = append(os.Environ(), PluginLocationEnv+"="+s.Location)
+
+	slog.Debug("Running storage plugin", "plugin", s.Plugin, "args", args, "location", redactedLocation(s.Location))
+
+	return cmd
+}
+
+func (s *PluginStorage) run(cmd *exec.Cmd) error {
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("storage plugin %s failed: %v: %s", s.Plugin, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// redactedLocation returns the storage location without the user information and the query parameters which might
+// contain credentials
+func redactedLocation(location string) string {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return "REDACTED"
+	}
+
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	return parsed.String()
+}
+
+// pluginReader reads the backup from the standard output of the plugin and waits for the plugin to exit when closed
+type pluginReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (r *pluginReader) Close() error {
+	_ = r.ReadCloser.Close()
+
+	if err := r.cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == PluginNotFoundExitCode {
+			return fmt.Errorf("storage plugin %s failed: %w: %s", r.cmd.Path, os.ErrNotExist, strings.TrimSpace(r.stderr.String()))
+		}
+
+		return fmt.Errorf("storage plugin %s failed: %v: %s", r.cmd.Path, err, strings.TrimSpace(r.stderr.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Storage is the place where the backups are stored
+type Storage interface {
+	// Upload stores the backup under the given name
+	Upload(ctx context.Context, name string, data io.Reader) error
+	// Open opens the stored backup for reading. When the backup does not exist, the error wraps os.ErrNotExist.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// List lists the stored backups
+	List(ctx context.Context) ([]Object, error)
+}
+
+// Object describes a single stored backup
+type Object struct {
+	Name    string    `json:"name"`
+	ModTime time.Time `json:"modTime"`
+	Size    int64     `json:"size"`
+}
+
+// NewFromFlags creates the storage from the --storage and --storage-header options. The {namespace} and {name}
+// placeholders in the storage location are replaced with the namespace and the name of the Kafka cluster, so that each
+// cluster can use its own subdirectory. Returns nil when no storage is configured.
+func NewFromFlags(cmd *cobra.Command, namespace string, name string) (Storage, error) {
+	location := cmd.Flag("storage").Value.String()
+	if location == "" {
+		return nil, nil
+	}
+
+	location, err := ExpandLocation(location, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := cmd.Flags().GetStringArray("storage-header")
+	if err != nil {
+		slog.Error("Failed to get the --storage-header flag", "error", err)
+		return nil, err
+	}
+
+	return New(location, headers)
+}
+
+// ExpandLocation replaces the {namespace} and {name} placeholders in the storage location
+func ExpandLocation(location string, namespace string, name string) (string, error) {
+	if strings.Contains(location, "{namespace}") && namespace == "" {
+		return "", fmt.Errorf("storage location %s uses the {namespace} placeholder but the namespace is not known", location)
+	}
+
+	if strings.Contains(location, "{name}") && name == "" {
+		return "", fmt.Errorf("storage location %s uses the {name} placeholder but the name of the Kafka cluster is not known", location)
+	}
+
+	return strings.NewReplacer("{namespace}", namespace, "{name}", name).Replace(location), nil
+}
+
+// New creates the storage for the location. Locations without a scheme and file:// URLs are local directories. HTTP(S)
+// URLs are uploaded using HTTP PUT requests with the given headers. Other schemes are handled by external storage
+// plugins.
+func New(location string, headers []string) (Storage, error) {
+	if !strings.Contains(location, "://") {
+		return &LocalStorage{Directory: location}, nil
+	}
+
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the storage location %s: %v", location, err)
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		return &LocalStorage{Directory: parsed.Path}, nil
+	case "http", "https":
+		return NewHTTPStorage(location, headers)
+	default:
+		return NewPluginStorage(parsed.Scheme, location)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// StreamingUpload uploads the backup into the storage while it is still being written. The written data are passed to
+// the storage through a pipe, so the writes block until the storage consumes them and a slow storage slows down the
+// backup instead of the data piling up in memory. The SHA-256 checksum is calculated from the written data and
+// uploaded once the backup is complete.
+type StreamingUpload struct {
+	ctx     context.Context
+	storage Storage
+	name    string
+	pipe    *io.PipeWriter
+	hash    hash.Hash
+	done    chan error
+}
+
+// StartUpload starts uploading the backup with the given name into the storage in the background
+func StartUpload(ctx context.Context, s Storage, name string) *StreamingUpload {
+	reader, writer := io.Pipe()
+
+	upload := StreamingUpload{
+		ctx:     ctx,
+		storage: s,
+		name:    name,
+		pipe:    writer,
+		hash:    sha256.New(),
+		done:    make(chan error, 1),
+	}
+
+	go func() {
+		err := s.Upload(ctx, name, reader)
+		if err == nil {
+			// The storage should read everything until the end, but the writes must not block forever if it does not
+			err = io.ErrClosedPipe
+		}
+
+		// Unblocks the writer and passes the upload error to it
+		_ = reader.CloseWithError(err)
+
+		if err == io.ErrClosedPipe {
+			err = nil
+		}
+		upload.done <- err
+	}()
+
+	return &upload
+}
+
+func (u *StreamingUpload) Write(p []byte) (int, error) {
+	n, err := u.pipe.Write(p)
+	u.hash.Write(p[:n])
+
+	return n, err
+}
+
+// Finish waits until all written data are uploaded and uploads the checksum of the backup
+func (u *StreamingUpload) Finish() error {
+	_ = u.pipe.Close()
+
+	if err := <-u.done; err != nil {
+		return err
+	}
+
+	digest := hex.EncodeToString(u.hash.Sum(nil))
+
+	return uploadChecksum(u.ctx, u.storage, u.name, digest)
+}
+
+// Abort stops the upload with the given error, so that the incomplete backup is not stored in the storage. No checksum
+// is uploaded for the aborted backup.
+func (u *StreamingUpload) Abort(err error) {
+	_ = u.pipe.CloseWithError(err)
+	<-u.done
+}
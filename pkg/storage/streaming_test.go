@@ -0,0 +1,160 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/scholzj/strimzi-backup/pkg/archive"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testData = []byte(strings.Repeat("apiVersion: v1\nkind: Secret\n", 1000))
+
+// testStages returns the stages used by the backups to encrypt the streams
+func testStages(passphrase string) []archive.Stage {
+	return []archive.Stage{
+		&archive.CompressionStage{Patterns: []string{"*"}},
+		&archive.EncryptionStage{Passphrase: []byte(passphrase), Patterns: []string{"*"}},
+	}
+}
+
+// uploadArchive writes the archive with the stream directly into the storage
+func uploadArchive(t *testing.T, s Storage, name string) {
+	t.Helper()
+
+	upload := StartUpload(context.Background(), s, name)
+	writer := archive.NewWriter(upload, testStages("secret")...)
+
+	if _, err := writer.Write(&archive.Stream{Name: "ca-secrets.yaml", ModTime: time.Now(), Data: testData}); err != nil {
+		t.Fatalf("failed to write the stream: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close the archive: %v", err)
+	}
+
+	if err := upload.Finish(); err != nil {
+		t.Fatalf("failed to finish the upload: %v", err)
+	}
+}
+
+// readUploaded downloads and verifies the archive and reads its only stream
+func readUploaded(s Storage, name string, passphrase string) ([]byte, error) {
+	file, err := OpenVerified(context.Background(), s, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := archive.NewReader(file, nil, testStages(passphrase)...)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	stream, err := reader.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.Data, nil
+}
+
+func TestStreamingUploadRoundTrip(t *testing.T) {
+	s := &LocalStorage{Directory: t.TempDir()}
+	uploadArchive(t, s, "backup.gz")
+
+	data, err := readUploaded(s, "backup.gz", "secret")
+	if err != nil {
+		t.Fatalf("failed to read the uploaded backup: %v", err)
+	} else if !bytes.Equal(data, testData) {
+		t.Fatalf("the stream read from the storage does not match the written stream")
+	}
+}
+
+func TestStreamingUploadWrongKey(t *testing.T) {
+	s := &LocalStorage{Directory: t.TempDir()}
+	uploadArchive(t, s, "backup.gz")
+
+	if _, err := readUploaded(s, "backup.gz", "wrong"); err == nil || !strings.Contains(err.Error(), "wrong encryption key") {
+		t.Fatalf("reading the backup with a wrong key should fail, got %v", err)
+	}
+}
+
+func TestStreamingUploadTruncated(t *testing.T) {
+	s := &LocalStorage{Directory: t.TempDir()}
+	uploadArchive(t, s, "backup.gz")
+
+	fileName := filepath.Join(s.Directory, "backup.gz")
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("failed to read the uploaded backup: %v", err)
+	}
+
+	if err := os.WriteFile(fileName, data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("failed to truncate the uploaded backup: %v", err)
+	}
+
+	if _, err := readUploaded(s, "backup.gz", "secret"); err == nil || !strings.Contains(err.Error(), "checksum of the backup backup.gz does not match") {
+		t.Fatalf("reading a truncated backup should fail, got %v", err)
+	}
+}
+
+func TestStreamingUploadAbort(t *testing.T) {
+	s := &LocalStorage{Directory: t.TempDir()}
+
+	upload := StartUpload(context.Background(), s, "backup.gz")
+	if _, err := upload.Write(testData); err != nil {
+		t.Fatalf("failed to write the data: %v", err)
+	}
+	upload.Abort(errors.New("the backup was discarded"))
+
+	if entries, _ := os.ReadDir(s.Directory); len(entries) != 0 {
+		t.Fatalf("the aborted backup should not be kept in the storage, found %d files", len(entries))
+	}
+}
+
+// failingStorage fails the uploads after reading some data
+type failingStorage struct {
+	LocalStorage
+}
+
+func (s *failingStorage) Upload(_ context.Context, _ string, data io.Reader) error {
+	_, _ = io.ReadFull(data, make([]byte, 10))
+	return errors.New("storage is not available")
+}
+
+func TestStreamingUploadFailure(t *testing.T) {
+	upload := StartUpload(context.Background(), &failingStorage{}, "backup.gz")
+
+	// The error of the storage is returned to the writer instead of blocking it
+	_, err := upload.Write(testData)
+	if err == nil || !strings.Contains(err.Error(), "storage is not available") {
+		t.Fatalf("writing into a failed upload should fail with the storage error, got %v", err)
+	}
+
+	if err := upload.Finish(); err == nil {
+		t.Fatalf("finishing a failed upload should fail")
+	}
+}
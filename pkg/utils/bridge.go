@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	kafkaapi "github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"time"
+)
+
+// WaitUntilBridgeReady waits until the Kafka Bridge is ready
+func WaitUntilBridgeReady(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaBridge, error) {
+	return waitForBridge(kubeClient, client, name, namespace, timeout, "ready", IsBridgeReady)
+}
+
+// WaitUntilBridgeReconciliationPaused waits until the Cluster Operator confirms that the reconciliation of the Kafka
+// Bridge is paused
+func WaitUntilBridgeReconciliationPaused(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaBridge, error) {
+	return waitForBridge(kubeClient, client, name, namespace, timeout, "paused", IsBridgeReconciliationPaused)
+}
+
+func waitForBridge(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32, state string, condition func(*kafkaapi.KafkaBridge) bool) (*kafkaapi.KafkaBridge, error) {
+	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	defer watchContextCancel()
+
+	listOptions := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()}
+	watcher, err := client.KafkaV1beta2().KafkaBridges(namespace).Watch(watchContext, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the Kafka Bridge %s in namespace %s: %v", name, namespace, err)
+	}
+
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka Bridge", name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				if watchContext.Err() != nil {
+					return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka Bridge %s in namespace %s to be %s", name, namespace, state))
+				}
+
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the KafkaBridge resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.KafkaV1beta2().KafkaBridges(namespace).Watch(watchContext, listOptions)
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka Bridge %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			b, ok := event.Object.(*kafkaapi.KafkaBridge)
+			if !ok {
+				continue
+			}
+
+			if condition(b) {
+				return b, nil
+			}
+
+			if b.Status != nil {
+				reporter.reportConditions(b.Status.Conditions)
+			}
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
+		case <-watchContext.Done():
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka Bridge %s in namespace %s to be %s", name, namespace, state))
+		}
+	}
+}
+
+func IsBridgeReady(b *kafkaapi.KafkaBridge) bool {
+	if b.Status == nil {
+		return false
+	}
+
+	for _, condition := range b.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" && b.Status.ObservedGeneration == b.ObjectMeta.Generation {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsBridgeReconciliationPaused(b *kafkaapi.KafkaBridge) bool {
+	if b.Status == nil {
+		return false
+	}
+
+	for _, condition := range b.Status.Conditions {
+		if condition.Type == "ReconciliationPaused" && condition.Status == "True" {
+			return true
+		}
+	}
+
+	return false
+}
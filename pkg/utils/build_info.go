@@ -0,0 +1,69 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// buildDate is the date when the binary was built. It is set by the release builds using
+// -ldflags "-X github.com/scholzj/strimzi-backup/pkg/utils.buildDate=..."
+var buildDate = ""
+
+// BuildInfo describes the build of the Strimzi Backup binary
+type BuildInfo struct {
+	Version    string `json:"version"`
+	GoVersion  string `json:"goVersion"`
+	Commit     string `json:"commit,omitempty"`
+	CommitDate string `json:"commitDate,omitempty"`
+	Modified   bool   `json:"modified,omitempty"`
+	BuildDate  string `json:"buildDate,omitempty"`
+	Platform   string `json:"platform"`
+	CgoEnabled bool   `json:"cgoEnabled"`
+}
+
+// GetBuildInfo returns the information about the build of the Strimzi Backup binary. The commit is taken from the
+// version control information embedded by the Go toolchain.
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version(),
+		GoVersion: runtime.Version(),
+		BuildDate: buildDate,
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.CommitDate = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		case "CGO_ENABLED":
+			info.CgoEnabled = setting.Value == "1"
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"strings"
+)
+
+// AddKafkaFlags adds the flags used to connect to the Kafka cluster using the Kafka protocol
+func AddKafkaFlags(flags *pflag.FlagSet) {
+	flags.String("bootstrap-server", "", "Bootstrap server of the Kafka cluster in the <host>:<port> format. Multiple bootstrap servers can be specified as a comma-separated list.")
+	flags.Bool("tls", false, "Connect to the Kafka cluster using TLS and trust the Cluster CA certificate from the <name>-cluster-ca-cert Secret")
+	flags.String("kafka-user", "", "Name of the KafkaUser whose credentials from its Secret should be used to authenticate to the Kafka cluster. Both TLS client authentication and SCRAM-SHA-512 authentication are supported.")
+}
+
+// CreateKafkaClient creates the Kafka client connected to the Kafka cluster. The Cluster CA certificate and the user
+// credentials are read from the Secrets created by the Strimzi operators.
+func CreateKafkaClient(cmd *cobra.Command, kubeClient *kubernetes.Clientset, namespace string, name string, opts ...kgo.Opt) (*kgo.Client, error) {
+	bootstrapServer := cmd.Flag("bootstrap-server").Value.String()
+	if bootstrapServer == "" {
+		slog.Error("--bootstrap-server option is required")
+		return nil, fmt.Errorf("--bootstrap-server option is required")
+	}
+
+	useTls, err := cmd.Flags().GetBool("tls")
+	if err != nil {
+		slog.Error("Failed to get the --tls flag", "error", err)
+		return nil, err
+	}
+
+	opts = append(opts, kgo.SeedBrokers(strings.Split(bootstrapServer, ",")...), kgo.ClientID("strimzi-backup"))
+
+	var tlsConfig *tls.Config
+	if useTls {
+		caSecretName := name + "-cluster-ca-cert"
+		caSecret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), caSecretName, metav1.GetOptions{})
+		if err != nil {
+			slog.Error("Failed to get the Cluster CA certificate", "secret", caSecretName, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caSecret.Data["ca.crt"]) {
+			slog.Error("The Cluster CA Secret does not contain a valid certificate", "secret", caSecretName, "namespace", namespace)
+			return nil, fmt.Errorf("the secret %s does not contain a valid CA certificate", caSecretName)
+		}
+
+		tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	if kafkaUser := cmd.Flag("kafka-user").Value.String(); kafkaUser != "" {
+		// The Secret has the same name as the KafkaUser unless a secret prefix is configured in the User Operator
+		userSecret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), kafkaUser, metav1.GetOptions{})
+		if err != nil {
+			slog.Error("Failed to get the Secret of the KafkaUser", "secret", kafkaUser, "namespace", namespace, "error", err)
+			return nil, err
+		}
+
+		if userSecret.Data["user.crt"] != nil {
+			if tlsConfig == nil {
+				slog.Error("The --tls option is required to use TLS client authentication", "user", kafkaUser)
+				return nil, fmt.Errorf("the --tls option is required to use TLS client authentication of the user %s", kafkaUser)
+			}
+
+			certificate, err := tls.X509KeyPair(userSecret.Data["user.crt"], userSecret.Data["user.key"])
+			if err != nil {
+				slog.Error("Failed to load the user certificate", "secret", kafkaUser, "namespace", namespace, "error", err)
+				return nil, err
+			}
+
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		} else if userSecret.Data["password"] != nil {
+			opts = append(opts, kgo.SASL(scram.Auth{User: kafkaUser, Pass: string(userSecret.Data["password"])}.AsSha512Mechanism()))
+		} else {
+			slog.Error("The Secret of the KafkaUser does not contain any supported credentials", "secret", kafkaUser, "namespace", namespace)
+			return nil, fmt.Errorf("the secret %s does not contain a user certificate or a password", kafkaUser)
+		}
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		slog.Error("Failed to create Kafka client", "bootstrapServer", bootstrapServer, "error", err)
+		return nil, err
+	}
+
+	return client, nil
+}
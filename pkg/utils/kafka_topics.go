@@ -0,0 +1,58 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+)
+
+// TopicNameAnnotation can be used on the KafkaTopic resources to record the name of the Kafka topic when it differs
+// from the name of the resource
+const TopicNameAnnotation = "strimzi.io/topic-name"
+
+// KafkaTopicName returns the name of the Kafka topic managed by the KafkaTopic resource. It is taken from the
+// spec.topicName field, the strimzi.io/topic-name annotation, or the status.topicName field set by the Topic Operator.
+// When none of them is set, the Kafka topic has the same name as the resource.
+func KafkaTopicName(topic *v1beta2.KafkaTopic) string {
+	if topic.Spec != nil && topic.Spec.TopicName != "" {
+		return topic.Spec.TopicName
+	} else if name := topic.Annotations[TopicNameAnnotation]; name != "" {
+		return name
+	} else if topic.Status != nil && topic.Status.TopicName != "" {
+		return topic.Status.TopicName
+	}
+
+	return topic.Name
+}
+
+// PinKafkaTopicName sets the spec.topicName field of the KafkaTopic resource whose name differs from the name of the
+// Kafka topic (for example for topics with uppercase characters). Without it, the Topic Operator would create a topic
+// named after the resource when the KafkaTopic is recreated. It returns true when the field was set.
+func PinKafkaTopicName(topic *v1beta2.KafkaTopic) bool {
+	name := KafkaTopicName(topic)
+	if name == topic.Name || (topic.Spec != nil && topic.Spec.TopicName == name) {
+		return false
+	}
+
+	if topic.Spec == nil {
+		topic.Spec = &v1beta2.KafkaTopicSpec{}
+	}
+
+	topic.Spec.TopicName = name
+
+	return true
+}
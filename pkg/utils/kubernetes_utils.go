@@ -18,49 +18,239 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	kafkaapi "github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
 	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 )
 
-func CreateKubernetesClients(cmd *cobra.Command) (*kubernetes.Clientset, *strimzi.Clientset, string, error) {
+// TransientAnnotations are the annotations which are removed when cleansing the annotations of the Kafka, KafkaConnect,
+// KafkaMirrorMaker2, KafkaMirrorMaker, and KafkaBridge resources. They are one-off requests handled by the Cluster
+// Operator which would be acted on again after the restore. The last applied configuration is removed by
+// CleanseMetadata.
+var TransientAnnotations = []string{
+	"strimzi.io/restart",
+	"strimzi.io/restart-task",
+	"strimzi.io/restart-connector",
+	"strimzi.io/restart-connector-task",
+}
+
+// DefaultPreservedAnnotations are the annotations controlling the behavior of the Cluster Operator. They are not
+// transient and are kept by the cleansing anyway, but the list of preserved annotations can also keep any of the
+// transient annotations.
+var DefaultPreservedAnnotations = []string{
+	"strimzi.io/kraft",
+	"strimzi.io/node-pools",
+	"strimzi.io/manual-rolling-update",
+	"strimzi.io/pause-reconciliation",
+	"strimzi.io/skip-broker-scaledown-check",
+	"strimzi.io/use-connector-resources",
+}
+
+// FieldManager is the field manager used by strimzi-backup for all server-side apply requests
+const FieldManager = "strimzi-backup"
+
+func CreateKubernetesClients(cmd *cobra.Command) (*kubernetes.Clientset, *strimzi.Clientset, *dynamic.DynamicClient, string, error) {
 	kubeConfigFlag := cmd.Flag("kubeconfig").Value.String()
-	namespaceFlag := cmd.Flag("namespace").Value.String()
+	contextFlag := cmd.Flag("context").Value.String()
+	namespaceFlag, err := namespaceFromFlags(cmd)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
 
-	kubeConfig, kubeConfigNamespace, err := tryToFindKubeConfigAndCurrentNamespace(kubeConfigFlag)
+	kubeConfig, kubeConfigNamespace, err := tryToFindKubeConfigAndCurrentNamespace(kubeConfigFlag, contextFlag)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
+	}
+
+	if err := configureConnection(cmd, kubeConfig); err != nil {
+		return nil, nil, nil, "", err
 	}
 
 	namespace, err := determineNamespaceFromOptionOrKubeConfig(namespaceFlag, kubeConfigNamespace)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
 	}
 
 	kubeClient, err := createKubernetesClient(kubeConfig)
 	if err != nil {
 		slog.Error("Failed to create Kubernetes client", "error", err)
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
 	}
 
 	strimziClient, err := createStrimziClient(kubeConfig)
 	if err != nil {
 		slog.Error("Failed to create Strimzi client", "error", err)
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		slog.Error("Failed to create dynamic Kubernetes client", "error", err)
+		return nil, nil, nil, "", err
+	}
+
+	return kubeClient, strimziClient, dynamicClient, namespace, nil
+}
+
+// namespaceFromFlags returns the namespace from the --namespace option or, on commands which have it, from the
+// --target-namespace option. Both options can be used only when they point to the same namespace.
+func namespaceFromFlags(cmd *cobra.Command) (string, error) {
+	namespace := cmd.Flag("namespace").Value.String()
+
+	if targetNamespaceFlag := cmd.Flags().Lookup("target-namespace"); targetNamespaceFlag != nil && targetNamespaceFlag.Value.String() != "" {
+		targetNamespace := targetNamespaceFlag.Value.String()
+
+		if namespace != "" && namespace != targetNamespace {
+			slog.Error("The --namespace and --target-namespace options point to different namespaces", "namespace", namespace, "targetNamespace", targetNamespace)
+			return "", fmt.Errorf("the --namespace option (%s) and the --target-namespace option (%s) point to different namespaces", namespace, targetNamespace)
+		}
+
+		namespace = targetNamespace
+	}
+
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			slog.Error("Invalid namespace", "namespace", namespace, "errors", errs)
+			return "", fmt.Errorf("invalid namespace %s: %s", namespace, strings.Join(errs, ", "))
+		}
 	}
 
-	return kubeClient, strimziClient, namespace, nil
+	return namespace, nil
+}
+
+// AddConnectionFlags adds the flags used to tune the connection to the Kubernetes API server
+func AddConnectionFlags(flags *pflag.FlagSet) {
+	flags.String("context", "", "Name of the context from the kubeconfig file to use. If not specified, the current context is used.")
+	flags.Uint32("request-timeout", 0, "Timeout for a single Kubernetes API request. Set to 0 to disable the timeout. In milliseconds.")
+	flags.Uint32("tls-handshake-timeout", 10000, "Timeout for the TLS handshake with the Kubernetes API server. In milliseconds.")
+	flags.Uint32("keep-alive", 30000, "Interval of the TCP keep-alive probes sent to the Kubernetes API server. In milliseconds.")
+	flags.Bool("disable-http2", false, "Use HTTP/1.1 instead of HTTP/2 to connect to the Kubernetes API server")
+	flags.Float32("api-qps", 5, "Maximal average number of requests per second sent to the Kubernetes API server.")
+	flags.Int("api-burst", 10, "Maximal number of requests sent to the Kubernetes API server in a single burst.")
+}
+
+// Version returns the version of strimzi-backup
+func Version() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok || buildInfo.Main.Version == "" {
+		return "unknown"
+	}
+
+	return buildInfo.Main.Version
+}
+
+// configureConnection configures the user agent, the timeouts, and the protocol used to connect to the Kubernetes API
+// server
+func configureConnection(cmd *cobra.Command, kubeConfig *rest.Config) error {
+	requestTimeout, err := cmd.Flags().GetUint32("request-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --request-timeout flag", "error", err)
+		return err
+	}
+
+	tlsHandshakeTimeout, err := cmd.Flags().GetUint32("tls-handshake-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --tls-handshake-timeout flag", "error", err)
+		return err
+	}
+
+	keepAlive, err := cmd.Flags().GetUint32("keep-alive")
+	if err != nil {
+		slog.Error("Failed to get the --keep-alive flag", "error", err)
+		return err
+	}
+
+	disableHttp2, err := cmd.Flags().GetBool("disable-http2")
+	if err != nil {
+		slog.Error("Failed to get the --disable-http2 flag", "error", err)
+		return err
+	}
+
+	apiQps, err := cmd.Flags().GetFloat32("api-qps")
+	if err != nil {
+		slog.Error("Failed to get the --api-qps flag", "error", err)
+		return err
+	} else if apiQps <= 0 {
+		slog.Error("The --api-qps option has to be higher than 0", "apiQps", apiQps)
+		return fmt.Errorf("the --api-qps option has to be higher than 0")
+	}
+
+	apiBurst, err := cmd.Flags().GetInt("api-burst")
+	if err != nil {
+		slog.Error("Failed to get the --api-burst flag", "error", err)
+		return err
+	} else if apiBurst < 1 {
+		slog.Error("The --api-burst option has to be at least 1", "apiBurst", apiBurst)
+		return fmt.Errorf("the --api-burst option has to be at least 1")
+	}
+
+	kubeConfig.UserAgent = "strimzi-backup/" + Version()
+	kubeConfig.Timeout = time.Millisecond * time.Duration(requestTimeout)
+	// Rate-limits the requests so that restoring large numbers of resources does not overload the API server
+	kubeConfig.QPS = apiQps
+	kubeConfig.Burst = apiBurst
+	kubeConfig.Dial = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: time.Millisecond * time.Duration(keepAlive),
+	}).DialContext
+
+	// The read-only check is available only in the backup commands
+	var readOnly bool
+	if cmd.Flags().Lookup("read-only-check") != nil {
+		readOnly, err = cmd.Flags().GetBool("read-only-check")
+		if err != nil {
+			slog.Error("Failed to get the --read-only-check flag", "error", err)
+			return err
+		}
+	}
+
+	// The transport created by client-go might be shared => we change a copy of it
+	kubeConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if transport, ok := rt.(*http.Transport); ok {
+			transport = transport.Clone()
+			transport.TLSHandshakeTimeout = time.Millisecond * time.Duration(tlsHandshakeTimeout)
+			rt = transport
+		}
+
+		if readOnly {
+			// Guards against any modifications of the Kubernetes resources sneaking into the backup
+			return &readOnlyRoundTripper{delegate: rt}
+		}
+
+		return rt
+	}
+
+	if disableHttp2 {
+		kubeConfig.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+
+	return nil
+}
+
+// IsRunningInCluster indicates whether strimzi-backup runs inside a Kubernetes Pod (for example as a Job)
+func IsRunningInCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != ""
 }
 
 func createKubernetesClient(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
@@ -71,10 +261,31 @@ func createStrimziClient(kubeConfig *rest.Config) (*strimzi.Clientset, error) {
 	return strimzi.NewForConfig(kubeConfig)
 }
 
-func tryToFindKubeConfigAndCurrentNamespace(kubeConfigOption string) (*rest.Config, string, error) {
+func tryToFindKubeConfigAndCurrentNamespace(kubeConfigOption string, contextOption string) (*rest.Config, string, error) {
 	kubeConfigPath := tryToFindKubeConfigPath(kubeConfigOption)
 
-	if kubeConfigPath != "" {
+	if kubeConfigPath != "" && contextOption != "" {
+		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfigPath},
+			&clientcmd.ConfigOverrides{CurrentContext: contextOption},
+		)
+
+		config, err := clientConfig.ClientConfig()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to instantiate Kubernetes configuration for context %v from %v: %v", contextOption, kubeConfigPath, err)
+		}
+
+		// Try to get the namespace -> we might not need it, so we silence the errors
+		namespace, _, err := clientConfig.Namespace()
+		if err != nil {
+			slog.Debug("Failed to get default namespace of the context from kubeconfig file", "context", contextOption, "error", err)
+			namespace = ""
+		}
+
+		return config, namespace, nil
+	} else if contextOption != "" {
+		return nil, "", fmt.Errorf("the --context option cannot be used without a kubeconfig file")
+	} else if kubeConfigPath != "" {
 		// Create the config
 		config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
 		if err != nil {
@@ -151,26 +362,68 @@ func determineNamespaceFromOptionOrKubeConfig(namespaceOption string, kubeConfig
 	}
 }
 
-func WaitUntilReady(client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.Kafka, error) {
-	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+// WaitUntilReady waits until the Kafka cluster is ready. When the timeout expires while the Cluster Operator is still
+// making progress (for example rolling the Pods of a large cluster), the wait is extended until no progress has been
+// observed for the stall timeout. Setting the stall timeout to 0 disables the extension.
+func WaitUntilReady(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32, stallTimeout uint32) (*kafkaapi.Kafka, error) {
+	watchContext, watchContextCancel := context.WithCancel(context.Background())
 	defer watchContextCancel()
 
 	watcher, err := client.KafkaV1beta2().Kafkas(namespace).Watch(watchContext, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()})
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to watch the Kafka cluster %s in namespace %s: %v", name, namespace, err)
 	}
 
-	defer watcher.Stop()
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka cluster", name, namespace)
+	progress := newProgressTracker(kubeClient, name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
+	deadline := time.NewTimer(time.Millisecond * time.Duration(timeout))
+	defer deadline.Stop()
 
 	for {
 		select {
-		case event := <-watcher.ResultChan():
-			k := event.Object.(*kafkaapi.Kafka)
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the Kafka resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.KafkaV1beta2().Kafkas(namespace).Watch(watchContext, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()})
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka cluster %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			k, ok := event.Object.(*kafkaapi.Kafka)
+			if !ok {
+				continue
+			}
+
 			if IsReady(k) {
 				return k, nil
 			}
-		case <-watchContext.Done():
-			return nil, fmt.Errorf("timed out waiting for the Kafka cluster %s in namespace %s to be ready", name, namespace)
+
+			reporter.reportConditions(kafkaConditions(k))
+			progress.observeKafka(k)
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
+			progress.observePods(watchContext)
+		case <-deadline.C:
+			if stallTimeout > 0 {
+				if remaining := time.Until(progress.lastProgress.Add(time.Millisecond * time.Duration(stallTimeout))); remaining > 0 {
+					slog.Info("The Kafka cluster is not ready yet, but the Cluster Operator is making progress. Extending the timeout.", "name", name, "namespace", namespace, "lastProgress", progress.lastProgress.Format(time.RFC3339), "extension", remaining.Round(time.Second))
+					deadline.Reset(remaining)
+					continue
+				}
+			}
+
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka cluster %s in namespace %s to be ready", name, namespace))
 		}
 	}
 }
@@ -191,26 +444,57 @@ func IsReady(k *kafkaapi.Kafka) bool {
 	}
 }
 
-func WaitUntilReconciliationPaused(client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.Kafka, error) {
+func WaitUntilReconciliationPaused(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.Kafka, error) {
 	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
 	defer watchContextCancel()
 
 	watcher, err := client.KafkaV1beta2().Kafkas(namespace).Watch(watchContext, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()})
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to watch the Kafka cluster %s in namespace %s: %v", name, namespace, err)
 	}
 
-	defer watcher.Stop()
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka cluster", name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
 
 	for {
 		select {
-		case event := <-watcher.ResultChan():
-			k := event.Object.(*kafkaapi.Kafka)
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				if watchContext.Err() != nil {
+					return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka cluster %s in namespace %s to be paused", name, namespace))
+				}
+
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the Kafka resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.KafkaV1beta2().Kafkas(namespace).Watch(watchContext, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()})
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka cluster %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			// The error events (for example when the watch expires) do not contain the Kafka resource
+			k, ok := event.Object.(*kafkaapi.Kafka)
+			if !ok {
+				continue
+			}
+
 			if IsReconciliationPaused(k) {
 				return k, nil
 			}
+
+			reporter.reportConditions(kafkaConditions(k))
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
 		case <-watchContext.Done():
-			return nil, fmt.Errorf("timed out waiting for the Kafka cluster %s in namespace %s to be paused", name, namespace)
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka cluster %s in namespace %s to be paused", name, namespace))
 		}
 	}
 }
@@ -229,6 +513,239 @@ func IsReconciliationPaused(k *kafkaapi.Kafka) bool {
 	}
 }
 
+// kafkaConditions returns the conditions from the status of the Kafka resource
+func kafkaConditions(k *kafkaapi.Kafka) []kafkaapi.Condition {
+	if k.Status == nil {
+		return nil
+	}
+
+	return k.Status.Conditions
+}
+
+// WaitUntilConnectReady waits until the Kafka Connect cluster is ready
+func WaitUntilConnectReady(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaConnect, error) {
+	return waitForConnect(kubeClient, client, name, namespace, timeout, "ready", IsConnectReady)
+}
+
+// WaitUntilConnectReconciliationPaused waits until the Cluster Operator confirms that the reconciliation of the Kafka
+// Connect cluster is paused
+func WaitUntilConnectReconciliationPaused(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaConnect, error) {
+	return waitForConnect(kubeClient, client, name, namespace, timeout, "paused", IsConnectReconciliationPaused)
+}
+
+func waitForConnect(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32, state string, condition func(*kafkaapi.KafkaConnect) bool) (*kafkaapi.KafkaConnect, error) {
+	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	defer watchContextCancel()
+
+	listOptions := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()}
+	watcher, err := client.KafkaV1beta2().KafkaConnects(namespace).Watch(watchContext, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the Kafka Connect cluster %s in namespace %s: %v", name, namespace, err)
+	}
+
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka Connect cluster", name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				if watchContext.Err() != nil {
+					return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka Connect cluster %s in namespace %s to be %s", name, namespace, state))
+				}
+
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the KafkaConnect resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.KafkaV1beta2().KafkaConnects(namespace).Watch(watchContext, listOptions)
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka Connect cluster %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			c, ok := event.Object.(*kafkaapi.KafkaConnect)
+			if !ok {
+				continue
+			}
+
+			if condition(c) {
+				return c, nil
+			}
+
+			if c.Status != nil {
+				reporter.reportConditions(c.Status.Conditions)
+			}
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
+		case <-watchContext.Done():
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka Connect cluster %s in namespace %s to be %s", name, namespace, state))
+		}
+	}
+}
+
+func IsConnectReady(c *kafkaapi.KafkaConnect) bool {
+	if c.Status == nil {
+		return false
+	}
+
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" && c.Status.ObservedGeneration == c.ObjectMeta.Generation {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsConnectReconciliationPaused(c *kafkaapi.KafkaConnect) bool {
+	if c.Status == nil {
+		return false
+	}
+
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == "ReconciliationPaused" && condition.Status == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WaitUntilMirrorMaker2Ready waits until the Kafka MirrorMaker 2 cluster is ready
+func WaitUntilMirrorMaker2Ready(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaMirrorMaker2, error) {
+	return waitForMirrorMaker2(kubeClient, client, name, namespace, timeout, "ready", IsMirrorMaker2Ready)
+}
+
+// WaitUntilMirrorMaker2ReconciliationPaused waits until the Cluster Operator confirms that the reconciliation of the
+// Kafka MirrorMaker 2 cluster is paused
+func WaitUntilMirrorMaker2ReconciliationPaused(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32) (*kafkaapi.KafkaMirrorMaker2, error) {
+	return waitForMirrorMaker2(kubeClient, client, name, namespace, timeout, "paused", IsMirrorMaker2ReconciliationPaused)
+}
+
+func waitForMirrorMaker2(kubeClient *kubernetes.Clientset, client *strimzi.Clientset, name string, namespace string, timeout uint32, state string, condition func(*kafkaapi.KafkaMirrorMaker2) bool) (*kafkaapi.KafkaMirrorMaker2, error) {
+	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	defer watchContextCancel()
+
+	listOptions := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()}
+	watcher, err := client.KafkaV1beta2().KafkaMirrorMaker2s(namespace).Watch(watchContext, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the Kafka MirrorMaker 2 cluster %s in namespace %s: %v", name, namespace, err)
+	}
+
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka MirrorMaker 2 cluster", name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				if watchContext.Err() != nil {
+					return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka MirrorMaker 2 cluster %s in namespace %s to be %s", name, namespace, state))
+				}
+
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the KafkaMirrorMaker2 resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.KafkaV1beta2().KafkaMirrorMaker2s(namespace).Watch(watchContext, listOptions)
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka MirrorMaker 2 cluster %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			m, ok := event.Object.(*kafkaapi.KafkaMirrorMaker2)
+			if !ok {
+				continue
+			}
+
+			if condition(m) {
+				return m, nil
+			}
+
+			if m.Status != nil {
+				reporter.reportConditions(m.Status.Conditions)
+			}
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
+		case <-watchContext.Done():
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka MirrorMaker 2 cluster %s in namespace %s to be %s", name, namespace, state))
+		}
+	}
+}
+
+func IsMirrorMaker2Ready(m *kafkaapi.KafkaMirrorMaker2) bool {
+	if m.Status == nil {
+		return false
+	}
+
+	for _, condition := range m.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" && m.Status.ObservedGeneration == m.ObjectMeta.Generation {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsMirrorMaker2ReconciliationPaused(m *kafkaapi.KafkaMirrorMaker2) bool {
+	if m.Status == nil {
+		return false
+	}
+
+	for _, condition := range m.Status.Conditions {
+		if condition.Type == "ReconciliationPaused" && condition.Status == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UsesNodePools indicates whether the Kafka cluster uses Kafka Node Pools. Strimzi 0.46 and newer use node pools for all
+// Kafka clusters and do not set the strimzi.io/node-pools annotation anymore, so the cluster is considered a legacy
+// cluster configuring its nodes directly in the Kafka resource only when it has no KafkaNodePool resources.
+func UsesNodePools(ctx context.Context, client *strimzi.Clientset, k *kafkaapi.Kafka) (bool, error) {
+	if k.Annotations["strimzi.io/node-pools"] == "enabled" {
+		return true, nil
+	}
+
+	nodePools, err := client.KafkaV1beta2().KafkaNodePools(k.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + k.Name})
+	if err != nil {
+		return false, err
+	}
+
+	return len(nodePools.Items) > 0, nil
+}
+
+// UsesBootstrapService indicates whether the bootstrap servers of a resource in the given namespace use the bootstrap
+// service of the Kafka cluster with the given name and namespace. The bootstrap service without the namespace is used
+// only by resources in the same namespace as the Kafka cluster.
+func UsesBootstrapService(bootstrapServers string, resourceNamespace string, name string, namespace string) bool {
+	for _, bootstrapServer := range strings.Split(bootstrapServers, ",") {
+		host, _, _ := strings.Cut(strings.TrimSpace(bootstrapServer), ":")
+		service, hostNamespace, _ := strings.Cut(host, ".")
+
+		if service == name+"-kafka-bootstrap" && ((hostNamespace == "" && resourceNamespace == namespace) || strings.HasPrefix(hostNamespace+".", namespace+".")) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func CleanseMetadata(metadata *metav1.ObjectMeta) {
 	metadata.ResourceVersion = ""
 	metadata.CreationTimestamp = metav1.Time{}
@@ -243,3 +760,26 @@ func CleanseMetadata(metadata *metav1.ObjectMeta) {
 		delete(metadata.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
 	}
 }
+
+// Apply creates or updates the resource using the server-side apply with the strimzi-backup field manager. The resource
+// has to have the apiVersion and kind set. With force, the conflicts with the fields owned by other field managers (for
+// example Argo CD or Flux) are resolved by taking over the ownership of the fields.
+func Apply[T any](patch func(context.Context, string, types.PatchType, []byte, metav1.PatchOptions, ...string) (T, error), name string, resource any, force bool, subresources ...string) (T, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		var empty T
+		return empty, fmt.Errorf("failed to marshal resource %s for server-side apply: %v", name, err)
+	}
+
+	return patch(context.TODO(), name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager, Force: &force}, subresources...)
+}
+
+// CleanseAnnotations removes the transient annotations which are not in the list of preserved annotations. All other
+// annotations, including any other Strimzi annotations, are kept untouched.
+func CleanseAnnotations(metadata *metav1.ObjectMeta, preserved []string) {
+	for _, annotation := range TransientAnnotations {
+		if !slices.Contains(preserved, annotation) {
+			delete(metadata.Annotations, annotation)
+		}
+	}
+}
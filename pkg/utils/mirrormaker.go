@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	kafkaapi "github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"time"
+)
+
+// KafkaMirrorMakerResource is the legacy KafkaMirrorMaker custom resource. It was removed from Strimzi and is not part
+// of the Strimzi API used by strimzi-backup, so it is handled using the dynamic client.
+var KafkaMirrorMakerResource = schema.GroupVersionResource{Group: "kafka.strimzi.io", Version: "v1beta2", Resource: "kafkamirrormakers"}
+
+// WaitUntilMirrorMakerReady waits until the legacy Kafka MirrorMaker cluster is ready
+func WaitUntilMirrorMakerReady(kubeClient *kubernetes.Clientset, client *dynamic.DynamicClient, name string, namespace string, timeout uint32) (*unstructured.Unstructured, error) {
+	return waitForMirrorMaker(kubeClient, client, name, namespace, timeout, "ready", IsMirrorMakerReady)
+}
+
+// WaitUntilMirrorMakerReconciliationPaused waits until the Cluster Operator confirms that the reconciliation of the
+// legacy Kafka MirrorMaker cluster is paused
+func WaitUntilMirrorMakerReconciliationPaused(kubeClient *kubernetes.Clientset, client *dynamic.DynamicClient, name string, namespace string, timeout uint32) (*unstructured.Unstructured, error) {
+	return waitForMirrorMaker(kubeClient, client, name, namespace, timeout, "paused", IsMirrorMakerReconciliationPaused)
+}
+
+func waitForMirrorMaker(kubeClient *kubernetes.Clientset, client *dynamic.DynamicClient, name string, namespace string, timeout uint32, state string, condition func(*unstructured.Unstructured) bool) (*unstructured.Unstructured, error) {
+	watchContext, watchContextCancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(timeout))
+	defer watchContextCancel()
+
+	listOptions := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, name).String()}
+	watcher, err := client.Resource(KafkaMirrorMakerResource).Namespace(namespace).Watch(watchContext, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the Kafka MirrorMaker cluster %s in namespace %s: %v", name, namespace, err)
+	}
+
+	defer func() {
+		watcher.Stop()
+	}()
+
+	reporter := newWaitReporter(kubeClient, "Kafka MirrorMaker cluster", name, namespace)
+	eventsTicker := time.NewTicker(waitEventsInterval)
+	defer eventsTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				if watchContext.Err() != nil {
+					return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka MirrorMaker cluster %s in namespace %s to be %s", name, namespace, state))
+				}
+
+				// The API server closes long-running watches => we have to start a new one
+				slog.Debug("Restarting the watch of the KafkaMirrorMaker resource", "name", name, "namespace", namespace)
+
+				watcher, err = client.Resource(KafkaMirrorMakerResource).Namespace(namespace).Watch(watchContext, listOptions)
+				if err != nil {
+					return nil, reporter.wrap(fmt.Errorf("failed to watch the Kafka MirrorMaker cluster %s in namespace %s: %v", name, namespace, err))
+				}
+
+				continue
+			}
+
+			m, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+
+			if condition(m) {
+				return m, nil
+			}
+
+			reporter.reportConditions(mirrorMakerConditions(m))
+		case <-eventsTicker.C:
+			reporter.reportEvents(watchContext)
+		case <-watchContext.Done():
+			return nil, reporter.wrap(fmt.Errorf("timed out waiting for the Kafka MirrorMaker cluster %s in namespace %s to be %s", name, namespace, state))
+		}
+	}
+}
+
+// mirrorMakerConditions returns the conditions from the status of the legacy KafkaMirrorMaker resource
+func mirrorMakerConditions(m *unstructured.Unstructured) []kafkaapi.Condition {
+	status, found, _ := unstructured.NestedMap(m.Object, "status")
+	if !found {
+		return nil
+	}
+
+	var kafkaStatus kafkaapi.KafkaMirrorMaker2Status
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(status, &kafkaStatus); err != nil {
+		slog.Debug("Failed to convert the status of the KafkaMirrorMaker resource", "name", m.GetName(), "error", err)
+		return nil
+	}
+
+	return kafkaStatus.Conditions
+}
+
+func IsMirrorMakerReady(m *unstructured.Unstructured) bool {
+	observedGeneration, _, _ := unstructured.NestedInt64(m.Object, "status", "observedGeneration")
+
+	for _, condition := range mirrorMakerConditions(m) {
+		if condition.Type == "Ready" && condition.Status == "True" && observedGeneration == m.GetGeneration() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsMirrorMakerReconciliationPaused(m *unstructured.Unstructured) bool {
+	for _, condition := range mirrorMakerConditions(m) {
+		if condition.Type == "ReconciliationPaused" && condition.Status == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnstructuredObjectMeta returns the metadata of the unstructured resource, so that it can be handled the same way as
+// the metadata of the typed resources
+func UnstructuredObjectMeta(resource *unstructured.Unstructured) (*metav1.ObjectMeta, error) {
+	var metadata metav1.ObjectMeta
+
+	rawMetadata, _, err := unstructured.NestedMap(resource.Object, "metadata")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawMetadata, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// SetUnstructuredObjectMeta replaces the metadata of the unstructured resource
+func SetUnstructuredObjectMeta(resource *unstructured.Unstructured, metadata *metav1.ObjectMeta) error {
+	rawMetadata, err := runtime.DefaultUnstructuredConverter.ToUnstructured(metadata)
+	if err != nil {
+		return err
+	}
+
+	// The conversion adds the empty creation timestamp
+	if metadata.CreationTimestamp.IsZero() {
+		delete(rawMetadata, "creationTimestamp")
+	}
+
+	resource.Object["metadata"] = rawMetadata
+
+	return nil
+}
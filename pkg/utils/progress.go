@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	kafkaapi "github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// progressTracker tracks whether the Cluster Operator is making progress with the Kafka cluster. Changes to the
+// observed generation or the conditions of the Kafka resource and Pods of the Kafka cluster being created, replaced, or
+// becoming ready count as progress. Pods restarting in a crash loop do not count as progress.
+type progressTracker struct {
+	kubeClient   *kubernetes.Clientset
+	name         string
+	namespace    string
+	lastProgress time.Time
+	kafka        string
+	pods         string
+}
+
+func newProgressTracker(kubeClient *kubernetes.Clientset, name string, namespace string) *progressTracker {
+	return &progressTracker{
+		kubeClient:   kubeClient,
+		name:         name,
+		namespace:    namespace,
+		lastProgress: time.Now(),
+	}
+}
+
+// observeKafka records progress when the status of the Kafka resource changed
+func (p *progressTracker) observeKafka(k *kafkaapi.Kafka) {
+	if k.Status == nil {
+		return
+	}
+
+	var state strings.Builder
+	state.WriteString(fmt.Sprintf("%d", k.Status.ObservedGeneration))
+	for _, condition := range k.Status.Conditions {
+		state.WriteString("/" + condition.Type + "=" + string(condition.Status) + ":" + condition.Reason)
+	}
+
+	if p.kafka != "" && p.kafka != state.String() {
+		slog.Debug("The status of the Kafka resource changed", "name", p.name, "namespace", p.namespace)
+		p.lastProgress = time.Now()
+	}
+	p.kafka = state.String()
+}
+
+// observePods records progress when the Pods of the Kafka cluster were created, replaced, deleted, or changed their
+// phase or readiness
+func (p *progressTracker) observePods(ctx context.Context) {
+	if p.kubeClient == nil {
+		return
+	}
+
+	pods, err := p.kubeClient.CoreV1().Pods(p.namespace).List(ctx, metav1.ListOptions{LabelSelector: "strimzi.io/cluster=" + p.name})
+	if err != nil {
+		slog.Debug("Failed to list the Pods of the Kafka cluster", "name", p.name, "namespace", p.namespace, "error", err)
+		return
+	}
+
+	var states []string
+	for _, pod := range pods.Items {
+		ready := v1.ConditionFalse
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodReady {
+				ready = condition.Status
+			}
+		}
+
+		states = append(states, string(pod.UID)+"/"+string(pod.Status.Phase)+"/"+string(ready))
+	}
+	sort.Strings(states)
+
+	state := strings.Join(states, ",")
+	if p.pods != "" && p.pods != state {
+		slog.Debug("The Pods of the Kafka cluster changed", "name", p.name, "namespace", p.namespace)
+		p.lastProgress = time.Now()
+	}
+	p.pods = state
+}
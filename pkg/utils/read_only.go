@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// readOnlyRoundTripper rejects all Kubernetes API requests which could modify the resources. Only the requests reading
+// the resources and the access reviews used to check the permissions are sent to the Kubernetes API server.
+type readOnlyRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func (rt *readOnlyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return rt.delegate.RoundTrip(req)
+	case http.MethodPost:
+		if strings.HasSuffix(req.URL.Path, "/selfsubjectaccessreviews") {
+			return rt.delegate.RoundTrip(req)
+		}
+	}
+
+	slog.Error("Rejecting the Kubernetes API request as only reading the resources is allowed when using the --read-only-check option", "method", req.Method, "path", req.URL.Path)
+	return nil, fmt.Errorf("the %s request to %s was rejected because only reading the resources is allowed when using the --read-only-check option", req.Method, req.URL.Path)
+}
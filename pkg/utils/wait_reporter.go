@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	kafkaapi "github.com/scholzj/strimzi-go/pkg/apis/kafka.strimzi.io/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// waitEventsInterval is how often the Kubernetes Events are checked while waiting for the Kafka or Kafka Connect
+	// cluster
+	waitEventsInterval = 10 * time.Second
+	// maxReportedEvents is the maximal number of the recent Events included in the error when the wait times out
+	maxReportedEvents = 5
+)
+
+// waitReporter logs the problems reported in the conditions of the Kafka resource and the warning Events of the Kafka
+// cluster and its Pods, PVCs, and other resources while waiting for the Kafka cluster. That way, users see why the
+// Kafka cluster is not getting ready without having to check the Cluster Operator logs. It is used in the same way when
+// waiting for the Kafka Connect clusters.
+type waitReporter struct {
+	kubeClient     *kubernetes.Clientset
+	description    string
+	name           string
+	namespace      string
+	since          time.Time
+	lastConditions map[string]bool
+	conditions     []string
+	seenEvents     map[string]int32
+	events         []string
+}
+
+func newWaitReporter(kubeClient *kubernetes.Clientset, description string, name string, namespace string) *waitReporter {
+	return &waitReporter{
+		kubeClient:  kubeClient,
+		description: description,
+		name:        name,
+		namespace:   namespace,
+		since:       time.Now(),
+		seenEvents:  map[string]int32{},
+	}
+}
+
+// reportConditions logs the NotReady and Warning conditions of the Kafka or KafkaConnect resource when they change
+func (r *waitReporter) reportConditions(statusConditions []kafkaapi.Condition) {
+	var conditions []string
+	current := map[string]bool{}
+	for _, condition := range statusConditions {
+		if (condition.Type != "NotReady" && condition.Type != "Warning") || condition.Status != "True" {
+			continue
+		}
+
+		key := condition.Type + "/" + condition.Reason + "/" + condition.Message
+		current[key] = true
+		conditions = append(conditions, fmt.Sprintf("%s (%s): %s", condition.Type, condition.Reason, condition.Message))
+
+		if r.lastConditions[key] {
+			// Already reported
+			continue
+		}
+
+		if condition.Type == "NotReady" && (condition.Reason == "" || condition.Reason == "Creating") {
+			// The cluster is still being deployed, which is not a problem
+			slog.Info("The "+r.description+" is not ready yet", "name", r.name, "namespace", r.namespace, "reason", condition.Reason, "message", condition.Message)
+		} else {
+			slog.Warn("The Cluster Operator reported a problem with the "+r.description, "name", r.name, "namespace", r.namespace, "condition", condition.Type, "reason", condition.Reason, "message", condition.Message)
+		}
+	}
+
+	r.lastConditions = current
+	r.conditions = conditions
+}
+
+// reportEvents logs the new warning Events related to the Kafka cluster which happened since the wait started. The
+// Events are matched by the name of the involved object, which starts with the name of the Kafka cluster for all
+// resources created by the Cluster Operator.
+func (r *waitReporter) reportEvents(ctx context.Context) {
+	if r.kubeClient == nil {
+		return
+	}
+
+	events, err := r.kubeClient.CoreV1().Events(r.namespace).List(ctx, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("type", v1.EventTypeWarning).String()})
+	if err != nil {
+		slog.Debug("Failed to list the Kubernetes Events", "namespace", r.namespace, "error", err)
+		return
+	}
+
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name != r.name && !strings.HasPrefix(event.InvolvedObject.Name, r.name+"-") {
+			continue
+		}
+
+		if eventTime(event).Before(r.since) {
+			continue
+		}
+
+		if count, seen := r.seenEvents[string(event.UID)]; seen && count == event.Count {
+			continue
+		}
+		r.seenEvents[string(event.UID)] = event.Count
+
+		slog.Warn("Kubernetes Event", "kind", event.InvolvedObject.Kind, "name", event.InvolvedObject.Name, "namespace", r.namespace, "reason", event.Reason, "message", event.Message)
+
+		r.events = append(r.events, fmt.Sprintf("%s %s: %s: %s", event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, event.Message))
+		if len(r.events) > maxReportedEvents {
+			r.events = r.events[len(r.events)-maxReportedEvents:]
+		}
+	}
+}
+
+// wrap adds the last reported conditions and the recent Events to the error, so that the reason why the Kafka cluster
+// did not get ready is part of the final error
+func (r *waitReporter) wrap(err error) error {
+	var details []string
+	if len(r.conditions) > 0 {
+		details = append(details, "conditions: "+strings.Join(r.conditions, "; "))
+	}
+	if len(r.events) > 0 {
+		details = append(details, "recent events: "+strings.Join(r.events, "; "))
+	}
+
+	if len(details) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%v (%s)", err, strings.Join(details, ", "))
+}
+
+// eventTime returns the time when the Event happened last
+func eventTime(event v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	} else if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+
+	return event.CreationTimestamp.Time
+}
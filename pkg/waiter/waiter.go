@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 Jakub Scholz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package waiter
+
+import (
+	"fmt"
+	"github.com/scholzj/strimzi-backup/pkg/utils"
+	strimzi "github.com/scholzj/strimzi-go/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"log/slog"
+)
+
+const (
+	// ForReady waits until the Kafka cluster is ready
+	ForReady = "ready"
+	// ForPaused waits until the reconciliation of the Kafka cluster is paused
+	ForPaused = "paused"
+)
+
+type Waiter struct {
+	KubernetesClient *kubernetes.Clientset
+	StrimziClient    *strimzi.Clientset
+	Namespace        string
+	Name             string
+	For              string
+	Timeout          uint32
+	StallTimeout     uint32
+}
+
+func NewWaiter(cmd *cobra.Command) (*Waiter, error) {
+	name := cmd.Flag("name").Value.String()
+	if name == "" {
+		slog.Error("--name option is required")
+		return nil, fmt.Errorf("--name option is required")
+	}
+
+	waitFor := cmd.Flag("for").Value.String()
+	if waitFor != ForReady && waitFor != ForPaused {
+		slog.Error("Unsupported condition in the --for option", "for", waitFor)
+		return nil, fmt.Errorf("unsupported condition %s in the --for option (supported are %s and %s)", waitFor, ForReady, ForPaused)
+	}
+
+	timeout, err := cmd.Flags().GetUint32("timeout")
+	if err != nil {
+		slog.Error("Failed to get the --timeout flag", "error", err)
+		return nil, err
+	}
+
+	stallTimeout, err := cmd.Flags().GetUint32("stall-timeout")
+	if err != nil {
+		slog.Error("Failed to get the --stall-timeout flag", "error", err)
+		return nil, err
+	}
+
+	kubeClient, strimziClient, _, namespace, err := utils.CreateKubernetesClients(cmd)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes clients", "error", err)
+		return nil, err
+	}
+
+	waiter := Waiter{
+		KubernetesClient: kubeClient,
+		StrimziClient:    strimziClient,
+		Namespace:        namespace,
+		Name:             name,
+		For:              waitFor,
+		Timeout:          timeout,
+		StallTimeout:     stallTimeout,
+	}
+
+	return &waiter, nil
+}
+
+// Wait waits until the Kafka cluster is ready or until its reconciliation is paused using the same wait as the restore
+func (w *Waiter) Wait() error {
+	var err error
+	if w.For == ForPaused {
+		_, err = utils.WaitUntilReconciliationPaused(w.KubernetesClient, w.StrimziClient, w.Name, w.Namespace, w.Timeout)
+	} else {
+		_, err = utils.WaitUntilReady(w.KubernetesClient, w.StrimziClient, w.Name, w.Namespace, w.Timeout, w.StallTimeout)
+	}
+
+	return err
+}